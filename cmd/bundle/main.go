@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"ezzygo/pkg/cms"
+	"ezzygo/pkg/database"
+	"ezzygo/pkg/events"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// bundle moves CMS content between environments:
+//
+//	bundle export [-types content,templates] [-locale es] [-format csv] [-o file]
+//	bundle import [-dry-run] [-conflict skip|overwrite|fail] [-format csv -type content] file
+//
+// Export writes to stdout unless -o is given. Import prints the report as
+// JSON. Its events are recorded in the outbox and published by the server.
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	db := database.NewDatabase()
+	if err := cms.AutoMigrate(db); err != nil {
+		log.Fatal(err)
+	}
+	outbox := events.NewOutbox(db, nil, nil, logger)
+	service := cms.NewBundleService(db, cms.NewLocaleConfig(), cms.NewVersionService(db, outbox), outbox)
+
+	switch os.Args[1] {
+	case "export":
+		exportBundle(service, os.Args[2:])
+	case "import":
+		importBundle(service, os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bundle export|import [flags]")
+	os.Exit(2)
+}
+
+func exportBundle(service *cms.BundleService, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	types := flags.String("types", "", "comma separated sections: "+strings.Join(cms.BundleKinds, ", "))
+	locale := flags.String("locale", "", "only export content in this locale")
+	format := flags.String("format", "json", "json or csv (csv needs a single type)")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	flags.Parse(args)
+
+	var kinds []string
+	if *types != "" {
+		kinds = strings.Split(*types, ",")
+	}
+	if *format == "csv" && len(kinds) != 1 {
+		log.Fatal("csv export needs a single type")
+	}
+
+	bundle, err := service.Export(context.Background(), cms.BundleFilter{Kinds: kinds, Locale: *locale})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if *format == "csv" {
+		err = cms.WriteCSV(out, bundle, kinds[0])
+	} else {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(bundle)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func importBundle(service *cms.BundleService, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report the changes without writing them")
+	conflict := flags.String("conflict", cms.ConflictSkip, "skip, overwrite or fail on existing items")
+	format := flags.String("format", "json", "json or csv")
+	kind := flags.String("type", "", "section of a csv file: content or media")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal("import needs a bundle file")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	var bundle *cms.Bundle
+	if *format == "csv" {
+		bundle, err = cms.ReadCSV(file, *kind)
+	} else {
+		err = json.NewDecoder(file).Decode(&bundle)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := service.Import(context.Background(), bundle, cms.ImportOptions{DryRun: *dryRun, Conflict: *conflict})
+	if err != nil {
+		log.Fatal(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
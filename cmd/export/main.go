@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"ezzygo/pkg/cms"
+	"ezzygo/pkg/database"
+	"ezzygo/pkg/storage"
+	"flag"
+	"log"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// export renders the published CMS content to a static site. By default it
+// only regenerates the pages affected by changes since the last export; the
+// destination is -dir, or the S3_BUCKET storage under -prefix, falling back
+// to the same STATIC_EXPORT_* settings as the server.
+func main() {
+	dir := flag.String("dir", "", "write the site to this directory")
+	prefix := flag.String("prefix", "", "write the site to the S3_BUCKET storage under this prefix")
+	full := flag.Bool("full", false, "regenerate every page")
+	flag.Parse()
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	var target cms.SiteTarget
+	switch {
+	case *dir != "":
+		target = cms.NewDirTarget(*dir)
+	case *prefix != "":
+		s3Storage, err := storage.NewS3Storage(os.Getenv("S3_BUCKET"))
+		if err != nil {
+			logger.Fatal("Failed to configure storage", zap.Error(err))
+		}
+		target = cms.NewStorageTarget(s3Storage, *prefix)
+	default:
+		target = cms.NewSiteTargetFromEnv(nil)
+	}
+	if target == nil {
+		log.Fatal("no destination: use -dir or -prefix, or set STATIC_EXPORT_DIR")
+	}
+
+	db := database.NewDatabase()
+	if err := cms.AutoMigrate(db); err != nil {
+		log.Fatal(err)
+	}
+
+	locales := cms.NewLocaleConfig()
+	exporter := cms.NewStaticExporter(db, target, locales, cms.NewSEOService(db, locales), logger)
+	result, err := exporter.Export(context.Background(), *full)
+	if err != nil {
+		logger.Fatal("Failed to export static site", zap.Error(err))
+	}
+	logger.Info("Static site exported",
+		zap.Int("pages", result.Pages),
+		zap.Int("written", result.Written),
+		zap.Int("unchanged", result.Unchanged),
+		zap.Int("removed", result.Removed),
+		zap.Int("assets", result.Assets),
+		zap.Strings("errors", result.Errors))
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
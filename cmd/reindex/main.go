@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"ezzygo/pkg/cms"
+	"ezzygo/pkg/database"
+	"log"
+
+	"go.uber.org/zap"
+)
+
+// reindex rebuilds the CMS search index from the database. It uses the same
+// CMS_SEARCH_* settings as the server and is meant to be run after switching
+// engines or when the index has drifted.
+func main() {
+	db := database.NewDatabase()
+	if err := cms.AutoMigrate(db); err != nil {
+		log.Fatal(err)
+	}
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	index, err := cms.NewSearchIndex(db)
+	if err != nil {
+		logger.Fatal("Failed to configure CMS search index", zap.Error(err))
+	}
+
+	count, err := cms.NewSearchIndexer(db, index, logger).Reindex(context.Background())
+	if err != nil {
+		logger.Fatal("Failed to reindex CMS content", zap.Int("indexed", count), zap.Error(err))
+	}
+	logger.Info("Search index rebuilt", zap.Int("indexed", count))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"ezzygo/pkg/api"
 	"ezzygo/pkg/cache"
+	"ezzygo/pkg/cms"
 	"ezzygo/pkg/database"
 	"log"
 
@@ -40,6 +41,9 @@ import (
 func main() {
 	redisClient := cache.NewRedisClient()
 	db := database.NewDatabase()
+	if err := cms.AutoMigrate(db); err != nil {
+		log.Fatal(err)
+	}
 	dbWrapper := &database.GormDatabase{DB: db}
 	mongo := database.SetupMongoDB()
 	ctx := context.Background()
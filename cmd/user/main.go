@@ -0,0 +1,42 @@
+package main
+
+import (
+	"ezzygo/pkg/database"
+	"ezzygo/pkg/models"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// user manages user roles from the command line:
+//
+//	user role <username> author|editor|reviewer|admin
+//
+// It talks to the database directly, so it is how the first admin is set up;
+// after that admins can change roles through PUT /users/{username}/role.
+func main() {
+	if len(os.Args) != 4 || os.Args[1] != "role" {
+		usage()
+	}
+	username, role := os.Args[2], os.Args[3]
+	if !slices.Contains(models.Roles, role) {
+		log.Fatalf("invalid role %q, expected one of %s", role, strings.Join(models.Roles, ", "))
+	}
+
+	db := database.NewDatabase()
+	result := db.Model(&models.User{}).Where("username = ?", username).Update("role", role)
+	if result.Error != nil {
+		log.Fatal(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		log.Fatalf("user %q not found, register it first", username)
+	}
+	fmt.Printf("%s is now %s\n", username, role)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: user role <username> "+strings.Join(models.Roles, "|"))
+	os.Exit(2)
+}
@@ -2,12 +2,11 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"ezzygo/pkg/cache"
 	"ezzygo/pkg/database"
 	"ezzygo/pkg/models"
+	"ezzygo/pkg/query"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,19 +21,35 @@ type BookRepository interface {
 	DeleteBook(c *gin.Context)
 }
 
-// bookRepository holds shared resources like database and Redis client
+// booksTag groups every cached page of the book list so writes can drop them
+// all at once.
+const booksTag = "books"
+
+// bookQuery whitelists the fields the book list can be filtered and sorted by.
+var bookQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.Int, Sortable: true},
+		"title":      {Column: "title", Type: query.String, Sortable: true},
+		"author":     {Column: "author", Type: query.String, Sortable: true},
+		"created_at": {Column: "created_at", Type: query.Time, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: query.Time, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Field: "created_at", Desc: true}},
+}
+
+// bookRepository holds shared resources like database and cache
 type bookRepository struct {
-	DB          database.Database
-	RedisClient cache.Cache
-	Ctx         *context.Context
+	DB    database.Database
+	Cache *cache.Store
+	Ctx   *context.Context
 }
 
 // NewAppContext creates a new AppContext
-func NewBookRepository(db database.Database, redisClient cache.Cache, ctx *context.Context) *bookRepository {
+func NewBookRepository(db database.Database, store *cache.Store, ctx *context.Context) *bookRepository {
 	return &bookRepository{
-		DB:          db,
-		RedisClient: redisClient,
-		Ctx:         ctx,
+		DB:    db,
+		Cache: store,
+		Ctx:   ctx,
 	}
 }
 
@@ -54,66 +69,38 @@ func (r *bookRepository) Healthcheck(c *gin.Context) {
 }
 
 // FindBooks godoc
-// @Summary Get all books with pagination
-// @Description Get a list of all books with optional pagination
+// @Summary Get all books with cursor pagination
+// @Description Get a page of books. Filters use the field[op]=value form, e.g. title[contains]=go or created_at[gte]=2024-01-01
 // @Tags books
 // @Security ApiKeyAuth
 // @Produce json
-// @Param offset query int false "Offset for pagination" default(0)
-// @Param limit query int false "Limit for pagination" default(10)
-// @Success 200 {array} models.Book "Successfully retrieved list of books"
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of a previous page"
+// @Param sort query string false "Comma separated fields, prefixed with - for descending" default(-created_at)
+// @Param total query bool false "Include the number of matching books"
+// @Param fields query string false "Comma separated fields to return, e.g. title,author"
+// @Success 200 {object} query.Page[models.Book] "Successfully retrieved a page of books"
+// @Failure 400 {string} string "Invalid list parameters"
 // @Router /books [get]
 func (r *bookRepository) FindBooks(c *gin.Context) {
-	var books []models.Book
-
-	// Get query params
-	offsetQuery := c.DefaultQuery("offset", "0")
-	limitQuery := c.DefaultQuery("limit", "10")
-
-	// Convert query params to integers
-	offset, err := strconv.Atoi(offsetQuery)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+	params, ok := parseListParams(c, bookQuery, &models.Book{})
+	if !ok {
 		return
 	}
 
-	limit, err := strconv.Atoi(limitQuery)
+	// Serve from cache; concurrent misses share a single query
+	page, err := cache.GetOrLoad(c.Request.Context(), r.Cache, "books:"+params.Key(), cache.Options[*query.Page[models.Book]]{
+		TTL:  time.Minute,
+		Tags: []string{booksTag},
+	}, func(ctx context.Context) (*query.Page[models.Book], error) {
+		return query.Find[models.Book](ctx, r.DB.Model(&models.Book{}), params, bookQuery)
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		writeListError(c, err)
 		return
 	}
 
-	// Create a cache key based on query params
-	cacheKey := "books_offset_" + offsetQuery + "_limit_" + limitQuery
-
-	// Try fetching the data from Redis first
-	cachedBooks, err := r.RedisClient.Get(*r.Ctx, cacheKey).Result()
-	if err == nil {
-		err := json.Unmarshal([]byte(cachedBooks), &books)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"data": books})
-		return
-	}
-
-	// If cache missed, fetch data from the database
-	r.DB.Offset(offset).Limit(limit).Find(&books)
-
-	// Serialize books object and store it in Redis
-	serializedBooks, err := json.Marshal(books)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
-		return
-	}
-	err = r.RedisClient.Set(*r.Ctx, cacheKey, serializedBooks, time.Minute).Err() // Here TTL is set to one hour
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cache"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"data": books})
+	writePage(c, page, params.Fields)
 }
 
 // CreateBook godoc
@@ -147,13 +134,7 @@ func (r *bookRepository) CreateBook(c *gin.Context) {
 	appCtx.DB.Create(&book)
 
 	// Invalidate cache
-	keysPattern := "books_offset_*"
-	keys, err := appCtx.RedisClient.Keys(*appCtx.Ctx, keysPattern).Result()
-	if err == nil {
-		for _, key := range keys {
-			appCtx.RedisClient.Del(*appCtx.Ctx, key)
-		}
-	}
+	appCtx.invalidateBooks(c)
 
 	c.JSON(http.StatusCreated, gin.H{"data": book})
 }
@@ -165,18 +146,38 @@ func (r *bookRepository) CreateBook(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Produce json
 // @Param id path string true "Book ID"
+// @Param fields query string false "Comma separated fields to return, e.g. title,author"
 // @Success 200 {object} models.Book "Successfully retrieved book"
+// @Failure 400 {string} string "Unknown field"
 // @Failure 404 {string} string "Book not found"
 // @Router /books/{id} [get]
 func (r *bookRepository) FindBook(c *gin.Context) {
 	var book models.Book
 
-	if err := r.DB.Where("id = ?", c.Param("id")).First(&book).Error(); err != nil {
+	fields, ok := parseFields(c, &models.Book{})
+	if !ok {
+		return
+	}
+
+	var err error
+	if len(fields) == 0 {
+		err = r.DB.Where("id = ?", c.Param("id")).First(&book).Error()
+	} else {
+		// Only load the requested columns
+		err = r.DB.Model(&models.Book{}).Scopes(fields.Scope(&models.Book{})).Where("id = ?", c.Param("id")).First(&book).Error
+	}
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": book})
+	data, err := fields.Project(book)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
 }
 
 // UpdateBook godoc
@@ -207,6 +208,7 @@ func (r *bookRepository) UpdateBook(c *gin.Context) {
 	}
 
 	r.DB.Model(&book).Updates(models.Book{Title: input.Title, Author: input.Author})
+	r.invalidateBooks(c)
 
 	c.JSON(http.StatusOK, gin.H{"data": book})
 }
@@ -230,6 +232,16 @@ func (r *bookRepository) DeleteBook(c *gin.Context) {
 	}
 
 	r.DB.Delete(&book)
+	r.invalidateBooks(c)
 
 	c.JSON(http.StatusNoContent, gin.H{"data": true})
 }
+
+// invalidateBooks drops every cached page of the book list. A failure only
+// leaves pages stale until their TTL expires, so it is not reported.
+func (r *bookRepository) invalidateBooks(c *gin.Context) {
+	if r.Cache == nil {
+		return
+	}
+	r.Cache.InvalidateTags(c.Request.Context(), booksTag)
+}
@@ -7,14 +7,14 @@ import (
 	"ezzygo/pkg/cache"
 	"ezzygo/pkg/database"
 	"ezzygo/pkg/models"
+	"ezzygo/pkg/query"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 
-	"github.com/go-redis/redis/v8"
-
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -25,14 +25,14 @@ func TestNewBookRepository(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := database.NewMockDatabase(ctrl)
-	mockCache := cache.NewMockCache(ctrl)
+	store := cache.NewStore(cache.NewMemoryBackend())
 	mockCtx := context.Background()
 
-	repo := NewBookRepository(mockDB, mockCache, &mockCtx)
+	repo := NewBookRepository(mockDB, store, &mockCtx)
 
 	assert.NotNil(t, repo, "NewBookRepository should return a non-nil instance of bookRepository")
 	assert.Equal(t, mockDB, repo.DB, "DB should be set to the mock database instance")
-	assert.Equal(t, mockCache, repo.RedisClient, "RedisClient should be set to the mock cache instance")
+	assert.Equal(t, store, repo.Cache, "Cache should be set to the given store")
 }
 
 func TestHealthcheck(t *testing.T) {
@@ -68,11 +68,11 @@ func TestFindBooks(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := database.NewMockDatabase(ctrl)
-	mockCache := cache.NewMockCache(ctrl)
+	store := cache.NewStore(cache.NewMemoryBackend())
 	mockGormDB := database.NewMockDatabase(ctrl) // Correct type for GORM DB operations
 	ctx := context.Background()
 
-	repo := NewBookRepository(mockDB, mockCache, &ctx)
+	repo := NewBookRepository(mockDB, store, &ctx)
 
 	// Set up Gin
 	gin.SetMode(gin.TestMode)
@@ -85,16 +85,39 @@ func TestFindBooks(t *testing.T) {
 		return &gorm.DB{Error: nil} // Assume this is the struct provided by the actual Gorm package
 	}).AnyTimes()
 
-	books := []models.Book{{Title: "Book One", Author: "Author One"}}
-	cachedData, _ := json.Marshal(books)
-	mockCache.EXPECT().Get(ctx, "books_offset_0_limit_10").Return(redis.NewStringResult(string(cachedData), nil))
+	params := query.Params{Limit: 10, Sort: []query.Sort{{Field: "title"}}}
+	page := &query.Page[models.Book]{
+		Data: []models.Book{{Title: "Book One", Author: "Author One"}},
+		Page: query.PageInfo{Limit: 10, NextCursor: "next"},
+	}
+	store.Set(ctx, "books:"+params.Key(), page, time.Minute, booksTag)
 
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/books?offset=0&limit=10", nil)
+	req, _ := http.NewRequest("GET", "/books?limit=10&sort=title", nil)
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Contains(t, w.Body.String(), "Book One")
+	var response query.Page[models.Book]
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Book One", response.Data[0].Title)
+	assert.Equal(t, "next", response.Page.NextCursor)
+}
+
+func TestFindBooksInvalidParams(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBookRepository(nil, cache.NewStore(cache.NewMemoryBackend()), &ctx)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/books", repo.FindBooks)
+
+	for _, url := range []string{"/books?sort=password", "/books?title[matches]=go", "/books?limit=abc"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
 }
 
 func TestCreateBook(t *testing.T) {
@@ -102,10 +125,10 @@ func TestCreateBook(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockDB := database.NewMockDatabase(ctrl)
-	mockCache := cache.NewMockCache(ctrl)
+	store := cache.NewStore(cache.NewMemoryBackend())
 	ctx := context.Background()
 
-	repo := NewBookRepository(mockDB, mockCache, &ctx)
+	repo := NewBookRepository(mockDB, store, &ctx)
 
 	// Set up Gin
 	gin.SetMode(gin.TestMode)
@@ -129,10 +152,9 @@ func TestCreateBook(t *testing.T) {
 		return &gorm.DB{Error: nil}
 	})
 
-	// Set up cache mock to simulate key retrieval and deletion
-	keyPattern := "books_offset_*"
-	mockCache.EXPECT().Keys(ctx, keyPattern).Return(redis.NewStringSliceResult([]string{"books_offset_0_limit_10"}, nil))
-	mockCache.EXPECT().Del(ctx, "books_offset_0_limit_10").Return(redis.NewIntResult(1, nil))
+	// Cache a page of the book list so we can check it gets invalidated
+	key := "books:" + query.Params{Limit: query.DefaultLimit}.Key()
+	store.Set(ctx, key, &query.Page[models.Book]{Data: []models.Book{}}, time.Minute, booksTag)
 
 	w := httptest.NewRecorder()
 	req, err := http.NewRequest("POST", "/books", bytes.NewBuffer(requestBody))
@@ -147,6 +169,9 @@ func TestCreateBook(t *testing.T) {
 	// Assertions to check the response
 	assert.Equal(t, http.StatusCreated, w.Code, "Expected HTTP status code 201")
 	assert.Contains(t, w.Body.String(), "New Book", "Response body should contain the book title")
+
+	var cached query.Page[models.Book]
+	assert.ErrorIs(t, store.Get(ctx, key, &cached), cache.ErrMiss, "Creating a book should invalidate cached pages")
 }
 
 func TestFindBook(t *testing.T) {
@@ -0,0 +1,136 @@
+// pkg/api/bundle.go
+package api
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "slices"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cms"
+)
+
+type BundleService interface {
+    Export(ctx context.Context, filter cms.BundleFilter) (*cms.Bundle, error)
+    Import(ctx context.Context, bundle *cms.Bundle, opts cms.ImportOptions) (*cms.ImportReport, error)
+}
+
+type BundleAPI struct {
+    service BundleService
+}
+
+func NewBundleAPI(service BundleService) *BundleAPI {
+    return &BundleAPI{service: service}
+}
+
+// RegisterRoutes registra las rutas de bundle. El import escribe el estado
+// del contenido sin pasar por el workflow, así que router debe estar
+// restringido a administradores.
+func (api *BundleAPI) RegisterRoutes(router *gin.RouterGroup) {
+    bundle := router.Group("/bundle")
+    {
+        bundle.GET("/export", api.Export)
+        bundle.POST("/import", api.Import)
+    }
+}
+
+// Export descarga el bundle. ?types=content,templates limita las secciones y
+// ?locale= el contenido. Con ?format=csv se exporta una sola sección plana
+// (content, media o taxonomies).
+func (api *BundleAPI) Export(c *gin.Context) {
+    var kinds []string
+    if types := c.Query("types"); types != "" {
+        for _, kind := range strings.Split(types, ",") {
+            kinds = append(kinds, strings.TrimSpace(kind))
+        }
+    }
+
+    format := c.DefaultQuery("format", "json")
+    if format != "json" && format != "csv" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+        return
+    }
+    if format == "csv" && (len(kinds) != 1 || !slices.Contains(cms.CSVKinds, kinds[0])) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "csv export needs a single type: " + strings.Join(cms.CSVKinds, ", ")})
+        return
+    }
+
+    bundle, err := api.service.Export(c.Request.Context(), cms.BundleFilter{Kinds: kinds, Locale: c.Query("locale")})
+    if err != nil {
+        writeBundleError(c, err)
+        return
+    }
+
+    name := "bundle-" + bundle.ExportedAt.Format("20060102-150405")
+    if format == "csv" {
+        var body bytes.Buffer
+        if err := cms.WriteCSV(&body, bundle, kinds[0]); err != nil {
+            writeBundleError(c, err)
+            return
+        }
+        c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, name, kinds[0]))
+        c.Data(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+        return
+    }
+
+    c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+    c.JSON(http.StatusOK, bundle)
+}
+
+// Import aplica un bundle JSON o, con ?format=csv&type=content|media, un CSV.
+// ?dry_run=true devuelve el diff sin escribir y ?conflict=skip|overwrite|fail
+// decide qué hacer con lo que ya existe.
+func (api *BundleAPI) Import(c *gin.Context) {
+    opts := cms.ImportOptions{Conflict: c.Query("conflict")}
+    if value := c.Query("dry_run"); value != "" {
+        parsed, err := strconv.ParseBool(value)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+            return
+        }
+        opts.DryRun = parsed
+    }
+
+    var bundle *cms.Bundle
+    switch c.DefaultQuery("format", "json") {
+    case "json":
+        if err := json.NewDecoder(c.Request.Body).Decode(&bundle); err != nil || bundle == nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bundle"})
+            return
+        }
+    case "csv":
+        parsed, err := cms.ReadCSV(c.Request.Body, c.Query("type"))
+        if err != nil {
+            writeBundleError(c, err)
+            return
+        }
+        bundle = parsed
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+        return
+    }
+
+    report, err := api.service.Import(c.Request.Context(), bundle, opts)
+    if err != nil {
+        writeBundleError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, report)
+}
+
+func writeBundleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, cms.ErrImportConflict):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, cms.ErrInvalidBundle), errors.Is(err, cms.ErrBundleVersion), errors.Is(err, cms.ErrInvalidConflict),
+        errors.Is(err, cms.ErrUnsupportedLocale), errors.Is(err, cms.ErrInvalidSEO):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
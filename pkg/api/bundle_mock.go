@@ -0,0 +1,28 @@
+// pkg/api/bundle_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockBundleService struct {
+    mock.Mock
+}
+
+func (m *MockBundleService) Export(ctx context.Context, filter cms.BundleFilter) (*cms.Bundle, error) {
+    args := m.Called(ctx, filter)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Bundle), args.Error(1)
+}
+
+func (m *MockBundleService) Import(ctx context.Context, bundle *cms.Bundle, opts cms.ImportOptions) (*cms.ImportReport, error) {
+    args := m.Called(ctx, bundle, opts)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.ImportReport), args.Error(1)
+}
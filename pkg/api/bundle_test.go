@@ -0,0 +1,140 @@
+// pkg/api/bundle_test.go
+package api
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupBundleTest() (*gin.Engine, *MockBundleService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockBundleService)
+    router := gin.New()
+    NewBundleAPI(mockService).RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestBundleExportJSON(t *testing.T) {
+    router, mockService := setupBundleTest()
+    exportedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+    filter := cms.BundleFilter{Kinds: []string{"content", "templates"}, Locale: "es"}
+    mockService.On("Export", mock.Anything, filter).
+        Return(&cms.Bundle{Version: cms.BundleVersion, ExportedAt: exportedAt, Content: []cms.Content{{Slug: "hola"}}}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/bundle/export?types=content,templates&locale=es", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, `attachment; filename="bundle-20240301-100000.json"`, w.Header().Get("Content-Disposition"))
+    var response cms.Bundle
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, cms.BundleVersion, response.Version)
+    assert.Equal(t, "hola", response.Content[0].Slug)
+    mockService.AssertExpectations(t)
+}
+
+func TestBundleExportCSV(t *testing.T) {
+    router, mockService := setupBundleTest()
+    mockService.On("Export", mock.Anything, cms.BundleFilter{Kinds: []string{"taxonomies"}}).
+        Return(&cms.Bundle{Version: cms.BundleVersion, Taxonomies: []cms.TaxonomyTerm{{Term: "go", Count: 2}}}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/bundle/export?types=taxonomies&format=csv", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+    assert.Equal(t, "term,count\ngo,2\n", w.Body.String())
+
+    // Las plantillas no tienen formato CSV
+    for _, url := range []string{
+        "/api/v1/cms/bundle/export?types=templates&format=csv",
+        "/api/v1/cms/bundle/export?format=csv",
+        "/api/v1/cms/bundle/export?format=xml",
+    } {
+        w = httptest.NewRecorder()
+        req, _ = http.NewRequest("GET", url, nil)
+        router.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusBadRequest, w.Code, url)
+    }
+}
+
+func TestBundleImportDryRun(t *testing.T) {
+    router, mockService := setupBundleTest()
+    opts := cms.ImportOptions{DryRun: true, Conflict: cms.ConflictOverwrite}
+    mockService.On("Import", mock.Anything, mock.MatchedBy(func(bundle *cms.Bundle) bool {
+        return len(bundle.Templates) == 1 && bundle.Templates[0].Name == "post"
+    }), opts).Return(&cms.ImportReport{DryRun: true, Created: 1, Items: []cms.ImportItem{
+        {Kind: cms.BundleTemplates, Key: "post", Action: cms.ImportCreate, SourceID: 4, TargetID: 9},
+    }}, nil)
+
+    body := fmt.Sprintf(`{"version":%d,"templates":[{"ID":4,"name":"post"}]}`, cms.BundleVersion)
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/bundle/import?dry_run=true&conflict=overwrite", strings.NewReader(body))
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response cms.ImportReport
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.True(t, response.DryRun)
+    assert.Equal(t, uint(9), response.Items[0].TargetID)
+    mockService.AssertExpectations(t)
+}
+
+func TestBundleImportCSV(t *testing.T) {
+    router, mockService := setupBundleTest()
+    mockService.On("Import", mock.Anything, mock.MatchedBy(func(bundle *cms.Bundle) bool {
+        return len(bundle.Media) == 1 && bundle.Media[0].URL == "https://cdn.test/a.png"
+    }), cms.ImportOptions{}).Return(&cms.ImportReport{Created: 1}, nil)
+
+    body := "id,name,mime_type,url\n3,a.png,image/png,https://cdn.test/a.png\n"
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/bundle/import?format=csv&type=media", strings.NewReader(body))
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("POST", "/api/v1/cms/bundle/import?format=csv&type=templates", strings.NewReader(body))
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBundleImportErrors(t *testing.T) {
+    router, mockService := setupBundleTest()
+    mockService.On("Import", mock.Anything, mock.Anything, cms.ImportOptions{Conflict: cms.ConflictFail}).
+        Return(nil, fmt.Errorf("%w: content en/hello", cms.ErrImportConflict)).Once()
+    mockService.On("Import", mock.Anything, mock.Anything, cms.ImportOptions{}).
+        Return(nil, fmt.Errorf("%w: 2", cms.ErrBundleVersion)).Once()
+    mockService.On("Import", mock.Anything, mock.Anything, cms.ImportOptions{}).
+        Return(nil, errors.New("db down")).Once()
+
+    for _, tc := range []struct {
+        url    string
+        body   string
+        status int
+    }{
+        {"/api/v1/cms/bundle/import?conflict=fail", `{"version":1}`, http.StatusConflict},
+        {"/api/v1/cms/bundle/import", `{"version":2}`, http.StatusBadRequest},
+        {"/api/v1/cms/bundle/import", `{"version":1}`, http.StatusInternalServerError},
+        {"/api/v1/cms/bundle/import", `not json`, http.StatusBadRequest},
+        {"/api/v1/cms/bundle/import?dry_run=maybe", `{"version":1}`, http.StatusBadRequest},
+    } {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+        router.ServeHTTP(w, req)
+        assert.Equal(t, tc.status, w.Code, tc.url)
+    }
+    mockService.AssertExpectations(t)
+}
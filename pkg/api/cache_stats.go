@@ -0,0 +1,37 @@
+// pkg/api/cache_stats.go
+package api
+
+import (
+    "context"
+    "net/http"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cache"
+)
+
+type CacheStatsService interface {
+    Stats(ctx context.Context) (map[string]cache.Stats, error)
+}
+
+type CacheAPI struct {
+    service CacheStatsService
+}
+
+func NewCacheAPI(service CacheStatsService) *CacheAPI {
+    return &CacheAPI{service: service}
+}
+
+func (api *CacheAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/cache/stats", api.Stats)
+}
+
+// Stats devuelve aciertos, fallos y desalojos por nivel de caché (local y
+// redis). Los contadores locales son los de esta réplica.
+func (api *CacheAPI) Stats(c *gin.Context) {
+    stats, err := api.service.Stats(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"data": stats})
+}
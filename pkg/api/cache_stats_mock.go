@@ -0,0 +1,20 @@
+// pkg/api/cache_stats_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cache"
+)
+
+type MockCacheStatsService struct {
+    mock.Mock
+}
+
+func (m *MockCacheStatsService) Stats(ctx context.Context) (map[string]cache.Stats, error) {
+    args := m.Called(ctx)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(map[string]cache.Stats), args.Error(1)
+}
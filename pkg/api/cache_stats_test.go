@@ -0,0 +1,54 @@
+// pkg/api/cache_stats_test.go
+package api
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cache"
+)
+
+func setupCacheTest() (*gin.Engine, *MockCacheStatsService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockCacheStatsService)
+    router := gin.New()
+    NewCacheAPI(mockService).RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestCacheStats(t *testing.T) {
+    router, mockService := setupCacheTest()
+    mockService.On("Stats", mock.Anything).Return(map[string]cache.Stats{
+        "local": {Hits: 10, Misses: 2, Evictions: 1},
+        "redis": {Hits: 2},
+    }, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/cache/stats", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response struct {
+        Data map[string]cache.Stats `json:"data"`
+    }
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, uint64(10), response.Data["local"].Hits)
+    assert.Equal(t, uint64(1), response.Data["local"].Evictions)
+    assert.Equal(t, uint64(2), response.Data["redis"].Hits)
+}
+
+func TestCacheStatsError(t *testing.T) {
+    router, mockService := setupCacheTest()
+    mockService.On("Stats", mock.Anything).Return(nil, errors.New("redis down"))
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/cache/stats", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -2,17 +2,31 @@
 package api
 
 import (
+    "context"
+    "errors"
     "net/http"
     "strconv"
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
+type ContentService interface {
+    Create(ctx context.Context, content *cms.Content) error
+    Get(ctx context.Context, id uint) (*cms.Content, error)
+    List(ctx context.Context, params query.Params) (*query.Page[cms.Content], error)
+    Replace(ctx context.Context, id uint, body []byte, revision int) (*cms.Content, error)
+    Patch(ctx context.Context, id uint, patch []byte, revision int) (*cms.Content, error)
+    Delete(ctx context.Context, id uint) error
+    Publish(ctx context.Context, id uint) error
+}
+
 type ContentAPI struct {
-    service *cms.ContentService
+    service ContentService
 }
 
-func NewContentAPI(service *cms.ContentService) *ContentAPI {
+func NewContentAPI(service ContentService) *ContentAPI {
     return &ContentAPI{service: service}
 }
 
@@ -23,6 +37,7 @@ func (api *ContentAPI) RegisterRoutes(router *gin.Engine) {
         content.GET("/", api.List)
         content.GET("/:id", api.Get)
         content.PUT("/:id", api.Update)
+        content.PATCH("/:id", api.Patch)
         content.DELETE("/:id", api.Delete)
         content.POST("/:id/publish", api.Publish)
     }
@@ -36,6 +51,10 @@ func (api *ContentAPI) Create(c *gin.Context) {
     }
 
     if err := api.service.Create(c.Request.Context(), &content); err != nil {
+        if errors.Is(err, cms.ErrUnsupportedLocale) || errors.Is(err, cms.ErrInvalidSEO) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -50,27 +69,43 @@ func (api *ContentAPI) Get(c *gin.Context) {
         return
     }
 
+    fields, ok := parseFields(c, &cms.Content{})
+    if !ok {
+        return
+    }
+
+    // Get sale de la caché con la entidad completa, así que aquí solo se
+    // recorta la respuesta
     content, err := api.service.Get(c.Request.Context(), uint(id))
     if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
         return
     }
 
-    c.JSON(http.StatusOK, content)
+    body, err := fields.Project(content)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    writeWithETag(c, content.Revision, fields, body)
 }
 
+// List admite paginación por cursor, sort, filtros y campos como
+// ?status=draft&author_id[in]=1,2&title[contains]=go&sort=-updated_at&fields=title,slug.
+// Solo se leen de la base de datos las columnas pedidas.
 func (api *ContentAPI) List(c *gin.Context) {
-    filter := cms.ContentFilter{
-        Status: c.Query("status"),
+    params, ok := parseListParams(c, cms.ContentQuery, &cms.Content{})
+    if !ok {
+        return
     }
 
-    contents, err := api.service.List(c.Request.Context(), filter)
+    page, err := api.service.List(c.Request.Context(), params)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        writeListError(c, err)
         return
     }
 
-    c.JSON(http.StatusOK, contents)
+    writePage(c, page, params.Fields)
 }
 
 func (api *ContentAPI) Update(c *gin.Context) {
@@ -80,21 +115,71 @@ func (api *ContentAPI) Update(c *gin.Context) {
         return
     }
 
-    var content cms.Content
-    if err := c.ShouldBindJSON(&content); err != nil {
+    revision, err := ifMatchRevision(c)
+    if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
-    content.ID = uint(id)
 
-    if err := api.service.Update(c.Request.Context(), &content); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    body, ok := readReplaceBody(c)
+    if !ok {
+        return
+    }
+
+    // Los campos que no vienen en la petición se mantienen. If-Match tiene
+    // prioridad sobre la revisión del cuerpo
+    content, err := api.service.Replace(c.Request.Context(), uint(id), body, revision)
+    if err != nil {
+        api.writeUpdateError(c, err)
         return
     }
 
+    c.Header("ETag", revisionETag(content.Revision))
     c.JSON(http.StatusOK, content)
 }
 
+func (api *ContentAPI) Patch(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    revision, err := ifMatchRevision(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    patch, ok := readMergePatch(c)
+    if !ok {
+        return
+    }
+
+    content, err := api.service.Patch(c.Request.Context(), uint(id), patch, revision)
+    if err != nil {
+        api.writeUpdateError(c, err)
+        return
+    }
+
+    c.Header("ETag", revisionETag(content.Revision))
+    c.JSON(http.StatusOK, content)
+}
+
+func (api *ContentAPI) writeUpdateError(c *gin.Context, err error) {
+    if abortStaleRevision(c, err) {
+        return
+    }
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
+    case errors.Is(err, cms.ErrUnsupportedLocale), errors.Is(err, cms.ErrInvalidPatch), errors.Is(err, cms.ErrInvalidSEO):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
 func (api *ContentAPI) Delete(c *gin.Context) {
     id, err := strconv.ParseUint(c.Param("id"), 10, 32)
     if err != nil {
@@ -118,6 +203,10 @@ func (api *ContentAPI) Publish(c *gin.Context) {
     }
 
     if err := api.service.Publish(c.Request.Context(), uint(id)); err != nil {
+        if errors.Is(err, cms.ErrIllegalTransition) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -5,6 +5,7 @@ import (
     "context"
     "github.com/stretchr/testify/mock"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
 type MockContentService struct {
@@ -24,14 +25,28 @@ func (m *MockContentService) Get(ctx context.Context, id uint) (*cms.Content, er
     return args.Get(0).(*cms.Content), args.Error(1)
 }
 
-func (m *MockContentService) List(ctx context.Context, filter cms.ContentFilter) ([]cms.Content, error) {
-    args := m.Called(ctx, filter)
-    return args.Get(0).([]cms.Content), args.Error(1)
+func (m *MockContentService) List(ctx context.Context, params query.Params) (*query.Page[cms.Content], error) {
+    args := m.Called(ctx, params)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*query.Page[cms.Content]), args.Error(1)
 }
 
-func (m *MockContentService) Update(ctx context.Context, content *cms.Content) error {
-    args := m.Called(ctx, content)
-    return args.Error(0)
+func (m *MockContentService) Replace(ctx context.Context, id uint, body []byte, revision int) (*cms.Content, error) {
+    args := m.Called(ctx, id, body, revision)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Content), args.Error(1)
+}
+
+func (m *MockContentService) Patch(ctx context.Context, id uint, patch []byte, revision int) (*cms.Content, error) {
+    args := m.Called(ctx, id, patch, revision)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Content), args.Error(1)
 }
 
 func (m *MockContentService) Delete(ctx context.Context, id uint) error {
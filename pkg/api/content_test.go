@@ -11,6 +11,7 @@ import (
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
 func setupTest() (*gin.Engine, *MockContentService) {
@@ -70,38 +71,54 @@ func TestList(t *testing.T) {
         {Title: "Content 2", Slug: "content-2"},
     }
 
-    filter := cms.ContentFilter{Status: "published"}
-    mockService.On("List", mock.Anything, filter).Return(contents, nil)
+    params := query.Params{
+        Limit:   10,
+        Sort:    []query.Sort{{Field: "title"}},
+        Filters: []query.Filter{{Field: "status", Op: "eq", Values: []interface{}{"published"}}},
+    }
+    page := &query.Page[cms.Content]{Data: contents, Page: query.PageInfo{Limit: 10, NextCursor: "next"}}
+    mockService.On("List", mock.Anything, params).Return(page, nil)
 
     w := httptest.NewRecorder()
-    req, _ := http.NewRequest("GET", "/api/v1/content?status=published", nil)
+    req, _ := http.NewRequest("GET", "/api/v1/content/?status=published&limit=10&sort=title", nil)
     router.ServeHTTP(w, req)
 
     assert.Equal(t, http.StatusOK, w.Code)
-    
-    var response []cms.Content
+
+    var response query.Page[cms.Content]
     _ = json.Unmarshal(w.Body.Bytes(), &response)
-    assert.Equal(t, 2, len(response))
+    assert.Equal(t, 2, len(response.Data))
+    assert.Equal(t, "next", response.Page.NextCursor)
     mockService.AssertExpectations(t)
 }
 
+func TestListInvalidSort(t *testing.T) {
+    router, mockService := setupTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/content/?sort=password", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
 func TestUpdate(t *testing.T) {
     router, mockService := setupTest()
-    content := cms.Content{
-        Title: "Updated Content",
-        Slug:  "updated-content",
-    }
-    content.ID = 1
+    body := []byte(`{"title":"Updated Content","slug":"updated-content"}`)
+    updated := &cms.Content{Title: "Updated Content", Slug: "updated-content", Revision: 4}
+    updated.ID = 1
 
-    mockService.On("Update", mock.Anything, &content).Return(nil)
+    // Sin If-Match la revisión la pone el servicio con la fila que lee
+    mockService.On("Replace", mock.Anything, uint(1), body, 0).Return(updated, nil)
 
-    body, _ := json.Marshal(content)
     w := httptest.NewRecorder()
     req, _ := http.NewRequest("PUT", "/api/v1/content/1", bytes.NewBuffer(body))
     req.Header.Set("Content-Type", "application/json")
     router.ServeHTTP(w, req)
 
     assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, `"4"`, w.Header().Get("ETag"))
     mockService.AssertExpectations(t)
 }
 
@@ -128,3 +145,140 @@ func TestPublish(t *testing.T) {
     assert.Equal(t, http.StatusOK, w.Code)
     mockService.AssertExpectations(t)
 }
+
+func TestGetETag(t *testing.T) {
+    router, mockService := setupTest()
+    content := &cms.Content{Title: "Test Content", Revision: 3}
+    content.ID = 1
+
+    mockService.On("Get", mock.Anything, uint(1)).Return(content, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/content/1", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("GET", "/api/v1/content/1", nil)
+    req.Header.Set("If-None-Match", `"3"`)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotModified, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestGetFieldsETag(t *testing.T) {
+    router, mockService := setupTest()
+    content := &cms.Content{Title: "Test Content", Slug: "test-content", Revision: 3}
+    content.ID = 1
+
+    mockService.On("Get", mock.Anything, uint(1)).Return(content, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/content/1?fields=title,slug", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, `"3;slug,title"`, w.Header().Get("ETag"))
+
+    // El ETag de la entidad completa no vale para la proyección
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("GET", "/api/v1/content/1?fields=title,slug", nil)
+    req.Header.Set("If-None-Match", `"3"`)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestUpdateStaleRevision(t *testing.T) {
+    router, mockService := setupTest()
+    body := []byte(`{"title":"Updated Content"}`)
+
+    mockService.On("Replace", mock.Anything, uint(1), body, 2).Return(nil, &cms.RevisionMismatchError{Current: 4})
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PUT", "/api/v1/content/1", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("If-Match", `"2"`)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+    assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+    var response map[string]interface{}
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, float64(4), response["current_revision"])
+    mockService.AssertExpectations(t)
+}
+
+func TestUpdateInvalidIfMatch(t *testing.T) {
+    router, _ := setupTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PUT", "/api/v1/content/1", bytes.NewBufferString(`{"title":"x"}`))
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("If-Match", "abc")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPatch(t *testing.T) {
+    router, mockService := setupTest()
+    patch := []byte(`{"title":"Patched"}`)
+    patched := &cms.Content{Title: "Patched", Revision: 6}
+    patched.ID = 1
+
+    mockService.On("Patch", mock.Anything, uint(1), patch, 5).Return(patched, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PATCH", "/api/v1/content/1", bytes.NewBuffer(patch))
+    req.Header.Set("Content-Type", "application/merge-patch+json")
+    req.Header.Set("If-Match", `"5"`)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, `"6"`, w.Header().Get("ETag"))
+    mockService.AssertExpectations(t)
+}
+
+func TestPatchUnsupportedMediaType(t *testing.T) {
+    router, _ := setupTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PATCH", "/api/v1/content/1", bytes.NewBufferString(`title=x`))
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestListFields(t *testing.T) {
+    router, mockService := setupTest()
+    contents := []cms.Content{{Title: "Content 1", Slug: "content-1", Content: "long body"}}
+
+    params := query.Params{Limit: query.DefaultLimit, Fields: query.Fieldset{"title", "slug"}}
+    mockService.On("List", mock.Anything, params).Return(&query.Page[cms.Content]{Data: contents}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/content/?fields=title,slug", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.JSONEq(t, `{"data":[{"title":"Content 1","slug":"content-1"}],"page":{"limit":0}}`, w.Body.String())
+    mockService.AssertExpectations(t)
+}
+
+func TestGetUnknownField(t *testing.T) {
+    router, mockService := setupTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/content/1?fields=title,author", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,105 @@
+// pkg/api/etag.go
+package api
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "sort"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
+)
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+func revisionETag(revision int) string {
+    return `"` + strconv.Itoa(revision) + `"`
+}
+
+// fieldsETag es el ETag de una respuesta recortada con ?fields=: lleva los
+// campos para que una caché no sirva la proyección de otra petición, como
+// "3;title,slug". Sin campos es el de la revisión.
+func fieldsETag(revision int, fields query.Fieldset) string {
+    if len(fields) == 0 {
+        return revisionETag(revision)
+    }
+    names := append([]string(nil), fields...)
+    sort.Strings(names)
+    return `"` + strconv.Itoa(revision) + ";" + strings.Join(names, ",") + `"`
+}
+
+// ifMatchRevision devuelve la revisión de la cabecera If-Match, o 0 si no se
+// envió (o es "*"), en cuyo caso la escritura no se condiciona.
+func ifMatchRevision(c *gin.Context) (int, error) {
+    value := strings.TrimSpace(c.GetHeader("If-Match"))
+    if value == "" || value == "*" {
+        return 0, nil
+    }
+
+    value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+    // Un ETag con campos condiciona igual por su revisión
+    value, _, _ = strings.Cut(value, ";")
+    revision, err := strconv.Atoi(value)
+    if err != nil || revision <= 0 {
+        return 0, errInvalidIfMatch
+    }
+    return revision, nil
+}
+
+// writeWithETag responde con la entidad y su ETag, o 304 si el cliente ya
+// tiene esa revisión con los mismos campos.
+func writeWithETag(c *gin.Context, revision int, fields query.Fieldset, body interface{}) {
+    etag := fieldsETag(revision, fields)
+    c.Header("ETag", etag)
+    if match := c.GetHeader("If-None-Match"); match != "" && strings.TrimPrefix(match, "W/") == etag {
+        c.Status(http.StatusNotModified)
+        return
+    }
+    c.JSON(http.StatusOK, body)
+}
+
+// abortStaleRevision responde 412 con la revisión actual si err es un
+// conflicto de revisión.
+func abortStaleRevision(c *gin.Context, err error) bool {
+    var mismatch *cms.RevisionMismatchError
+    if !errors.As(err, &mismatch) {
+        return false
+    }
+
+    c.Header("ETag", revisionETag(mismatch.Current))
+    c.JSON(http.StatusPreconditionFailed, gin.H{
+        "error":            err.Error(),
+        "current_revision": mismatch.Current,
+    })
+    return true
+}
+
+// readReplaceBody lee el cuerpo de un PUT, que se aplica sobre la entidad
+// guardada.
+func readReplaceBody(c *gin.Context) ([]byte, bool) {
+    body, err := c.GetRawData()
+    if err != nil || len(body) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "empty body"})
+        return nil, false
+    }
+    return body, true
+}
+
+// readMergePatch lee el cuerpo de un PATCH. Se acepta
+// application/merge-patch+json y también application/json.
+func readMergePatch(c *gin.Context) ([]byte, bool) {
+    contentType := c.ContentType()
+    if contentType != "application/merge-patch+json" && contentType != "application/json" {
+        c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "expected application/merge-patch+json"})
+        return nil, false
+    }
+
+    patch, err := c.GetRawData()
+    if err != nil || len(patch) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "empty patch"})
+        return nil, false
+    }
+    return patch, true
+}
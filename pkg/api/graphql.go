@@ -0,0 +1,123 @@
+// pkg/api/graphql.go
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/graphql"
+    "ezzygo/pkg/middleware"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// GraphQLAPI sirve /graphql. Acepta POST con un cuerpo JSON y GET con query,
+// operationName, variables y extensions en la query string; los GET con una
+// persisted query son cacheables por la CDN.
+type GraphQLAPI struct {
+    schema *graphQLSchema
+    server *graphql.Server
+}
+
+// NewGraphQLAPI crea el endpoint. Con public solo se expone lo que ven las rutas
+// públicas REST. Los límites se leen de GRAPHQL_MAX_DEPTH (10 por defecto) y
+// GRAPHQL_MAX_COMPLEXITY (1000).
+func NewGraphQLAPI(db *gorm.DB, contentService *cms.ContentService, templateService *cms.TemplateService, locales *cms.LocaleConfig, store *cache.Store, public bool) *GraphQLAPI {
+    schema := &graphQLSchema{
+        db:        db,
+        contents:  contentService,
+        templates: templateService,
+        locales:   locales,
+        public:    public,
+    }
+    return &GraphQLAPI{
+        schema: schema,
+        server: &graphql.Server{
+            Schema: schema.build(),
+            Limits: graphql.Limits{
+                MaxDepth:      graphQLLimit("GRAPHQL_MAX_DEPTH", 10),
+                MaxComplexity: graphQLLimit("GRAPHQL_MAX_COMPLEXITY", 1000),
+            },
+            Persisted: graphql.NewCachePersistedQueries(store),
+        },
+    }
+}
+
+func (api *GraphQLAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.POST("/graphql", api.Post)
+    router.GET("/graphql", api.Get)
+    router.GET("/graphql/schema", api.Schema)
+}
+
+func (api *GraphQLAPI) Post(c *gin.Context) {
+    var request graphql.Request
+    decoder := json.NewDecoder(c.Request.Body)
+    decoder.UseNumber()
+    if err := decoder.Decode(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid GraphQL request: " + err.Error()})
+        return
+    }
+    api.execute(c, &request)
+}
+
+func (api *GraphQLAPI) Get(c *gin.Context) {
+    request := graphql.Request{
+        Query:         c.Query("query"),
+        OperationName: c.Query("operationName"),
+    }
+    if err := decodeParam(c.Query("variables"), &request.Variables); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variables: " + err.Error()})
+        return
+    }
+    if err := decodeParam(c.Query("extensions"), &request.Extensions); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid extensions: " + err.Error()})
+        return
+    }
+    api.execute(c, &request)
+}
+
+// Schema devuelve el esquema en SDL, para generar los tipos del frontend.
+func (api *GraphQLAPI) Schema(c *gin.Context) {
+    c.String(http.StatusOK, api.server.Schema.SDL())
+}
+
+func (api *GraphQLAPI) execute(c *gin.Context, request *graphql.Request) {
+    ctx := api.schema.newRequest(c.Request.Context(), c.GetHeader("Accept-Language"))
+    response := api.server.Do(ctx, request)
+
+    // Una respuesta con errores (p. ej. PersistedQueryNotFound) no debe quedarse
+    // en la CDN
+    if len(response.Errors) > 0 {
+        c.Header("Cache-Control", "no-store")
+    } else {
+        request := graphQLRequestOf(ctx)
+        if len(request.tags) > 0 {
+            middleware.SurrogateKeys(c, request.tags...)
+        }
+        if request.until != nil {
+            middleware.CacheUntil(c, *request.until)
+        }
+    }
+    c.Header("Vary", "Accept-Language")
+    c.JSON(http.StatusOK, response)
+}
+
+func decodeParam(raw string, dest interface{}) error {
+    if raw == "" {
+        return nil
+    }
+    decoder := json.NewDecoder(strings.NewReader(raw))
+    decoder.UseNumber()
+    return decoder.Decode(dest)
+}
+
+func graphQLLimit(name string, fallback int) int {
+    if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+        return value
+    }
+    return fallback
+}
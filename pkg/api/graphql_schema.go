@@ -0,0 +1,449 @@
+// pkg/api/graphql_schema.go
+package api
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/url"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/graphql"
+    "ezzygo/pkg/models"
+    "ezzygo/pkg/query"
+    "gorm.io/gorm"
+)
+
+// graphQLSchema genera el esquema GraphQL a partir de los modelos del CMS y de
+// Book. El esquema público solo ve el contenido entregable y los libros, como
+// las rutas públicas REST; el del CMS ve además borradores, plantillas y medios.
+type graphQLSchema struct {
+    db        *gorm.DB
+    contents  *cms.ContentService
+    templates *cms.TemplateService
+    locales   *cms.LocaleConfig
+    public    bool
+}
+
+// graphQLRequest guarda los loaders de una petición, que agrupan las cargas de
+// las relaciones en una consulta por nivel, y las surrogate keys de la respuesta.
+type graphQLRequest struct {
+    acceptLanguage string
+    templates      *graphql.Loader[uint, *cms.Template]
+    media          *graphql.Loader[uint, *cms.Media]
+    translations   *graphql.Loader[uint, []cms.Content]
+
+    mu    sync.Mutex
+    tags  []string
+    until *time.Time // la caducidad más próxima del contenido de la respuesta
+}
+
+type graphQLRequestKey struct{}
+
+func (r *graphQLRequest) tag(tags ...string) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.tags = append(r.tags, tags...)
+}
+
+// expire limita la caché de la respuesta a la caducidad de un contenido.
+func (r *graphQLRequest) expire(at *time.Time) {
+    if at == nil {
+        return
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.until == nil || at.Before(*r.until) {
+        r.until = at
+    }
+}
+
+func graphQLRequestOf(ctx context.Context) *graphQLRequest {
+    return ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+}
+
+// tagCount es una etiqueta de la taxonomía con el número de contenidos que la usan.
+type tagCount struct {
+    Name  string `json:"name"`
+    Count int64  `json:"count"`
+}
+
+// listFilter expone un filtro de la query string REST como argumento del listado.
+type listFilter struct {
+    arg   *graphql.ArgDef
+    param string
+}
+
+func (s *graphQLSchema) newRequest(ctx context.Context, acceptLanguage string) context.Context {
+    request := &graphQLRequest{acceptLanguage: acceptLanguage}
+
+    request.templates = graphql.NewLoader(func(ctx context.Context, ids []uint) (map[uint]*cms.Template, error) {
+        var templates []cms.Template
+        if err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&templates).Error; err != nil {
+            return nil, err
+        }
+        byID := map[uint]*cms.Template{}
+        for i := range templates {
+            byID[templates[i].ID] = &templates[i]
+        }
+        return byID, nil
+    })
+
+    request.media = graphql.NewLoader(func(ctx context.Context, ids []uint) (map[uint]*cms.Media, error) {
+        var media []cms.Media
+        if err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&media).Error; err != nil {
+            return nil, err
+        }
+        byID := map[uint]*cms.Media{}
+        for i := range media {
+            byID[media[i].ID] = &media[i]
+        }
+        return byID, nil
+    })
+
+    request.translations = graphql.NewLoader(func(ctx context.Context, groups []uint) (map[uint][]cms.Content, error) {
+        db := s.db.WithContext(ctx)
+        if s.public {
+            db = db.Scopes(cms.Delivered(time.Now()))
+        }
+        var contents []cms.Content
+        if err := db.Where("translation_group_id IN ?", groups).Order("locale").Find(&contents).Error; err != nil {
+            return nil, err
+        }
+        byGroup := map[uint][]cms.Content{}
+        for _, content := range contents {
+            byGroup[content.TranslationGroupID] = append(byGroup[content.TranslationGroupID], content)
+        }
+        return byGroup, nil
+    })
+
+    return context.WithValue(ctx, graphQLRequestKey{}, request)
+}
+
+func (s *graphQLSchema) build() *graphql.Schema {
+    pageInfo := graphql.FromStruct("PageInfo", "Paginación por cursor, como en los listados REST", query.PageInfo{})
+    book := graphql.FromStruct("Book", "", models.Book{})
+    tag := graphql.FromStruct("Tag", "Etiqueta de la taxonomía de contenido", tagCount{})
+    content := graphql.FromStruct("Content", "", cms.Content{})
+
+    content.AddField(&graphql.FieldDef{
+        Name:        "translations",
+        Description: "Las demás traducciones del contenido",
+        Type:        nonNullList(content),
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            source := contentSource(p.Source)
+            request := graphQLRequestOf(p.Context)
+            load := request.translations.Load(p.Context, source.TranslationGroupID)
+            return graphql.Thunk(func() (interface{}, error) {
+                group, err := load()
+                if err != nil {
+                    return nil, err
+                }
+                others := []cms.Content{}
+                for _, translation := range group.([]cms.Content) {
+                    if translation.ID != source.ID {
+                        others = append(others, translation)
+                    }
+                }
+                if s.public {
+                    for _, translation := range others {
+                        request.tag(cms.ContentTag(translation.ID))
+                        request.expire(translation.ExpiresAt)
+                    }
+                }
+                return others, nil
+            }), nil
+        },
+    })
+
+    // La plantilla y la imagen de cada contenido se cargan en lote por nivel.
+    // En la API pública la respuesta se purga cuando cambian
+    template := graphql.FromStruct("Template", "", cms.Template{})
+    media := graphql.FromStruct("Media", "", cms.Media{})
+    content.AddField(&graphql.FieldDef{
+        Name: "template",
+        Type: template,
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            source := contentSource(p.Source)
+            if source.TemplateID == 0 {
+                return nil, nil
+            }
+            request := graphQLRequestOf(p.Context)
+            if s.public {
+                request.tag(cms.TemplateTag(source.TemplateID))
+            }
+            return request.templates.Load(p.Context, source.TemplateID), nil
+        },
+    })
+    content.AddField(&graphql.FieldDef{
+        Name:        "ogImage",
+        Description: "La imagen de og_image_id",
+        Type:        media,
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            source := contentSource(p.Source)
+            if source.OGImageID == nil {
+                return nil, nil
+            }
+            request := graphQLRequestOf(p.Context)
+            if s.public {
+                request.tag(cms.MediaTag(*source.OGImageID))
+            }
+            return request.media.Load(p.Context, *source.OGImageID), nil
+        },
+    })
+
+    root := graphql.NewObject("Query", "")
+    root.AddField(listField[models.Book]("books", "Libros, paginados", book, pageOf(book, pageInfo), bookQuery,
+        func(ctx context.Context) *gorm.DB { return s.db.Model(&models.Book{}) }, nil))
+    root.AddField(&graphql.FieldDef{
+        Name: "book",
+        Type: book,
+        Args: []*graphql.ArgDef{{Name: "id", Type: &graphql.NonNull{Of: graphql.ID}}},
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            var found models.Book
+            fields := query.Fieldset(selectedColumns(book, p.Selected()))
+            err := s.db.WithContext(p.Context).Model(&models.Book{}).Scopes(fields.Scope(&models.Book{})).
+                Where("id = ?", p.Args["id"]).First(&found).Error
+            if err != nil {
+                return nil, notFoundAsNull(err)
+            }
+            return found, nil
+        },
+    })
+
+    root.AddField(&graphql.FieldDef{
+        Name:        "tags",
+        Description: "Etiquetas usadas por el contenido, con el número de contenidos de cada una",
+        Type:        nonNullList(tag),
+        Args:        []*graphql.ArgDef{{Name: "locale", Type: graphql.String}},
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            db := s.db.WithContext(p.Context).Model(&cms.Content{}).
+                Select("unnest(tags) AS name, count(*) AS count").
+                Group("name").
+                Order("name")
+            if s.public {
+                db = db.Scopes(cms.Delivered(time.Now()))
+            }
+            if locale, ok := p.Args["locale"].(string); ok {
+                db = db.Where("locale = ?", s.locales.Normalize(locale))
+            }
+            tags := []tagCount{}
+            err := db.Scan(&tags).Error
+            return tags, err
+        },
+    })
+
+    contentFilters := []listFilter{
+        {arg: &graphql.ArgDef{Name: "locale", Type: graphql.String}, param: "locale"},
+        {arg: &graphql.ArgDef{Name: "tag", Type: graphql.String}, param: "tags[contains]"},
+        {arg: &graphql.ArgDef{Name: "template_id", Type: graphql.ID}, param: "template_id"},
+    }
+    contentsBase := func(ctx context.Context) *gorm.DB {
+        if s.public {
+            return s.db.Model(&cms.Content{}).Scopes(cms.Delivered(time.Now()))
+        }
+        return s.db.Model(&cms.Content{})
+    }
+    root.AddField(listField[cms.Content]("contents", "Contenido, paginado", content, pageOf(content, pageInfo), cms.ContentQuery,
+        contentsBase, contentFilters, "translation_group_id", "template_id", "og_image_id"))
+
+    if s.public {
+        root.AddField(&graphql.FieldDef{
+            Name:        "content",
+            Description: "Contenido publicado por slug, siguiendo la cadena de fallback del idioma",
+            Type:        content,
+            Args: []*graphql.ArgDef{
+                {Name: "slug", Type: &graphql.NonNull{Of: graphql.String}},
+                {Name: "locale", Type: graphql.String},
+            },
+            Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+                request := graphQLRequestOf(p.Context)
+                locale, _ := p.Args["locale"].(string)
+                if locale == "" {
+                    locale = s.locales.Negotiate(request.acceptLanguage)
+                }
+                fields := query.Fieldset(selectedColumns(content, p.Selected()))
+                found, err := s.contents.Resolve(p.Context, p.Args["slug"].(string), locale, fields)
+                if err != nil {
+                    return nil, notFoundAsNull(err)
+                }
+                s.contents.RecordView(found.ID)
+                request.tag(cms.ContentTag(found.ID), cms.TemplateTag(found.TemplateID), cms.TranslationTag(found.TranslationGroupID))
+                request.expire(found.ExpiresAt)
+                return found, nil
+            },
+        })
+        return &graphql.Schema{Query: root}
+    }
+
+    // Los listados de plantillas y medios solo se ven desde el CMS
+
+    root.AddField(&graphql.FieldDef{
+        Name: "content",
+        Type: content,
+        Args: []*graphql.ArgDef{{Name: "id", Type: &graphql.NonNull{Of: graphql.ID}}},
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            found, err := s.contents.Get(p.Context, p.Args["id"].(uint))
+            if err != nil {
+                return nil, notFoundAsNull(err)
+            }
+            return found, nil
+        },
+    })
+    root.AddField(listField[cms.Template]("templates", "Plantillas, paginadas", template, pageOf(template, pageInfo), cms.TemplateQuery,
+        func(ctx context.Context) *gorm.DB { return s.db.Model(&cms.Template{}) },
+        []listFilter{{arg: &graphql.ArgDef{Name: "type", Type: graphql.String}, param: "type"}}))
+    root.AddField(&graphql.FieldDef{
+        Name: "template",
+        Type: template,
+        Args: []*graphql.ArgDef{{Name: "id", Type: &graphql.NonNull{Of: graphql.ID}}},
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            found, err := s.templates.Get(p.Context, p.Args["id"].(uint))
+            if err != nil {
+                return nil, notFoundAsNull(err)
+            }
+            return found, nil
+        },
+    })
+    root.AddField(listField[cms.Media]("mediaList", "Medios, paginados", media, pageOf(media, pageInfo), cms.MediaQuery,
+        func(ctx context.Context) *gorm.DB { return s.db.Model(&cms.Media{}) },
+        []listFilter{{arg: &graphql.ArgDef{Name: "type", Type: graphql.String}, param: "type"}}))
+    root.AddField(&graphql.FieldDef{
+        Name: "media",
+        Type: media,
+        Args: []*graphql.ArgDef{{Name: "id", Type: &graphql.NonNull{Of: graphql.ID}}},
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            var found cms.Media
+            fields := query.Fieldset(selectedColumns(media, p.Selected()))
+            err := s.db.WithContext(p.Context).Model(&cms.Media{}).Scopes(fields.Scope(&cms.Media{})).
+                Where("id = ?", p.Args["id"]).First(&found).Error
+            if err != nil {
+                return nil, notFoundAsNull(err)
+            }
+            return found, nil
+        },
+    })
+
+    return &graphql.Schema{Query: root}
+}
+
+// listField crea un listado con la misma paginación y los mismos filtros que
+// los endpoints REST: limit, cursor, sort y total, los filtros de filters y
+// filter, un objeto con parámetros de la query string, p. ej.
+// {"created_at[gte]": "2024-01-01"}. Solo se leen las columnas seleccionadas
+// en data, además de always.
+func listField[T any](name, description string, item, page *graphql.Object, schema query.Schema, base func(context.Context) *gorm.DB, filters []listFilter, always ...string) *graphql.FieldDef {
+    args := []*graphql.ArgDef{
+        {Name: "limit", Type: graphql.Int, Default: query.DefaultLimit},
+        {Name: "cursor", Type: graphql.String},
+        {Name: "sort", Type: graphql.String, Description: "Campos separados por comas, con - para orden descendente"},
+        {Name: "total", Type: graphql.Boolean, Default: false},
+        {Name: "filter", Type: graphql.JSON},
+    }
+    for _, filter := range filters {
+        args = append(args, filter.arg)
+    }
+
+    return &graphql.FieldDef{
+        Name:        name,
+        Description: description,
+        Type:        &graphql.NonNull{Of: page},
+        Args:        args,
+        Multiplier: func(args map[string]interface{}) int {
+            limit, _ := args["limit"].(int)
+            return max(min(limit, query.MaxLimit), 1)
+        },
+        Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+            values := url.Values{}
+            if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+                for param, value := range filter {
+                    values.Add(param, filterValue(value))
+                }
+            } else if p.Args["filter"] != nil {
+                return nil, fmt.Errorf("%w: filter must be an object", query.ErrInvalidQuery)
+            }
+            for _, filter := range filters {
+                if value, ok := p.Args[filter.arg.Name]; ok {
+                    values.Add(filter.param, filterValue(value))
+                }
+            }
+
+            values.Set("limit", strconv.Itoa(p.Args["limit"].(int)))
+            values.Set("total", strconv.FormatBool(p.Args["total"].(bool)))
+            values.Set("fields", strings.Join(selectedColumns(item, p.Selected("data"), always...), ","))
+            if cursor, ok := p.Args["cursor"].(string); ok {
+                values.Set("cursor", cursor)
+            }
+            if sort, ok := p.Args["sort"].(string); ok {
+                values.Set("sort", sort)
+            }
+
+            params, err := query.Parse(values, schema)
+            if err != nil {
+                return nil, err
+            }
+            return query.Find[T](p.Context, base(p.Context), params, schema)
+        },
+    }
+}
+
+// pageOf es el tipo de la página de un listado, como query.Page.
+func pageOf(item, pageInfo *graphql.Object) *graphql.Object {
+    page := graphql.NewObject(item.Name+"Page", "")
+    page.AddField(&graphql.FieldDef{Name: "data", Type: nonNullList(item), Index: []int{0}})
+    page.AddField(&graphql.FieldDef{Name: "page", Type: &graphql.NonNull{Of: pageInfo}, Index: []int{1}})
+    return page
+}
+
+func nonNullList(item graphql.Type) graphql.Type {
+    return &graphql.NonNull{Of: &graphql.List{Of: &graphql.NonNull{Of: item}}}
+}
+
+// selectedColumns devuelve los campos seleccionados que son columnas del
+// modelo, para cargar solo esas; las relaciones se descartan.
+func selectedColumns(object *graphql.Object, selected []string, always ...string) []string {
+    var columns []string
+    for _, name := range selected {
+        if field := object.Field(name); field != nil && field.Index != nil {
+            columns = append(columns, name)
+        }
+    }
+    if len(columns) == 0 && len(always) == 0 {
+        return nil
+    }
+    return append(columns, always...)
+}
+
+func filterValue(value interface{}) string {
+    if list, ok := value.([]interface{}); ok {
+        values := make([]string, len(list))
+        for i, item := range list {
+            values[i] = fmt.Sprint(item)
+        }
+        return strings.Join(values, ",")
+    }
+    return fmt.Sprint(value)
+}
+
+// contentSource admite el contenido de un listado (valor) o de una búsqueda
+// por slug o id (puntero).
+func contentSource(source interface{}) *cms.Content {
+    switch content := source.(type) {
+    case *cms.Content:
+        return content
+    case cms.Content:
+        return &content
+    }
+    return &cms.Content{}
+}
+
+// Una entidad que no existe se devuelve como null, no como error
+func notFoundAsNull(err error) error {
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil
+    }
+    return err
+}
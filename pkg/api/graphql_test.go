@@ -0,0 +1,107 @@
+// pkg/api/graphql_test.go
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "strings"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/cms"
+)
+
+func setupGraphQLTest(public bool) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    api := NewGraphQLAPI(nil, nil, nil, cms.NewLocaleConfig(), cache.NewStore(cache.NewMemoryBackend()), public)
+    api.RegisterRoutes(router.Group("/api/v1"))
+    return router
+}
+
+func graphQLErrors(t *testing.T, w *httptest.ResponseRecorder) []map[string]interface{} {
+    var response struct {
+        Errors []map[string]interface{} `json:"errors"`
+    }
+    assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+    return response.Errors
+}
+
+func TestGraphQLSchema(t *testing.T) {
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/graphql/schema", nil)
+    setupGraphQLTest(true).ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Contains(t, w.Body.String(), "type Content {")
+    assert.Contains(t, w.Body.String(), "contents(")
+    assert.Contains(t, w.Body.String(), "content(slug: String!, locale: String): Content")
+    // El esquema público expone la plantilla y la imagen de cada contenido,
+    // pero no sus listados
+    assert.Contains(t, w.Body.String(), "template: Template")
+    assert.Contains(t, w.Body.String(), "ogImage: Media")
+    assert.NotContains(t, w.Body.String(), "templates(")
+    assert.NotContains(t, w.Body.String(), "mediaList")
+
+    w = httptest.NewRecorder()
+    setupGraphQLTest(false).ServeHTTP(w, req)
+    assert.Contains(t, w.Body.String(), "type Template {")
+    assert.Contains(t, w.Body.String(), "content(id: ID!): Content")
+    assert.Contains(t, w.Body.String(), "mediaList(")
+}
+
+func TestGraphQLInvalidBody(t *testing.T) {
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/graphql", strings.NewReader("{"))
+    setupGraphQLTest(true).ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGraphQLValidation(t *testing.T) {
+    t.Setenv("GRAPHQL_MAX_DEPTH", "4")
+    router := setupGraphQLTest(true)
+
+    tests := []struct {
+        name  string
+        query string
+        code  string
+    }{
+        {"unknown field", `{ books { data { isbn } } }`, ""},
+        {"too deep", `{ contents { data { translations { translations { slug } } } } }`, "QUERY_TOO_DEEP"},
+        {"too complex", `{ contents(limit: 100) { data { translations { title } } } }`, "QUERY_TOO_COMPLEX"},
+        {"templates are cms only", `{ templates { data { name } } }`, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            body, _ := json.Marshal(map[string]string{"query": tt.query})
+            w := httptest.NewRecorder()
+            req, _ := http.NewRequest("POST", "/api/v1/graphql", strings.NewReader(string(body)))
+            router.ServeHTTP(w, req)
+
+            assert.Equal(t, http.StatusOK, w.Code)
+            errors := graphQLErrors(t, w)
+            assert.NotEmpty(t, errors)
+            if tt.code != "" && len(errors) > 0 {
+                assert.Equal(t, tt.code, errors[0]["extensions"].(map[string]interface{})["code"])
+            }
+        })
+    }
+}
+
+func TestGraphQLPersistedQueryNotFound(t *testing.T) {
+    extensions := `{"persistedQuery":{"version":1,"sha256Hash":"0000"}}`
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/graphql?extensions="+url.QueryEscape(extensions), nil)
+    setupGraphQLTest(true).ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+    errors := graphQLErrors(t, w)
+    if assert.Len(t, errors, 1) {
+        assert.Equal(t, "PersistedQueryNotFound", errors[0]["message"])
+    }
+}
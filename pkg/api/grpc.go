@@ -0,0 +1,309 @@
+// pkg/api/grpc.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/url"
+    "os"
+    "strconv"
+    "time"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/database"
+    "ezzygo/pkg/models"
+    "ezzygo/pkg/query"
+    "ezzygo/pkg/rpc"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/timestamppb"
+    "gorm.io/gorm"
+)
+
+// Métodos gRPC que, como sus rutas REST, solo necesitan la API key
+var grpcAPIKeyOnlyMethods = map[string]bool{
+    rpc.BookService_GetBook_FullMethodName:                true,
+    rpc.BookService_ListBooks_FullMethodName:              true,
+    rpc.ContentService_GetPublishedContent_FullMethodName: true,
+}
+
+// exportBatchSize es el número de filas leídas por consulta en ExportContents.
+const exportBatchSize = 100
+
+// NewGRPCServer crea el servidor gRPC de los servicios internos sobre la misma
+// capa de datos que las rutas REST: database.Database para los libros y los
+// servicios del CMS para el contenido y los medios.
+func NewGRPCServer(db database.Database, store *cache.Store, contentService *cms.ContentService, mediaService *cms.MediaService) *grpc.Server {
+    server := grpc.NewServer(rpc.Auth(func(method string) bool {
+        return !grpcAPIKeyOnlyMethods[method]
+    })...)
+    rpc.RegisterBookServiceServer(server, &grpcBookService{db: db, cache: store})
+    rpc.RegisterContentServiceServer(server, &grpcContentService{contents: contentService})
+    rpc.RegisterMediaServiceServer(server, &grpcMediaService{media: mediaService})
+    return server
+}
+
+// GRPCAddr es la dirección del servidor gRPC, GRPC_ADDR o :9001.
+func GRPCAddr() string {
+    if addr := os.Getenv("GRPC_ADDR"); addr != "" {
+        return addr
+    }
+    return ":9001"
+}
+
+type grpcBookService struct {
+    rpc.UnimplementedBookServiceServer
+    db    database.Database
+    cache *cache.Store
+}
+
+func (s *grpcBookService) GetBook(ctx context.Context, req *rpc.GetRequest) (*rpc.Book, error) {
+    var book models.Book
+    if err := s.db.Where("id = ?", req.Id).First(&book).Error(); err != nil {
+        return nil, grpcError(err)
+    }
+    return bookMessage(book), nil
+}
+
+func (s *grpcBookService) ListBooks(ctx context.Context, req *rpc.ListRequest) (*rpc.ListBooksResponse, error) {
+    params, err := grpcListParams(req, bookQuery)
+    if err != nil {
+        return nil, grpcError(err)
+    }
+
+    // Comparte la caché con GET /books
+    page, err := cache.GetOrLoad(ctx, s.cache, "books:"+params.Key(), cache.Options[*query.Page[models.Book]]{
+        TTL:  time.Minute,
+        Tags: []string{booksTag},
+    }, func(ctx context.Context) (*query.Page[models.Book], error) {
+        return query.Find[models.Book](ctx, s.db.Model(&models.Book{}), params, bookQuery)
+    })
+    if err != nil {
+        return nil, grpcError(err)
+    }
+
+    response := &rpc.ListBooksResponse{Page: pageMessage(page.Page)}
+    for _, book := range page.Data {
+        response.Books = append(response.Books, bookMessage(book))
+    }
+    return response, nil
+}
+
+func (s *grpcBookService) CreateBook(ctx context.Context, req *rpc.CreateBookRequest) (*rpc.Book, error) {
+    if req.Title == "" || req.Author == "" {
+        return nil, status.Errorf(codes.InvalidArgument, "title and author are required")
+    }
+
+    book := models.Book{Title: req.Title, Author: req.Author}
+    if err := s.db.Create(&book).Error; err != nil {
+        return nil, grpcError(err)
+    }
+    if s.cache != nil {
+        s.cache.InvalidateTags(ctx, booksTag)
+    }
+    return bookMessage(book), nil
+}
+
+type grpcContentService struct {
+    rpc.UnimplementedContentServiceServer
+    contents *cms.ContentService
+}
+
+func (s *grpcContentService) GetContent(ctx context.Context, req *rpc.GetRequest) (*rpc.Content, error) {
+    content, err := s.contents.Get(ctx, uint(req.Id))
+    if err != nil {
+        return nil, grpcError(err)
+    }
+    return contentMessage(*content), nil
+}
+
+// GetPublishedContent resuelve el slug como la API de entrega, pero no cuenta
+// la lectura: los servicios internos no son visitas.
+func (s *grpcContentService) GetPublishedContent(ctx context.Context, req *rpc.GetPublishedContentRequest) (*rpc.Content, error) {
+    if req.Slug == "" {
+        return nil, status.Errorf(codes.InvalidArgument, "slug is required")
+    }
+    content, err := s.contents.Resolve(ctx, req.Slug, req.Locale, nil)
+    if err != nil {
+        return nil, grpcError(err)
+    }
+    return contentMessage(*content), nil
+}
+
+func (s *grpcContentService) ListContents(ctx context.Context, req *rpc.ListRequest) (*rpc.ListContentsResponse, error) {
+    params, err := grpcListParams(req, cms.ContentQuery)
+    if err != nil {
+        return nil, grpcError(err)
+    }
+    page, err := s.contents.List(ctx, params)
+    if err != nil {
+        return nil, grpcError(err)
+    }
+
+    response := &rpc.ListContentsResponse{Page: pageMessage(page.Page)}
+    for _, content := range page.Data {
+        response.Contents = append(response.Contents, contentMessage(content))
+    }
+    return response, nil
+}
+
+// ExportContents recorre el listado por id con cursores, de modo que cada
+// consulta es acotada aunque la exportación sea grande.
+func (s *grpcContentService) ExportContents(req *rpc.ExportContentsRequest, stream grpc.ServerStreamingServer[rpc.Content]) error {
+    batchSize := req.BatchSize
+    if batchSize <= 0 {
+        batchSize = exportBatchSize
+    }
+    params, err := grpcListParams(&rpc.ListRequest{Filter: req.Filter, Limit: batchSize, Sort: "id"}, cms.ContentQuery)
+    if err != nil {
+        return grpcError(err)
+    }
+
+    for {
+        page, err := s.contents.List(stream.Context(), params)
+        if err != nil {
+            return grpcError(err)
+        }
+        for _, content := range page.Data {
+            if err := stream.Send(contentMessage(content)); err != nil {
+                return err
+            }
+        }
+        if page.Page.NextCursor == "" {
+            return nil
+        }
+        params.Cursor = page.Page.NextCursor
+    }
+}
+
+type grpcMediaService struct {
+    rpc.UnimplementedMediaServiceServer
+    media *cms.MediaService
+}
+
+func (s *grpcMediaService) GetMedia(ctx context.Context, req *rpc.GetRequest) (*rpc.Media, error) {
+    media, err := s.media.Get(ctx, uint(req.Id))
+    if err != nil {
+        return nil, grpcError(err)
+    }
+    return mediaMessage(*media), nil
+}
+
+func (s *grpcMediaService) ListMedia(ctx context.Context, req *rpc.ListRequest) (*rpc.ListMediaResponse, error) {
+    params, err := grpcListParams(req, cms.MediaQuery)
+    if err != nil {
+        return nil, grpcError(err)
+    }
+    page, err := s.media.List(ctx, params)
+    if err != nil {
+        return nil, grpcError(err)
+    }
+
+    response := &rpc.ListMediaResponse{Page: pageMessage(page.Page)}
+    for _, media := range page.Data {
+        response.Media = append(response.Media, mediaMessage(media))
+    }
+    return response, nil
+}
+
+// grpcListParams lee un ListRequest como la query string de los listados REST.
+func grpcListParams(req *rpc.ListRequest, schema query.Schema) (query.Params, error) {
+    values := url.Values{}
+    for param, value := range req.Filter {
+        values.Set(param, value)
+    }
+    // Las respuestas gRPC tienen un esquema fijo
+    values.Del("fields")
+    if req.Limit > 0 {
+        values.Set("limit", strconv.Itoa(int(req.Limit)))
+    }
+    if req.Cursor != "" {
+        values.Set("cursor", req.Cursor)
+    }
+    if req.Sort != "" {
+        values.Set("sort", req.Sort)
+    }
+    if req.Total {
+        values.Set("total", "true")
+    }
+    return query.Parse(values, schema)
+}
+
+// grpcError traduce los errores de la capa de datos a códigos gRPC, como
+// hacen los handlers REST con los códigos HTTP.
+func grpcError(err error) error {
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        return status.Error(codes.NotFound, "not found")
+    case errors.Is(err, query.ErrInvalidQuery), errors.Is(err, cms.ErrUnsupportedLocale):
+        return status.Errorf(codes.InvalidArgument, "%v", err)
+    case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+        return status.FromContextError(err).Err()
+    }
+    return status.Errorf(codes.Internal, "%v", err)
+}
+
+func pageMessage(page query.PageInfo) *rpc.PageInfo {
+    return &rpc.PageInfo{
+        Limit:      int32(page.Limit),
+        NextCursor: page.NextCursor,
+        PrevCursor: page.PrevCursor,
+        Total:      page.Total,
+    }
+}
+
+func bookMessage(book models.Book) *rpc.Book {
+    return &rpc.Book{
+        Id:        uint64(book.ID),
+        Title:     book.Title,
+        Author:    book.Author,
+        CreatedAt: timestamppb.New(book.CreatedAt),
+        UpdatedAt: timestamppb.New(book.UpdatedAt),
+    }
+}
+
+func contentMessage(content cms.Content) *rpc.Content {
+    return &rpc.Content{
+        Id:                 uint64(content.ID),
+        Title:              content.Title,
+        Slug:               content.Slug,
+        Locale:             content.Locale,
+        TranslationGroupId: uint64(content.TranslationGroupID),
+        Content:            content.Content,
+        Status:             content.Status,
+        TemplateId:         uint64(content.TemplateID),
+        AuthorId:           uint64(content.AuthorID),
+        PublishedAt:        timestamppb.New(content.PublishedAt),
+        EmbargoUntil:       timestampOrNil(content.EmbargoUntil),
+        ExpiresAt:          timestampOrNil(content.ExpiresAt),
+        Tags:               []string(content.Tags),
+        MetaData:           []byte(content.MetaData),
+        Revision:           int64(content.Revision),
+        ViewCount:          content.ViewCount,
+        CreatedAt:          timestamppb.New(content.CreatedAt),
+        UpdatedAt:          timestamppb.New(content.UpdatedAt),
+    }
+}
+
+func mediaMessage(media cms.Media) *rpc.Media {
+    return &rpc.Media{
+        Id:        uint64(media.ID),
+        Name:      media.Name,
+        Type:      media.Type,
+        Url:       media.URL,
+        Size:      media.Size,
+        Path:      media.Path,
+        MimeType:  media.MimeType,
+        CreatedAt: timestamppb.New(media.CreatedAt),
+        UpdatedAt: timestamppb.New(media.UpdatedAt),
+    }
+}
+
+// timestampOrNil deja sin valor las fechas opcionales vacías.
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+    if t == nil {
+        return nil
+    }
+    return timestamppb.New(*t)
+}
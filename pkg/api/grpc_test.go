@@ -0,0 +1,47 @@
+// pkg/api/grpc_test.go
+package api
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
+    "ezzygo/pkg/rpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "gorm.io/gorm"
+)
+
+func TestGRPCListParams(t *testing.T) {
+    params, err := grpcListParams(&rpc.ListRequest{
+        Limit:  5,
+        Sort:   "-id",
+        Filter: map[string]string{"status": "published", "fields": "title"},
+        Total:  true,
+    }, cms.ContentQuery)
+    require.NoError(t, err)
+    assert.Equal(t, 5, params.Limit)
+    assert.True(t, params.Total)
+    assert.Len(t, params.Filters, 1)
+    assert.Empty(t, params.Fields)
+
+    _, err = grpcListParams(&rpc.ListRequest{Sort: "unknown"}, cms.ContentQuery)
+    assert.True(t, errors.Is(err, query.ErrInvalidQuery))
+}
+
+func TestGRPCError(t *testing.T) {
+    assert.Equal(t, codes.NotFound, status.Code(grpcError(gorm.ErrRecordNotFound)))
+    assert.Equal(t, codes.InvalidArgument, status.Code(grpcError(query.ErrInvalidQuery)))
+    assert.Equal(t, codes.InvalidArgument, status.Code(grpcError(cms.ErrUnsupportedLocale)))
+    assert.Equal(t, codes.DeadlineExceeded, status.Code(grpcError(context.DeadlineExceeded)))
+    assert.Equal(t, codes.Internal, status.Code(grpcError(errors.New("connection refused"))))
+}
+
+func TestGRPCAuth(t *testing.T) {
+    assert.True(t, grpcAPIKeyOnlyMethods[rpc.BookService_ListBooks_FullMethodName])
+    assert.False(t, grpcAPIKeyOnlyMethods[rpc.BookService_CreateBook_FullMethodName])
+    assert.False(t, grpcAPIKeyOnlyMethods[rpc.ContentService_ExportContents_FullMethodName])
+}
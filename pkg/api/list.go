@@ -0,0 +1,53 @@
+// pkg/api/list.go
+package api
+
+import (
+    "errors"
+    "net/http"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/query"
+)
+
+// parseListParams lee limit, cursor, sort, total, fields y los filtros de la
+// query; fields se valida contra model. Si no son válidos responde 400 y
+// devuelve false.
+func parseListParams(c *gin.Context, schema query.Schema, model interface{}) (query.Params, bool) {
+    params, err := query.Parse(c.Request.URL.Query(), schema)
+    if err == nil {
+        _, err = params.Fields.Columns(model)
+    }
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return params, false
+    }
+    return params, true
+}
+
+// parseFields lee ?fields= de los endpoints que devuelven una sola entidad.
+func parseFields(c *gin.Context, model interface{}) (query.Fieldset, bool) {
+    fields := query.ParseFieldset(c.Query("fields"))
+    if _, err := fields.Columns(model); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return nil, false
+    }
+    return fields, true
+}
+
+// writePage responde con la página, reducida a los campos pedidos.
+func writePage[T any](c *gin.Context, page *query.Page[T], fields query.Fieldset) {
+    body, err := page.Project(fields)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, body)
+}
+
+// writeListError responde 400 si el cursor no es válido para la consulta.
+func writeListError(c *gin.Context, err error) {
+    if errors.Is(err, query.ErrInvalidQuery) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,256 @@
+// pkg/api/lock.go
+package api
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+// Intervalo de los comentarios keep-alive del stream SSE, para que los
+// proxies no corten la conexión
+const presenceKeepAlive = 15 * time.Second
+
+type LockService interface {
+    Acquire(ctx context.Context, contentID uint, username string) (*cms.EditLock, error)
+    Heartbeat(ctx context.Context, contentID uint, username string) (*cms.EditLock, error)
+    Release(ctx context.Context, contentID uint, username string) error
+    Takeover(ctx context.Context, contentID uint, username string) (*cms.EditLock, error)
+    Get(ctx context.Context, contentID uint) (*cms.EditLock, error)
+    Touch(ctx context.Context, contentID uint, username, mode string) error
+    Leave(ctx context.Context, contentID uint, username string) error
+    Presence(ctx context.Context, contentID uint) ([]cms.Presence, error)
+    Subscribe(ctx context.Context) (<-chan cms.PresenceEvent, func(), error)
+}
+
+type LockAPI struct {
+    service LockService
+}
+
+type PresenceRequest struct {
+    Mode string `json:"mode" binding:"required"`
+}
+
+func NewLockAPI(service LockService) *LockAPI {
+    return &LockAPI{service: service}
+}
+
+func (api *LockAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/content/:id/lock", api.Get)
+    router.POST("/content/:id/lock", api.Acquire)
+    router.POST("/content/:id/lock/heartbeat", api.Heartbeat)
+    router.POST("/content/:id/lock/takeover", api.Takeover)
+    router.DELETE("/content/:id/lock", api.Release)
+    router.GET("/content/:id/presence", api.Presence)
+    router.POST("/content/:id/presence", api.Touch)
+    router.DELETE("/content/:id/presence", api.Leave)
+    router.GET("/presence/stream", api.Stream)
+}
+
+func (api *LockAPI) Get(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    lock, err := api.service.Get(c.Request.Context(), id)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    if lock == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "content is not locked"})
+        return
+    }
+
+    c.JSON(http.StatusOK, lock)
+}
+
+func (api *LockAPI) Acquire(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    lock, err := api.service.Acquire(c.Request.Context(), id, c.GetString("username"))
+    if err != nil {
+        writeLockError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, lock)
+}
+
+func (api *LockAPI) Heartbeat(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    lock, err := api.service.Heartbeat(c.Request.Context(), id, c.GetString("username"))
+    if err != nil {
+        writeLockError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, lock)
+}
+
+func (api *LockAPI) Release(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    if err := api.service.Release(c.Request.Context(), id, c.GetString("username")); err != nil {
+        writeLockError(c, err)
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+func (api *LockAPI) Takeover(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    lock, err := api.service.Takeover(c.Request.Context(), id, c.GetString("username"))
+    if err != nil {
+        writeLockError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, lock)
+}
+
+func (api *LockAPI) Presence(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    presence, err := api.service.Presence(c.Request.Context(), id)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, presence)
+}
+
+func (api *LockAPI) Touch(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    var request PresenceRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := api.service.Touch(c.Request.Context(), id, c.GetString("username"), request.Mode); err != nil {
+        writeLockError(c, err)
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+func (api *LockAPI) Leave(c *gin.Context) {
+    id, ok := parseContentID(c)
+    if !ok {
+        return
+    }
+
+    if err := api.service.Leave(c.Request.Context(), id, c.GetString("username")); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+// Stream envía los eventos de presencia y locks como Server-Sent Events.
+// Con ?content_id= solo se envían los de ese contenido.
+func (api *LockAPI) Stream(c *gin.Context) {
+    var contentID uint
+    if value := c.Query("content_id"); value != "" {
+        id, err := strconv.ParseUint(value, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid content_id"})
+            return
+        }
+        contentID = uint(id)
+    }
+
+    events, cancel, err := api.service.Subscribe(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    defer cancel()
+
+    c.Header("Content-Type", "text/event-stream")
+    c.Header("Cache-Control", "no-cache")
+    c.Header("X-Accel-Buffering", "no")
+    c.Status(http.StatusOK)
+    c.Writer.Flush()
+
+    keepAlive := time.NewTicker(presenceKeepAlive)
+    defer keepAlive.Stop()
+
+    for {
+        select {
+        case <-c.Request.Context().Done():
+            return
+        case <-keepAlive.C:
+            io.WriteString(c.Writer, ": keep-alive\n\n")
+        case event, ok := <-events:
+            if !ok {
+                return
+            }
+            if contentID != 0 && event.ContentID != contentID {
+                continue
+            }
+            c.SSEvent(event.Type, event)
+        }
+        c.Writer.Flush()
+    }
+}
+
+func writeLockError(c *gin.Context, err error) {
+    var locked *cms.LockedError
+    switch {
+    case errors.As(err, &locked):
+        c.JSON(http.StatusLocked, gin.H{"error": err.Error(), "lock": locked.Lock})
+    case errors.Is(err, cms.ErrLockNotHeld):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, cms.ErrTakeoverForbidden):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, cms.ErrInvalidPresence):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func parseContentID(c *gin.Context) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return 0, false
+    }
+    return uint(id), true
+}
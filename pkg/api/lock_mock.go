@@ -0,0 +1,72 @@
+// pkg/api/lock_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockLockService struct {
+    mock.Mock
+}
+
+func (m *MockLockService) Acquire(ctx context.Context, contentID uint, username string) (*cms.EditLock, error) {
+    args := m.Called(ctx, contentID, username)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.EditLock), args.Error(1)
+}
+
+func (m *MockLockService) Heartbeat(ctx context.Context, contentID uint, username string) (*cms.EditLock, error) {
+    args := m.Called(ctx, contentID, username)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.EditLock), args.Error(1)
+}
+
+func (m *MockLockService) Release(ctx context.Context, contentID uint, username string) error {
+    args := m.Called(ctx, contentID, username)
+    return args.Error(0)
+}
+
+func (m *MockLockService) Takeover(ctx context.Context, contentID uint, username string) (*cms.EditLock, error) {
+    args := m.Called(ctx, contentID, username)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.EditLock), args.Error(1)
+}
+
+func (m *MockLockService) Get(ctx context.Context, contentID uint) (*cms.EditLock, error) {
+    args := m.Called(ctx, contentID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.EditLock), args.Error(1)
+}
+
+func (m *MockLockService) Touch(ctx context.Context, contentID uint, username, mode string) error {
+    args := m.Called(ctx, contentID, username, mode)
+    return args.Error(0)
+}
+
+func (m *MockLockService) Leave(ctx context.Context, contentID uint, username string) error {
+    args := m.Called(ctx, contentID, username)
+    return args.Error(0)
+}
+
+func (m *MockLockService) Presence(ctx context.Context, contentID uint) ([]cms.Presence, error) {
+    args := m.Called(ctx, contentID)
+    return args.Get(0).([]cms.Presence), args.Error(1)
+}
+
+func (m *MockLockService) Subscribe(ctx context.Context) (<-chan cms.PresenceEvent, func(), error) {
+    args := m.Called(ctx)
+    if args.Get(0) == nil {
+        return nil, nil, args.Error(1)
+    }
+    return args.Get(0).(chan cms.PresenceEvent), func() {}, args.Error(1)
+}
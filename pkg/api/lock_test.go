@@ -0,0 +1,133 @@
+// pkg/api/lock_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupLockTest() (*gin.Engine, *MockLockService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockLockService)
+    router := gin.New()
+    router.Use(func(c *gin.Context) {
+        c.Set("username", "editor")
+        c.Next()
+    })
+    api := NewLockAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestLockAcquire(t *testing.T) {
+    router, mockService := setupLockTest()
+    lock := &cms.EditLock{ContentID: 1, Username: "editor", ExpiresAt: time.Now().Add(time.Minute)}
+
+    mockService.On("Acquire", mock.Anything, uint(1), "editor").Return(lock, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/lock", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestLockAcquireLocked(t *testing.T) {
+    router, mockService := setupLockTest()
+    holder := &cms.EditLock{ContentID: 1, Username: "other"}
+
+    mockService.On("Acquire", mock.Anything, uint(1), "editor").Return(nil, &cms.LockedError{Lock: holder})
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/lock", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusLocked, w.Code)
+
+    var response struct {
+        Lock cms.EditLock `json:"lock"`
+    }
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "other", response.Lock.Username)
+    mockService.AssertExpectations(t)
+}
+
+func TestLockHeartbeatLost(t *testing.T) {
+    router, mockService := setupLockTest()
+    mockService.On("Heartbeat", mock.Anything, uint(1), "editor").Return(nil, cms.ErrLockNotHeld)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/lock/heartbeat", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusConflict, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestLockTakeoverForbidden(t *testing.T) {
+    router, mockService := setupLockTest()
+    mockService.On("Takeover", mock.Anything, uint(1), "editor").Return(nil, cms.ErrTakeoverForbidden)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/lock/takeover", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusForbidden, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestLockGetUnlocked(t *testing.T) {
+    router, mockService := setupLockTest()
+    mockService.On("Get", mock.Anything, uint(1)).Return(nil, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/lock", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestPresenceTouch(t *testing.T) {
+    router, mockService := setupLockTest()
+    mockService.On("Touch", mock.Anything, uint(1), "editor", cms.PresenceViewing).Return(nil)
+
+    body, _ := json.Marshal(PresenceRequest{Mode: cms.PresenceViewing})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/presence", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNoContent, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestPresenceStream(t *testing.T) {
+    router, mockService := setupLockTest()
+    events := make(chan cms.PresenceEvent, 2)
+    events <- cms.PresenceEvent{Type: cms.EventLock, ContentID: 1, Username: "editor"}
+    events <- cms.PresenceEvent{Type: cms.EventPresence, ContentID: 2, Username: "other"}
+    close(events)
+
+    mockService.On("Subscribe", mock.Anything).Return(events, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/presence/stream?content_id=1", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+    assert.True(t, strings.Contains(w.Body.String(), "event:lock"))
+    assert.False(t, strings.Contains(w.Body.String(), "other"))
+    mockService.AssertExpectations(t)
+}
@@ -1,80 +1,114 @@
-// pkg/cms/media.go
-package cms
+// pkg/api/media.go
+package api
 
 import (
     "context"
+    "errors"
     "io"
-    "path/filepath"
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
     "gorm.io/gorm"
-    "ezzygo/pkg/storage"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
-type MediaService struct {
-    db      *gorm.DB
-    storage *storage.S3Storage
+type MediaService interface {
+    Upload(ctx context.Context, media *cms.Media, file io.Reader) error
+    Get(ctx context.Context, id uint) (*cms.Media, error)
+    List(ctx context.Context, params query.Params) (*query.Page[cms.Media], error)
+    Delete(ctx context.Context, id uint) error
 }
 
-func NewMediaService(db *gorm.DB, storage *storage.S3Storage) *MediaService {
-    return &MediaService{
-        db:      db,
-        storage: storage,
-    }
+type MediaAPI struct {
+    service MediaService
+}
+
+func NewMediaAPI(service MediaService) *MediaAPI {
+    return &MediaAPI{service: service}
 }
 
-func (s *MediaService) Upload(ctx context.Context, media *Media, file io.Reader) error {
-    url, err := s.storage.Upload(ctx, media.Name, file, media.MimeType)
+// Upload sube el fichero del campo file de un formulario multipart. El tipo
+// se toma del campo type o, si no viene, del tipo MIME (image, video...).
+func (api *MediaAPI) Upload(c *gin.Context) {
+    header, err := c.FormFile("file")
     if err != nil {
-        return err
+        c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+        return
     }
+    file, err := header.Open()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    defer file.Close()
 
-    media.URL = url
-    media.Path = filepath.Base(url)
-
-    return s.db.WithContext(ctx).Create(media).Error
-}
+    mimeType := header.Header.Get("Content-Type")
+    mediaType, _, _ := strings.Cut(mimeType, "/")
+    media := cms.Media{
+        Name:     header.Filename,
+        Type:     c.DefaultPostForm("type", mediaType),
+        Size:     header.Size,
+        MimeType: mimeType,
+    }
 
-func (s *MediaService) Get(ctx context.Context, id uint) (*Media, error) {
-    var media Media
-    if err := s.db.WithContext(ctx).First(&media, id).Error; err != nil {
-        return nil, err
+    if err := api.service.Upload(c.Request.Context(), &media, file); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
     }
-    return &media, nil
+
+    c.JSON(http.StatusCreated, media)
 }
 
-func (s *MediaService) List(ctx context.Context, filter MediaFilter) ([]Media, error) {
-    var medias []Media
-    query := s.db.WithContext(ctx)
+func (api *MediaAPI) Get(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
 
-    if filter.Type != "" {
-        query = query.Where("type = ?", filter.Type)
+    media, err := api.service.Get(c.Request.Context(), uint(id))
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+        return
     }
 
-    return medias, query.Find(&medias).Error
+    c.JSON(http.StatusOK, media)
 }
 
-func (s *MediaService) Delete(ctx context.Context, id uint) error {
-    var media Media
-    if err := s.db.WithContext(ctx).First(&media, id).Error; err != nil {
-        return err
+// List devuelve una página de medios con los mismos parámetros que el resto
+// de listados (limit, cursor, sort, filtros por campo y fields).
+func (api *MediaAPI) List(c *gin.Context) {
+    params, ok := parseListParams(c, cms.MediaQuery, &cms.Media{})
+    if !ok {
+        return
     }
 
-    if err := s.storage.Delete(ctx, media.Path); err != nil {
-        return err
+    page, err := api.service.List(c.Request.Context(), params)
+    if err != nil {
+        writeListError(c, err)
+        return
     }
 
-    return s.db.WithContext(ctx).Delete(&media).Error
+    writePage(c, page, params.Fields)
 }
 
-type Media struct {
-    gorm.Model
-    Name     string `json:"name"`
-    Type     string `json:"type"`
-    URL      string `json:"url"`
-    Size     int64  `json:"size"`
-    Path     string `json:"path"`
-    MimeType string `json:"mime_type"`
-}
+func (api *MediaAPI) Delete(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    if err := api.service.Delete(c.Request.Context(), uint(id)); err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
 
-type MediaFilter struct {
-    Type string
+    c.Status(http.StatusNoContent)
 }
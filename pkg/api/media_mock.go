@@ -6,6 +6,7 @@ import (
     "io"
     "github.com/stretchr/testify/mock"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
 type MockMediaService struct {
@@ -25,9 +26,12 @@ func (m *MockMediaService) Get(ctx context.Context, id uint) (*cms.Media, error)
     return args.Get(0).(*cms.Media), args.Error(1)
 }
 
-func (m *MockMediaService) List(ctx context.Context, filter cms.MediaFilter) ([]cms.Media, error) {
-    args := m.Called(ctx, filter)
-    return args.Get(0).([]cms.Media), args.Error(1)
+func (m *MockMediaService) List(ctx context.Context, params query.Params) (*query.Page[cms.Media], error) {
+    args := m.Called(ctx, params)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*query.Page[cms.Media]), args.Error(1)
 }
 
 func (m *MockMediaService) Delete(ctx context.Context, id uint) error {
@@ -1,136 +1,91 @@
-// pkg/cms/media_test.go
-package cms
+// pkg/api/media_test.go
+package api
 
 import (
     "bytes"
-    "context"
-    "io"
+    "mime/multipart"
+    "net/http"
+    "net/http/httptest"
     "testing"
+    "github.com/gin-gonic/gin"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
-type MockS3Storage struct {
-    mock.Mock
-}
-
-func (m *MockS3Storage) Upload(ctx context.Context, filePath string, content io.Reader, contentType string) (string, error) {
-    args := m.Called(ctx, filePath, content, contentType)
-    return args.String(0), args.Error(1)
-}
-
-func (m *MockS3Storage) Delete(ctx context.Context, key string) error {
-    args := m.Called(ctx, key)
-    return args.Error(0)
-}
-
-func (m *MockS3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
-    args := m.Called(ctx, key)
-    return args.Get(0).(io.ReadCloser), args.Error(1)
+func setupMediaTest() (*gin.Engine, *MockMediaService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockMediaService)
+    api := NewMediaAPI(mockService)
+    router := gin.New()
+    media := router.Group("/api/v1/cms/media")
+    media.POST("/upload", api.Upload)
+    media.GET("/", api.List)
+    media.GET("/:id", api.Get)
+    media.DELETE("/:id", api.Delete)
+    return router, mockService
 }
 
 func TestMediaUpload(t *testing.T) {
-    db, mock, err := sqlmock.New()
-    assert.NoError(t, err)
-    defer db.Close()
-
-    gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
-    assert.NoError(t, err)
-
-    mockStorage := new(MockS3Storage)
-    service := NewMediaService(gormDB, mockStorage)
-
-    media := &Media{
-        Name:     "test.jpg",
-        MimeType: "image/jpeg",
-        Size:     1024,
-    }
-
-    fileContent := []byte("test content")
-    reader := bytes.NewReader(fileContent)
-
-    expectedURL := "https://bucket.s3.region.amazonaws.com/test.jpg"
-    mockStorage.On("Upload", mock.Anything, media.Name, reader, media.MimeType).Return(expectedURL, nil)
-
-    mock.ExpectBegin()
-    mock.ExpectExec("INSERT INTO media").WillReturnResult(sqlmock.NewResult(1, 1))
-    mock.ExpectCommit()
-
-    err = service.Upload(context.Background(), media, reader)
-    assert.NoError(t, err)
-    assert.Equal(t, expectedURL, media.URL)
+    router, mockService := setupMediaTest()
+    mockService.On("Upload", mock.Anything, mock.MatchedBy(func(media *cms.Media) bool {
+        return media.Name == "photo.png" && media.Type == "image" && media.MimeType == "image/png" && media.Size == 4
+    }), mock.Anything).Return(nil)
+
+    var body bytes.Buffer
+    form := multipart.NewWriter(&body)
+    part, _ := form.CreatePart(map[string][]string{
+        "Content-Disposition": {`form-data; name="file"; filename="photo.png"`},
+        "Content-Type":        {"image/png"},
+    })
+    part.Write([]byte("data"))
+    form.Close()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/media/upload", &body)
+    req.Header.Set("Content-Type", form.FormDataContentType())
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+    mockService.AssertExpectations(t)
 }
 
-func TestMediaDelete(t *testing.T) {
-    db, mock, err := sqlmock.New()
-    assert.NoError(t, err)
-    defer db.Close()
-
-    gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
-    assert.NoError(t, err)
+func TestMediaListPage(t *testing.T) {
+    router, mockService := setupMediaTest()
+    mockService.On("List", mock.Anything, mock.MatchedBy(func(params query.Params) bool {
+        return params.Limit == 5 && len(params.Filters) == 1 && params.Filters[0].Field == "type"
+    })).Return(&query.Page[cms.Media]{Data: []cms.Media{{Name: "a.png"}}, Page: query.PageInfo{NextCursor: "abc"}}, nil)
 
-    mockStorage := new(MockS3Storage)
-    service := NewMediaService(gormDB, mockStorage)
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/media/?type=image&limit=5", nil)
+    router.ServeHTTP(w, req)
 
-    media := &Media{
-        Path: "test.jpg",
-    }
-    media.ID = 1
-
-    rows := sqlmock.NewRows([]string{"id", "path"}).AddRow(1, "test.jpg")
-    mock.ExpectQuery("^SELECT (.+) FROM media").WillReturnRows(rows)
-    
-    mockStorage.On("Delete", mock.Anything, media.Path).Return(nil)
-    
-    mock.ExpectBegin()
-    mock.ExpectExec("DELETE FROM media").WillReturnResult(sqlmock.NewResult(1, 1))
-    mock.ExpectCommit()
-
-    err = service.Delete(context.Background(), media.ID)
-    assert.NoError(t, err)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Contains(t, w.Body.String(), `"next_cursor":"abc"`)
+    mockService.AssertExpectations(t)
 }
 
-func TestMediaGet(t *testing.T) {
-    db, mock, err := sqlmock.New()
-    assert.NoError(t, err)
-    defer db.Close()
-
-    gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
-    assert.NoError(t, err)
+func TestMediaListInvalidSort(t *testing.T) {
+    router, mockService := setupMediaTest()
 
-    mockStorage := new(MockS3Storage)
-    service := NewMediaService(gormDB, mockStorage)
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/media/?sort=url", nil)
+    router.ServeHTTP(w, req)
 
-    rows := sqlmock.NewRows([]string{"id", "name", "type", "url"}).
-        AddRow(1, "test.jpg", "image", "https://example.com/test.jpg")
-
-    mock.ExpectQuery("^SELECT (.+) FROM media").WillReturnRows(rows)
-
-    media, err := service.Get(context.Background(), 1)
-    assert.NoError(t, err)
-    assert.Equal(t, "test.jpg", media.Name)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
 }
 
-func TestMediaList(t *testing.T) {
-    db, mock, err := sqlmock.New()
-    assert.NoError(t, err)
-    defer db.Close()
-
-    gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
-    assert.NoError(t, err)
-
-    mockStorage := new(MockS3Storage)
-    service := NewMediaService(gormDB, mockStorage)
-
-    rows := sqlmock.NewRows([]string{"id", "name", "type"}).
-        AddRow(1, "test1.jpg", "image").
-        AddRow(2, "test2.jpg", "image")
+func TestMediaDeleteNotFound(t *testing.T) {
+    router, mockService := setupMediaTest()
+    mockService.On("Delete", mock.Anything, uint(9)).Return(gorm.ErrRecordNotFound)
 
-    mock.ExpectQuery("^SELECT (.+) FROM media").WillReturnRows(rows)
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("DELETE", "/api/v1/cms/media/9", nil)
+    router.ServeHTTP(w, req)
 
-    filter := MediaFilter{Type: "image"}
-    medias, err := service.List(context.Background(), filter)
-    
-    assert.NoError(t, err)
-    assert.Equal(t, 2, len(medias))
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    mockService.AssertExpectations(t)
 }
@@ -0,0 +1,123 @@
+// pkg/api/promotion.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+type PromotionService interface {
+    Environments() []cms.Environment
+    Preview(ctx context.Context, req cms.PromotionRequest) (*cms.PromotionPreview, error)
+    Promote(ctx context.Context, req cms.PromotionRequest) (*cms.Promotion, error)
+    List(ctx context.Context, filter cms.PromotionFilter) ([]cms.Promotion, error)
+    Get(ctx context.Context, id uint) (*cms.Promotion, error)
+}
+
+type PromotionAPI struct {
+    service PromotionService
+}
+
+func NewPromotionAPI(service PromotionService) *PromotionAPI {
+    return &PromotionAPI{service: service}
+}
+
+// RegisterRoutes registra las rutas de promoción. Promocionar importa un
+// bundle en destino sin pasar por el workflow, así que router debe estar
+// restringido a administradores.
+func (api *PromotionAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/environments", api.Environments)
+
+    promotions := router.Group("/promotions")
+    {
+        promotions.POST("/preview", api.Preview)
+        promotions.POST("/", api.Promote)
+        promotions.GET("/", api.List)
+        promotions.GET("/:id", api.Get)
+    }
+}
+
+func (api *PromotionAPI) Environments(c *gin.Context) {
+    c.JSON(http.StatusOK, api.service.Environments())
+}
+
+// Preview devuelve las dependencias que se añadirían y el diff contra el
+// destino, sin escribir nada.
+func (api *PromotionAPI) Preview(c *gin.Context) {
+    var req cms.PromotionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    preview, err := api.service.Preview(c.Request.Context(), req)
+    if err != nil {
+        writePromotionError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, preview)
+}
+
+// Promote aplica la promoción y devuelve su registro de auditoría.
+func (api *PromotionAPI) Promote(c *gin.Context) {
+    var req cms.PromotionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    promotion, err := api.service.Promote(c.Request.Context(), req)
+    if err != nil {
+        writePromotionError(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, promotion)
+}
+
+// List devuelve el registro de promociones, filtrable por ?environment= y
+// ?promoted_by=.
+func (api *PromotionAPI) List(c *gin.Context) {
+    promotions, err := api.service.List(c.Request.Context(), cms.PromotionFilter{
+        Environment: c.Query("environment"),
+        PromotedBy:  c.Query("promoted_by"),
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, promotions)
+}
+
+func (api *PromotionAPI) Get(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    promotion, err := api.service.Get(c.Request.Context(), uint(id))
+    if err != nil {
+        writePromotionError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, promotion)
+}
+
+func writePromotionError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+    case errors.Is(err, cms.ErrImportConflict):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, cms.ErrInvalidPromotion), errors.Is(err, cms.ErrUnknownEnvironment), errors.Is(err, cms.ErrInvalidConflict),
+        errors.Is(err, cms.ErrInvalidBundle), errors.Is(err, cms.ErrUnsupportedLocale), errors.Is(err, cms.ErrInvalidSEO):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
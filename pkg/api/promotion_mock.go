@@ -0,0 +1,49 @@
+// pkg/api/promotion_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockPromotionService struct {
+    mock.Mock
+}
+
+func (m *MockPromotionService) Environments() []cms.Environment {
+    args := m.Called()
+    return args.Get(0).([]cms.Environment)
+}
+
+func (m *MockPromotionService) Preview(ctx context.Context, req cms.PromotionRequest) (*cms.PromotionPreview, error) {
+    args := m.Called(ctx, req)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.PromotionPreview), args.Error(1)
+}
+
+func (m *MockPromotionService) Promote(ctx context.Context, req cms.PromotionRequest) (*cms.Promotion, error) {
+    args := m.Called(ctx, req)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionService) List(ctx context.Context, filter cms.PromotionFilter) ([]cms.Promotion, error) {
+    args := m.Called(ctx, filter)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]cms.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionService) Get(ctx context.Context, id uint) (*cms.Promotion, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Promotion), args.Error(1)
+}
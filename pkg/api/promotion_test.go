@@ -0,0 +1,118 @@
+// pkg/api/promotion_test.go
+package api
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+func setupPromotionTest() (*gin.Engine, *MockPromotionService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockPromotionService)
+    router := gin.New()
+    NewPromotionAPI(mockService).RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestPromotionEnvironments(t *testing.T) {
+    router, mockService := setupPromotionTest()
+    mockService.On("Environments").Return([]cms.Environment{{Name: "production"}, {Name: "staging", Current: true}})
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/environments", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.JSONEq(t, `[{"name":"production","current":false},{"name":"staging","current":true}]`, w.Body.String())
+}
+
+func TestPromotionPreview(t *testing.T) {
+    router, mockService := setupPromotionTest()
+    request := cms.PromotionRequest{Source: "staging", Target: "production", ContentIDs: []uint{3}}
+    mockService.On("Preview", mock.Anything, request).Return(&cms.PromotionPreview{
+        Source: "staging",
+        Target: "production",
+        Dependencies: []cms.PromotionDependency{
+            {Kind: cms.BundleTemplates, SourceID: 2, Key: "post", RequiredBy: []string{"content:en/hello"}},
+        },
+        Report: &cms.ImportReport{DryRun: true, Updated: 1},
+    }, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/promotions/preview", strings.NewReader(`{"source":"staging","target":"production","content_ids":[3]}`))
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response cms.PromotionPreview
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "post", response.Dependencies[0].Key)
+    assert.True(t, response.Report.DryRun)
+    mockService.AssertExpectations(t)
+}
+
+func TestPromotionPromote(t *testing.T) {
+    router, mockService := setupPromotionTest()
+    request := cms.PromotionRequest{Source: "staging", Target: "production", MediaIDs: []uint{5}, Conflict: cms.ConflictFail}
+    mockService.On("Promote", mock.Anything, request).
+        Return(&cms.Promotion{ID: 1, Source: "staging", Target: "production", Status: cms.PromotionCompleted, Created: 1, PromotedBy: "editor"}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/promotions/", strings.NewReader(`{"source":"staging","target":"production","media_ids":[5],"conflict":"fail"}`))
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+    var response cms.Promotion
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "editor", response.PromotedBy)
+    assert.Equal(t, 1, response.Created)
+}
+
+func TestPromotionErrors(t *testing.T) {
+    router, mockService := setupPromotionTest()
+    mockService.On("Promote", mock.Anything, cms.PromotionRequest{Source: "staging", Target: "qa", ContentIDs: []uint{1}}).
+        Return(nil, fmt.Errorf("%w: %q", cms.ErrUnknownEnvironment, "qa"))
+    mockService.On("Promote", mock.Anything, cms.PromotionRequest{Source: "staging", Target: "production", ContentIDs: []uint{1}, Conflict: "fail"}).
+        Return(nil, fmt.Errorf("%w: content en/hello", cms.ErrImportConflict))
+    mockService.On("Get", mock.Anything, uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+    for _, tc := range []struct {
+        method, url, body string
+        code              int
+    }{
+        {"POST", "/api/v1/cms/promotions/", `{"source":"staging","target":"qa","content_ids":[1]}`, http.StatusBadRequest},
+        {"POST", "/api/v1/cms/promotions/", `{"source":"staging","target":"production","content_ids":[1],"conflict":"fail"}`, http.StatusConflict},
+        {"POST", "/api/v1/cms/promotions/", `{"content_ids":"x"}`, http.StatusBadRequest},
+        {"GET", "/api/v1/cms/promotions/9", "", http.StatusNotFound},
+        {"GET", "/api/v1/cms/promotions/x", "", http.StatusBadRequest},
+    } {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+        router.ServeHTTP(w, req)
+        assert.Equal(t, tc.code, w.Code, tc.url+" "+tc.body)
+    }
+}
+
+func TestPromotionList(t *testing.T) {
+    router, mockService := setupPromotionTest()
+    mockService.On("List", mock.Anything, cms.PromotionFilter{Environment: "production", PromotedBy: "editor"}).
+        Return([]cms.Promotion{{ID: 2, Target: "production"}}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/promotions/?environment=production&promoted_by=editor", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response []cms.Promotion
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Len(t, response, 1)
+    mockService.AssertExpectations(t)
+}
@@ -4,11 +4,18 @@ import (
     "context"
     "time"
     "ezzygo/pkg/cache"
+    "ezzygo/pkg/cdn"
+    "ezzygo/pkg/cms"
     "ezzygo/pkg/database"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/frontend"
     "ezzygo/pkg/middleware"
+    "ezzygo/pkg/models"
+    "ezzygo/pkg/rpc"
     "ezzygo/pkg/storage"
     docs "ezzygo/docs"
     "github.com/gin-gonic/gin"
+    "github.com/redis/go-redis/v9"
     swaggerfiles "github.com/swaggo/files"
     ginSwagger "github.com/swaggo/gin-swagger"
     "go.mongodb.org/mongo-driver/mongo"
@@ -20,18 +27,123 @@ func NewRouter(
     logger *zap.Logger,
     mongoCollection *mongo.Collection,
     db database.Database,
-    redisClient cache.Cache,
+    redisClient *redis.Client,
     s3Storage *storage.S3Storage,
     ctx *context.Context,
 ) *gin.Engine {
+    // Caché compartida por repositorios y servicios
+    cacheBackend := cache.NewTieredRedisBackend(redisClient)
+    store := cache.NewStore(cacheBackend)
+
+    // Las etiquetas invalidadas son también las surrogate keys de la CDN
+    store.OnInvalidate(cdn.PurgeHook(cdn.NewPurgerFromEnv(), logger))
+
     // Repositorios existentes
-    bookRepository := NewBookRepository(db, redisClient, ctx)
+    bookRepository := NewBookRepository(db, store, ctx)
     userRepository := NewUserRepository(db, ctx)
 
-    // Nuevos servicios CMS
-    contentAPI := NewContentAPI(db, redisClient)
-    templateAPI := NewTemplateAPI(db, redisClient)
-    mediaAPI := NewMediaAPI(db, s3Storage)
+    // Servicios CMS compartidos
+    gormDB := db.(*database.GormDatabase).DB
+    locales := cms.NewLocaleConfig()
+    workflow, err := cms.NewWorkflow()
+    if err != nil {
+        logger.Fatal("Failed to load CMS workflow", zap.Error(err))
+    }
+    searchIndex, err := cms.NewSearchIndex(gormDB)
+    if err != nil {
+        logger.Fatal("Failed to configure CMS search index", zap.Error(err))
+    }
+    searchIndexer := cms.NewSearchIndexer(gormDB, searchIndex, logger)
+    webhookService := cms.NewWebhookService(gormDB, logger)
+
+    siteTarget := cms.NewSiteTargetFromEnv(s3Storage)
+    seoService := cms.NewSEOService(gormDB, locales)
+    staticExporter := cms.NewStaticExporter(gormDB, siteTarget, locales, seoService, logger)
+
+    // Las escrituras registran sus eventos en el outbox y el bus los reparte:
+    // caché, búsqueda, webhooks, auditoría y sitio estático
+    bus := events.NewBus()
+    cms.SubscribeEvents(bus, store, searchIndexer, webhookService, logger)
+    bus.Subscribe("static", "*", staticExporter.HandleEvent)
+    outbox := events.NewOutbox(gormDB, bus, events.NewRedisStreamFromEnv(redisClient), logger)
+
+    versionService := cms.NewVersionService(gormDB, outbox)
+    viewCounter := cms.NewViewCounter(gormDB, logger)
+    contentService := cms.NewContentService(gormDB, store, locales, workflow, versionService, outbox, viewCounter)
+    templateService := cms.NewTemplateService(gormDB, store, versionService, outbox)
+    workflowService := cms.NewWorkflowService(gormDB, contentService, workflow)
+    schedulerService := cms.NewSchedulerService(gormDB, contentService, versionService)
+    lockService := cms.NewLockService(gormDB, redisClient)
+    synonymService := cms.NewSynonymService(gormDB, store, searchIndex, locales)
+    searchAnalytics := cms.NewSearchAnalytics(mongoCollection, logger)
+    searchService := cms.NewSearchService(gormDB, searchIndex, store, locales, synonymService, searchAnalytics)
+    bundleService := cms.NewBundleService(gormDB, locales, versionService, outbox)
+
+    // Entornos con nombre entre los que se promociona contenido
+    environments, err := cms.NewEnvironmentsFromEnv(bundleService, locales, logger)
+    if err != nil {
+        logger.Fatal("Failed to configure CMS environments", zap.Error(err))
+    }
+
+    // Ejecuta las publicaciones programadas en segundo plano
+    go cms.NewSchedulerRunner(schedulerService, redisClient, logger).Run(*ctx)
+
+    // Escribe en lote las lecturas de la API de entrega
+    go viewCounter.Run(*ctx)
+
+    // Mantiene el índice de búsqueda al día con las escrituras
+    go searchIndexer.Run(*ctx)
+
+    // Aplica las invalidaciones de caché de las demás réplicas
+    go cacheBackend.Run(*ctx)
+
+    // Publica los eventos pendientes y reintenta los fallidos
+    go outbox.Run(*ctx)
+
+    // Entrega los webhooks encolados y sus reintentos
+    go webhookService.Run(*ctx)
+
+    // Regenera el sitio estático tras los cambios, si hay destino configurado
+    if siteTarget != nil {
+        go staticExporter.Run(*ctx)
+    }
+
+    // Servidor gRPC para los servicios internos, en su propio puerto
+    mediaService := cms.NewMediaService(gormDB, s3Storage, outbox)
+    grpcServer := NewGRPCServer(db, store, contentService, mediaService)
+    go func() {
+        if err := rpc.ListenAndServe(*ctx, grpcServer, GRPCAddr()); err != nil {
+            logger.Error("gRPC server stopped", zap.Error(err))
+        }
+    }()
+
+    // Importaciones de WordPress en segundo plano; se reanudan tras un reinicio
+    wxrImporter := cms.NewWXRImporter(gormDB, mediaService, cms.NewAttachmentFetcherFromEnv(), versionService, outbox, store, locales, logger)
+    go wxrImporter.Run(*ctx)
+
+    contentAPI := NewContentAPI(contentService)
+    templateAPI := NewTemplateAPI(templateService)
+    mediaAPI := NewMediaAPI(mediaService)
+    contentVersionAPI := NewVersionAPI(versionService, cms.EntityContent)
+    templateVersionAPI := NewVersionAPI(versionService, cms.EntityTemplate)
+    translationAPI := NewTranslationAPI(contentService)
+    workflowAPI := NewWorkflowAPI(workflowService)
+    scheduleAPI := NewScheduleAPI(schedulerService)
+    lockAPI := NewLockAPI(lockService)
+    searchAPI := NewSearchAPI(searchService)
+    searchAnalyticsAPI := NewSearchAnalyticsAPI(searchAnalytics)
+    synonymAPI := NewSynonymAPI(synonymService)
+    cacheAPI := NewCacheAPI(store)
+    webhookAPI := NewWebhookAPI(webhookService)
+    staticExportAPI := NewStaticExportAPI(staticExporter)
+    bundleAPI := NewBundleAPI(bundleService)
+    promotionAPI := NewPromotionAPI(cms.NewPromotionService(gormDB, environments))
+    wordPressImportAPI := NewWordPressImportAPI(wxrImporter)
+    redirectAPI := frontend.NewRedirectAPI(cms.NewRedirectService(gormDB))
+    frontendAPI := frontend.NewFrontendAPI(contentService, seoService, locales)
+    feedAPI := frontend.NewFeedAPI(cms.NewFeedService(gormDB, store, locales))
+    graphQLAPI := NewGraphQLAPI(gormDB, contentService, templateService, locales, store, false)
+    publicGraphQLAPI := NewGraphQLAPI(gormDB, contentService, templateService, locales, store, true)
 
     r := gin.Default()
 
@@ -62,10 +174,17 @@ func NewRouter(
         v1.DELETE("/books/:id", middleware.APIKeyAuth(), bookRepository.DeleteBook)
         v1.POST("/login", middleware.APIKeyAuth(), userRepository.LoginHandler)
         v1.POST("/register", middleware.APIKeyAuth(), userRepository.RegisterHandler)
+        v1.GET("/users", middleware.APIKeyAuth(), middleware.JWTAuth(),
+            middleware.RequireRole(gormDB, models.RoleAdmin), userRepository.ListUsers)
+        v1.PUT("/users/:username/role", middleware.APIKeyAuth(), middleware.JWTAuth(),
+            middleware.RequireRole(gormDB, models.RoleAdmin), userRepository.SetRoleHandler)
 
         // Nuevas rutas CMS
         cms := v1.Group("/cms", middleware.APIKeyAuth(), middleware.JWTAuth())
         {
+            // Routes that change how the CMS itself runs are admin only
+            admin := cms.Group("", middleware.RequireRole(gormDB, models.RoleAdmin))
+
             // Content routes
             content := cms.Group("/content")
             {
@@ -73,8 +192,10 @@ func NewRouter(
                 content.GET("/", contentAPI.List)
                 content.GET("/:id", contentAPI.Get)
                 content.PUT("/:id", contentAPI.Update)
+                content.PATCH("/:id", contentAPI.Patch)
                 content.DELETE("/:id", contentAPI.Delete)
-                content.POST("/:id/publish", contentAPI.Publish)
+                content.POST("/:id/publish", workflowAPI.Publish)
+                contentVersionAPI.RegisterRoutes(content)
             }
 
             // Template routes
@@ -84,7 +205,9 @@ func NewRouter(
                 templates.GET("/", templateAPI.List)
                 templates.GET("/:id", templateAPI.Get)
                 templates.PUT("/:id", templateAPI.Update)
+                templates.PATCH("/:id", templateAPI.Patch)
                 templates.DELETE("/:id", templateAPI.Delete)
+                templateVersionAPI.RegisterRoutes(templates)
             }
 
             // Media routes
@@ -95,9 +218,64 @@ func NewRouter(
                 media.GET("/:id", mediaAPI.Get)
                 media.DELETE("/:id", mediaAPI.Delete)
             }
+
+            // Translation routes
+            translationAPI.RegisterRoutes(cms)
+
+            // Workflow routes
+            workflowAPI.RegisterRoutes(cms)
+
+            // Schedule routes
+            scheduleAPI.RegisterRoutes(cms)
+
+            // Edit lock and presence routes
+            lockAPI.RegisterRoutes(cms)
+
+            // Search routes
+            searchAPI.RegisterRoutes(cms)
+            searchAnalyticsAPI.RegisterRoutes(cms)
+            synonymAPI.RegisterRoutes(cms)
+
+            // Cache counters
+            cacheAPI.RegisterRoutes(cms)
+
+            // Webhook subscriptions and delivery log. The server sends requests
+            // to the configured URLs, so only admins manage them
+            webhookAPI.RegisterRoutes(admin)
+
+            // Static site export
+            staticExportAPI.RegisterRoutes(cms)
+
+            // Content bundles to move content between environments. Imports
+            // set the status without the workflow, so only admins use them
+            bundleAPI.RegisterRoutes(admin)
+
+            // Environments and content promotion between them. A promotion
+            // imports like a bundle, so it is admin only too
+            promotionAPI.RegisterRoutes(admin)
+
+            // WordPress (WXR) import jobs. They fetch the attachment URLs of
+            // the uploaded file and create users and published content
+            wordPressImportAPI.RegisterRoutes(admin)
+
+            // GraphQL sobre todo el CMS
+            graphQLAPI.RegisterRoutes(cms)
         }
+
+        // API de entrega para el frontend
+        public := v1.Group("", middleware.APIKeyAuth(), middleware.ResponseCache(middleware.CachePolicyFromEnv()))
+        frontendAPI.RegisterRoutes(public)
+        redirectAPI.RegisterRoutes(public)
+        searchAPI.RegisterPublicRoutes(public)
+        searchAnalyticsAPI.RegisterPublicRoutes(public)
+        publicGraphQLAPI.RegisterRoutes(public)
     }
 
+    // Sitemap, robots.txt y feeds para buscadores y lectores de feeds
+    root := r.Group("", middleware.ResponseCache(middleware.CachePolicyFromEnv()))
+    frontendAPI.RegisterSEORoutes(root)
+    feedAPI.RegisterRoutes(root)
+
     r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
     
     return r
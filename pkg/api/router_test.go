@@ -30,25 +30,6 @@ func (m *MockDatabase) DB() interface{} {
     return args.Get(0)
 }
 
-type MockCache struct {
-    mock.Mock
-}
-
-func (m *MockCache) Set(key string, value interface{}, ttl time.Duration) error {
-    args := m.Called(key, value, ttl)
-    return args.Error(0)
-}
-
-func (m *MockCache) Get(key string) (interface{}, error) {
-    args := m.Called(key)
-    return args.Get(0), args.Error(1)
-}
-
-func (m *MockCache) Delete(key string) error {
-    args := m.Called(key)
-    return args.Error(0)
-}
-
 type MockMongoCollection struct {
     mock.Mock
 }
@@ -65,11 +46,8 @@ func createMockDatabase() database.Database {
     return mockDB
 }
 
-func createMockCache() cache.Cache {
-    mockCache := new(MockCache)
-    mockCache.On("Get", mock.Anything).Return(nil, nil)
-    mockCache.On("Set", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-    return mockCache
+func createMockCache() *cache.Store {
+    return cache.NewStore(cache.NewMemoryBackend())
 }
 
 func createMockMongoCollection() *mongo.Collection {
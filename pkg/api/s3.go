@@ -55,6 +55,21 @@ func (s *S3Storage) Upload(ctx context.Context, filePath string, content io.Read
     return result.Location, nil
 }
 
+// Put stores content under the given key, unlike Upload which generates one.
+func (s *S3Storage) Put(ctx context.Context, key string, content io.Reader, contentType string) error {
+    _, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+        Bucket:      aws.String(s.bucket),
+        Key:         aws.String(key),
+        Body:        content,
+        ContentType: aws.String(contentType),
+    })
+    if err != nil {
+        return fmt.Errorf("failed to put file: %w", err)
+    }
+
+    return nil
+}
+
 func (s *S3Storage) Delete(ctx context.Context, key string) error {
     _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
         Bucket: aws.String(s.bucket),
@@ -0,0 +1,123 @@
+// pkg/api/schedule.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+type ScheduleService interface {
+    Schedule(ctx context.Context, schedule *cms.ScheduledPublication) error
+    List(ctx context.Context, filter cms.ScheduleFilter) ([]cms.ScheduledPublication, error)
+    Cancel(ctx context.Context, scheduleID uint) error
+}
+
+type ScheduleAPI struct {
+    service ScheduleService
+}
+
+type CreateScheduleRequest struct {
+    ContentID uint      `json:"content_id" binding:"required"`
+    Action    string    `json:"action"`
+    Version   int       `json:"version"`
+    RunAt     time.Time `json:"run_at" binding:"required"`
+}
+
+func NewScheduleAPI(service ScheduleService) *ScheduleAPI {
+    return &ScheduleAPI{service: service}
+}
+
+func (api *ScheduleAPI) RegisterRoutes(router *gin.RouterGroup) {
+    schedules := router.Group("/schedules")
+    {
+        schedules.POST("/", api.Create)
+        schedules.GET("/", api.List)
+        schedules.DELETE("/:id", api.Cancel)
+    }
+}
+
+func (api *ScheduleAPI) Create(c *gin.Context) {
+    var request CreateScheduleRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    schedule := cms.ScheduledPublication{
+        ContentID: request.ContentID,
+        Action:    request.Action,
+        Version:   request.Version,
+        PublishAt: request.RunAt,
+        CreatedBy: c.GetString("username"),
+    }
+
+    if err := api.service.Schedule(c.Request.Context(), &schedule); err != nil {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "content or version not found"})
+        case errors.Is(err, cms.ErrInvalidScheduleAction), errors.Is(err, cms.ErrScheduleInPast):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrIllegalTransition):
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrTransitionForbidden):
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        }
+        return
+    }
+
+    c.JSON(http.StatusCreated, schedule)
+}
+
+func (api *ScheduleAPI) List(c *gin.Context) {
+    filter := cms.ScheduleFilter{
+        Status: c.Query("status"),
+    }
+    if contentID := c.Query("content_id"); contentID != "" {
+        id, err := strconv.ParseUint(contentID, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid content_id"})
+            return
+        }
+        filter.ContentID = uint(id)
+    }
+
+    schedules, err := api.service.List(c.Request.Context(), filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, schedules)
+}
+
+func (api *ScheduleAPI) Cancel(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    if err := api.service.Cancel(c.Request.Context(), uint(id)); err != nil {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+        case errors.Is(err, cms.ErrScheduleForbidden):
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrScheduleNotPending):
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        }
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
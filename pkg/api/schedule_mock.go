@@ -0,0 +1,27 @@
+// pkg/api/schedule_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockScheduleService struct {
+    mock.Mock
+}
+
+func (m *MockScheduleService) Schedule(ctx context.Context, schedule *cms.ScheduledPublication) error {
+    args := m.Called(ctx, schedule)
+    return args.Error(0)
+}
+
+func (m *MockScheduleService) List(ctx context.Context, filter cms.ScheduleFilter) ([]cms.ScheduledPublication, error) {
+    args := m.Called(ctx, filter)
+    return args.Get(0).([]cms.ScheduledPublication), args.Error(1)
+}
+
+func (m *MockScheduleService) Cancel(ctx context.Context, scheduleID uint) error {
+    args := m.Called(ctx, scheduleID)
+    return args.Error(0)
+}
@@ -0,0 +1,132 @@
+// pkg/api/schedule_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupScheduleTest() (*gin.Engine, *MockScheduleService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockScheduleService)
+    router := gin.New()
+    api := NewScheduleAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestScheduleCreate(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    runAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+    mockService.On("Schedule", mock.Anything, mock.MatchedBy(func(schedule *cms.ScheduledPublication) bool {
+        return schedule.ContentID == 1 && schedule.Action == cms.ScheduleActionUnpublish && schedule.PublishAt.Equal(runAt)
+    })).Return(nil)
+
+    body, _ := json.Marshal(CreateScheduleRequest{ContentID: 1, Action: cms.ScheduleActionUnpublish, RunAt: runAt})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/schedules/", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestScheduleCreateInvalidAction(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    mockService.On("Schedule", mock.Anything, mock.Anything).Return(cms.ErrInvalidScheduleAction)
+
+    body, _ := json.Marshal(CreateScheduleRequest{ContentID: 1, Action: "delete", RunAt: time.Now().Add(time.Hour)})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/schedules/", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestScheduleCreateWorkflowRules(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    mockService.On("Schedule", mock.Anything, mock.MatchedBy(func(schedule *cms.ScheduledPublication) bool {
+        return schedule.ContentID == 1
+    })).Return(cms.ErrTransitionForbidden)
+    mockService.On("Schedule", mock.Anything, mock.MatchedBy(func(schedule *cms.ScheduledPublication) bool {
+        return schedule.ContentID == 2
+    })).Return(cms.ErrIllegalTransition)
+
+    for contentID, code := range map[uint]int{1: http.StatusForbidden, 2: http.StatusConflict} {
+        body, _ := json.Marshal(CreateScheduleRequest{ContentID: contentID, RunAt: time.Now().Add(time.Hour)})
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("POST", "/api/v1/cms/schedules/", bytes.NewBuffer(body))
+        req.Header.Set("Content-Type", "application/json")
+        router.ServeHTTP(w, req)
+
+        assert.Equal(t, code, w.Code)
+    }
+}
+
+func TestScheduleList(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    schedules := []cms.ScheduledPublication{
+        {ContentID: 1, Action: cms.ScheduleActionPublish, Status: "pending"},
+    }
+
+    mockService.On("List", mock.Anything, cms.ScheduleFilter{Status: "pending", ContentID: 1}).Return(schedules, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/schedules/?status=pending&content_id=1", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response []cms.ScheduledPublication
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 1, len(response))
+    mockService.AssertExpectations(t)
+}
+
+func TestScheduleCancel(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    mockService.On("Cancel", mock.Anything, uint(3)).Return(nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("DELETE", "/api/v1/cms/schedules/3", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNoContent, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestScheduleCancelNotPending(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    mockService.On("Cancel", mock.Anything, uint(3)).Return(cms.ErrScheduleNotPending)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("DELETE", "/api/v1/cms/schedules/3", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusConflict, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestScheduleCancelForbidden(t *testing.T) {
+    router, mockService := setupScheduleTest()
+    mockService.On("Cancel", mock.Anything, uint(3)).Return(cms.ErrScheduleForbidden)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("DELETE", "/api/v1/cms/schedules/3", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusForbidden, w.Code)
+    mockService.AssertExpectations(t)
+}
@@ -0,0 +1,158 @@
+// pkg/api/search.go
+package api
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cms"
+)
+
+type SearchService interface {
+    Search(ctx context.Context, query string, filter cms.SearchFilter) (*cms.SearchResponse, error)
+    Suggest(ctx context.Context, prefix string, filter cms.SuggestFilter) ([]cms.Suggestion, error)
+}
+
+type SearchAPI struct {
+    service SearchService
+}
+
+func NewSearchAPI(service SearchService) *SearchAPI {
+    return &SearchAPI{service: service}
+}
+
+// RegisterRoutes registra la búsqueda del panel, que ve todos los estados y
+// también las plantillas.
+func (api *SearchAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/search", api.Search)
+    router.GET("/search/suggest", api.Suggest)
+}
+
+// RegisterPublicRoutes registra la búsqueda pública, limitada al contenido
+// publicado y dentro de su ventana de embargo.
+func (api *SearchAPI) RegisterPublicRoutes(router *gin.RouterGroup) {
+    router.GET("/search", api.PublicSearch)
+    router.GET("/search/suggest", api.PublicSuggest)
+}
+
+func (api *SearchAPI) Search(c *gin.Context) {
+    api.search(c, false)
+}
+
+func (api *SearchAPI) PublicSearch(c *gin.Context) {
+    api.search(c, true)
+}
+
+func (api *SearchAPI) Suggest(c *gin.Context) {
+    api.suggest(c, false)
+}
+
+func (api *SearchAPI) PublicSuggest(c *gin.Context) {
+    api.suggest(c, true)
+}
+
+func (api *SearchAPI) search(c *gin.Context, publicOnly bool) {
+    query := c.Query("q")
+    if strings.TrimSpace(query) == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+        return
+    }
+
+    filter, err := parseSearchFilter(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    filter.PublicOnly = publicOnly
+
+    response, err := api.service.Search(c.Request.Context(), query, filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    // query_id es de esta búsqueda: una respuesta compartida por la CDN
+    // mezclaría los clics de distintos visitantes
+    if publicOnly {
+        c.Header("Cache-Control", "no-store")
+    }
+    c.JSON(http.StatusOK, response)
+}
+
+// suggest admite ?locale= para sugerir solo en un idioma y ?limit= (máximo 20).
+func (api *SearchAPI) suggest(c *gin.Context, publicOnly bool) {
+    filter := cms.SuggestFilter{
+        Locale:     c.Query("locale"),
+        PublicOnly: publicOnly,
+    }
+    if limit := c.Query("limit"); limit != "" {
+        value, err := strconv.Atoi(limit)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("limit").Error()})
+            return
+        }
+        filter.Limit = value
+    }
+
+    suggestions, err := api.service.Suggest(c.Request.Context(), c.Query("q"), filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, suggestions)
+}
+
+// parseSearchFilter lee los filtros de la query string. type y tag admiten
+// varios valores separados por comas.
+func parseSearchFilter(c *gin.Context) (cms.SearchFilter, error) {
+    filter := cms.SearchFilter{
+        Types:  splitList(c.Query("type")),
+        Status: c.Query("status"),
+        Tags:   splitList(c.Query("tag")),
+        Locale: c.Query("locale"),
+    }
+
+    if author := c.Query("author"); author != "" {
+        id, err := strconv.ParseUint(author, 10, 32)
+        if err != nil {
+            return filter, errInvalidParam("author")
+        }
+        filter.AuthorID = uint(id)
+    }
+
+    if page := c.Query("page"); page != "" {
+        value, err := strconv.Atoi(page)
+        if err != nil {
+            return filter, errInvalidParam("page")
+        }
+        filter.Page = value
+    }
+
+    if pageSize := c.Query("page_size"); pageSize != "" {
+        value, err := strconv.Atoi(pageSize)
+        if err != nil {
+            return filter, errInvalidParam("page_size")
+        }
+        filter.PageSize = value
+    }
+    return filter, nil
+}
+
+func splitList(value string) []string {
+    var items []string
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+type errInvalidParam string
+
+func (e errInvalidParam) Error() string {
+    return "invalid " + string(e)
+}
@@ -0,0 +1,131 @@
+// pkg/api/search_analytics.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cms"
+)
+
+type SearchAnalyticsService interface {
+    LogClick(ctx context.Context, queryID string, click cms.SearchClick) error
+    TopQueries(ctx context.Context, filter cms.SearchReportFilter) ([]cms.SearchQueryReport, error)
+    ZeroResultQueries(ctx context.Context, filter cms.SearchReportFilter) ([]cms.SearchQueryReport, error)
+}
+
+type SearchAnalyticsAPI struct {
+    service SearchAnalyticsService
+}
+
+type SearchClickRequest struct {
+    QueryID  string `json:"query_id" binding:"required"`
+    Type     string `json:"type" binding:"required"`
+    ID       uint   `json:"id" binding:"required"`
+    Position int    `json:"position"`
+}
+
+func NewSearchAnalyticsAPI(service SearchAnalyticsService) *SearchAnalyticsAPI {
+    return &SearchAnalyticsAPI{service: service}
+}
+
+// RegisterRoutes registra los informes del panel.
+func (api *SearchAnalyticsAPI) RegisterRoutes(router *gin.RouterGroup) {
+    analytics := router.Group("/search/analytics")
+    {
+        analytics.GET("/top-queries", api.TopQueries)
+        analytics.GET("/zero-results", api.ZeroResultQueries)
+    }
+}
+
+// RegisterPublicRoutes registra el endpoint con el que el frontend informa
+// de los resultados abiertos.
+func (api *SearchAnalyticsAPI) RegisterPublicRoutes(router *gin.RouterGroup) {
+    router.POST("/search/click", api.Click)
+}
+
+func (api *SearchAnalyticsAPI) Click(c *gin.Context) {
+    var request SearchClickRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    click := cms.SearchClick{
+        Type:     request.Type,
+        ID:       request.ID,
+        Position: request.Position,
+    }
+    if err := api.service.LogClick(c.Request.Context(), request.QueryID, click); err != nil {
+        switch {
+        case errors.Is(err, cms.ErrInvalidSearchClick):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrSearchQueryNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        }
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+func (api *SearchAnalyticsAPI) TopQueries(c *gin.Context) {
+    api.report(c, api.service.TopQueries)
+}
+
+func (api *SearchAnalyticsAPI) ZeroResultQueries(c *gin.Context) {
+    api.report(c, api.service.ZeroResultQueries)
+}
+
+func (api *SearchAnalyticsAPI) report(c *gin.Context, load func(context.Context, cms.SearchReportFilter) ([]cms.SearchQueryReport, error)) {
+    filter, err := parseReportFilter(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    reports, err := load(c.Request.Context(), filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, reports)
+}
+
+// parseReportFilter lee from y to (RFC 3339 o YYYY-MM-DD) y limit. Un to
+// con solo la fecha incluye ese día entero.
+func parseReportFilter(c *gin.Context) (cms.SearchReportFilter, error) {
+    var filter cms.SearchReportFilter
+
+    for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+        value := c.Query(name)
+        if value == "" {
+            continue
+        }
+        parsed, err := time.Parse(time.RFC3339, value)
+        if err != nil {
+            if parsed, err = time.Parse("2006-01-02", value); err != nil {
+                return filter, errInvalidParam(name)
+            }
+            if name == "to" {
+                parsed = parsed.AddDate(0, 0, 1)
+            }
+        }
+        *target = &parsed
+    }
+
+    if limit := c.Query("limit"); limit != "" {
+        value, err := strconv.Atoi(limit)
+        if err != nil {
+            return filter, errInvalidParam("limit")
+        }
+        filter.Limit = value
+    }
+    return filter, nil
+}
@@ -0,0 +1,27 @@
+// pkg/api/search_analytics_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockSearchAnalyticsService struct {
+    mock.Mock
+}
+
+func (m *MockSearchAnalyticsService) LogClick(ctx context.Context, queryID string, click cms.SearchClick) error {
+    args := m.Called(ctx, queryID, click)
+    return args.Error(0)
+}
+
+func (m *MockSearchAnalyticsService) TopQueries(ctx context.Context, filter cms.SearchReportFilter) ([]cms.SearchQueryReport, error) {
+    args := m.Called(ctx, filter)
+    return args.Get(0).([]cms.SearchQueryReport), args.Error(1)
+}
+
+func (m *MockSearchAnalyticsService) ZeroResultQueries(ctx context.Context, filter cms.SearchReportFilter) ([]cms.SearchQueryReport, error) {
+    args := m.Called(ctx, filter)
+    return args.Get(0).([]cms.SearchQueryReport), args.Error(1)
+}
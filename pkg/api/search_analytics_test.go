@@ -0,0 +1,96 @@
+// pkg/api/search_analytics_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupSearchAnalyticsTest() (*gin.Engine, *MockSearchAnalyticsService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockSearchAnalyticsService)
+    router := gin.New()
+    api := NewSearchAnalyticsAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    api.RegisterPublicRoutes(router.Group("/api/v1"))
+    return router, mockService
+}
+
+func TestSearchClick(t *testing.T) {
+    router, mockService := setupSearchAnalyticsTest()
+    mockService.On("LogClick", mock.Anything, "65f0c0ffee", cms.SearchClick{Type: "content", ID: 4, Position: 2}).Return(nil)
+
+    body, _ := json.Marshal(SearchClickRequest{QueryID: "65f0c0ffee", Type: "content", ID: 4, Position: 2})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/search/click", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNoContent, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestSearchClickUnknownQuery(t *testing.T) {
+    router, mockService := setupSearchAnalyticsTest()
+    mockService.On("LogClick", mock.Anything, "missing", mock.Anything).Return(cms.ErrSearchQueryNotFound)
+
+    body, _ := json.Marshal(SearchClickRequest{QueryID: "missing", Type: "content", ID: 4})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/search/click", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTopQueries(t *testing.T) {
+    router, mockService := setupSearchAnalyticsTest()
+    from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+    mockService.On("TopQueries", mock.Anything, mock.MatchedBy(func(filter cms.SearchReportFilter) bool {
+        return filter.From.Equal(from) && filter.To == nil && filter.Limit == 5
+    })).Return([]cms.SearchQueryReport{{Query: "golang", Searches: 12, Clicks: 3}}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search/analytics/top-queries?from=2024-03-01&limit=5", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var reports []cms.SearchQueryReport
+    json.Unmarshal(w.Body.Bytes(), &reports)
+    assert.Equal(t, "golang", reports[0].Query)
+    mockService.AssertExpectations(t)
+}
+
+func TestZeroResultQueriesInvalidDate(t *testing.T) {
+    router, _ := setupSearchAnalyticsTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search/analytics/zero-results?to=yesterday", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestZeroResultQueriesDateOnlyTo(t *testing.T) {
+    router, mockService := setupSearchAnalyticsTest()
+    // El día de to entra entero: el límite es el inicio del siguiente
+    end := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+    mockService.On("ZeroResultQueries", mock.Anything, mock.MatchedBy(func(filter cms.SearchReportFilter) bool {
+        return filter.From == nil && filter.To.Equal(end)
+    })).Return([]cms.SearchQueryReport{}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search/analytics/zero-results?to=2026-10-19", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
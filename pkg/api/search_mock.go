@@ -0,0 +1,28 @@
+// pkg/api/search_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockSearchService struct {
+    mock.Mock
+}
+
+func (m *MockSearchService) Search(ctx context.Context, query string, filter cms.SearchFilter) (*cms.SearchResponse, error) {
+    args := m.Called(ctx, query, filter)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.SearchResponse), args.Error(1)
+}
+
+func (m *MockSearchService) Suggest(ctx context.Context, prefix string, filter cms.SuggestFilter) ([]cms.Suggestion, error) {
+    args := m.Called(ctx, prefix, filter)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]cms.Suggestion), args.Error(1)
+}
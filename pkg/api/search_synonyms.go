@@ -0,0 +1,112 @@
+// pkg/api/search_synonyms.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+type SynonymService interface {
+    List(ctx context.Context, locale string) ([]cms.SearchSynonym, error)
+    Create(ctx context.Context, entry *cms.SearchSynonym) error
+    Update(ctx context.Context, entry *cms.SearchSynonym) error
+    Delete(ctx context.Context, id uint) error
+}
+
+type SynonymAPI struct {
+    service SynonymService
+}
+
+func NewSynonymAPI(service SynonymService) *SynonymAPI {
+    return &SynonymAPI{service: service}
+}
+
+func (api *SynonymAPI) RegisterRoutes(router *gin.RouterGroup) {
+    synonyms := router.Group("/search/synonyms")
+    {
+        synonyms.GET("/", api.List)
+        synonyms.POST("/", api.Create)
+        synonyms.PUT("/:id", api.Update)
+        synonyms.DELETE("/:id", api.Delete)
+    }
+}
+
+func (api *SynonymAPI) List(c *gin.Context) {
+    entries, err := api.service.List(c.Request.Context(), c.Query("locale"))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, entries)
+}
+
+func (api *SynonymAPI) Create(c *gin.Context) {
+    var entry cms.SearchSynonym
+    if err := c.ShouldBindJSON(&entry); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := api.service.Create(c.Request.Context(), &entry); err != nil {
+        writeSynonymError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, entry)
+}
+
+func (api *SynonymAPI) Update(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    var entry cms.SearchSynonym
+    if err := c.ShouldBindJSON(&entry); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    entry.ID = uint(id)
+
+    if err := api.service.Update(c.Request.Context(), &entry); err != nil {
+        writeSynonymError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, entry)
+}
+
+func (api *SynonymAPI) Delete(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    if err := api.service.Delete(c.Request.Context(), uint(id)); err != nil {
+        writeSynonymError(c, err)
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+func writeSynonymError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, cms.ErrInvalidSynonym):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "synonym not found"})
+    case errors.Is(err, cms.ErrSynonymExists):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
@@ -0,0 +1,32 @@
+// pkg/api/search_synonyms_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockSynonymService struct {
+    mock.Mock
+}
+
+func (m *MockSynonymService) List(ctx context.Context, locale string) ([]cms.SearchSynonym, error) {
+    args := m.Called(ctx, locale)
+    return args.Get(0).([]cms.SearchSynonym), args.Error(1)
+}
+
+func (m *MockSynonymService) Create(ctx context.Context, entry *cms.SearchSynonym) error {
+    args := m.Called(ctx, entry)
+    return args.Error(0)
+}
+
+func (m *MockSynonymService) Update(ctx context.Context, entry *cms.SearchSynonym) error {
+    args := m.Called(ctx, entry)
+    return args.Error(0)
+}
+
+func (m *MockSynonymService) Delete(ctx context.Context, id uint) error {
+    args := m.Called(ctx, id)
+    return args.Error(0)
+}
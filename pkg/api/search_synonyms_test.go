@@ -0,0 +1,88 @@
+// pkg/api/search_synonyms_test.go
+package api
+
+import (
+    "bytes"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+func setupSynonymTest() (*gin.Engine, *MockSynonymService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockSynonymService)
+    router := gin.New()
+    api := NewSynonymAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestSynonymList(t *testing.T) {
+    router, mockService := setupSynonymTest()
+    mockService.On("List", mock.Anything, "es").Return([]cms.SearchSynonym{{Term: "coche", Synonyms: cms.StringArray{"auto"}}}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search/synonyms/?locale=es", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestSynonymCreate(t *testing.T) {
+    router, mockService := setupSynonymTest()
+    mockService.On("Create", mock.Anything, mock.MatchedBy(func(entry *cms.SearchSynonym) bool {
+        return entry.Term == "the" && entry.StopWord
+    })).Return(nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/search/synonyms/", bytes.NewBufferString(`{"term":"the","stop_word":true}`))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestSynonymCreateInvalid(t *testing.T) {
+    router, mockService := setupSynonymTest()
+    mockService.On("Create", mock.Anything, mock.Anything).Return(cms.ErrInvalidSynonym)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/search/synonyms/", bytes.NewBufferString(`{"term":"car"}`))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSynonymUpdate(t *testing.T) {
+    router, mockService := setupSynonymTest()
+    mockService.On("Update", mock.Anything, mock.MatchedBy(func(entry *cms.SearchSynonym) bool {
+        return entry.ID == 3 && entry.Term == "car"
+    })).Return(nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PUT", "/api/v1/cms/search/synonyms/3", bytes.NewBufferString(`{"term":"car","synonyms":["auto"]}`))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestSynonymDeleteNotFound(t *testing.T) {
+    router, mockService := setupSynonymTest()
+    mockService.On("Delete", mock.Anything, uint(9)).Return(gorm.ErrRecordNotFound)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("DELETE", "/api/v1/cms/search/synonyms/9", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
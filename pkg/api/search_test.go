@@ -0,0 +1,109 @@
+// pkg/api/search_test.go
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupSearchTest() (*gin.Engine, *MockSearchService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockSearchService)
+    router := gin.New()
+    api := NewSearchAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    api.RegisterPublicRoutes(router.Group("/api/v1"))
+    return router, mockService
+}
+
+func TestSearch(t *testing.T) {
+    router, mockService := setupSearchTest()
+    filter := cms.SearchFilter{
+        Types:    []string{"content"},
+        Status:   "draft",
+        Tags:     []string{"go", "cms"},
+        AuthorID: 3,
+        Page:     2,
+        PageSize: 10,
+    }
+    response := &cms.SearchResponse{
+        Results: []cms.SearchResult{{Type: "content", ID: 1, Title: "Go CMS", Highlight: "<mark>Go</mark> CMS"}},
+        Total:   11,
+        Page:    2,
+        Facets:  map[string][]cms.FacetCount{"tag": {{Value: "go", Count: 11}}},
+    }
+
+    mockService.On("Search", mock.Anything, "go", filter).Return(response, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search?q=go&type=content&status=draft&tag=go,cms&author=3&page=2&page_size=10", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var body cms.SearchResponse
+    _ = json.Unmarshal(w.Body.Bytes(), &body)
+    assert.Equal(t, int64(11), body.Total)
+    assert.Equal(t, int64(11), body.Facets["tag"][0].Count)
+    mockService.AssertExpectations(t)
+}
+
+func TestPublicSearchOnlyDelivered(t *testing.T) {
+    router, mockService := setupSearchTest()
+    filter := cms.SearchFilter{PublicOnly: true}
+
+    mockService.On("Search", mock.Anything, "go", filter).Return(&cms.SearchResponse{}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/search?q=go", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestSearchValidation(t *testing.T) {
+    router, _ := setupSearchTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("GET", "/api/v1/cms/search?q=go&page=x", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPublicSuggest(t *testing.T) {
+    router, mockService := setupSearchTest()
+    filter := cms.SuggestFilter{Locale: "es", Limit: 5, PublicOnly: true}
+    mockService.On("Suggest", mock.Anything, "Go", filter).Return([]cms.Suggestion{{Text: "Go CMS", Type: "content", ID: 1, Locale: "es"}}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/search/suggest?q=Go&locale=es&limit=5", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var suggestions []cms.Suggestion
+    json.Unmarshal(w.Body.Bytes(), &suggestions)
+    assert.Equal(t, "Go CMS", suggestions[0].Text)
+    mockService.AssertExpectations(t)
+}
+
+func TestSuggestInvalidLimit(t *testing.T) {
+    router, _ := setupSearchTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/search/suggest?q=Go&limit=ten", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
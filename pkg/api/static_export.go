@@ -0,0 +1,57 @@
+// pkg/api/static_export.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cms"
+)
+
+type StaticExportService interface {
+    Export(ctx context.Context, full bool) (*cms.StaticExportResult, error)
+}
+
+type StaticExportAPI struct {
+    service StaticExportService
+}
+
+func NewStaticExportAPI(service StaticExportService) *StaticExportAPI {
+    return &StaticExportAPI{service: service}
+}
+
+func (api *StaticExportAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.POST("/static-export", api.Export)
+}
+
+// Export regenera el sitio estático y responde con el resumen. Por defecto
+// es incremental; ?full=true regenera todas las páginas.
+func (api *StaticExportAPI) Export(c *gin.Context) {
+    full := false
+    if value := c.Query("full"); value != "" {
+        parsed, err := strconv.ParseBool(value)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid full"})
+            return
+        }
+        full = parsed
+    }
+
+    result, err := api.service.Export(c.Request.Context(), full)
+    if err != nil {
+        if errors.Is(err, cms.ErrStaticExportDisabled) {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, cms.ErrStaticExportRunning) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, result)
+}
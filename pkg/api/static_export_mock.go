@@ -0,0 +1,20 @@
+// pkg/api/static_export_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockStaticExportService struct {
+    mock.Mock
+}
+
+func (m *MockStaticExportService) Export(ctx context.Context, full bool) (*cms.StaticExportResult, error) {
+    args := m.Called(ctx, full)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.StaticExportResult), args.Error(1)
+}
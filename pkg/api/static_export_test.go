@@ -0,0 +1,82 @@
+// pkg/api/static_export_test.go
+package api
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupStaticExportTest() (*gin.Engine, *MockStaticExportService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockStaticExportService)
+    router := gin.New()
+    NewStaticExportAPI(mockService).RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestStaticExportIncremental(t *testing.T) {
+    router, mockService := setupStaticExportTest()
+    mockService.On("Export", mock.Anything, false).Return(&cms.StaticExportResult{Pages: 3, Written: 1, Unchanged: 2}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/static-export", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response cms.StaticExportResult
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 1, response.Written)
+    assert.Equal(t, 2, response.Unchanged)
+    mockService.AssertExpectations(t)
+}
+
+func TestStaticExportFull(t *testing.T) {
+    router, mockService := setupStaticExportTest()
+    mockService.On("Export", mock.Anything, true).Return(&cms.StaticExportResult{Pages: 3, Written: 3}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/static-export?full=true", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestStaticExportInvalidFull(t *testing.T) {
+    router, _ := setupStaticExportTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/static-export?full=maybe", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStaticExportErrors(t *testing.T) {
+    router, mockService := setupStaticExportTest()
+    mockService.On("Export", mock.Anything, false).Return(nil, cms.ErrStaticExportDisabled).Once()
+    mockService.On("Export", mock.Anything, false).Return(nil, cms.ErrStaticExportRunning).Once()
+    mockService.On("Export", mock.Anything, false).Return(nil, errors.New("db down")).Once()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/static-export", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("POST", "/api/v1/cms/static-export", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusConflict, w.Code)
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("POST", "/api/v1/cms/static-export", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
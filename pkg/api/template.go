@@ -2,17 +2,30 @@
 package api
 
 import (
+    "context"
+    "errors"
     "net/http"
     "strconv"
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
+type TemplateService interface {
+    Create(ctx context.Context, template *cms.Template) error
+    Get(ctx context.Context, id uint) (*cms.Template, error)
+    List(ctx context.Context, params query.Params) (*query.Page[cms.Template], error)
+    Replace(ctx context.Context, id uint, body []byte, version int) (*cms.Template, error)
+    Patch(ctx context.Context, id uint, patch []byte, version int) (*cms.Template, error)
+    Delete(ctx context.Context, id uint) error
+}
+
 type TemplateAPI struct {
-    service *cms.TemplateService
+    service TemplateService
 }
 
-func NewTemplateAPI(service *cms.TemplateService) *TemplateAPI {
+func NewTemplateAPI(service TemplateService) *TemplateAPI {
     return &TemplateAPI{service: service}
 }
 
@@ -23,6 +36,7 @@ func (api *TemplateAPI) RegisterRoutes(router *gin.Engine) {
         templates.GET("/", api.List)
         templates.GET("/:id", api.Get)
         templates.PUT("/:id", api.Update)
+        templates.PATCH("/:id", api.Patch)
         templates.DELETE("/:id", api.Delete)
     }
 }
@@ -49,27 +63,38 @@ func (api *TemplateAPI) Get(c *gin.Context) {
         return
     }
 
+    fields, ok := parseFields(c, &cms.Template{})
+    if !ok {
+        return
+    }
+
     template, err := api.service.Get(c.Request.Context(), uint(id))
     if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
         return
     }
 
-    c.JSON(http.StatusOK, template)
+    body, err := fields.Project(template)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    writeWithETag(c, template.Version, fields, body)
 }
 
 func (api *TemplateAPI) List(c *gin.Context) {
-    filter := cms.TemplateFilter{
-        Type: c.Query("type"),
+    params, ok := parseListParams(c, cms.TemplateQuery, &cms.Template{})
+    if !ok {
+        return
     }
 
-    templates, err := api.service.List(c.Request.Context(), filter)
+    page, err := api.service.List(c.Request.Context(), params)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        writeListError(c, err)
         return
     }
 
-    c.JSON(http.StatusOK, templates)
+    writePage(c, page, params.Fields)
 }
 
 func (api *TemplateAPI) Update(c *gin.Context) {
@@ -79,21 +104,71 @@ func (api *TemplateAPI) Update(c *gin.Context) {
         return
     }
 
-    var template cms.Template
-    if err := c.ShouldBindJSON(&template); err != nil {
+    version, err := ifMatchRevision(c)
+    if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
-    template.ID = uint(id)
 
-    if err := api.service.Update(c.Request.Context(), &template); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    body, ok := readReplaceBody(c)
+    if !ok {
+        return
+    }
+
+    // Como en el contenido, los campos que no vienen se mantienen. If-Match
+    // tiene prioridad sobre la versión del cuerpo
+    template, err := api.service.Replace(c.Request.Context(), uint(id), body, version)
+    if err != nil {
+        api.writeUpdateError(c, err)
         return
     }
 
+    c.Header("ETag", revisionETag(template.Version))
     c.JSON(http.StatusOK, template)
 }
 
+func (api *TemplateAPI) Patch(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    version, err := ifMatchRevision(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    patch, ok := readMergePatch(c)
+    if !ok {
+        return
+    }
+
+    template, err := api.service.Patch(c.Request.Context(), uint(id), patch, version)
+    if err != nil {
+        api.writeUpdateError(c, err)
+        return
+    }
+
+    c.Header("ETag", revisionETag(template.Version))
+    c.JSON(http.StatusOK, template)
+}
+
+func (api *TemplateAPI) writeUpdateError(c *gin.Context, err error) {
+    if abortStaleRevision(c, err) {
+        return
+    }
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+    case errors.Is(err, cms.ErrInvalidPatch):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
 func (api *TemplateAPI) Delete(c *gin.Context) {
     id, err := strconv.ParseUint(c.Param("id"), 10, 32)
     if err != nil {
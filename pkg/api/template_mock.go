@@ -5,6 +5,7 @@ import (
     "context"
     "github.com/stretchr/testify/mock"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
 type MockTemplateService struct {
@@ -24,14 +25,28 @@ func (m *MockTemplateService) Get(ctx context.Context, id uint) (*cms.Template,
     return args.Get(0).(*cms.Template), args.Error(1)
 }
 
-func (m *MockTemplateService) List(ctx context.Context, filter cms.TemplateFilter) ([]cms.Template, error) {
-    args := m.Called(ctx, filter)
-    return args.Get(0).([]cms.Template), args.Error(1)
+func (m *MockTemplateService) List(ctx context.Context, params query.Params) (*query.Page[cms.Template], error) {
+    args := m.Called(ctx, params)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*query.Page[cms.Template]), args.Error(1)
 }
 
-func (m *MockTemplateService) Update(ctx context.Context, template *cms.Template) error {
-    args := m.Called(ctx, template)
-    return args.Error(0)
+func (m *MockTemplateService) Replace(ctx context.Context, id uint, body []byte, version int) (*cms.Template, error) {
+    args := m.Called(ctx, id, body, version)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) Patch(ctx context.Context, id uint, patch []byte, version int) (*cms.Template, error) {
+    args := m.Called(ctx, id, patch, version)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Template), args.Error(1)
 }
 
 func (m *MockTemplateService) Delete(ctx context.Context, id uint) error {
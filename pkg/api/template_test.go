@@ -11,6 +11,7 @@ import (
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
     "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
 )
 
 func setupTemplateTest() (*gin.Engine, *MockTemplateService) {
@@ -70,32 +71,32 @@ func TestTemplateList(t *testing.T) {
         {Name: "Page", Type: "page"},
     }
 
-    filter := cms.TemplateFilter{Type: "post"}
-    mockService.On("List", mock.Anything, filter).Return(templates, nil)
+    params := query.Params{
+        Limit:   query.DefaultLimit,
+        Filters: []query.Filter{{Field: "type", Op: "eq", Values: []interface{}{"post"}}},
+    }
+    mockService.On("List", mock.Anything, params).Return(&query.Page[cms.Template]{Data: templates}, nil)
 
     w := httptest.NewRecorder()
-    req, _ := http.NewRequest("GET", "/api/v1/templates?type=post", nil)
+    req, _ := http.NewRequest("GET", "/api/v1/templates/?type=post", nil)
     router.ServeHTTP(w, req)
 
     assert.Equal(t, http.StatusOK, w.Code)
-    
-    var response []cms.Template
+
+    var response query.Page[cms.Template]
     _ = json.Unmarshal(w.Body.Bytes(), &response)
-    assert.Equal(t, 2, len(response))
+    assert.Equal(t, 2, len(response.Data))
     mockService.AssertExpectations(t)
 }
 
 func TestTemplateUpdate(t *testing.T) {
     router, mockService := setupTemplateTest()
-    template := cms.Template{
-        Name: "Updated Template",
-        Type: "post",
-    }
+    body := []byte(`{"name":"Updated Template","type":"post"}`)
+    template := &cms.Template{Name: "Updated Template", Type: "post", Version: 3}
     template.ID = 1
 
-    mockService.On("Update", mock.Anything, &template).Return(nil)
+    mockService.On("Replace", mock.Anything, uint(1), body, 0).Return(template, nil)
 
-    body, _ := json.Marshal(template)
     w := httptest.NewRecorder()
     req, _ := http.NewRequest("PUT", "/api/v1/templates/1", bytes.NewBuffer(body))
     req.Header.Set("Content-Type", "application/json")
@@ -116,3 +117,34 @@ func TestTemplateDelete(t *testing.T) {
     assert.Equal(t, http.StatusNoContent, w.Code)
     mockService.AssertExpectations(t)
 }
+
+func TestTemplateUpdateStaleVersion(t *testing.T) {
+    router, mockService := setupTemplateTest()
+    body := []byte(`{"name":"Updated Template","fields":[],"version":1}`)
+
+    mockService.On("Replace", mock.Anything, uint(1), body, 0).Return(nil, &cms.RevisionMismatchError{Current: 2})
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PUT", "/api/v1/templates/1", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+    assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+    mockService.AssertExpectations(t)
+}
+
+func TestTemplatePatchInvalid(t *testing.T) {
+    router, mockService := setupTemplateTest()
+    patch := []byte(`{"version":"x"}`)
+
+    mockService.On("Patch", mock.Anything, uint(1), patch, 0).Return(nil, cms.ErrInvalidPatch)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PATCH", "/api/v1/templates/1", bytes.NewBuffer(patch))
+    req.Header.Set("Content-Type", "application/merge-patch+json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertExpectations(t)
+}
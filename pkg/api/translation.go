@@ -0,0 +1,102 @@
+// pkg/api/translation.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+type TranslationService interface {
+    Translations(ctx context.Context, id uint) ([]cms.Content, error)
+    CreateTranslation(ctx context.Context, sourceID uint, translation *cms.Content) error
+    MissingTranslations(ctx context.Context, locales []string) ([]cms.MissingTranslation, error)
+}
+
+type TranslationAPI struct {
+    service TranslationService
+}
+
+func NewTranslationAPI(service TranslationService) *TranslationAPI {
+    return &TranslationAPI{service: service}
+}
+
+func (api *TranslationAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/content/:id/translations", api.List)
+    router.POST("/content/:id/translations", api.Create)
+    router.GET("/translations/missing", api.Missing)
+}
+
+func (api *TranslationAPI) List(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    translations, err := api.service.Translations(c.Request.Context(), uint(id))
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, translations)
+}
+
+func (api *TranslationAPI) Create(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    var translation cms.Content
+    if err := c.ShouldBindJSON(&translation); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    if translation.Locale == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "locale is required"})
+        return
+    }
+
+    if err := api.service.CreateTranslation(c.Request.Context(), uint(id), &translation); err != nil {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
+        case errors.Is(err, cms.ErrUnsupportedLocale), errors.Is(err, cms.ErrInvalidSEO):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrTranslationExists):
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        }
+        return
+    }
+
+    c.JSON(http.StatusCreated, translation)
+}
+
+func (api *TranslationAPI) Missing(c *gin.Context) {
+    var locales []string
+    if query := c.Query("locales"); query != "" {
+        locales = strings.Split(query, ",")
+    }
+
+    report, err := api.service.MissingTranslations(c.Request.Context(), locales)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,30 @@
+// pkg/api/translation_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockTranslationService struct {
+    mock.Mock
+}
+
+func (m *MockTranslationService) Translations(ctx context.Context, id uint) ([]cms.Content, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]cms.Content), args.Error(1)
+}
+
+func (m *MockTranslationService) CreateTranslation(ctx context.Context, sourceID uint, translation *cms.Content) error {
+    args := m.Called(ctx, sourceID, translation)
+    return args.Error(0)
+}
+
+func (m *MockTranslationService) MissingTranslations(ctx context.Context, locales []string) ([]cms.MissingTranslation, error) {
+    args := m.Called(ctx, locales)
+    return args.Get(0).([]cms.MissingTranslation), args.Error(1)
+}
@@ -0,0 +1,113 @@
+// pkg/api/translation_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+func setupTranslationTest() (*gin.Engine, *MockTranslationService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockTranslationService)
+    router := gin.New()
+    api := NewTranslationAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestTranslationList(t *testing.T) {
+    router, mockService := setupTranslationTest()
+    translations := []cms.Content{
+        {Title: "About", Slug: "about", Locale: "en"},
+        {Title: "Acerca de", Slug: "acerca-de", Locale: "es"},
+    }
+
+    mockService.On("Translations", mock.Anything, uint(1)).Return(translations, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/translations", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response []cms.Content
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 2, len(response))
+    mockService.AssertExpectations(t)
+}
+
+func TestTranslationListNotFound(t *testing.T) {
+    router, mockService := setupTranslationTest()
+    mockService.On("Translations", mock.Anything, uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/9/translations", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestTranslationCreate(t *testing.T) {
+    router, mockService := setupTranslationTest()
+    translation := cms.Content{
+        Title:  "Acerca de",
+        Slug:   "acerca-de",
+        Locale: "es",
+    }
+
+    mockService.On("CreateTranslation", mock.Anything, uint(1), &translation).Return(nil)
+
+    body, _ := json.Marshal(translation)
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/translations", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestTranslationCreateConflict(t *testing.T) {
+    router, mockService := setupTranslationTest()
+    translation := cms.Content{Title: "Acerca de", Locale: "es"}
+
+    mockService.On("CreateTranslation", mock.Anything, uint(1), &translation).Return(cms.ErrTranslationExists)
+
+    body, _ := json.Marshal(translation)
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/translations", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusConflict, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestTranslationMissing(t *testing.T) {
+    router, mockService := setupTranslationTest()
+    report := []cms.MissingTranslation{
+        {TranslationGroupID: 1, Title: "About", Locales: []string{"en"}, Missing: []string{"es"}},
+    }
+
+    mockService.On("MissingTranslations", mock.Anything, []string{"es"}).Return(report, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/translations/missing?locales=es", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response []cms.MissingTranslation
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, []string{"es"}, response[0].Missing)
+    mockService.AssertExpectations(t)
+}
@@ -7,7 +7,9 @@ import (
 	"ezzygo/pkg/auth"
 	"ezzygo/pkg/database"
 	"ezzygo/pkg/models"
+	"ezzygo/pkg/query"
 	"net/http"
+	"slices"
 
 	"gorm.io/gorm"
 
@@ -18,6 +20,19 @@ import (
 type UserRepository interface {
 	LoginHandler(c *gin.Context)
 	RegisterHandler(c *gin.Context)
+	ListUsers(c *gin.Context)
+	SetRoleHandler(c *gin.Context)
+}
+
+// userQuery whitelists the fields the user list can be filtered and sorted by.
+var userQuery = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.Int, Sortable: true},
+		"username":   {Column: "username", Type: query.String, Sortable: true},
+		"role":       {Column: "role", Type: query.String},
+		"created_at": {Column: "created_at", Type: query.Time, Sortable: true},
+	},
+	DefaultSort: []query.Sort{{Field: "username"}},
 }
 
 // bookRepository holds shared resources like database and Redis client
@@ -124,3 +139,75 @@ func (r *userRepository) RegisterHandler(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Registration successful"})
 }
+
+// ListUsers godoc
+// @Summary List users
+// @Description Get a page of users without their passwords. Only admins can list users
+// @Tags user
+// @Security ApiKeyAuth
+// @Security JwtAuth
+// @Produce json
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param cursor query string false "Cursor from next_cursor or prev_cursor of a previous page"
+// @Param sort query string false "Comma separated fields, prefixed with - for descending" default(username)
+// @Param total query bool false "Include the number of matching users"
+// @Param role query string false "Filter by role"
+// @Param fields query string false "Comma separated fields to return, e.g. username,role"
+// @Success 200 {object} query.Page[models.UserSummary] "Successfully retrieved a page of users"
+// @Failure 400 {string} string "Invalid list parameters"
+// @Failure 403 {string} string "Forbidden"
+// @Router /users [get]
+func (r *userRepository) ListUsers(c *gin.Context) {
+	params, ok := parseListParams(c, userQuery, &models.UserSummary{})
+	if !ok {
+		return
+	}
+
+	db := r.DB.Model(&models.User{}).Select("id", "username", "role", "created_at", "updated_at")
+	page, err := query.Find[models.UserSummary](c.Request.Context(), db, params, userQuery)
+	if err != nil {
+		writeListError(c, err)
+		return
+	}
+
+	writePage(c, page, params.Fields)
+}
+
+// SetRoleHandler godoc
+// @Summary Change the role of a user
+// @Description Sets the editorial role of a user. Only admins can change roles; the first admin is set with the user command
+// @Tags user
+// @Security ApiKeyAuth
+// @Security JwtAuth
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param role body models.UserRole true "New role: author, editor, reviewer or admin"
+// @Success 200 {object} models.UserRole "Role changed"
+// @Failure 400 {string} string "Invalid role"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Router /users/{username}/role [put]
+func (r *userRepository) SetRoleHandler(c *gin.Context) {
+	var body models.UserRole
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !slices.Contains(models.Roles, body.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid role %q", body.Role)})
+		return
+	}
+
+	result := r.DB.Model(&models.User{}).Where("username = ?", c.Param("username")).Update("role", body.Role)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, body)
+}
@@ -34,6 +34,18 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ListUsers mocks base method.
+func (m *MockUserRepository) ListUsers(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListUsers", c)
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserRepositoryMockRecorder) ListUsers(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRepository)(nil).ListUsers), c)
+}
+
 // LoginHandler mocks base method.
 func (m *MockUserRepository) LoginHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -57,3 +69,15 @@ func (mr *MockUserRepositoryMockRecorder) RegisterHandler(c interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterHandler", reflect.TypeOf((*MockUserRepository)(nil).RegisterHandler), c)
 }
+
+// SetRoleHandler mocks base method.
+func (m *MockUserRepository) SetRoleHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRoleHandler", c)
+}
+
+// SetRoleHandler indicates an expected call of SetRoleHandler.
+func (mr *MockUserRepositoryMockRecorder) SetRoleHandler(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoleHandler", reflect.TypeOf((*MockUserRepository)(nil).SetRoleHandler), c)
+}
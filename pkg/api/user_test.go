@@ -1 +1,56 @@
 package api
+
+import (
+	"context"
+	"ezzygo/pkg/database"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListUsersInvalidParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The role is checked by RequireRole; invalid parameters never reach the database
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewUserRepository(mockDB, &ctx)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/users", repo.ListUsers)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users?sort=password", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetRoleRejectsUnknownRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The database is not touched for an invalid role
+	mockDB := database.NewMockDatabase(ctrl)
+	ctx := context.Background()
+	repo := NewUserRepository(mockDB, &ctx)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/users/:username/role", repo.SetRoleHandler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/users/alice/role", strings.NewReader(`{"role":"owner"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid role")
+}
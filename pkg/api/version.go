@@ -0,0 +1,139 @@
+// pkg/api/version.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+type VersionService interface {
+    ListVersions(ctx context.Context, entityType string, entityID uint) ([]cms.Version, error)
+    GetVersion(ctx context.Context, entityType string, entityID uint, version int) (*cms.Version, error)
+    Restore(ctx context.Context, entityType string, entityID uint, version int) error
+    Compare(ctx context.Context, entityType string, entityID uint, v1, v2 int) (map[string]cms.FieldDiff, error)
+}
+
+// VersionAPI expone el historial de versiones de un tipo de entidad
+// (contenido o plantillas) bajo /<recurso>/:id/versions.
+type VersionAPI struct {
+    service    VersionService
+    entityType string
+}
+
+func NewVersionAPI(service VersionService, entityType string) *VersionAPI {
+    return &VersionAPI{service: service, entityType: entityType}
+}
+
+func (api *VersionAPI) RegisterRoutes(router *gin.RouterGroup) {
+    versions := router.Group("/:id/versions")
+    {
+        versions.GET("/", api.List)
+        versions.GET("/compare", api.Compare)
+        versions.GET("/:version", api.Get)
+        versions.POST("/:version/restore", api.Restore)
+    }
+}
+
+func (api *VersionAPI) List(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    versions, err := api.service.ListVersions(c.Request.Context(), api.entityType, uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, versions)
+}
+
+func (api *VersionAPI) Get(c *gin.Context) {
+    id, version, ok := parseVersionParams(c)
+    if !ok {
+        return
+    }
+
+    v, err := api.service.GetVersion(c.Request.Context(), api.entityType, id, version)
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, v)
+}
+
+func (api *VersionAPI) Restore(c *gin.Context) {
+    id, version, ok := parseVersionParams(c)
+    if !ok {
+        return
+    }
+
+    if err := api.service.Restore(c.Request.Context(), api.entityType, id, version); err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"restored": version})
+}
+
+func (api *VersionAPI) Compare(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    from, err := strconv.Atoi(c.Query("from"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from version"})
+        return
+    }
+    to, err := strconv.Atoi(c.Query("to"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to version"})
+        return
+    }
+
+    diff, err := api.service.Compare(c.Request.Context(), api.entityType, uint(id), from, to)
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "changes": diff})
+}
+
+func parseVersionParams(c *gin.Context) (uint, int, bool) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return 0, 0, false
+    }
+
+    version, err := strconv.Atoi(c.Param("version"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+        return 0, 0, false
+    }
+    return uint(id), version, true
+}
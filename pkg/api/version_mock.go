@@ -0,0 +1,38 @@
+// pkg/api/version_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockVersionService struct {
+    mock.Mock
+}
+
+func (m *MockVersionService) ListVersions(ctx context.Context, entityType string, entityID uint) ([]cms.Version, error) {
+    args := m.Called(ctx, entityType, entityID)
+    return args.Get(0).([]cms.Version), args.Error(1)
+}
+
+func (m *MockVersionService) GetVersion(ctx context.Context, entityType string, entityID uint, version int) (*cms.Version, error) {
+    args := m.Called(ctx, entityType, entityID, version)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Version), args.Error(1)
+}
+
+func (m *MockVersionService) Restore(ctx context.Context, entityType string, entityID uint, version int) error {
+    args := m.Called(ctx, entityType, entityID, version)
+    return args.Error(0)
+}
+
+func (m *MockVersionService) Compare(ctx context.Context, entityType string, entityID uint, v1, v2 int) (map[string]cms.FieldDiff, error) {
+    args := m.Called(ctx, entityType, entityID, v1, v2)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(map[string]cms.FieldDiff), args.Error(1)
+}
@@ -0,0 +1,108 @@
+// pkg/api/version_test.go
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+func setupVersionTest() (*gin.Engine, *MockVersionService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockVersionService)
+    router := gin.New()
+    api := NewVersionAPI(mockService, cms.EntityContent)
+    api.RegisterRoutes(router.Group("/api/v1/cms/content"))
+    return router, mockService
+}
+
+func TestVersionList(t *testing.T) {
+    router, mockService := setupVersionTest()
+    versions := []cms.Version{
+        {EntityID: 1, EntityType: cms.EntityContent, Version: 2, CreatedByName: "editor"},
+        {EntityID: 1, EntityType: cms.EntityContent, Version: 1, CreatedByName: "author"},
+    }
+
+    mockService.On("ListVersions", mock.Anything, cms.EntityContent, uint(1)).Return(versions, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/versions/", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response []cms.Version
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 2, len(response))
+    mockService.AssertExpectations(t)
+}
+
+func TestVersionGetNotFound(t *testing.T) {
+    router, mockService := setupVersionTest()
+    mockService.On("GetVersion", mock.Anything, cms.EntityContent, uint(1), 7).Return(nil, gorm.ErrRecordNotFound)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/versions/7", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestVersionRestore(t *testing.T) {
+    router, mockService := setupVersionTest()
+    mockService.On("Restore", mock.Anything, cms.EntityContent, uint(1), 2).Return(nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/versions/2/restore", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestVersionCompare(t *testing.T) {
+    router, mockService := setupVersionTest()
+    diff := map[string]cms.FieldDiff{
+        "title": {
+            Old: "Hello world",
+            New: "Hello there",
+            Ops: []cms.DiffOp{
+                {Op: cms.DiffEqual, Text: "Hello "},
+                {Op: cms.DiffDelete, Text: "world"},
+                {Op: cms.DiffInsert, Text: "there"},
+            },
+        },
+    }
+
+    mockService.On("Compare", mock.Anything, cms.EntityContent, uint(1), 1, 2).Return(diff, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/versions/compare?from=1&to=2", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response struct {
+        Changes map[string]cms.FieldDiff `json:"changes"`
+    }
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Len(t, response.Changes["title"].Ops, 3)
+    mockService.AssertExpectations(t)
+}
+
+func TestVersionCompareInvalidQuery(t *testing.T) {
+    router, _ := setupVersionTest()
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/versions/compare?from=x&to=2", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
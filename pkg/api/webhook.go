@@ -0,0 +1,215 @@
+// pkg/api/webhook.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
+)
+
+type WebhookService interface {
+    List(ctx context.Context) ([]cms.Webhook, error)
+    Get(ctx context.Context, id uint) (*cms.Webhook, error)
+    Create(ctx context.Context, hook *cms.Webhook) error
+    Update(ctx context.Context, hook *cms.Webhook) error
+    Delete(ctx context.Context, id uint) error
+    Deliveries(ctx context.Context, webhookID uint, params query.Params) (*query.Page[cms.WebhookDelivery], error)
+    Redeliver(ctx context.Context, webhookID, deliveryID uint) (*cms.WebhookDelivery, error)
+}
+
+type WebhookAPI struct {
+    service WebhookService
+}
+
+// WebhookRequest es el cuerpo de alta y edición. Active es opcional y por
+// defecto true.
+type WebhookRequest struct {
+    Name   string   `json:"name"`
+    URL    string   `json:"url" binding:"required"`
+    Secret string   `json:"secret"`
+    Events []string `json:"events" binding:"required"`
+    Active *bool    `json:"active"`
+}
+
+func NewWebhookAPI(service WebhookService) *WebhookAPI {
+    return &WebhookAPI{service: service}
+}
+
+func (api *WebhookAPI) RegisterRoutes(router *gin.RouterGroup) {
+    webhooks := router.Group("/webhooks")
+    {
+        webhooks.GET("/", api.List)
+        webhooks.POST("/", api.Create)
+        webhooks.GET("/events", api.Events)
+        webhooks.GET("/:id", api.Get)
+        webhooks.PUT("/:id", api.Update)
+        webhooks.DELETE("/:id", api.Delete)
+        webhooks.GET("/:id/deliveries", api.Deliveries)
+        webhooks.POST("/:id/deliveries/:delivery/redeliver", api.Redeliver)
+    }
+}
+
+func (api *WebhookAPI) List(c *gin.Context) {
+    hooks, err := api.service.List(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, hooks)
+}
+
+// Events lista los tipos de evento que se pueden suscribir.
+func (api *WebhookAPI) Events(c *gin.Context) {
+    c.JSON(http.StatusOK, cms.EventTypes)
+}
+
+func (api *WebhookAPI) Get(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    hook, err := api.service.Get(c.Request.Context(), uint(id))
+    if err != nil {
+        writeWebhookError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, hook)
+}
+
+func (api *WebhookAPI) Create(c *gin.Context) {
+    var request WebhookRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    hook := request.webhook()
+    if err := api.service.Create(c.Request.Context(), &hook); err != nil {
+        writeWebhookError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, hook)
+}
+
+func (api *WebhookAPI) Update(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    var request WebhookRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    // El secreto no se cambia al editar
+    hook := request.webhook()
+    hook.ID = uint(id)
+    hook.Secret = ""
+    if err := api.service.Update(c.Request.Context(), &hook); err != nil {
+        writeWebhookError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, hook)
+}
+
+func (api *WebhookAPI) Delete(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    if err := api.service.Delete(c.Request.Context(), uint(id)); err != nil {
+        writeWebhookError(c, err)
+        return
+    }
+
+    c.Status(http.StatusNoContent)
+}
+
+// Deliveries devuelve el log de entregas, con la paginación y los filtros de
+// los demás listados (?status=failed, ?event=content.published...).
+func (api *WebhookAPI) Deliveries(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    params, ok := parseListParams(c, cms.WebhookDeliveryQuery, &cms.WebhookDelivery{})
+    if !ok {
+        return
+    }
+
+    page, err := api.service.Deliveries(c.Request.Context(), uint(id), params)
+    if err != nil {
+        writeWebhookError(c, err)
+        return
+    }
+
+    writePage(c, page, params.Fields)
+}
+
+// Redeliver encola de nuevo una entrega, p. ej. después de arreglar el
+// receptor. Responde con la entrega nueva.
+func (api *WebhookAPI) Redeliver(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+    deliveryID, err := strconv.ParseUint(c.Param("delivery"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+        return
+    }
+
+    delivery, err := api.service.Redeliver(c.Request.Context(), uint(id), uint(deliveryID))
+    if err != nil {
+        writeWebhookError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusAccepted, delivery)
+}
+
+func (r WebhookRequest) webhook() cms.Webhook {
+    active := true
+    if r.Active != nil {
+        active = *r.Active
+    }
+    return cms.Webhook{
+        Name:   r.Name,
+        URL:    r.URL,
+        Secret: r.Secret,
+        Events: cms.StringArray(r.Events),
+        Active: active,
+    }
+}
+
+func writeWebhookError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, cms.ErrInvalidWebhook):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "webhook or delivery not found"})
+    case errors.Is(err, query.ErrInvalidQuery):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
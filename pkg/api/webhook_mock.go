@@ -0,0 +1,57 @@
+// pkg/api/webhook_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
+)
+
+type MockWebhookService struct {
+    mock.Mock
+}
+
+func (m *MockWebhookService) List(ctx context.Context) ([]cms.Webhook, error) {
+    args := m.Called(ctx)
+    return args.Get(0).([]cms.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Get(ctx context.Context, id uint) (*cms.Webhook, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Create(ctx context.Context, hook *cms.Webhook) error {
+    args := m.Called(ctx, hook)
+    return args.Error(0)
+}
+
+func (m *MockWebhookService) Update(ctx context.Context, hook *cms.Webhook) error {
+    args := m.Called(ctx, hook)
+    return args.Error(0)
+}
+
+func (m *MockWebhookService) Delete(ctx context.Context, id uint) error {
+    args := m.Called(ctx, id)
+    return args.Error(0)
+}
+
+func (m *MockWebhookService) Deliveries(ctx context.Context, webhookID uint, params query.Params) (*query.Page[cms.WebhookDelivery], error) {
+    args := m.Called(ctx, webhookID, params)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*query.Page[cms.WebhookDelivery]), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, webhookID, deliveryID uint) (*cms.WebhookDelivery, error) {
+    args := m.Called(ctx, webhookID, deliveryID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.WebhookDelivery), args.Error(1)
+}
@@ -0,0 +1,126 @@
+// pkg/api/webhook_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/query"
+)
+
+func setupWebhookTest() (*gin.Engine, *MockWebhookService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockWebhookService)
+    router := gin.New()
+    api := NewWebhookAPI(mockService)
+    api.RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func TestWebhookCreate(t *testing.T) {
+    router, mockService := setupWebhookTest()
+
+    mockService.On("Create", mock.Anything, mock.MatchedBy(func(hook *cms.Webhook) bool {
+        return hook.URL == "https://example.com/hook" && hook.Active && len(hook.Events) == 1
+    })).Run(func(args mock.Arguments) {
+        args.Get(1).(*cms.Webhook).Secret = "whsec_generated"
+    }).Return(nil)
+
+    body, _ := json.Marshal(WebhookRequest{URL: "https://example.com/hook", Events: []string{cms.EventContentPublished}})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/webhooks/", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusCreated, w.Code)
+
+    // El secreto generado solo se devuelve al crear
+    var response cms.Webhook
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, "whsec_generated", response.Secret)
+    mockService.AssertExpectations(t)
+}
+
+func TestWebhookCreateInvalid(t *testing.T) {
+    router, mockService := setupWebhookTest()
+    mockService.On("Create", mock.Anything, mock.Anything).Return(cms.ErrInvalidWebhook)
+
+    body, _ := json.Marshal(WebhookRequest{URL: "ftp://example.com", Events: []string{"content.moved"}})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/webhooks/", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWebhookUpdateKeepsSecret(t *testing.T) {
+    router, mockService := setupWebhookTest()
+
+    inactive := false
+    mockService.On("Update", mock.Anything, mock.MatchedBy(func(hook *cms.Webhook) bool {
+        return hook.ID == 4 && hook.Secret == "" && !hook.Active
+    })).Return(nil)
+
+    body, _ := json.Marshal(WebhookRequest{URL: "https://example.com/hook", Secret: "ignored", Events: []string{"*"}, Active: &inactive})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("PUT", "/api/v1/cms/webhooks/4", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+    router, mockService := setupWebhookTest()
+    page := &query.Page[cms.WebhookDelivery]{
+        Data: []cms.WebhookDelivery{{WebhookID: 4, Event: cms.EventContentPublished, Status: cms.DeliveryFailed}},
+        Page: query.PageInfo{Limit: 20},
+    }
+
+    mockService.On("Deliveries", mock.Anything, uint(4), mock.MatchedBy(func(params query.Params) bool {
+        return len(params.Filters) == 1 && params.Filters[0].Field == "status"
+    })).Return(page, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/webhooks/4/deliveries?status=failed", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Contains(t, w.Body.String(), `"status":"failed"`)
+    mockService.AssertExpectations(t)
+}
+
+func TestWebhookRedeliver(t *testing.T) {
+    router, mockService := setupWebhookTest()
+    mockService.On("Redeliver", mock.Anything, uint(4), uint(9)).
+        Return(&cms.WebhookDelivery{WebhookID: 4, Status: cms.DeliveryPending}, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/webhooks/4/deliveries/9/redeliver", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusAccepted, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWebhookRedeliverNotFound(t *testing.T) {
+    router, mockService := setupWebhookTest()
+    mockService.On("Redeliver", mock.Anything, uint(4), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/webhooks/4/deliveries/9/redeliver", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    mockService.AssertExpectations(t)
+}
@@ -0,0 +1,130 @@
+// pkg/api/wordpress_import.go
+package api
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+// maxWXRSize limita el fichero subido; los adjuntos se descargan aparte.
+const maxWXRSize = 64 << 20
+
+type WordPressImportService interface {
+    Create(ctx context.Context, fileName string, source []byte, locale string) (*cms.WXRImportJob, error)
+    List(ctx context.Context) ([]cms.WXRImportJob, error)
+    Get(ctx context.Context, id uint) (*cms.WXRImportJob, error)
+    Resume(ctx context.Context, id uint) (*cms.WXRImportJob, error)
+}
+
+type WordPressImportAPI struct {
+    service WordPressImportService
+}
+
+func NewWordPressImportAPI(service WordPressImportService) *WordPressImportAPI {
+    return &WordPressImportAPI{service: service}
+}
+
+func (api *WordPressImportAPI) RegisterRoutes(router *gin.RouterGroup) {
+    imports := router.Group("/imports/wordpress")
+    {
+        imports.POST("/", api.Create)
+        imports.GET("/", api.List)
+        imports.GET("/:id", api.Get)
+        imports.POST("/:id/resume", api.Resume)
+    }
+}
+
+// Create recibe el export WXR en el campo "file" y, opcionalmente, el idioma
+// del contenido en "locale". Responde 202 con el trabajo, cuyo progreso se
+// consulta con Get.
+func (api *WordPressImportAPI) Create(c *gin.Context) {
+    header, err := c.FormFile("file")
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+        return
+    }
+    if header.Size > maxWXRSize {
+        c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+        return
+    }
+    file, err := header.Open()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    defer file.Close()
+    source, err := io.ReadAll(file)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    job, err := api.service.Create(c.Request.Context(), header.Filename, source, c.PostForm("locale"))
+    if err != nil {
+        if errors.Is(err, cms.ErrInvalidWXR) || errors.Is(err, cms.ErrUnsupportedLocale) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusAccepted, job)
+}
+
+func (api *WordPressImportAPI) List(c *gin.Context) {
+    jobs, err := api.service.List(c.Request.Context())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, jobs)
+}
+
+func (api *WordPressImportAPI) Get(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    job, err := api.service.Get(c.Request.Context(), uint(id))
+    if err != nil {
+        writeImportJobError(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, job)
+}
+
+// Resume reanuda un trabajo fallido desde el último elemento procesado.
+func (api *WordPressImportAPI) Resume(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    job, err := api.service.Resume(c.Request.Context(), uint(id))
+    if err != nil {
+        writeImportJobError(c, err)
+        return
+    }
+    c.JSON(http.StatusAccepted, job)
+}
+
+func writeImportJobError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "import job not found"})
+    case errors.Is(err, cms.ErrImportJobState):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
@@ -0,0 +1,44 @@
+// pkg/api/wordpress_import_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockWordPressImportService struct {
+    mock.Mock
+}
+
+func (m *MockWordPressImportService) Create(ctx context.Context, fileName string, source []byte, locale string) (*cms.WXRImportJob, error) {
+    args := m.Called(ctx, fileName, source, locale)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.WXRImportJob), args.Error(1)
+}
+
+func (m *MockWordPressImportService) List(ctx context.Context) ([]cms.WXRImportJob, error) {
+    args := m.Called(ctx)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]cms.WXRImportJob), args.Error(1)
+}
+
+func (m *MockWordPressImportService) Get(ctx context.Context, id uint) (*cms.WXRImportJob, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.WXRImportJob), args.Error(1)
+}
+
+func (m *MockWordPressImportService) Resume(ctx context.Context, id uint) (*cms.WXRImportJob, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.WXRImportJob), args.Error(1)
+}
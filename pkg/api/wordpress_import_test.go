@@ -0,0 +1,117 @@
+// pkg/api/wordpress_import_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "mime/multipart"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+func setupWordPressImportTest() (*gin.Engine, *MockWordPressImportService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockWordPressImportService)
+    router := gin.New()
+    NewWordPressImportAPI(mockService).RegisterRoutes(router.Group("/api/v1/cms"))
+    return router, mockService
+}
+
+func wxrUpload(t *testing.T, content, locale string) (*bytes.Buffer, string) {
+    var body bytes.Buffer
+    writer := multipart.NewWriter(&body)
+    part, err := writer.CreateFormFile("file", "blog.xml")
+    assert.NoError(t, err)
+    part.Write([]byte(content))
+    if locale != "" {
+        writer.WriteField("locale", locale)
+    }
+    writer.Close()
+    return &body, writer.FormDataContentType()
+}
+
+func TestWordPressImportCreate(t *testing.T) {
+    router, mockService := setupWordPressImportTest()
+    job := &cms.WXRImportJob{ID: 1, FileName: "blog.xml", Locale: "es", Status: cms.ImportJobPending, Total: 12}
+    mockService.On("Create", mock.Anything, "blog.xml", []byte("<rss/>"), "es").Return(job, nil)
+
+    body, contentType := wxrUpload(t, "<rss/>", "es")
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/imports/wordpress/", body)
+    req.Header.Set("Content-Type", contentType)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusAccepted, w.Code)
+    var response cms.WXRImportJob
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, cms.ImportJobPending, response.Status)
+    assert.Equal(t, 12, response.Total)
+    mockService.AssertExpectations(t)
+}
+
+func TestWordPressImportCreateErrors(t *testing.T) {
+    router, mockService := setupWordPressImportTest()
+    mockService.On("Create", mock.Anything, "blog.xml", []byte("not xml"), "").
+        Return(nil, fmt.Errorf("%w: EOF", cms.ErrInvalidWXR))
+
+    body, contentType := wxrUpload(t, "not xml", "")
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/imports/wordpress/", body)
+    req.Header.Set("Content-Type", contentType)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    // Sin fichero
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("POST", "/api/v1/cms/imports/wordpress/", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWordPressImportProgress(t *testing.T) {
+    router, mockService := setupWordPressImportTest()
+    mockService.On("Get", mock.Anything, uint(1)).
+        Return(&cms.WXRImportJob{ID: 1, Status: cms.ImportJobRunning, Total: 10, Processed: 4}, nil)
+    mockService.On("Get", mock.Anything, uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/imports/wordpress/1", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+    var response cms.WXRImportJob
+    json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 4, response.Processed)
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("GET", "/api/v1/cms/imports/wordpress/2", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWordPressImportResume(t *testing.T) {
+    router, mockService := setupWordPressImportTest()
+    mockService.On("Resume", mock.Anything, uint(1)).
+        Return(&cms.WXRImportJob{ID: 1, Status: cms.ImportJobPending}, nil)
+    mockService.On("Resume", mock.Anything, uint(2)).
+        Return(nil, fmt.Errorf("%w: running", cms.ErrImportJobState))
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/imports/wordpress/1/resume", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusAccepted, w.Code)
+
+    w = httptest.NewRecorder()
+    req, _ = http.NewRequest("POST", "/api/v1/cms/imports/wordpress/2/resume", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusConflict, w.Code)
+    mockService.AssertExpectations(t)
+}
@@ -0,0 +1,103 @@
+// pkg/api/workflow.go
+package api
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+)
+
+type WorkflowService interface {
+    Workflow() *cms.Workflow
+    Transition(ctx context.Context, contentID uint, to, username, comment string) (*cms.TransitionResult, error)
+    History(ctx context.Context, contentID uint) ([]cms.ContentTransition, error)
+}
+
+type WorkflowAPI struct {
+    service WorkflowService
+}
+
+type TransitionRequest struct {
+    To      string `json:"to" binding:"required"`
+    Comment string `json:"comment"`
+}
+
+func NewWorkflowAPI(service WorkflowService) *WorkflowAPI {
+    return &WorkflowAPI{service: service}
+}
+
+func (api *WorkflowAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/workflow", api.Get)
+    router.GET("/content/:id/transitions", api.History)
+    router.POST("/content/:id/transitions", api.Transition)
+}
+
+func (api *WorkflowAPI) Get(c *gin.Context) {
+    c.JSON(http.StatusOK, api.service.Workflow())
+}
+
+func (api *WorkflowAPI) History(c *gin.Context) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    history, err := api.service.History(c.Request.Context(), uint(id))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, history)
+}
+
+func (api *WorkflowAPI) Transition(c *gin.Context) {
+    var request TransitionRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    api.transition(c, request.To, request.Comment)
+}
+
+// Publish reemplaza a ContentAPI.Publish en las rutas CMS para que publicar
+// también pase por el workflow y los permisos por rol.
+func (api *WorkflowAPI) Publish(c *gin.Context) {
+    api.transition(c, cms.StatusPublished, "")
+}
+
+func (api *WorkflowAPI) transition(c *gin.Context, to, comment string) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+        return
+    }
+
+    result, err := api.service.Transition(c.Request.Context(), uint(id), to, c.GetString("username"), comment)
+    if err != nil {
+        switch {
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
+        case errors.Is(err, cms.ErrIllegalTransition):
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrTransitionForbidden):
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+        case errors.Is(err, cms.ErrCommentRequired):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        }
+        return
+    }
+
+    if !result.Applied {
+        c.JSON(http.StatusAccepted, result)
+        return
+    }
+    c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,30 @@
+// pkg/api/workflow_mock.go
+package api
+
+import (
+    "context"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+type MockWorkflowService struct {
+    mock.Mock
+}
+
+func (m *MockWorkflowService) Workflow() *cms.Workflow {
+    args := m.Called()
+    return args.Get(0).(*cms.Workflow)
+}
+
+func (m *MockWorkflowService) Transition(ctx context.Context, contentID uint, to, username, comment string) (*cms.TransitionResult, error) {
+    args := m.Called(ctx, contentID, to, username, comment)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*cms.TransitionResult), args.Error(1)
+}
+
+func (m *MockWorkflowService) History(ctx context.Context, contentID uint) ([]cms.ContentTransition, error) {
+    args := m.Called(ctx, contentID)
+    return args.Get(0).([]cms.ContentTransition), args.Error(1)
+}
@@ -0,0 +1,106 @@
+// pkg/api/workflow_test.go
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "ezzygo/pkg/cms"
+)
+
+func setupWorkflowTest() (*gin.Engine, *MockWorkflowService) {
+    gin.SetMode(gin.TestMode)
+    mockService := new(MockWorkflowService)
+    router := gin.New()
+    router.Use(func(c *gin.Context) {
+        c.Set("username", "editor")
+        c.Next()
+    })
+    api := NewWorkflowAPI(mockService)
+    group := router.Group("/api/v1/cms")
+    api.RegisterRoutes(group)
+    group.POST("/content/:id/publish", api.Publish)
+    return router, mockService
+}
+
+func TestWorkflowTransition(t *testing.T) {
+    router, mockService := setupWorkflowTest()
+    result := &cms.TransitionResult{Content: &cms.Content{Status: cms.StatusInReview}, Applied: true}
+
+    mockService.On("Transition", mock.Anything, uint(1), cms.StatusInReview, "editor", "ready").Return(result, nil)
+
+    body, _ := json.Marshal(TransitionRequest{To: cms.StatusInReview, Comment: "ready"})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/transitions", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWorkflowTransitionPendingApprovals(t *testing.T) {
+    router, mockService := setupWorkflowTest()
+    result := &cms.TransitionResult{Applied: false, Approvals: 1, Required: 2}
+
+    mockService.On("Transition", mock.Anything, uint(1), cms.StatusApproved, "editor", "").Return(result, nil)
+
+    body, _ := json.Marshal(TransitionRequest{To: cms.StatusApproved})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/transitions", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusAccepted, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWorkflowIllegalTransition(t *testing.T) {
+    router, mockService := setupWorkflowTest()
+    mockService.On("Transition", mock.Anything, uint(1), cms.StatusPublished, "editor", "").Return(nil, cms.ErrIllegalTransition)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/publish", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusConflict, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWorkflowForbiddenTransition(t *testing.T) {
+    router, mockService := setupWorkflowTest()
+    mockService.On("Transition", mock.Anything, uint(1), cms.StatusArchived, "editor", "").Return(nil, cms.ErrTransitionForbidden)
+
+    body, _ := json.Marshal(TransitionRequest{To: cms.StatusArchived})
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("POST", "/api/v1/cms/content/1/transitions", bytes.NewBuffer(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusForbidden, w.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestWorkflowHistory(t *testing.T) {
+    router, mockService := setupWorkflowTest()
+    history := []cms.ContentTransition{
+        {ContentID: 1, FromState: cms.StatusDraft, ToState: cms.StatusInReview, Applied: true},
+    }
+    mockService.On("History", mock.Anything, uint(1)).Return(history, nil)
+
+    w := httptest.NewRecorder()
+    req, _ := http.NewRequest("GET", "/api/v1/cms/content/1/transitions", nil)
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response []cms.ContentTransition
+    _ = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.Equal(t, 1, len(response))
+    mockService.AssertExpectations(t)
+}
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"os"
 	"time"
 
@@ -18,6 +20,21 @@ type Claims struct {
 
 var JwtKey = []byte(os.Getenv("JWT_SECRET_KEY"))
 
+type contextKey string
+
+const usernameKey contextKey = "username"
+
+// WithUsername stores the authenticated username in the request context
+func WithUsername(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, usernameKey, username)
+}
+
+// UsernameFromContext returns the authenticated username, or "" if there is none
+func UsernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value(usernameKey).(string)
+	return username
+}
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
 	return string(bytes), err
@@ -28,10 +45,13 @@ func GenerateToken(username string) (string, error) {
 	expirationTime := time.Now().Add(5 * time.Minute).Unix()
 
 	// Create the JWT claims, which includes the username and expiration time
-	claims := &jwt.StandardClaims{
-		// In JWT, the expiry time is expressed as unix milliseconds
-		ExpiresAt: expirationTime,
-		Issuer:    username,
+	claims := &Claims{
+		Username: username,
+		StandardClaims: jwt.StandardClaims{
+			// In JWT, the expiry time is expressed as unix milliseconds
+			ExpiresAt: expirationTime,
+			Issuer:    username,
+		},
 	}
 
 	// Declare the token with the algorithm used for signing, and the claims
@@ -47,6 +67,21 @@ func GenerateToken(username string) (string, error) {
 	return tokenString, nil
 }
 
+// ParseToken validates a token issued by GenerateToken and returns its claims.
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return JwtKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
 func GenerateRandomKey() string {
 	key := make([]byte, 32) // generate a 256 bit key
 	_, err := rand.Read(key)
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"context"
 	"testing"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,3 +27,21 @@ func TestGenerateRandomKey(t *testing.T) {
 	assert.NotEmpty(t, randomKey)
 	assert.Len(t, randomKey, 44)
 }
+
+func TestGenerateTokenUsernameClaim(t *testing.T) {
+	token, err := GenerateToken("chud")
+	assert.Nil(t, err)
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return JwtKey, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "chud", claims.Username)
+}
+
+func TestUsernameContext(t *testing.T) {
+	ctx := WithUsername(context.Background(), "chud")
+	assert.Equal(t, "chud", UsernameFromContext(ctx))
+	assert.Equal(t, "", UsernameFromContext(context.Background()))
+}
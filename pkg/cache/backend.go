@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned when a key is not cached.
+var ErrMiss = errors.New("cache miss")
+
+// Backend stores encoded values. Tags group keys so they can be invalidated
+// together, e.g. every entry that depends on template:5.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const invalidationChannel = "cache:invalidations"
+
+// Invalidation is broadcast to every replica when keys are deleted or tags
+// are invalidated, so each one can evict its local copies.
+type Invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// Bus carries invalidations between replicas. Subscribe blocks until ctx is
+// done. resync is called whenever the subscription is (re)established, since
+// messages sent while disconnected are lost.
+type Bus interface {
+	Publish(ctx context.Context, message Invalidation) error
+	Subscribe(ctx context.Context, handle func(Invalidation), resync func()) error
+}
+
+// RedisBus is a Bus over Redis pub/sub.
+type RedisBus struct {
+	client  *redis.Client
+	channel string
+}
+
+func NewRedisBus(client *redis.Client) *RedisBus {
+	return &RedisBus{client: client, channel: invalidationChannel}
+}
+
+func (b *RedisBus) Publish(ctx context.Context, message Invalidation) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+func (b *RedisBus) Subscribe(ctx context.Context, handle func(Invalidation), resync func()) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	for {
+		received, err := pubsub.Receive(ctx)
+		if err != nil {
+			// The client reconnects and resubscribes on the next Receive
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch message := received.(type) {
+		case *redis.Subscription:
+			if message.Kind == "subscribe" {
+				resync()
+			}
+		case *redis.Message:
+			var invalidation Invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &invalidation); err == nil {
+				handle(invalidation)
+			}
+		}
+	}
+}
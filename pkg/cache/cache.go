@@ -1,20 +1,15 @@
 package cache
 
 import (
-	"context"
 	"os"
+	"strconv"
 	"time"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/redis/go-redis/v9"
 )
 
-type Cache interface {
-	Get(ctx context.Context, key string) *redis.StringCmd
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
-	Keys(context.Context, string) *redis.StringSliceCmd
-	Del(context.Context, ...string) *redis.IntCmd
-}
-
+// NewRedisClient returns the Redis client shared by the cache store, the CMS
+// edit locks and the scheduler.
 func NewRedisClient() *redis.Client {
 	return redis.NewClient(&redis.Options{
 		Addr:     os.Getenv("REDIS_HOST") + ":6379", // Redis server address (change to localhost when running local)
@@ -22,3 +17,27 @@ func NewRedisClient() *redis.Client {
 		DB:       0,                                 // Default DB
 	})
 }
+
+// NewTieredRedisBackend puts a local LRU in front of Redis. The local tier is
+// sized with CACHE_LOCAL_MAX_ENTRIES (default 10000) and CACHE_LOCAL_MAX_BYTES
+// (default 64MB); CACHE_LOCAL_TTL (default 1m) caps how long an entry is kept
+// locally. Call Run on the result to receive invalidations from other
+// replicas.
+func NewTieredRedisBackend(client *redis.Client) *TieredBackend {
+	local := NewLRUBackend(
+		intEnv("CACHE_LOCAL_MAX_ENTRIES", 10000),
+		int64(intEnv("CACHE_LOCAL_MAX_BYTES", 64<<20)),
+	)
+	localTTL := time.Minute
+	if value, err := time.ParseDuration(os.Getenv("CACHE_LOCAL_TTL")); err == nil && value > 0 {
+		localTTL = value
+	}
+	return NewTieredBackend(local, NewRedisBackend(client), NewRedisBus(client), localTTL)
+}
+
+func intEnv(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+		return value
+	}
+	return fallback
+}
@@ -1,17 +1,166 @@
 package cache
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
-
-	"github.com/golang/mock/gomock"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestNewRedisClient(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+type item struct {
+	ID   int
+	Name string
+}
+
+func TestGetOrLoadCachesValue(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	ctx := context.Background()
+	loads := 0
+	load := func(context.Context) (item, error) {
+		loads++
+		return item{ID: 1, Name: "one"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		value, err := GetOrLoad(ctx, store, "item:1", Options[item]{TTL: time.Minute}, load)
+		require.NoError(t, err)
+		assert.Equal(t, item{ID: 1, Name: "one"}, value)
+	}
+	assert.Equal(t, 1, loads)
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	ctx := context.Background()
+	failure := errors.New("db down")
+
+	_, err := GetOrLoad(ctx, store, "item:1", Options[item]{TTL: time.Minute}, func(context.Context) (item, error) {
+		return item{}, failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	var cached item
+	assert.ErrorIs(t, store.Get(ctx, "item:1", &cached), ErrMiss)
+}
+
+func TestGetOrLoadSingleFlight(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	ctx := context.Background()
+	var loads int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrLoad(ctx, store, "item:1", Options[item]{TTL: time.Minute}, func(context.Context) (item, error) {
+				atomic.AddInt32(&loads, 1)
+				<-release
+				return item{ID: 1}, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, value.ID)
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
+
+func TestGetOrLoadCancelledCallerDoesNotFailOthers(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (item, error) {
+		close(started)
+		select {
+		case <-release:
+			return item{ID: 1}, ctx.Err()
+		case <-ctx.Done():
+			return item{}, ctx.Err()
+		}
+	}
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := GetOrLoad(first, store, "item:1", Options[item]{TTL: time.Minute}, load)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan item, 1)
+	go func() {
+		value, err := GetOrLoad(context.Background(), store, "item:1", Options[item]{TTL: time.Minute}, load)
+		assert.NoError(t, err)
+		second <- value
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	assert.Equal(t, 1, (<-second).ID)
+}
+
+func TestInvalidateTags(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	ctx := context.Background()
+
+	_, err := GetOrLoad(ctx, store, "content:1", Options[item]{
+		TTL:    time.Minute,
+		Tags:   []string{"content:1"},
+		TagsOf: func(v item) []string { return []string{"template:5"} },
+	}, func(context.Context) (item, error) { return item{ID: 1}, nil })
+	require.NoError(t, err)
+	require.NoError(t, store.Set(ctx, "content:2", item{ID: 2}, time.Minute, "content:2", "template:6"))
+
+	require.NoError(t, store.InvalidateTags(ctx, "template:5"))
+
+	var cached item
+	assert.ErrorIs(t, store.Get(ctx, "content:1", &cached), ErrMiss)
+	assert.NoError(t, store.Get(ctx, "content:2", &cached))
+}
+
+func TestOnInvalidate(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	var invalidated [][]string
+	store.OnInvalidate(func(ctx context.Context, tags []string) {
+		invalidated = append(invalidated, tags)
+	})
+
+	require.NoError(t, store.InvalidateTags(context.Background(), "content:1", "template:5"))
+	assert.Equal(t, [][]string{{"content:1", "template:5"}}, invalidated)
+}
+
+func TestMemoryBackendExpires(t *testing.T) {
+	backend := NewMemoryBackend()
+	now := time.Now()
+	backend.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, backend.Set(ctx, "key", []byte("1"), time.Second, nil))
+	_, err := backend.Get(ctx, "key")
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Second)
+	_, err = backend.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrMiss)
+}
 
-	redisClient := NewMockCache(ctrl)
-	assert.NotNil(t, redisClient)
+func TestJitterTTL(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	for i := 0; i < 100; i++ {
+		ttl := store.jitterTTL(time.Minute)
+		assert.GreaterOrEqual(t, ttl, 54*time.Second)
+		assert.LessOrEqual(t, ttl, 66*time.Second)
+	}
+	assert.Equal(t, time.Duration(0), store.jitterTTL(0))
 }
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	tags      []string
+	expiresAt time.Time
+}
+
+// LRUBackend is an in-process backend bounded by entry count and by the total
+// size of keys and values. When either limit is exceeded the least recently
+// used entries are evicted.
+type LRUBackend struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	size       int64
+	order      *list.List
+	entries    map[string]*list.Element
+	tags       map[string]map[string]bool
+	stats      counters
+	now        func() time.Time
+}
+
+// NewLRUBackend returns an LRU limited to maxEntries entries and maxBytes
+// bytes. A limit of 0 disables it.
+func NewLRUBackend(maxEntries int, maxBytes int64) *LRUBackend {
+	return &LRUBackend{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+		tags:       map[string]map[string]bool{},
+		now:        time.Now,
+	}
+}
+
+func (b *LRUBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	value, _, err := b.get(key)
+	return value, err
+}
+
+func (b *LRUBackend) get(key string) ([]byte, []string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	element, ok := b.entries[key]
+	if !ok {
+		b.stats.misses.Add(1)
+		return nil, nil, ErrMiss
+	}
+	entry := element.Value.(*lruEntry)
+	if b.now().After(entry.expiresAt) {
+		b.remove(element)
+		b.stats.misses.Add(1)
+		return nil, nil, ErrMiss
+	}
+
+	b.order.MoveToFront(element)
+	b.stats.hits.Add(1)
+	return entry.value, entry.tags, nil
+}
+
+func (b *LRUBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if element, ok := b.entries[key]; ok {
+		b.remove(element)
+	}
+
+	entry := &lruEntry{key: key, value: value, tags: tags, expiresAt: b.now().Add(ttl)}
+	b.entries[key] = b.order.PushFront(entry)
+	b.size += entrySize(entry)
+	for _, tag := range tags {
+		if b.tags[tag] == nil {
+			b.tags[tag] = map[string]bool{}
+		}
+		b.tags[tag][key] = true
+	}
+
+	for b.order.Len() > 0 && b.overLimit() {
+		b.remove(b.order.Back())
+		b.stats.evictions.Add(1)
+	}
+	return nil
+}
+
+func (b *LRUBackend) Delete(ctx context.Context, keys ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := b.entries[key]; ok {
+			b.remove(element)
+		}
+	}
+	return nil
+}
+
+func (b *LRUBackend) InvalidateTags(ctx context.Context, tags ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range b.tags[tag] {
+			if element, ok := b.entries[key]; ok {
+				b.remove(element)
+			}
+		}
+		delete(b.tags, tag)
+	}
+	return nil
+}
+
+// Flush drops every entry. Counters are kept.
+func (b *LRUBackend) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.order.Init()
+	b.entries = map[string]*list.Element{}
+	b.tags = map[string]map[string]bool{}
+	b.size = 0
+}
+
+func (b *LRUBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.order.Len()
+}
+
+func (b *LRUBackend) Stats(ctx context.Context) (map[string]Stats, error) {
+	return map[string]Stats{"local": b.stats.snapshot()}, nil
+}
+
+func (b *LRUBackend) overLimit() bool {
+	return (b.maxEntries > 0 && b.order.Len() > b.maxEntries) ||
+		(b.maxBytes > 0 && b.size > b.maxBytes)
+}
+
+// remove must be called with the lock held.
+func (b *LRUBackend) remove(element *list.Element) {
+	entry := b.order.Remove(element).(*lruEntry)
+	delete(b.entries, entry.key)
+	b.size -= entrySize(entry)
+	for _, tag := range entry.tags {
+		if keys := b.tags[tag]; keys != nil {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(b.tags, tag)
+			}
+		}
+	}
+}
+
+func entrySize(entry *lruEntry) int64 {
+	return int64(len(entry.key) + len(entry.value))
+}
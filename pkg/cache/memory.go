@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryBackend is an unbounded in-process backend, used in tests and when
+// there is no Redis to talk to.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	tags    map[string]map[string]bool
+	now     func() time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		entries: map[string]memoryEntry{},
+		tags:    map[string]map[string]bool{},
+		now:     time.Now,
+	}
+}
+
+func (b *MemoryBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	if b.now().After(entry.expiresAt) {
+		delete(b.entries, key)
+		return nil, ErrMiss
+	}
+	return entry.value, nil
+}
+
+func (b *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[key] = memoryEntry{value: value, expiresAt: b.now().Add(ttl)}
+	for _, tag := range tags {
+		if b.tags[tag] == nil {
+			b.tags[tag] = map[string]bool{}
+		}
+		b.tags[tag][key] = true
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, keys ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		delete(b.entries, key)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) InvalidateTags(ctx context.Context, tags ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range b.tags[tag] {
+			delete(b.entries, key)
+		}
+		delete(b.tags, tag)
+	}
+	return nil
+}
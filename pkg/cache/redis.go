@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const tagPrefix = "tag:"
+
+// setWithTags stores the value and adds its key to every tag set. Tag sets
+// live at least as long as their longest-lived member.
+var setWithTags = redis.NewScript(`
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+for i = 2, #KEYS do
+    redis.call("SADD", KEYS[i], KEYS[1])
+    if redis.call("PTTL", KEYS[i]) < tonumber(ARGV[2]) then
+        redis.call("PEXPIRE", KEYS[i], ARGV[2])
+    end
+end
+return 1
+`)
+
+// invalidateTags deletes every key in the given tag sets and the sets
+// themselves.
+var invalidateTags = redis.NewScript(`
+local deleted = 0
+for _, tag in ipairs(KEYS) do
+    for _, key in ipairs(redis.call("SMEMBERS", tag)) do
+        deleted = deleted + redis.call("DEL", key)
+    end
+    redis.call("DEL", tag)
+end
+return deleted
+`)
+
+// RedisBackend keeps values in Redis and tags in Redis sets, so invalidation
+// never has to scan the keyspace.
+type RedisBackend struct {
+	client *redis.Client
+	stats  counters
+}
+
+func NewRedisBackend(client *redis.Client) *RedisBackend {
+	return &RedisBackend{client: client}
+}
+
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := b.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		b.stats.misses.Add(1)
+		return nil, ErrMiss
+	}
+	if err == nil {
+		b.stats.hits.Add(1)
+	}
+	return value, err
+}
+
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	if len(tags) == 0 {
+		return b.client.Set(ctx, key, value, ttl).Err()
+	}
+
+	keys := append([]string{key}, tagKeys(tags)...)
+	return setWithTags.Run(ctx, b.client, keys, value, ttl.Milliseconds()).Err()
+}
+
+func (b *RedisBackend) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return b.client.Del(ctx, keys...).Err()
+}
+
+func (b *RedisBackend) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return invalidateTags.Run(ctx, b.client, tagKeys(tags)).Err()
+}
+
+// Stats reports the hits and misses seen by this process. Evictions come from
+// the server's evicted_keys, so they cover every client of the instance.
+func (b *RedisBackend) Stats(ctx context.Context) (map[string]Stats, error) {
+	stats := b.stats.snapshot()
+	info, err := b.client.Info(ctx, "stats").Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "evicted_keys:"); ok {
+			stats.Evictions, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+	return map[string]Stats{"redis": stats}, nil
+}
+
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagPrefix + tag
+	}
+	return keys
+}
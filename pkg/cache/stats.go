@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Stats are the counters of a single cache tier.
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// StatsReporter is implemented by backends that keep counters, keyed by tier
+// name ("local", "redis").
+type StatsReporter interface {
+	Stats(ctx context.Context) (map[string]Stats, error)
+}
+
+type counters struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultJitter spreads expirations by up to ±10% so entries written together
+// do not all expire, and reload, at the same moment.
+const defaultJitter = 0.1
+
+// loadTimeout bounds a shared load in GetOrLoad, which no longer follows the
+// cancellation of the caller that started it.
+const loadTimeout = 10 * time.Second
+
+// Store is the cache used by every repository and service. Values are stored
+// as JSON in a Backend.
+type Store struct {
+	backend Backend
+	group   singleflight.Group
+	jitter  float64
+	hooks   []func(ctx context.Context, tags []string)
+}
+
+func NewStore(backend Backend) *Store {
+	return &Store{
+		backend: backend,
+		jitter:  defaultJitter,
+	}
+}
+
+// Options configures how GetOrLoad caches a loaded value.
+type Options[T any] struct {
+	TTL  time.Duration
+	Tags []string
+	// TagsOf adds tags that depend on the loaded value, such as the template
+	// a content entry uses.
+	TagsOf func(T) []string
+}
+
+// Get decodes the cached value into dest. It returns ErrMiss when the key is
+// not cached.
+func (s *Store) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := s.backend.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Set caches value for roughly ttl (see jitter) under the given tags.
+func (s *Store) Set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.backend.Set(ctx, key, data, s.jitterTTL(ttl), tags)
+}
+
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	return s.backend.Delete(ctx, keys...)
+}
+
+// InvalidateTags deletes every entry cached under any of the tags and then
+// runs the invalidation hooks.
+func (s *Store) InvalidateTags(ctx context.Context, tags ...string) error {
+	if err := s.backend.InvalidateTags(ctx, tags...); err != nil {
+		return err
+	}
+	for _, hook := range s.hooks {
+		hook(ctx, tags)
+	}
+	return nil
+}
+
+// OnInvalidate registers a hook that runs after tags are invalidated through
+// this store, e.g. to purge the same surrogate keys from a CDN. Hooks must be
+// registered before the store is used.
+func (s *Store) OnInvalidate(hook func(ctx context.Context, tags []string)) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// Stats returns the counters of each tier, or none if the backend does not
+// keep them.
+func (s *Store) Stats(ctx context.Context) (map[string]Stats, error) {
+	reporter, ok := s.backend.(StatsReporter)
+	if !ok {
+		return map[string]Stats{}, nil
+	}
+	return reporter.Stats(ctx)
+}
+
+func (s *Store) jitterTTL(ttl time.Duration) time.Duration {
+	if s.jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	spread := float64(ttl) * s.jitter
+	return ttl + time.Duration(spread*(2*rand.Float64()-1))
+}
+
+// GetOrLoad returns the cached value for key or calls load and caches its
+// result. Concurrent misses for the same key share a single load, so an
+// expired hot key does not stampede the database. Cache errors are not
+// returned: on failure the value is loaded and served uncached.
+//
+// Callers that share a load also share its result, so pointer values must be
+// treated as read-only.
+//
+// The load runs detached from the context of the caller that started it, with
+// its own loadTimeout, so one cancelled request does not fail the others
+// waiting on the same key. Each caller still stops waiting when its own
+// context is done.
+func GetOrLoad[T any](ctx context.Context, s *Store, key string, opts Options[T], load func(context.Context) (T, error)) (T, error) {
+	var value T
+	if err := s.Get(ctx, key, &value); err == nil {
+		return value, nil
+	}
+
+	results := s.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		// Another caller may have filled the key while we waited
+		var cached T
+		if err := s.Get(ctx, key, &cached); err == nil {
+			return cached, nil
+		}
+
+		loaded, err := load(ctx)
+		if err != nil {
+			return loaded, err
+		}
+
+		tags := opts.Tags
+		if opts.TagsOf != nil {
+			tags = append(append([]string{}, tags...), opts.TagsOf(loaded)...)
+		}
+		s.Set(ctx, key, loaded, opts.TTL, tags...)
+		return loaded, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return value, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
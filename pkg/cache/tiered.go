@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// TieredBackend keeps an in-process LRU in front of a shared backend. Reads
+// are served locally when possible; deletes and tag invalidations are applied
+// to both tiers and broadcast on the bus so other replicas evict their local
+// copies too.
+//
+// Local entries live at most localTTL, which bounds staleness if a broadcast
+// is missed or races with a read.
+type TieredBackend struct {
+	local    *LRUBackend
+	remote   Backend
+	bus      Bus
+	origin   string
+	localTTL time.Duration
+}
+
+func NewTieredBackend(local *LRUBackend, remote Backend, bus Bus, localTTL time.Duration) *TieredBackend {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &TieredBackend{
+		local:    local,
+		remote:   remote,
+		bus:      bus,
+		origin:   hex.EncodeToString(id),
+		localTTL: localTTL,
+	}
+}
+
+func (b *TieredBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := b.local.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	data, err := b.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	value, tags, err := decodeEntry(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tags travel with the value so tag invalidations also reach entries
+	// that were filled from the shared tier
+	b.local.Set(ctx, key, value, b.localTTL, tags)
+	return value, nil
+}
+
+func (b *TieredBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	if err := b.remote.Set(ctx, key, encodeEntry(value, tags), ttl, tags); err != nil {
+		return err
+	}
+	return b.local.Set(ctx, key, value, min(ttl, b.localTTL), tags)
+}
+
+func (b *TieredBackend) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := b.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	b.local.Delete(ctx, keys...)
+	return b.bus.Publish(ctx, Invalidation{Origin: b.origin, Keys: keys})
+}
+
+func (b *TieredBackend) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := b.remote.InvalidateTags(ctx, tags...); err != nil {
+		return err
+	}
+	b.local.InvalidateTags(ctx, tags...)
+	return b.bus.Publish(ctx, Invalidation{Origin: b.origin, Tags: tags})
+}
+
+// Run applies invalidations from other replicas until ctx is done. The local
+// tier is flushed every time the subscription is established, since
+// broadcasts sent while disconnected are lost.
+func (b *TieredBackend) Run(ctx context.Context) error {
+	return b.bus.Subscribe(ctx, b.apply, b.local.Flush)
+}
+
+func (b *TieredBackend) apply(message Invalidation) {
+	if message.Origin == b.origin {
+		return
+	}
+	ctx := context.Background()
+	b.local.Delete(ctx, message.Keys...)
+	b.local.InvalidateTags(ctx, message.Tags...)
+}
+
+// Stats reports the counters of the local tier and, if it keeps them, of the
+// shared one.
+func (b *TieredBackend) Stats(ctx context.Context) (map[string]Stats, error) {
+	stats, _ := b.local.Stats(ctx)
+	if reporter, ok := b.remote.(StatsReporter); ok {
+		remote, err := reporter.Stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for tier, tierStats := range remote {
+			stats[tier] = tierStats
+		}
+	}
+	return stats, nil
+}
+
+var errCorruptEntry = errors.New("cache: corrupt entry")
+
+// encodeEntry prefixes the value with its tags: a uvarint length followed by
+// the tags joined with NUL.
+func encodeEntry(value []byte, tags []string) []byte {
+	joined := strings.Join(tags, "\x00")
+	data := binary.AppendUvarint(nil, uint64(len(joined)))
+	data = append(data, joined...)
+	return append(data, value...)
+}
+
+func decodeEntry(data []byte) ([]byte, []string, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return nil, nil, errCorruptEntry
+	}
+	var tags []string
+	if length > 0 {
+		tags = strings.Split(string(data[n:n+int(length)]), "\x00")
+	}
+	return data[n+int(length):], tags, nil
+}
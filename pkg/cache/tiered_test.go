@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBus delivers invalidations synchronously to every subscriber.
+type memoryBus struct {
+	mu       sync.Mutex
+	handlers []func(Invalidation)
+}
+
+func (b *memoryBus) Publish(ctx context.Context, message Invalidation) error {
+	b.mu.Lock()
+	handlers := append([]func(Invalidation){}, b.handlers...)
+	b.mu.Unlock()
+	for _, handle := range handlers {
+		handle(message)
+	}
+	return nil
+}
+
+func (b *memoryBus) Subscribe(ctx context.Context, handle func(Invalidation), resync func()) error {
+	resync()
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handle)
+	b.mu.Unlock()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *memoryBus) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.handlers)
+}
+
+func TestLRUBackendEvictsLeastRecentlyUsed(t *testing.T) {
+	backend := NewLRUBackend(2, 0)
+	ctx := context.Background()
+
+	backend.Set(ctx, "a", []byte("1"), time.Minute, nil)
+	backend.Set(ctx, "b", []byte("2"), time.Minute, nil)
+	backend.Get(ctx, "a")
+	backend.Set(ctx, "c", []byte("3"), time.Minute, nil)
+
+	_, err := backend.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = backend.Get(ctx, "a")
+	assert.NoError(t, err)
+
+	stats, _ := backend.Stats(ctx)
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Evictions: 1}, stats["local"])
+}
+
+func TestLRUBackendByteLimit(t *testing.T) {
+	backend := NewLRUBackend(0, 8)
+	ctx := context.Background()
+
+	backend.Set(ctx, "a", []byte("1234"), time.Minute, []string{"t"})
+	backend.Set(ctx, "b", []byte("1234"), time.Minute, []string{"t"})
+	assert.Equal(t, 1, backend.Len())
+
+	// Evicted entries are also dropped from their tags
+	require.NoError(t, backend.InvalidateTags(ctx, "t"))
+	assert.Equal(t, 0, backend.Len())
+	assert.Empty(t, backend.tags)
+}
+
+func TestTieredBackendInvalidatesReplicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shared := NewMemoryBackend()
+	bus := &memoryBus{}
+	first := NewTieredBackend(NewLRUBackend(100, 0), shared, bus, time.Minute)
+	second := NewTieredBackend(NewLRUBackend(100, 0), shared, bus, time.Minute)
+	go first.Run(ctx)
+	go second.Run(ctx)
+	require.Eventually(t, func() bool { return bus.subscribers() == 2 }, time.Second, time.Millisecond)
+
+	require.NoError(t, first.Set(ctx, "content:1", []byte(`{"id":1}`), time.Minute, []string{"content:1", "template:5"}))
+
+	// The second replica fills its local tier, with tags, from the shared one
+	value, err := second.Get(ctx, "content:1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`, string(value))
+	assert.Equal(t, 1, second.local.Len())
+
+	require.NoError(t, first.InvalidateTags(ctx, "template:5"))
+	assert.Equal(t, 0, first.local.Len())
+	assert.Equal(t, 0, second.local.Len())
+	_, err = second.Get(ctx, "content:1")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	stats, err := second.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Misses: 2}, stats["local"])
+}
+
+func TestTieredBackendServesLocally(t *testing.T) {
+	ctx := context.Background()
+	shared := NewMemoryBackend()
+	backend := NewTieredBackend(NewLRUBackend(100, 0), shared, &memoryBus{}, time.Minute)
+
+	require.NoError(t, backend.Set(ctx, "key", []byte("value"), time.Minute, nil))
+	shared.Delete(ctx, "key")
+
+	value, err := backend.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", string(value))
+}
+
+func TestEntryEncoding(t *testing.T) {
+	value, tags, err := decodeEntry(encodeEntry([]byte("data"), []string{"a", "b:1"}))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(value))
+	assert.Equal(t, []string{"a", "b:1"}, tags)
+
+	value, tags, err = decodeEntry(encodeEntry([]byte("data"), nil))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(value))
+	assert.Nil(t, tags)
+
+	_, _, err = decodeEntry([]byte{0x10, 'a'})
+	assert.ErrorIs(t, err, errCorruptEntry)
+}
@@ -0,0 +1,47 @@
+package cdn
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Purger removes cached responses from a CDN by surrogate key.
+type Purger interface {
+	Purge(ctx context.Context, keys ...string) error
+}
+
+// NopPurger is used when no CDN is configured.
+type NopPurger struct{}
+
+func (NopPurger) Purge(ctx context.Context, keys ...string) error {
+	return nil
+}
+
+// NewPurgerFromEnv returns an HTTPPurger for CDN_PURGE_URL, authenticated with
+// CDN_PURGE_TOKEN, or a NopPurger when the URL is not set.
+func NewPurgerFromEnv() Purger {
+	url := os.Getenv("CDN_PURGE_URL")
+	if url == "" {
+		return NopPurger{}
+	}
+	return NewHTTPPurger(url, os.Getenv("CDN_PURGE_TOKEN"))
+}
+
+// PurgeHook returns a cache invalidation hook that purges the invalidated
+// tags, which double as surrogate keys, from the CDN. Purges run in the
+// background so a slow CDN does not hold up writes; failures are logged.
+func PurgeHook(purger Purger, logger *zap.Logger) func(ctx context.Context, tags []string) {
+	return func(_ context.Context, tags []string) {
+		keys := append([]string{}, tags...)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := purger.Purge(ctx, keys...); err != nil {
+				logger.Error("Failed to purge CDN", zap.Strings("keys", keys), zap.Error(err))
+			}
+		}()
+	}
+}
@@ -0,0 +1,51 @@
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHTTPPurger(t *testing.T) {
+	var header, auth string
+	var body map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Surrogate-Key")
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	purger := NewHTTPPurger(server.URL, "secret")
+	require.NoError(t, purger.Purge(context.Background(), "content:1", "template:5"))
+	assert.Equal(t, "content:1 template:5", header)
+	assert.Equal(t, "Bearer secret", auth)
+	assert.Equal(t, []string{"content:1", "template:5"}, body["surrogate_keys"])
+}
+
+func TestHTTPPurgerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewHTTPPurger(server.URL, "").Purge(context.Background(), "content:1")
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestPurgeHook(t *testing.T) {
+	purger := &RecordingPurger{}
+	hook := PurgeHook(purger, zap.NewNop())
+
+	hook(context.Background(), []string{"content:1"})
+	require.Eventually(t, func() bool { return len(purger.Purges()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"content:1"}, purger.Keys())
+}
@@ -0,0 +1,61 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPPurger purges by surrogate key with a POST to a purge endpoint. The
+// keys are sent both as a space-separated Surrogate-Key header and as a JSON
+// body ({"surrogate_keys": [...]}), which covers most CDNs and purge proxies.
+type HTTPPurger struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewHTTPPurger(url, token string) *HTTPPurger {
+	return &HTTPPurger{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPPurger) Purge(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]string{"surrogate_keys": keys})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Surrogate-Key", strings.Join(keys, " "))
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("cdn purge: %d %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cdn
+
+import (
+	"context"
+	"sync"
+)
+
+// RecordingPurger is a fake Purger for tests. It records every purge and
+// returns Err, if set.
+type RecordingPurger struct {
+	mu     sync.Mutex
+	purges [][]string
+	Err    error
+}
+
+func (p *RecordingPurger) Purge(ctx context.Context, keys ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.purges = append(p.purges, append([]string{}, keys...))
+	return p.Err
+}
+
+// Purges returns the keys of each purge, in order.
+func (p *RecordingPurger) Purges() [][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]string{}, p.purges...)
+}
+
+// Keys returns every purged key.
+func (p *RecordingPurger) Keys() []string {
+	var keys []string
+	for _, purge := range p.Purges() {
+		keys = append(keys, purge...)
+	}
+	return keys
+}
@@ -0,0 +1,664 @@
+// pkg/cms/bundle.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sort"
+    "time"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/models"
+    "gorm.io/gorm"
+)
+
+// BundleVersion es la versión del formato del bundle. Import rechaza las
+// demás.
+const BundleVersion = 1
+
+// Secciones de un bundle.
+const (
+    BundleContent    = "content"
+    BundleTemplates  = "templates"
+    BundleMedia      = "media"
+    BundleTaxonomies = "taxonomies"
+    BundleRedirects  = "redirects"
+)
+
+var BundleKinds = []string{BundleContent, BundleTemplates, BundleMedia, BundleTaxonomies, BundleRedirects}
+
+// Política ante un elemento que ya existe en destino con otros datos.
+const (
+    ConflictSkip      = "skip"
+    ConflictOverwrite = "overwrite"
+    ConflictFail      = "fail"
+)
+
+// Acciones del informe de importación.
+const (
+    ImportCreate    = "create"
+    ImportUpdate    = "update"
+    ImportSkip      = "skip"
+    ImportUnchanged = "unchanged"
+)
+
+var (
+    ErrInvalidBundle   = errors.New("invalid bundle")
+    ErrBundleVersion   = errors.New("unsupported bundle version")
+    ErrImportConflict  = errors.New("import conflict")
+    ErrInvalidConflict = errors.New("invalid conflict policy")
+
+    // errDryRun deshace la transacción de una importación de prueba
+    errDryRun = errors.New("dry run")
+)
+
+// Bundle es el volcado versionado del CMS que se mueve entre entornos. Los
+// ids son los del origen: Import los usa solo para reasignar las
+// referencias (plantilla, og_image_id y grupo de traducciones).
+type Bundle struct {
+    Version    int            `json:"version"`
+    ExportedAt time.Time      `json:"exported_at"`
+    Templates  []Template     `json:"templates,omitempty"`
+    Media      []Media        `json:"media,omitempty"` // solo metadatos; los ficheros siguen en su URL
+    Content    []Content      `json:"content,omitempty"`
+    Taxonomies []TaxonomyTerm `json:"taxonomies,omitempty"`
+    Redirects  []Redirect     `json:"redirects,omitempty"`
+    Authors    map[uint]string `json:"authors,omitempty"` // username por author_id: los ids cambian entre entornos
+}
+
+// TaxonomyTerm es un término de taxonomía. Los términos son los tags del
+// contenido y viajan con él, así que la lista es informativa y Import la
+// ignora.
+type TaxonomyTerm struct {
+    Term  string `json:"term"`
+    Count int    `json:"count"`
+}
+
+// BundleFilter selecciona qué se exporta. Sin Kinds se exporta todo; Locale
+// limita el contenido (y sus términos) a un idioma.
+type BundleFilter struct {
+    Kinds  []string
+    Locale string
+}
+
+type ImportOptions struct {
+    DryRun   bool   `json:"dry_run"`
+    Conflict string `json:"conflict"` // skip (por defecto), overwrite o fail
+    Comment  string `json:"-"`        // de las versiones creadas; "import" por defecto
+}
+
+// ImportReport resume una importación. En dry-run es lo que se haría, con
+// los mismos ids de destino que tendría.
+type ImportReport struct {
+    DryRun    bool         `json:"dry_run"`
+    Created   int          `json:"created"`
+    Updated   int          `json:"updated"`
+    Skipped   int          `json:"skipped"`
+    Unchanged int          `json:"unchanged"`
+    Items     []ImportItem `json:"items"`
+}
+
+// ImportItem es el resultado de un elemento del bundle. Changes es el diff
+// frente a lo que hay en destino, también cuando se omite.
+type ImportItem struct {
+    Kind     string               `json:"kind"`
+    Key      string               `json:"key"` // locale/slug, nombre de plantilla, URL o ruta de origen
+    Action   string               `json:"action"`
+    SourceID uint                 `json:"source_id"`
+    TargetID uint                 `json:"target_id,omitempty"`
+    Changes  map[string]FieldDiff `json:"changes,omitempty"`
+    Warnings []string             `json:"warnings,omitempty"`
+}
+
+// BundleService exporta e importa bundles. Import escribe directamente, sin
+// pasar por el workflow: el estado y la fecha de publicación son los del
+// origen.
+type BundleService struct {
+    db       *gorm.DB
+    locales  *LocaleConfig
+    versions *VersionService
+    outbox   *events.Outbox
+}
+
+func NewBundleService(db *gorm.DB, locales *LocaleConfig, versions *VersionService, outbox *events.Outbox) *BundleService {
+    return &BundleService{
+        db:       db,
+        locales:  locales,
+        versions: versions,
+        outbox:   outbox,
+    }
+}
+
+func (s *BundleService) Export(ctx context.Context, filter BundleFilter) (*Bundle, error) {
+    kinds := map[string]bool{}
+    for _, kind := range filter.Kinds {
+        if !contains(BundleKinds, kind) {
+            return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidBundle, kind)
+        }
+        kinds[kind] = true
+    }
+    all := len(kinds) == 0
+    db := s.db.WithContext(ctx)
+
+    bundle := &Bundle{Version: BundleVersion, ExportedAt: time.Now().UTC()}
+    if all || kinds[BundleTemplates] {
+        if err := db.Order("id").Find(&bundle.Templates).Error; err != nil {
+            return nil, err
+        }
+    }
+    if all || kinds[BundleMedia] {
+        if err := db.Order("id").Find(&bundle.Media).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    if all || kinds[BundleRedirects] {
+        if err := db.Order("id").Find(&bundle.Redirects).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    if all || kinds[BundleContent] || kinds[BundleTaxonomies] {
+        var contents []Content
+        scope := db.Order("id")
+        if filter.Locale != "" {
+            scope = scope.Where("locale = ?", s.locales.Normalize(filter.Locale))
+        }
+        if err := scope.Find(&contents).Error; err != nil {
+            return nil, err
+        }
+        if all || kinds[BundleContent] {
+            bundle.Content = contents
+            authors, err := bundleAuthors(db, contents)
+            if err != nil {
+                return nil, err
+            }
+            bundle.Authors = authors
+        }
+        if all || kinds[BundleTaxonomies] {
+            bundle.Taxonomies = taxonomyTerms(contents)
+        }
+    }
+    return bundle, nil
+}
+
+// bundleAuthors devuelve el username de cada autor del contenido.
+func bundleAuthors(db *gorm.DB, contents []Content) (map[uint]string, error) {
+    var ids []uint
+    for _, content := range contents {
+        if content.AuthorID != 0 {
+            ids = append(ids, content.AuthorID)
+        }
+    }
+    if len(ids) == 0 {
+        return nil, nil
+    }
+
+    var users []models.User
+    if err := db.Select("id", "username").Where("id IN ?", ids).Find(&users).Error; err != nil {
+        return nil, err
+    }
+    authors := make(map[uint]string, len(users))
+    for _, user := range users {
+        authors[user.ID] = user.Username
+    }
+    return authors, nil
+}
+
+// taxonomyTerms cuenta los tags del contenido, ordenados por término.
+func taxonomyTerms(contents []Content) []TaxonomyTerm {
+    counts := map[string]int{}
+    for _, content := range contents {
+        for _, tag := range content.Tags {
+            counts[tag]++
+        }
+    }
+    terms := make([]TaxonomyTerm, 0, len(counts))
+    for term, count := range counts {
+        terms = append(terms, TaxonomyTerm{Term: term, Count: count})
+    }
+    sort.Slice(terms, func(i, j int) bool { return terms[i].Term < terms[j].Term })
+    return terms
+}
+
+// Import aplica un bundle en una sola transacción: plantillas por nombre,
+// medios por URL, contenido por locale y slug y redirecciones por ruta de
+// origen. Lo que no existe se crea y lo
+// que existe con otros datos se resuelve según la política de conflictos.
+// Con DryRun la transacción se deshace y solo queda el informe.
+//
+// El estado y published_at del bundle se escriben tal cual, sin pasar por el
+// workflow: un import replica otro entorno, no es una edición. Por eso solo lo
+// usan los administradores (las rutas de bundle y promoción) y el comando
+// bundle, que ya tiene acceso a la base de datos.
+func (s *BundleService) Import(ctx context.Context, bundle *Bundle, opts ImportOptions) (*ImportReport, error) {
+    if bundle.Version != BundleVersion {
+        return nil, fmt.Errorf("%w: %d", ErrBundleVersion, bundle.Version)
+    }
+    if opts.Conflict == "" {
+        opts.Conflict = ConflictSkip
+    }
+    if opts.Comment == "" {
+        opts.Comment = "import"
+    }
+    if opts.Conflict != ConflictSkip && opts.Conflict != ConflictOverwrite && opts.Conflict != ConflictFail {
+        return nil, fmt.Errorf("%w: %q", ErrInvalidConflict, opts.Conflict)
+    }
+
+    var run *bundleImport
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        run = &bundleImport{
+            ctx:       ctx,
+            tx:        tx,
+            service:   s,
+            conflict:  opts.Conflict,
+            comment:   opts.Comment,
+            report:    &ImportReport{DryRun: opts.DryRun, Items: []ImportItem{}},
+            templates: map[uint]uint{},
+            media:     map[uint]uint{},
+            contents:  map[uint]uint{},
+            written:   map[uint]bool{},
+            authors:   bundle.Authors,
+            users:     map[string]uint{},
+        }
+        if err := run.apply(bundle); err != nil {
+            return err
+        }
+        if opts.DryRun {
+            return errDryRun
+        }
+        return nil
+    })
+    if err != nil && !errors.Is(err, errDryRun) {
+        return nil, err
+    }
+
+    if !opts.DryRun {
+        s.outbox.Relay(ctx, run.events...)
+    }
+    return run.report, nil
+}
+
+// bundleImport es el estado de una importación: los ids de origen ya
+// resueltos a ids de destino y los eventos a publicar al confirmar.
+type bundleImport struct {
+    ctx       context.Context
+    tx        *gorm.DB
+    service   *BundleService
+    conflict  string
+    comment   string
+    report    *ImportReport
+    templates map[uint]uint
+    media     map[uint]uint
+    contents  map[uint]uint
+    written   map[uint]bool // contenido creado o actualizado, por id de destino
+    authors   map[uint]string // autor de origen -> username
+    users     map[string]uint // username -> usuario de destino, 0 si no existe
+    events    []*events.Event
+}
+
+func (r *bundleImport) apply(bundle *Bundle) error {
+    // Primero lo referenciado, para poder reasignar los ids del contenido
+    for n := range bundle.Templates {
+        if err := r.template(&bundle.Templates[n]); err != nil {
+            return err
+        }
+    }
+    for n := range bundle.Media {
+        if err := r.mediaItem(&bundle.Media[n]); err != nil {
+            return err
+        }
+    }
+    for n := range bundle.Content {
+        if err := r.content(&bundle.Content[n]); err != nil {
+            return err
+        }
+    }
+    if err := r.translationGroups(bundle.Content); err != nil {
+        return err
+    }
+    for n := range bundle.Redirects {
+        if err := r.redirect(&bundle.Redirects[n]); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *bundleImport) template(src *Template) error {
+    item := ImportItem{Kind: BundleTemplates, Key: src.Name, SourceID: src.ID}
+    if src.Name == "" {
+        return fmt.Errorf("%w: template %d has no name", ErrInvalidBundle, src.ID)
+    }
+
+    template := *src
+    template.Model = gorm.Model{}
+
+    // Incluye los borrados: el índice único también
+    var current Template
+    err := r.tx.Unscoped().Where("name = ?", src.Name).First(&current).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        template.Version = 1
+        if err := r.tx.Create(&template).Error; err != nil {
+            return err
+        }
+        r.templates[src.ID] = template.ID
+        item.TargetID = template.ID
+        return r.created(item, EntityTemplate, EventTemplateCreated, template.ID, &template)
+    }
+    if err != nil {
+        return err
+    }
+
+    r.templates[src.ID] = current.ID
+    item.TargetID = current.ID
+    template.Model = current.Model
+    template.DeletedAt = gorm.DeletedAt{}
+    template.Version = current.Version
+    return r.resolve(item, &current, &template, current.DeletedAt.Valid, func() error {
+        template.Version = current.Version + 1
+        if err := r.tx.Model(&Template{}).Unscoped().Where("id = ?", current.ID).
+            Select("*").Omit("id", "created_at").
+            Updates(&template).Error; err != nil {
+            return err
+        }
+        return r.record(EntityTemplate, EventTemplateUpdated, current.ID, &template)
+    })
+}
+
+func (r *bundleImport) mediaItem(src *Media) error {
+    item := ImportItem{Kind: BundleMedia, Key: src.URL, SourceID: src.ID}
+    if src.URL == "" {
+        return fmt.Errorf("%w: media %d has no url", ErrInvalidBundle, src.ID)
+    }
+
+    media := *src
+    media.Model = gorm.Model{}
+
+    var current Media
+    err := r.tx.Where("url = ?", src.URL).First(&current).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        if err := r.tx.Create(&media).Error; err != nil {
+            return err
+        }
+        r.media[src.ID] = media.ID
+        item.TargetID = media.ID
+        return r.created(item, "", EventMediaCreated, media.ID, &media)
+    }
+    if err != nil {
+        return err
+    }
+
+    r.media[src.ID] = current.ID
+    item.TargetID = current.ID
+    media.Model = current.Model
+    return r.resolve(item, &current, &media, false, func() error {
+        if err := r.tx.Model(&Media{}).Where("id = ?", current.ID).
+            Select("*").Omit("id", "created_at").
+            Updates(&media).Error; err != nil {
+            return err
+        }
+        return r.record("", EventMediaUpdated, current.ID, &media)
+    })
+}
+
+func (r *bundleImport) content(src *Content) error {
+    locales := r.service.locales
+    locale := src.Locale
+    if locale == "" {
+        locale = locales.Default
+    }
+    locale = locales.Normalize(locale)
+    item := ImportItem{Kind: BundleContent, Key: locale + "/" + src.Slug, SourceID: src.ID}
+    if src.Slug == "" {
+        return fmt.Errorf("%w: content %d has no slug", ErrInvalidBundle, src.ID)
+    }
+    if !locales.IsSupported(locale) {
+        return fmt.Errorf("%w: %s", ErrUnsupportedLocale, item.Key)
+    }
+    if err := checkSEOFields(src); err != nil {
+        return fmt.Errorf("%w (%s)", err, item.Key)
+    }
+
+    content := *src
+    content.Model = gorm.Model{}
+    content.Locale = locale
+    content.ViewCount = 0
+    content.TranslationGroupID = 0 // se resuelve en translationGroups
+
+    // Referencias: a lo importado en este bundle o, si no viene en él, nada
+    if src.TemplateID != 0 {
+        if id, ok := r.templates[src.TemplateID]; ok {
+            content.TemplateID = id
+        } else {
+            content.TemplateID = 0
+            item.Warnings = append(item.Warnings, fmt.Sprintf("template %d is not in the bundle", src.TemplateID))
+        }
+    }
+    if src.OGImageID != nil {
+        if id, ok := r.media[*src.OGImageID]; ok {
+            content.OGImageID = &id
+        } else {
+            content.OGImageID = nil
+            item.Warnings = append(item.Warnings, fmt.Sprintf("og image %d is not in the bundle", *src.OGImageID))
+        }
+    }
+    if src.AuthorID != 0 {
+        username, ok := r.authors[src.AuthorID]
+        id, err := r.user(username)
+        if err != nil {
+            return err
+        }
+        content.AuthorID = id
+        switch {
+        case !ok:
+            item.Warnings = append(item.Warnings, fmt.Sprintf("author %d is not in the bundle", src.AuthorID))
+        case id == 0:
+            item.Warnings = append(item.Warnings, fmt.Sprintf("author %q does not exist", username))
+        }
+    }
+
+    var current Content
+    err := r.tx.Unscoped().Where("locale = ? AND slug = ?", locale, src.Slug).First(&current).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        content.Revision = 1
+        if err := r.tx.Create(&content).Error; err != nil {
+            return err
+        }
+        r.contents[src.ID] = content.ID
+        r.written[content.ID] = true
+        item.TargetID = content.ID
+        return r.created(item, EntityContent, EventContentCreated, content.ID, &content)
+    }
+    if err != nil {
+        return err
+    }
+
+    r.contents[src.ID] = current.ID
+    item.TargetID = current.ID
+    content.Model = current.Model
+    content.DeletedAt = gorm.DeletedAt{}
+    content.Revision = current.Revision
+    content.ViewCount = current.ViewCount
+    content.TranslationGroupID = current.TranslationGroupID
+    return r.resolve(item, &current, &content, current.DeletedAt.Valid, func() error {
+        content.Revision = current.Revision + 1
+        if err := r.tx.Model(&Content{}).Unscoped().Where("id = ?", current.ID).
+            Select("*").Omit("id", "created_at", "view_count", "translation_group_id").
+            Updates(&content).Error; err != nil {
+            return err
+        }
+        r.written[current.ID] = true
+        return r.record(EntityContent, EventContentUpdated, current.ID, &content)
+    })
+}
+
+// user es el id en destino del usuario con ese username, o 0 si no existe.
+func (r *bundleImport) user(username string) (uint, error) {
+    if username == "" {
+        return 0, nil
+    }
+    if id, ok := r.users[username]; ok {
+        return id, nil
+    }
+    var user models.User
+    err := r.tx.Select("id").Where("username = ?", username).First(&user).Error
+    if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+        return 0, err
+    }
+    r.users[username] = user.ID
+    return user.ID, nil
+}
+
+// redirect importa una redirección. Las redirecciones no tienen versiones ni
+// eventos: las respuestas ya cacheadas caducan con el TTL de la caché.
+func (r *bundleImport) redirect(src *Redirect) error {
+    item := ImportItem{Kind: BundleRedirects, Key: src.FromPath, SourceID: src.ID}
+    if src.FromPath == "" || src.ToPath == "" {
+        return fmt.Errorf("%w: redirect %d has no from or to path", ErrInvalidBundle, src.ID)
+    }
+
+    redirect := *src
+    redirect.ID = 0
+    redirect.CreatedAt = time.Time{}
+    if redirect.StatusCode == 0 {
+        redirect.StatusCode = 301
+    }
+
+    var current Redirect
+    err := r.tx.Where("from_path = ?", src.FromPath).First(&current).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        if err := r.tx.Create(&redirect).Error; err != nil {
+            return err
+        }
+        item.TargetID = redirect.ID
+        item.Action = ImportCreate
+        r.report.Created++
+        r.report.Items = append(r.report.Items, item)
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    item.TargetID = current.ID
+    redirect.ID = current.ID
+    redirect.CreatedAt = current.CreatedAt
+    redirect.Source = current.Source // el trabajo que la creó es del origen
+    return r.resolve(item, &current, &redirect, false, func() error {
+        return r.tx.Model(&Redirect{}).Where("id = ?", current.ID).
+            Select("to_path", "status_code").
+            Updates(&redirect).Error
+    })
+}
+
+// translationGroups reasigna el grupo de traducciones del contenido escrito:
+// el id de destino del contenido de origen que daba nombre al grupo o, si no
+// venía en el bundle, el propio id.
+func (r *bundleImport) translationGroups(contents []Content) error {
+    for n := range contents {
+        src := &contents[n]
+        id, ok := r.contents[src.ID]
+        if !ok || !r.written[id] {
+            continue
+        }
+        group, ok := r.contents[src.TranslationGroupID]
+        if !ok {
+            var current Content
+            if err := r.tx.Unscoped().Select("id", "translation_group_id").First(&current, id).Error; err != nil {
+                return err
+            }
+            if current.TranslationGroupID != 0 {
+                continue
+            }
+            group = id
+        }
+        if err := r.tx.Model(&Content{}).Unscoped().Where("id = ?", id).
+            UpdateColumn("translation_group_id", group).Error; err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// resolve aplica la política de conflictos a un elemento que ya existe.
+// update solo se llama si hay cambios y la política es overwrite. Un elemento
+// borrado en destino cuenta como cambio: sobrescribirlo lo restaura.
+func (r *bundleImport) resolve(item ImportItem, current, incoming interface{}, deleted bool, update func() error) error {
+    changes, err := bundleDiff(current, incoming)
+    if err != nil {
+        return err
+    }
+    if deleted {
+        changes["DeletedAt"] = FieldDiff{Old: "deleted", New: nil}
+    }
+    item.Changes = changes
+
+    switch {
+    case len(changes) == 0:
+        item.Action = ImportUnchanged
+        r.report.Unchanged++
+    case r.conflict == ConflictFail:
+        return fmt.Errorf("%w: %s %s", ErrImportConflict, item.Kind, item.Key)
+    case r.conflict == ConflictSkip:
+        item.Action = ImportSkip
+        r.report.Skipped++
+    default:
+        if err := update(); err != nil {
+            return err
+        }
+        item.Action = ImportUpdate
+        r.report.Updated++
+    }
+    r.report.Items = append(r.report.Items, item)
+    return nil
+}
+
+// created registra la versión (si la entidad tiene) y el evento de un
+// elemento nuevo.
+func (r *bundleImport) created(item ImportItem, entityType, eventType string, id uint, data interface{}) error {
+    if err := r.record(entityType, eventType, id, data); err != nil {
+        return err
+    }
+    item.Action = ImportCreate
+    r.report.Created++
+    r.report.Items = append(r.report.Items, item)
+    return nil
+}
+
+func (r *bundleImport) record(entityType, eventType string, id uint, data interface{}) error {
+    if entityType != "" {
+        if err := r.service.versions.Record(r.ctx, r.tx, entityType, id, data, r.comment); err != nil {
+            return err
+        }
+    }
+    event, err := recordEvent(r.ctx, r.tx, r.service.outbox, eventType, id, data)
+    if err != nil {
+        return err
+    }
+    r.events = append(r.events, event)
+    return nil
+}
+
+// bundleDiff compara dos entidades campo a campo, como Compare con las
+// versiones; la revisión y el grupo de traducciones se ignoran porque no se
+// copian del origen.
+func bundleDiff(current, incoming interface{}) (map[string]FieldDiff, error) {
+    var data1, data2 map[string]interface{}
+    for _, pair := range []struct {
+        value  interface{}
+        target *map[string]interface{}
+    }{{current, &data1}, {incoming, &data2}} {
+        raw, err := json.Marshal(pair.value)
+        if err != nil {
+            return nil, err
+        }
+        if err := json.Unmarshal(raw, pair.target); err != nil {
+            return nil, err
+        }
+        delete(*pair.target, "revision")
+        delete(*pair.target, "translation_group_id")
+    }
+    return diffFields(data1, data2), nil
+}
@@ -0,0 +1,203 @@
+// pkg/cms/bundle_csv.go
+package cms
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Columnas del CSV de cada tipo plano. Las plantillas no son planas (campos y
+// HTML) y solo viajan en el bundle JSON.
+var (
+    contentCSVHeader  = []string{"id", "translation_group_id", "locale", "slug", "title", "status", "template_id", "published_at", "tags", "meta_title", "meta_description", "canonical_url", "og_image_id", "noindex", "meta_data", "content"}
+    mediaCSVHeader    = []string{"id", "name", "type", "mime_type", "size", "url", "path"}
+    taxonomyCSVHeader = []string{"term", "count"}
+)
+
+// csvTagSeparator separa los tags en una celda.
+const csvTagSeparator = "|"
+
+// CSVKinds son los tipos que se exportan en CSV; los términos de taxonomía
+// no se importan.
+var CSVKinds = []string{BundleContent, BundleMedia, BundleTaxonomies}
+
+// WriteCSV escribe una sección del bundle en CSV.
+func WriteCSV(w io.Writer, bundle *Bundle, kind string) error {
+    writer := csv.NewWriter(w)
+    switch kind {
+    case BundleContent:
+        writer.Write(contentCSVHeader)
+        for _, content := range bundle.Content {
+            ogImage := ""
+            if content.OGImageID != nil {
+                ogImage = strconv.FormatUint(uint64(*content.OGImageID), 10)
+            }
+            publishedAt := ""
+            if !content.PublishedAt.IsZero() {
+                publishedAt = content.PublishedAt.UTC().Format(time.RFC3339)
+            }
+            writer.Write([]string{
+                strconv.FormatUint(uint64(content.ID), 10),
+                strconv.FormatUint(uint64(content.TranslationGroupID), 10),
+                content.Locale,
+                content.Slug,
+                content.Title,
+                content.Status,
+                strconv.FormatUint(uint64(content.TemplateID), 10),
+                publishedAt,
+                strings.Join(content.Tags, csvTagSeparator),
+                content.MetaTitle,
+                content.MetaDescription,
+                content.CanonicalURL,
+                ogImage,
+                strconv.FormatBool(content.NoIndex),
+                string(content.MetaData),
+                content.Content,
+            })
+        }
+    case BundleMedia:
+        writer.Write(mediaCSVHeader)
+        for _, media := range bundle.Media {
+            writer.Write([]string{
+                strconv.FormatUint(uint64(media.ID), 10),
+                media.Name,
+                media.Type,
+                media.MimeType,
+                strconv.FormatInt(media.Size, 10),
+                media.URL,
+                media.Path,
+            })
+        }
+    case BundleTaxonomies:
+        writer.Write(taxonomyCSVHeader)
+        for _, term := range bundle.Taxonomies {
+            writer.Write([]string{term.Term, strconv.Itoa(term.Count)})
+        }
+    default:
+        return fmt.Errorf("%w: %s has no csv format", ErrInvalidBundle, kind)
+    }
+    writer.Flush()
+    return writer.Error()
+}
+
+// ReadCSV lee un CSV de contenido o de medios como un bundle con solo esa
+// sección, que se importa igual que uno JSON. Las columnas se buscan por
+// nombre, así que pueden venir en cualquier orden y faltar las opcionales.
+func ReadCSV(r io.Reader, kind string) (*Bundle, error) {
+    if kind != BundleContent && kind != BundleMedia {
+        return nil, fmt.Errorf("%w: %s cannot be imported from csv", ErrInvalidBundle, kind)
+    }
+
+    reader := csv.NewReader(r)
+    header, err := reader.Read()
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+    }
+    columns := map[string]int{}
+    for n, name := range header {
+        columns[strings.TrimSpace(name)] = n
+    }
+
+    bundle := &Bundle{Version: BundleVersion}
+    for line := 2; ; line++ {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+        }
+        row := csvRow{columns: columns, record: record}
+
+        if kind == BundleMedia {
+            media := Media{
+                Name:     row.get("name"),
+                Type:     row.get("type"),
+                MimeType: row.get("mime_type"),
+                URL:      row.get("url"),
+                Path:     row.get("path"),
+            }
+            media.ID = row.uint("id")
+            media.Size = int64(row.uint("size"))
+            if row.err != nil {
+                return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidBundle, line, row.err)
+            }
+            bundle.Media = append(bundle.Media, media)
+            continue
+        }
+
+        content := Content{
+            TranslationGroupID: row.uint("translation_group_id"),
+            Locale:             row.get("locale"),
+            Slug:               row.get("slug"),
+            Title:              row.get("title"),
+            Status:             row.get("status"),
+            TemplateID:         row.uint("template_id"),
+            Tags:               StringArray{},
+            MetaTitle:          row.get("meta_title"),
+            MetaDescription:    row.get("meta_description"),
+            CanonicalURL:       row.get("canonical_url"),
+            NoIndex:            row.get("noindex") == "true",
+            Content:            row.get("content"),
+        }
+        content.ID = row.uint("id")
+        if content.Status == "" {
+            content.Status = StatusDraft
+        }
+        if value := row.get("published_at"); value != "" {
+            content.PublishedAt, err = time.Parse(time.RFC3339, value)
+            if err != nil {
+                return nil, fmt.Errorf("%w: line %d: published_at: %v", ErrInvalidBundle, line, err)
+            }
+        }
+        for _, tag := range strings.Split(row.get("tags"), csvTagSeparator) {
+            if tag = strings.TrimSpace(tag); tag != "" {
+                content.Tags = append(content.Tags, tag)
+            }
+        }
+        if value := row.get("meta_data"); value != "" {
+            content.MetaData = JSON(value)
+        }
+        if row.get("og_image_id") != "" {
+            id := row.uint("og_image_id")
+            content.OGImageID = &id
+        }
+        if row.err != nil {
+            return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidBundle, line, row.err)
+        }
+        bundle.Content = append(bundle.Content, content)
+    }
+    return bundle, nil
+}
+
+// csvRow lee las celdas de una fila por nombre de columna y guarda el primer
+// error de conversión.
+type csvRow struct {
+    columns map[string]int
+    record  []string
+    err     error
+}
+
+func (r *csvRow) get(name string) string {
+    n, ok := r.columns[name]
+    if !ok || n >= len(r.record) {
+        return ""
+    }
+    return r.record[n]
+}
+
+func (r *csvRow) uint(name string) uint {
+    value := strings.TrimSpace(r.get(name))
+    if value == "" {
+        return 0
+    }
+    parsed, err := strconv.ParseUint(value, 10, 64)
+    if err != nil && r.err == nil {
+        r.err = fmt.Errorf("%s: %v", name, err)
+    }
+    return uint(parsed)
+}
@@ -0,0 +1,115 @@
+// pkg/cms/bundle_test.go
+package cms
+
+import (
+    "bytes"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestTaxonomyTerms(t *testing.T) {
+    terms := taxonomyTerms([]Content{
+        {Tags: StringArray{"go", "cms"}},
+        {Tags: StringArray{"go"}},
+        {},
+    })
+    assert.Equal(t, []TaxonomyTerm{{Term: "cms", Count: 1}, {Term: "go", Count: 2}}, terms)
+    assert.Empty(t, taxonomyTerms(nil))
+}
+
+func TestBundleDiff(t *testing.T) {
+    current := &Content{Title: "Hello", Slug: "hello", Revision: 4, TranslationGroupID: 1, ViewCount: 10}
+    incoming := &Content{Title: "Hello world", Slug: "hello", Revision: 1, TranslationGroupID: 7}
+
+    changes, err := bundleDiff(current, incoming)
+    require.NoError(t, err)
+    // La revisión, el grupo y las lecturas no se copian del origen
+    assert.Len(t, changes, 1)
+    assert.Equal(t, "Hello", changes["title"].Old)
+    assert.Equal(t, "Hello world", changes["title"].New)
+    assert.NotEmpty(t, changes["title"].Ops)
+
+    incoming.Title = "Hello"
+    changes, err = bundleDiff(current, incoming)
+    require.NoError(t, err)
+    assert.Empty(t, changes)
+}
+
+func TestBundleDiffRedirect(t *testing.T) {
+    current := &Redirect{ID: 3, FromPath: "/old", ToPath: "/es/new", StatusCode: 301}
+    incoming := &Redirect{ID: 3, FromPath: "/old", ToPath: "/es/newer", StatusCode: 301}
+
+    changes, err := bundleDiff(current, incoming)
+    require.NoError(t, err)
+    assert.Len(t, changes, 1)
+    assert.Equal(t, "/es/new", changes["to"].Old)
+    assert.Equal(t, "/es/newer", changes["to"].New)
+}
+
+func TestContentCSVRoundTrip(t *testing.T) {
+    imageID := uint(3)
+    content := Content{
+        Title:              "Hello, \"world\"",
+        Slug:               "hello",
+        Locale:             "en",
+        TranslationGroupID: 5,
+        Status:             StatusPublished,
+        TemplateID:         2,
+        PublishedAt:        time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+        Tags:               StringArray{"go", "cms"},
+        MetaTitle:          "Meta",
+        OGImageID:          &imageID,
+        NoIndex:            true,
+        Content:            "<p>Line one\nline two</p>",
+    }
+    content.ID = 5
+
+    var buf bytes.Buffer
+    require.NoError(t, WriteCSV(&buf, &Bundle{Content: []Content{content}}, BundleContent))
+    assert.True(t, strings.HasPrefix(buf.String(), strings.Join(contentCSVHeader, ",")+"\n"))
+
+    bundle, err := ReadCSV(&buf, BundleContent)
+    require.NoError(t, err)
+    assert.Equal(t, BundleVersion, bundle.Version)
+    require.Len(t, bundle.Content, 1)
+    read := bundle.Content[0]
+    assert.Equal(t, content.ID, read.ID)
+    assert.Equal(t, content.Title, read.Title)
+    assert.Equal(t, content.Content, read.Content)
+    assert.Equal(t, content.Tags, read.Tags)
+    assert.Equal(t, content.PublishedAt, read.PublishedAt)
+    assert.Equal(t, imageID, *read.OGImageID)
+    assert.True(t, read.NoIndex)
+}
+
+func TestReadCSVColumns(t *testing.T) {
+    // Columnas en cualquier orden y opcionales ausentes
+    bundle, err := ReadCSV(strings.NewReader("slug,title,locale\nhello,Hello,es\n"), BundleContent)
+    require.NoError(t, err)
+    require.Len(t, bundle.Content, 1)
+    assert.Equal(t, "hello", bundle.Content[0].Slug)
+    assert.Equal(t, "es", bundle.Content[0].Locale)
+    assert.Equal(t, StatusDraft, bundle.Content[0].Status)
+    assert.Nil(t, bundle.Content[0].OGImageID)
+
+    bundle, err = ReadCSV(strings.NewReader("url,size\nhttps://cdn.test/a.png,1024\n"), BundleMedia)
+    require.NoError(t, err)
+    assert.Equal(t, int64(1024), bundle.Media[0].Size)
+
+    for _, tc := range []struct {
+        body string
+        kind string
+    }{
+        {"slug,template_id\nhello,abc\n", BundleContent},
+        {"slug,published_at\nhello,yesterday\n", BundleContent},
+        {"", BundleContent},
+        {"name\nx\n", BundleTemplates},
+    } {
+        _, err := ReadCSV(strings.NewReader(tc.body), tc.kind)
+        assert.True(t, errors.Is(err, ErrInvalidBundle), tc.body)
+    }
+}
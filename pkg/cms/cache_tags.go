@@ -0,0 +1,39 @@
+// pkg/cms/cache_tags.go
+package cms
+
+import (
+    "fmt"
+)
+
+// Etiquetas de caché. Cada entrada se etiqueta con las entidades de las que
+// depende, y una escritura invalida solo las entradas de su etiqueta.
+
+// FeedsTag etiqueta todos los feeds: dependen del conjunto de contenido
+// publicado, así que cualquier cambio de contenido los invalida.
+const FeedsTag = "feeds"
+
+// RedirectsTag etiqueta las respuestas de redirecciones.
+const RedirectsTag = "redirects"
+
+func ContentTag(id uint) string {
+    return entityTag(EntityContent, id)
+}
+
+func TemplateTag(id uint) string {
+    return entityTag(EntityTemplate, id)
+}
+
+func MediaTag(id uint) string {
+    return entityTag(EntityMedia, id)
+}
+
+// TranslationTag etiqueta lo que se resuelve dentro de un grupo de
+// traducciones: una respuesta servida en el idioma de fallback cambia cuando
+// se crea o se publica la traducción que faltaba, que tiene otro id.
+func TranslationTag(group uint) string {
+    return fmt.Sprintf("translation:%d", group)
+}
+
+func entityTag(entityType string, id uint) string {
+    return fmt.Sprintf("%s:%d", entityType, id)
+}
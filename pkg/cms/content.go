@@ -5,86 +5,356 @@ import (
     "context"
     "errors"
     "time"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/query"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
+var (
+    ErrUnsupportedLocale = errors.New("unsupported locale")
+    ErrTranslationExists = errors.New("translation already exists for locale")
+)
+
+type Content struct {
+    gorm.Model
+    Title              string      `json:"title" gorm:"not null"`
+    Slug               string      `json:"slug" gorm:"not null;uniqueIndex:idx_content_locale_slug"`
+    Locale             string      `json:"locale" gorm:"size:16;not null;default:'en';uniqueIndex:idx_content_locale_slug"`
+    TranslationGroupID uint        `json:"translation_group_id" gorm:"index"`
+    Content            string      `json:"content"`
+    Status             string      `json:"status" gorm:"default:'draft'"` // draft, in_review, approved, published, archived
+    TemplateID         uint        `json:"template_id"`
+    AuthorID           uint        `json:"author_id"`
+    PublishedAt        time.Time   `json:"published_at"`
+    EmbargoUntil       *time.Time  `json:"embargo_until"` // no visible en la API de entrega antes de esta fecha
+    ExpiresAt          *time.Time  `json:"expires_at"`    // ni después de esta
+    Tags               StringArray `json:"tags" gorm:"type:text[];default:'{}'"`
+    MetaData           JSON        `json:"meta_data"`
+    Revision           int         `json:"revision" gorm:"not null;default:1"` // se incrementa en cada escritura, base del ETag
+    ViewCount          int64       `json:"view_count" gorm:"not null;default:0"` // lecturas desde la API de entrega
+
+    // SEO. Vacíos, se usan el título y un extracto del cuerpo
+    MetaTitle       string `json:"meta_title" gorm:"size:255"`
+    MetaDescription string `json:"meta_description" gorm:"size:500"`
+    CanonicalURL    string `json:"canonical_url"` // si apunta a otra URL, la página no entra en el sitemap
+    OGImageID       *uint  `json:"og_image_id"`   // Media de tipo imagen
+    NoIndex         bool   `json:"noindex" gorm:"not null;default:false"`
+}
+
+// Columnas que ni Update ni Restore escriben: el estado solo cambia mediante
+// el workflow y el idioma y el grupo mediante las traducciones.
+var contentProtectedFields = []string{
+    "id", "created_at", "deleted_at", "status", "published_at", "view_count",
+    "translation_group_id", "author_id", "locale",
+}
+
 type ContentService struct {
-    db      *gorm.DB
-    storage StorageService
-    cache   CacheService
+    db       *gorm.DB
+    storage  StorageService
+    cache    *cache.Store
+    locales  *LocaleConfig
+    workflow *Workflow
+    versions *VersionService
+    outbox   *events.Outbox
+    views    *ViewCounter
+}
+
+// NewContentService registra los cambios como eventos en outbox; la
+// invalidación de caché, la indexación y los webhooks se suscriben a ellos.
+func NewContentService(db *gorm.DB, store *cache.Store, locales *LocaleConfig, workflow *Workflow, versions *VersionService, outbox *events.Outbox, views *ViewCounter) *ContentService {
+    return &ContentService{
+        db:       db,
+        cache:    store,
+        locales:  locales,
+        workflow: workflow,
+        versions: versions,
+        outbox:   outbox,
+        views:    views,
+    }
 }
 
 func (s *ContentService) Create(ctx context.Context, content *Content) error {
     if content.Slug == "" {
         content.Slug = generateSlug(content.Title)
     }
-    
-    return s.db.WithContext(ctx).Create(content).Error
+
+    // El estado solo cambia mediante transiciones del workflow
+    content.Status = s.workflow.Initial
+    content.PublishedAt = time.Time{}
+    content.Revision = 1
+
+    if content.Locale == "" {
+        content.Locale = s.locales.Default
+    }
+    content.Locale = s.locales.Normalize(content.Locale)
+    if !s.locales.IsSupported(content.Locale) {
+        return ErrUnsupportedLocale
+    }
+    if err := s.validateSEO(ctx, content); err != nil {
+        return err
+    }
+
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        if err := tx.Create(content).Error; err != nil {
+            return err
+        }
+
+        // Un contenido nuevo sin grupo inicia su propio grupo de traducciones
+        if content.TranslationGroupID == 0 {
+            content.TranslationGroupID = content.ID
+            if err := tx.Model(content).Update("translation_group_id", content.ID).Error; err != nil {
+                return err
+            }
+        }
+
+        if err := s.versions.Record(ctx, tx, EntityContent, content.ID, content, ""); err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, EventContentCreated, content.ID, content)
+        return err
+    })
+    if err != nil {
+        return err
+    }
+
+    s.outbox.Relay(ctx, event)
+    return nil
 }
 
+// Get cachea el contenido etiquetado con su id y con su plantilla, para que
+// los cambios en cualquiera de los dos lo invaliden.
 func (s *ContentService) Get(ctx context.Context, id uint) (*Content, error) {
-    var content Content
-    
-    // Try cache first
-    if cached, err := s.cache.Get(cacheKey(id)); err == nil {
-        return cached.(*Content), nil
-    }
-    
-    if err := s.db.WithContext(ctx).First(&content, id).Error; err != nil {
+    content, err := cache.GetOrLoad(ctx, s.cache, ContentTag(id), cache.Options[Content]{
+        TTL:  time.Hour,
+        Tags: []string{ContentTag(id)},
+        TagsOf: func(content Content) []string {
+            return []string{TemplateTag(content.TemplateID)}
+        },
+    }, func(ctx context.Context) (Content, error) {
+        var content Content
+        err := s.db.WithContext(ctx).First(&content, id).Error
+        return content, err
+    })
+    if err != nil {
         return nil, err
     }
-    
-    s.cache.Set(cacheKey(id), &content, time.Hour)
     return &content, nil
 }
 
-func (s *ContentService) List(ctx context.Context, filter ContentFilter) ([]Content, error) {
-    var contents []Content
-    query := s.db.WithContext(ctx)
-    
-    if filter.Status != "" {
-        query = query.Where("status = ?", filter.Status)
-    }
-    
-    if filter.AuthorID != 0 {
-        query = query.Where("author_id = ?", filter.AuthorID)
+func (s *ContentService) List(ctx context.Context, params query.Params) (*query.Page[Content], error) {
+    for i, filter := range params.Filters {
+        if filter.Field != "locale" {
+            continue
+        }
+        for j, value := range filter.Values {
+            params.Filters[i].Values[j] = s.locales.Normalize(value.(string))
+        }
     }
-    
-    return contents, query.Find(&contents).Error
+    return query.Find[Content](ctx, s.db, params, ContentQuery)
 }
 
+// Update escribe todos los campos editables de content, así que debe llevar
+// la entidad completa (ver Replace). El idioma, el grupo de traducciones y el
+// autor no cambian aquí.
 func (s *ContentService) Update(ctx context.Context, content *Content) error {
-    if err := s.db.WithContext(ctx).Save(content).Error; err != nil {
+    if err := s.validateSEO(ctx, content); err != nil {
         return err
     }
-    
-    s.cache.Delete(cacheKey(content.ID))
+
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        var current Content
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "revision").First(&current, content.ID).Error; err != nil {
+            return err
+        }
+        // Revision 0 significa que el cliente no envió la revisión sobre la que edita
+        if content.Revision != 0 && content.Revision != current.Revision {
+            return &RevisionMismatchError{Current: current.Revision}
+        }
+        content.Revision = current.Revision + 1
+
+        // El estado solo cambia mediante el workflow
+        if err := tx.Model(&Content{}).Where("id = ?", content.ID).
+            Select("*").
+            Omit(contentProtectedFields...).
+            Updates(content).Error; err != nil {
+            return err
+        }
+        if err := tx.First(content, content.ID).Error; err != nil {
+            return err
+        }
+        if err := s.versions.Record(ctx, tx, EntityContent, content.ID, content, ""); err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, EventContentUpdated, content.ID, content)
+        return err
+    })
+    if err != nil {
+        return err
+    }
+
+    s.outbox.Relay(ctx, event)
     return nil
 }
 
+// Patch aplica un JSON Merge Patch sobre el contenido. Si revision no es 0
+// debe coincidir con la revisión actual.
+func (s *ContentService) Patch(ctx context.Context, id uint, patch []byte, revision int) (*Content, error) {
+    var content Content
+    if err := s.db.WithContext(ctx).First(&content, id).Error; err != nil {
+        return nil, err
+    }
+    if revision == 0 {
+        revision = content.Revision
+    }
+
+    if err := applyMergePatch(&content, patch); err != nil {
+        return nil, err
+    }
+    content.ID = id
+    content.Revision = revision
+
+    if err := s.Update(ctx, &content); err != nil {
+        return nil, err
+    }
+    return &content, nil
+}
+
+// Replace aplica el cuerpo de un PUT sobre el contenido leído de la base de
+// datos: los campos que no trae se mantienen. La revisión que se comprueba es
+// revision, la del cuerpo o, sin ninguna, la leída, así que una edición
+// concurrente entre la lectura y la escritura no se pisa.
+func (s *ContentService) Replace(ctx context.Context, id uint, body []byte, revision int) (*Content, error) {
+    var content Content
+    if err := s.db.WithContext(ctx).First(&content, id).Error; err != nil {
+        return nil, err
+    }
+    stored := content.Revision
+
+    if err := applyReplace(&content, body); err != nil {
+        return nil, err
+    }
+    content.ID = id
+    if revision != 0 {
+        content.Revision = revision
+    } else if content.Revision == 0 {
+        content.Revision = stored
+    }
+
+    if err := s.Update(ctx, &content); err != nil {
+        return nil, err
+    }
+    return &content, nil
+}
+
+// RecordView suma una lectura al contenido. No toca updated_at ni la
+// revisión: es una estadística, no una edición. Se escribe en lote desde el
+// ViewCounter, fuera de la petición.
+func (s *ContentService) RecordView(id uint) {
+    s.views.Add(id)
+}
+
 func (s *ContentService) Delete(ctx context.Context, id uint) error {
-    if err := s.db.WithContext(ctx).Delete(&Content{}, id).Error; err != nil {
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        if err := tx.Delete(&Content{}, id).Error; err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, EventContentDeleted, id, deletedEntity{ID: id})
+        return err
+    })
+    if err != nil {
         return err
     }
-    
-    s.cache.Delete(cacheKey(id))
+
+    s.outbox.Relay(ctx, event)
     return nil
 }
 
 func (s *ContentService) Publish(ctx context.Context, id uint) error {
-    return s.db.WithContext(ctx).Model(&Content{}).
-        Where("id = ?", id).
-        Updates(map[string]interface{}{
-            "status": "published",
-            "published_at": time.Now(),
-        }).Error
+    return s.setStatus(ctx, id, StatusPublished)
+}
+
+func (s *ContentService) Unpublish(ctx context.Context, id uint) error {
+    return s.setStatus(ctx, id, StatusDraft)
+}
+
+func (s *ContentService) Archive(ctx context.Context, id uint) error {
+    return s.setStatus(ctx, id, StatusArchived)
+}
+
+// setStatus aplica un cambio de estado del sistema (sin comprobar roles),
+// validando solo que el workflow permita la transición.
+func (s *ContentService) setStatus(ctx context.Context, id uint, to string) error {
+    var content Content
+    if err := s.db.WithContext(ctx).First(&content, id).Error; err != nil {
+        return err
+    }
+
+    from := content.Status
+    if from == "" {
+        from = s.workflow.Initial
+    }
+    if _, ok := s.workflow.Find(from, to); !ok {
+        return ErrIllegalTransition
+    }
+
+    updates := map[string]interface{}{
+        "status":   to,
+        "revision": gorm.Expr("revision + 1"),
+    }
+    if to == StatusPublished {
+        updates["published_at"] = time.Now()
+    }
+
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        if err := tx.Model(&Content{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+            return err
+        }
+        if err := tx.First(&content, id).Error; err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, statusEvent(from, to), id, &content)
+        return err
+    })
+    if err != nil {
+        return err
+    }
+
+    s.outbox.Relay(ctx, event)
+    return nil
 }
 
-type ContentFilter struct {
-    Status   string
-    AuthorID uint
-    Tags     []string
+// Delivered limita una consulta al contenido publicado y dentro de su
+// ventana de embargo, que es lo único que puede ver la API de entrega.
+func Delivered(now time.Time) func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        return db.Where("status = ?", StatusPublished).
+            Where("embargo_until IS NULL OR embargo_until <= ?", now).
+            Where("expires_at IS NULL OR expires_at > ?", now)
+    }
 }
 
-func cacheKey(id uint) string {
-    return fmt.Sprintf("content:%d", id)
+// Campos por los que se puede filtrar y ordenar el listado de contenido
+var ContentQuery = query.Schema{
+    Fields: map[string]query.Field{
+        "id":           {Column: "id", Type: query.Int, Sortable: true},
+        "title":        {Column: "title", Type: query.String, Sortable: true},
+        "slug":         {Column: "slug", Type: query.String, Sortable: true},
+        "status":       {Column: "status", Type: query.String},
+        "locale":       {Column: "locale", Type: query.String},
+        "author_id":    {Column: "author_id", Type: query.Int},
+        "template_id":  {Column: "template_id", Type: query.Int},
+        "tags":         {Column: "tags", Type: query.StringArray},
+        "view_count":   {Column: "view_count", Type: query.Int, Sortable: true},
+        "noindex":      {Column: "no_index", Type: query.Bool},
+        "created_at":   {Column: "created_at", Type: query.Time, Sortable: true},
+        "updated_at":   {Column: "updated_at", Type: query.Time, Sortable: true},
+        "published_at": {Column: "published_at", Type: query.Time, Sortable: true},
+    },
+    DefaultSort: []query.Sort{{Field: "created_at", Desc: true}},
 }
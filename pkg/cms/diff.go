@@ -0,0 +1,164 @@
+// pkg/cms/diff.go
+package cms
+
+import (
+    "regexp"
+    "strings"
+)
+
+const (
+    DiffEqual  = "equal"
+    DiffInsert = "insert"
+    DiffDelete = "delete"
+)
+
+type DiffOp struct {
+    Op   string `json:"op"`
+    Text string `json:"text"`
+}
+
+// wordTokens separa etiquetas HTML, espacios y palabras para que el diff de
+// texto enriquecido no parta una etiqueta por la mitad.
+var wordTokens = regexp.MustCompile(`<[^>]*>|\s+|[^\s<]+|<`)
+
+// DiffText compara dos textos. Si alguno tiene varias líneas el diff es por
+// líneas; si no, por palabras.
+func DiffText(oldText, newText string) []DiffOp {
+    if strings.Contains(oldText, "\n") || strings.Contains(newText, "\n") {
+        return DiffLines(oldText, newText)
+    }
+    return DiffWords(oldText, newText)
+}
+
+func DiffLines(oldText, newText string) []DiffOp {
+    return diffTokens(splitLines(oldText), splitLines(newText))
+}
+
+func DiffWords(oldText, newText string) []DiffOp {
+    return diffTokens(wordTokens.FindAllString(oldText, -1), wordTokens.FindAllString(newText, -1))
+}
+
+func splitLines(text string) []string {
+    if text == "" {
+        return nil
+    }
+    return strings.SplitAfter(text, "\n")
+}
+
+// maxDiffEdits limita las ediciones que busca Myers: la traza ocupa O(D²).
+// Con más cambios, el tramo que difiere se da como una sustitución entera.
+const maxDiffEdits = 1000
+
+// diffTokens compara dos listas de tokens y agrupa los consecutivos con la
+// misma operación. El prefijo y el sufijo comunes quedan fuera de la
+// búsqueda.
+func diffTokens(a, b []string) []DiffOp {
+    prefix := 0
+    for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+        prefix++
+    }
+    suffix := 0
+    for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+        suffix++
+    }
+
+    var ops []DiffOp
+    for _, token := range a[:prefix] {
+        ops = append(ops, DiffOp{Op: DiffEqual, Text: token})
+    }
+    oldMiddle, newMiddle := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+    if middle, ok := myers(oldMiddle, newMiddle); ok {
+        ops = append(ops, middle...)
+    } else {
+        ops = append(ops, DiffOp{Op: DiffDelete, Text: strings.Join(oldMiddle, "")}, DiffOp{Op: DiffInsert, Text: strings.Join(newMiddle, "")})
+    }
+    for _, token := range a[len(a)-suffix:] {
+        ops = append(ops, DiffOp{Op: DiffEqual, Text: token})
+    }
+
+    var merged []DiffOp
+    for _, op := range ops {
+        if last := len(merged) - 1; last >= 0 && merged[last].Op == op.Op {
+            merged[last].Text += op.Text
+            continue
+        }
+        merged = append(merged, op)
+    }
+    return merged
+}
+
+// myers implementa el algoritmo de Myers (O(ND)) con a lo sumo maxDiffEdits
+// ediciones; si hacen falta más devuelve false. De cada paso d solo se
+// guardan las diagonales -d-1..d+1, las que consulta la vuelta atrás.
+func myers(a, b []string) ([]DiffOp, bool) {
+    n, m := len(a), len(b)
+    limit := min(n+m, maxDiffEdits)
+    offset := limit + 1
+    v := make([]int, 2*limit+3)
+    var trace [][]int
+
+    found := false
+search:
+    for d := 0; d <= limit; d++ {
+        trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+        for k := -d; k <= d; k += 2 {
+            var x int
+            if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+                x = v[offset+k+1]
+            } else {
+                x = v[offset+k-1] + 1
+            }
+            y := x - k
+            for x < n && y < m && a[x] == b[y] {
+                x++
+                y++
+            }
+            v[offset+k] = x
+            if x >= n && y >= m {
+                found = true
+                break search
+            }
+        }
+    }
+    if !found {
+        return nil, false
+    }
+
+    var ops []DiffOp
+    x, y := n, m
+    for d := len(trace) - 1; d >= 0; d-- {
+        // trace[d] empieza en la diagonal -d-1
+        v := trace[d]
+        at := func(k int) int { return v[k+d+1] }
+        k := x - y
+
+        var prevK int
+        if k == -d || (k != d && at(k-1) < at(k+1)) {
+            prevK = k + 1
+        } else {
+            prevK = k - 1
+        }
+        prevX := at(prevK)
+        prevY := prevX - prevK
+
+        for x > prevX && y > prevY {
+            ops = append(ops, DiffOp{Op: DiffEqual, Text: a[x-1]})
+            x--
+            y--
+        }
+        if d > 0 {
+            if x == prevX {
+                ops = append(ops, DiffOp{Op: DiffInsert, Text: b[y-1]})
+            } else {
+                ops = append(ops, DiffOp{Op: DiffDelete, Text: a[x-1]})
+            }
+        }
+        x, y = prevX, prevY
+    }
+
+    // Los ops se generan al revés
+    for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+        ops[i], ops[j] = ops[j], ops[i]
+    }
+    return ops, true
+}
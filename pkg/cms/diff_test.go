@@ -0,0 +1,84 @@
+// pkg/cms/diff_test.go
+package cms
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestDiffWords(t *testing.T) {
+    ops := DiffWords("the quick brown fox", "the slow brown fox jumps")
+
+    assert.Equal(t, []DiffOp{
+        {Op: DiffEqual, Text: "the "},
+        {Op: DiffDelete, Text: "quick"},
+        {Op: DiffInsert, Text: "slow"},
+        {Op: DiffEqual, Text: " brown fox"},
+        {Op: DiffInsert, Text: " jumps"},
+    }, ops)
+}
+
+func TestDiffWordsRichText(t *testing.T) {
+    ops := DiffWords("<p>Hello world</p>", "<p>Hello <b>world</b></p>")
+
+    assert.Equal(t, []DiffOp{
+        {Op: DiffEqual, Text: "<p>Hello "},
+        {Op: DiffInsert, Text: "<b>"},
+        {Op: DiffEqual, Text: "world"},
+        {Op: DiffInsert, Text: "</b>"},
+        {Op: DiffEqual, Text: "</p>"},
+    }, ops)
+}
+
+func TestDiffLines(t *testing.T) {
+    ops := DiffText("one\ntwo\nthree\n", "one\n2\nthree\n")
+
+    assert.Equal(t, []DiffOp{
+        {Op: DiffEqual, Text: "one\n"},
+        {Op: DiffDelete, Text: "two\n"},
+        {Op: DiffInsert, Text: "2\n"},
+        {Op: DiffEqual, Text: "three\n"},
+    }, ops)
+}
+
+func TestDiffIdenticalAndEmpty(t *testing.T) {
+    assert.Equal(t, []DiffOp{{Op: DiffEqual, Text: "same text"}}, DiffText("same text", "same text"))
+    assert.Equal(t, []DiffOp{{Op: DiffInsert, Text: "new"}}, DiffText("", "new"))
+    assert.Nil(t, DiffText("", ""))
+}
+
+func TestDiffTooManyEdits(t *testing.T) {
+    var old, updated strings.Builder
+    for i := 0; i < maxDiffEdits; i++ {
+        fmt.Fprintf(&old, "a%d\n", i)
+        fmt.Fprintf(&updated, "b%d\n", i)
+    }
+
+    // Más ediciones que el límite: el tramo distinto se sustituye entero
+    ops := DiffLines("head\n"+old.String()+"tail\n", "head\n"+updated.String()+"tail\n")
+    assert.Equal(t, []DiffOp{
+        {Op: DiffEqual, Text: "head\n"},
+        {Op: DiffDelete, Text: old.String()},
+        {Op: DiffInsert, Text: updated.String()},
+        {Op: DiffEqual, Text: "tail\n"},
+    }, ops)
+}
+
+func TestDiffFields(t *testing.T) {
+    old := map[string]interface{}{"ID": 1.0, "UpdatedAt": "2024-01-01", "title": "Hello world", "template_id": 1.0}
+    updated := map[string]interface{}{"ID": 1.0, "UpdatedAt": "2024-02-01", "title": "Hello there", "template_id": 2.0, "slug": "hello"}
+
+    diff := diffFields(old, updated)
+
+    assert.Len(t, diff, 3)
+    assert.NotContains(t, diff, "UpdatedAt")
+    assert.Equal(t, []DiffOp{
+        {Op: DiffEqual, Text: "Hello "},
+        {Op: DiffDelete, Text: "world"},
+        {Op: DiffInsert, Text: "there"},
+    }, diff["title"].Ops)
+    assert.Nil(t, diff["template_id"].Ops)
+    assert.Equal(t, []DiffOp{{Op: DiffInsert, Text: "hello"}}, diff["slug"].Ops)
+}
@@ -0,0 +1,128 @@
+// pkg/cms/events.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/events"
+    "go.uber.org/zap"
+    "gorm.io/gorm"
+)
+
+// Los medios no tienen versiones, pero sí eventos.
+const EntityMedia = "media"
+
+// Eventos de dominio del CMS. El id del agregado es el de la entidad y los
+// datos, la entidad tras el cambio (solo el id en los borrados).
+const (
+    EventContentCreated      = "content.created"
+    EventContentUpdated      = "content.updated"
+    EventContentDeleted      = "content.deleted"
+    EventContentPublished    = "content.published"
+    EventContentUnpublished  = "content.unpublished"
+    EventContentArchived     = "content.archived"
+    EventContentTransitioned = "content.transitioned" // cualquier otro cambio de estado
+    EventTemplateCreated     = "template.created"
+    EventTemplateUpdated     = "template.updated"
+    EventTemplateDeleted     = "template.deleted"
+    EventMediaCreated        = "media.created"
+    EventMediaUpdated        = "media.updated"
+    EventMediaDeleted        = "media.deleted"
+)
+
+var EventTypes = []string{
+    EventContentCreated,
+    EventContentUpdated,
+    EventContentDeleted,
+    EventContentPublished,
+    EventContentUnpublished,
+    EventContentArchived,
+    EventContentTransitioned,
+    EventTemplateCreated,
+    EventTemplateUpdated,
+    EventTemplateDeleted,
+    EventMediaCreated,
+    EventMediaUpdated,
+    EventMediaDeleted,
+}
+
+// deletedEntity es el dato de los eventos de borrado.
+type deletedEntity struct {
+    ID uint `json:"id"`
+}
+
+// recordEvent registra el evento de una escritura con tx, la transacción de
+// la propia escritura. El evento devuelto se publica con Relay una vez
+// confirmada. Con outbox nil no registra nada.
+func recordEvent(ctx context.Context, tx *gorm.DB, outbox *events.Outbox, eventType string, id uint, data interface{}) (*events.Event, error) {
+    if outbox == nil {
+        return nil, nil
+    }
+    event, err := events.New(ctx, eventType, id, data)
+    if err != nil {
+        return nil, err
+    }
+    return event, outbox.Record(tx, event)
+}
+
+// statusEvent es el evento de un cambio de estado.
+func statusEvent(from, to string) string {
+    switch {
+    case to == StatusPublished:
+        return EventContentPublished
+    case to == StatusArchived:
+        return EventContentArchived
+    case from == StatusPublished:
+        return EventContentUnpublished
+    }
+    return EventContentTransitioned
+}
+
+// SubscribeEvents conecta al bus lo que antes hacía cada servicio tras
+// escribir: invalidar la caché, reindexar, encolar los webhooks y dejar
+// constancia en el log.
+func SubscribeEvents(bus *events.Bus, store *cache.Store, indexer *SearchIndexer, webhooks *WebhookService, logger *zap.Logger) {
+    bus.Subscribe("cache", "*", invalidateCache(store))
+    bus.Subscribe("search", "content.*", indexer.HandleEvent)
+    bus.Subscribe("search", "template.*", indexer.HandleEvent)
+    bus.Subscribe("webhooks", "*", webhooks.HandleEvent)
+    bus.Subscribe("audit", "*", auditLog(logger))
+}
+
+// invalidateCache invalida la etiqueta de la entidad del evento, que incluye
+// el contenido cacheado que usa una plantilla, la de su grupo de traducciones
+// y los feeds si el cambio puede alterarlos.
+func invalidateCache(store *cache.Store) events.Handler {
+    return func(ctx context.Context, event events.Event) error {
+        switch event.Aggregate() {
+        case EntityContent:
+            tags := []string{ContentTag(event.AggregateID), FeedsTag}
+            // Los borrados solo llevan el id; sus respuestas llevan su propia
+            // etiqueta
+            var data struct {
+                TranslationGroupID uint `json:"translation_group_id"`
+            }
+            if json.Unmarshal(event.Data, &data) == nil && data.TranslationGroupID != 0 {
+                tags = append(tags, TranslationTag(data.TranslationGroupID))
+            }
+            return store.InvalidateTags(ctx, tags...)
+        case EntityTemplate:
+            return store.InvalidateTags(ctx, TemplateTag(event.AggregateID), FeedsTag)
+        case EntityMedia:
+            return store.InvalidateTags(ctx, MediaTag(event.AggregateID), FeedsTag)
+        }
+        return nil
+    }
+}
+
+func auditLog(logger *zap.Logger) events.Handler {
+    return func(ctx context.Context, event events.Event) error {
+        logger.Info("CMS change",
+            zap.String("event", event.Type),
+            zap.String("event_id", event.ID),
+            zap.Uint("id", event.AggregateID),
+            zap.String("actor", event.Actor))
+        return nil
+    }
+}
@@ -0,0 +1,484 @@
+// pkg/cms/feed.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "encoding/xml"
+    "fmt"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+    "ezzygo/pkg/cache"
+    "gorm.io/gorm"
+)
+
+const (
+    // FeedAllTypes es el tipo de /feeds/all.rss: el contenido de cualquier
+    // plantilla.
+    FeedAllTypes = "all"
+
+    maxFeedItems      = 200
+    feedCacheTTL      = 10 * time.Minute // acota el retraso de los embargos que vencen
+    feedSummaryLength = 300
+)
+
+// FeedQuery selecciona el contenido de un feed.
+type FeedQuery struct {
+    Type   string // tipo de plantilla, o FeedAllTypes
+    Tag    string // término de taxonomía (tags del contenido)
+    Locale string
+}
+
+// Feed es un feed ya resuelto, independiente del formato.
+type Feed struct {
+    Title       string     `json:"title"`
+    Description string     `json:"description"`
+    Link        string     `json:"link"`
+    Locale      string     `json:"locale"`
+    Updated     time.Time  `json:"updated"`
+    Items       []FeedItem `json:"items"`
+    // Until es el próximo fin de embargo o caducidad que cambia las entradas,
+    // que no genera ningún evento. Cero si no hay ninguno.
+    Until time.Time `json:"until"`
+}
+
+type FeedItem struct {
+    URL       string         `json:"url"`
+    Title     string         `json:"title"`
+    Summary   string         `json:"summary"`
+    Content   string         `json:"content"`
+    Published time.Time      `json:"published"`
+    Updated   time.Time      `json:"updated"`
+    Tags      []string       `json:"tags"`
+    Enclosure *FeedEnclosure `json:"enclosure,omitempty"`
+}
+
+// FeedEnclosure es la imagen (og_image_id) de la entrada.
+type FeedEnclosure struct {
+    URL    string `json:"url"`
+    Type   string `json:"type"`
+    Length int64  `json:"length"`
+}
+
+// FeedService genera los feeds del contenido publicado.
+type FeedService struct {
+    db          *gorm.DB
+    cache       *cache.Store
+    locales     *LocaleConfig
+    siteURL     string
+    title       string
+    description string
+    limit       int
+}
+
+// NewFeedService lee del entorno la URL del sitio (CMS_SITE_URL), el título
+// y la descripción de los feeds (CMS_FEED_TITLE, CMS_FEED_DESCRIPTION) y el
+// número de entradas (CMS_FEED_LIMIT, 50 por defecto).
+func NewFeedService(db *gorm.DB, store *cache.Store, locales *LocaleConfig) *FeedService {
+    service := &FeedService{
+        db:          db,
+        cache:       store,
+        locales:     locales,
+        siteURL:     strings.TrimSuffix(os.Getenv("CMS_SITE_URL"), "/"),
+        title:       os.Getenv("CMS_FEED_TITLE"),
+        description: os.Getenv("CMS_FEED_DESCRIPTION"),
+        limit:       50,
+    }
+    if limit, err := strconv.Atoi(os.Getenv("CMS_FEED_LIMIT")); err == nil && limit > 0 {
+        service.limit = min(limit, maxFeedItems)
+    }
+    if service.title == "" {
+        service.title = "Feed"
+        if parsed, err := url.Parse(service.siteURL); err == nil && parsed.Host != "" {
+            service.title = parsed.Host
+        }
+    }
+    return service
+}
+
+// Feed devuelve las últimas entradas publicadas que cumplen q. Se cachea con
+// la etiqueta FeedsTag, que se invalida con cualquier cambio de contenido, y
+// se regenera si ya pasó su Until.
+func (s *FeedService) Feed(ctx context.Context, q FeedQuery) (*Feed, error) {
+    if q.Locale == "" {
+        q.Locale = s.locales.Default
+    }
+    q.Locale = s.locales.Normalize(q.Locale)
+    if !s.locales.IsSupported(q.Locale) {
+        return nil, ErrUnsupportedLocale
+    }
+
+    key := fmt.Sprintf("feeds:%s:%s:%s", q.Type, q.Locale, url.QueryEscape(q.Tag))
+    options := cache.Options[*Feed]{TTL: feedCacheTTL, Tags: []string{FeedsTag}}
+    load := func(ctx context.Context) (*Feed, error) {
+        return s.load(ctx, q)
+    }
+    feed, err := cache.GetOrLoad(ctx, s.cache, key, options, load)
+    if err != nil {
+        return nil, err
+    }
+    if !feed.Until.IsZero() && !feed.Until.After(time.Now()) {
+        if err := s.cache.Delete(ctx, key); err != nil {
+            return s.load(ctx, q)
+        }
+        return cache.GetOrLoad(ctx, s.cache, key, options, load)
+    }
+    return feed, nil
+}
+
+// URL es la URL pública de una ruta de feed; el frontend sirve /feeds/ con
+// un rewrite a esta API, como el sitemap.
+func (s *FeedService) URL(path string) string {
+    return s.siteURL + path
+}
+
+func (s *FeedService) load(ctx context.Context, q FeedQuery) (*Feed, error) {
+    db := s.db.WithContext(ctx)
+    now := time.Now()
+
+    matches := func(db *gorm.DB) *gorm.DB {
+        db = db.Where("locale = ?", q.Locale)
+        if q.Type != FeedAllTypes {
+            db = db.Where("template_id IN (?)", s.db.Model(&Template{}).Select("id").Where("type = ?", q.Type))
+        }
+        if q.Tag != "" {
+            db = db.Where("? = ANY(tags)", q.Tag)
+        }
+        return db
+    }
+
+    var contents []Content
+    if err := db.Scopes(Delivered(now), matches).
+        Order("published_at DESC").Order("id DESC").Limit(s.limit).
+        Find(&contents).Error; err != nil {
+        return nil, err
+    }
+
+    // Las entradas cambian sin evento cuando caduca una o vence el embargo
+    // de otra
+    var embargo *time.Time
+    if err := db.Model(&Content{}).Scopes(matches).
+        Where("status = ? AND embargo_until > ?", StatusPublished, now).
+        Select("MIN(embargo_until)").Scan(&embargo).Error; err != nil {
+        return nil, err
+    }
+
+    images := map[uint]*Media{}
+    var ids []uint
+    for _, content := range contents {
+        if content.OGImageID != nil {
+            ids = append(ids, *content.OGImageID)
+        }
+    }
+    if len(ids) > 0 {
+        var media []Media
+        if err := db.Where("id IN ?", ids).Find(&media).Error; err != nil {
+            return nil, err
+        }
+        for n := range media {
+            images[media[n].ID] = &media[n]
+        }
+    }
+
+    feed := &Feed{
+        Title:       s.title,
+        Description: s.description,
+        Link:        s.siteURL + "/",
+        Locale:      q.Locale,
+        Items:       make([]FeedItem, 0, len(contents)),
+    }
+    if embargo != nil {
+        feed.Until = *embargo
+    }
+    if q.Type != FeedAllTypes {
+        feed.Title += " - " + q.Type
+    }
+    if q.Tag != "" {
+        feed.Title += " - " + q.Tag
+    }
+
+    for n := range contents {
+        content := &contents[n]
+        item := FeedItem{
+            URL:       content.CanonicalURL,
+            Title:     content.Title,
+            Summary:   content.MetaDescription,
+            Content:   content.Content,
+            Published: content.PublishedAt,
+            Updated:   content.UpdatedAt,
+            Tags:      content.Tags,
+        }
+        if item.URL == "" {
+            item.URL = s.siteURL + ContentPath(s.locales, content)
+        }
+        if item.Summary == "" {
+            item.Summary = excerpt(content.Content, feedSummaryLength)
+        }
+        if content.OGImageID != nil {
+            if image, ok := images[*content.OGImageID]; ok {
+                item.Enclosure = &FeedEnclosure{URL: image.URL, Type: image.MimeType, Length: image.Size}
+            }
+        }
+        if item.Updated.After(feed.Updated) {
+            feed.Updated = item.Updated
+        }
+        if content.ExpiresAt != nil && (feed.Until.IsZero() || content.ExpiresAt.Before(feed.Until)) {
+            feed.Until = *content.ExpiresAt
+        }
+        feed.Items = append(feed.Items, item)
+    }
+    return feed, nil
+}
+
+type rssFeed struct {
+    XMLName      xml.Name   `xml:"rss"`
+    Version      string     `xml:"version,attr"`
+    XmlnsAtom    string     `xml:"xmlns:atom,attr"`
+    XmlnsContent string     `xml:"xmlns:content,attr"`
+    Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+    Title         string    `xml:"title"`
+    Link          string    `xml:"link"`
+    Description   string    `xml:"description"`
+    Language      string    `xml:"language"`
+    LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+    Self          atomLink  `xml:"atom:link"`
+    Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+    Title       string        `xml:"title"`
+    Link        string        `xml:"link"`
+    GUID        rssGUID       `xml:"guid"`
+    PubDate     string        `xml:"pubDate,omitempty"`
+    Description string        `xml:"description"`
+    Content     cdata         `xml:"content:encoded"`
+    Categories  []string      `xml:"category"`
+    Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+    IsPermaLink bool   `xml:"isPermaLink,attr"`
+    Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+    URL    string `xml:"url,attr"`
+    Length int64  `xml:"length,attr"`
+    Type   string `xml:"type,attr"`
+}
+
+type cdata struct {
+    Value string `xml:",cdata"`
+}
+
+// RSS genera el feed en RSS 2.0. self es la URL del propio feed.
+func (f *Feed) RSS(self string) ([]byte, error) {
+    channel := rssChannel{
+        Title:       f.Title,
+        Link:        f.Link,
+        Description: f.Description,
+        Language:    f.Locale,
+        Self:        atomLink{Href: self, Rel: "self", Type: "application/rss+xml"},
+    }
+    if channel.Description == "" {
+        channel.Description = f.Title
+    }
+    if !f.Updated.IsZero() {
+        channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+    }
+    for _, item := range f.Items {
+        entry := rssItem{
+            Title:       item.Title,
+            Link:        item.URL,
+            GUID:        rssGUID{IsPermaLink: true, Value: item.URL},
+            Description: item.Summary,
+            Content:     cdata{Value: item.Content},
+            Categories:  item.Tags,
+        }
+        if !item.Published.IsZero() {
+            entry.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+        }
+        if item.Enclosure != nil {
+            entry.Enclosure = &rssEnclosure{URL: item.Enclosure.URL, Length: item.Enclosure.Length, Type: item.Enclosure.Type}
+        }
+        channel.Items = append(channel.Items, entry)
+    }
+
+    return marshalXML(rssFeed{
+        Version:      "2.0",
+        XmlnsAtom:    "http://www.w3.org/2005/Atom",
+        XmlnsContent: "http://purl.org/rss/1.0/modules/content/",
+        Channel:      channel,
+    })
+}
+
+type atomFeed struct {
+    XMLName xml.Name    `xml:"feed"`
+    Xmlns   string      `xml:"xmlns,attr"`
+    Lang    string      `xml:"xml:lang,attr,omitempty"`
+    Title   string      `xml:"title"`
+    Tagline string      `xml:"subtitle,omitempty"`
+    ID      string      `xml:"id"`
+    Links   []atomLink  `xml:"link"`
+    Updated string      `xml:"updated"`
+    Author  atomAuthor  `xml:"author"`
+    Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+    Href   string `xml:"href,attr"`
+    Rel    string `xml:"rel,attr,omitempty"`
+    Type   string `xml:"type,attr,omitempty"`
+    Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomAuthor struct {
+    Name string `xml:"name"`
+}
+
+type atomEntry struct {
+    Title      string         `xml:"title"`
+    ID         string         `xml:"id"`
+    Links      []atomLink     `xml:"link"`
+    Published  string         `xml:"published,omitempty"`
+    Updated    string         `xml:"updated"`
+    Summary    string         `xml:"summary"`
+    Content    atomContent    `xml:"content"`
+    Categories []atomCategory `xml:"category"`
+}
+
+type atomContent struct {
+    Type  string `xml:"type,attr"`
+    Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+    Term string `xml:"term,attr"`
+}
+
+// Atom genera el feed en Atom 1.0. self es la URL del propio feed, que es
+// también su id.
+func (f *Feed) Atom(self string) ([]byte, error) {
+    feed := atomFeed{
+        Xmlns:   "http://www.w3.org/2005/Atom",
+        Lang:    f.Locale,
+        Title:   f.Title,
+        Tagline: f.Description,
+        ID:      self,
+        Links: []atomLink{
+            {Href: self, Rel: "self", Type: "application/atom+xml"},
+            {Href: f.Link, Rel: "alternate", Type: "text/html"},
+        },
+        Updated: atomTime(f.Updated),
+        Author:  atomAuthor{Name: f.Title},
+    }
+    for _, item := range f.Items {
+        entry := atomEntry{
+            Title:   item.Title,
+            ID:      item.URL,
+            Links:   []atomLink{{Href: item.URL, Rel: "alternate", Type: "text/html"}},
+            Updated: atomTime(item.Updated),
+            Summary: item.Summary,
+            Content: atomContent{Type: "html", Value: item.Content},
+        }
+        if !item.Published.IsZero() {
+            entry.Published = atomTime(item.Published)
+        }
+        for _, tag := range item.Tags {
+            entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+        }
+        if item.Enclosure != nil {
+            entry.Links = append(entry.Links, atomLink{
+                Href:   item.Enclosure.URL,
+                Rel:    "enclosure",
+                Type:   item.Enclosure.Type,
+                Length: item.Enclosure.Length,
+            })
+        }
+        feed.Entries = append(feed.Entries, entry)
+    }
+    return marshalXML(feed)
+}
+
+// atomTime formatea en RFC 3339; Atom exige updated aunque el feed esté
+// vacío.
+func atomTime(t time.Time) string {
+    if t.IsZero() {
+        t = time.Unix(0, 0)
+    }
+    return t.UTC().Format(time.RFC3339)
+}
+
+type jsonFeed struct {
+    Version     string         `json:"version"`
+    Title       string         `json:"title"`
+    HomePageURL string         `json:"home_page_url"`
+    FeedURL     string         `json:"feed_url"`
+    Description string         `json:"description,omitempty"`
+    Language    string         `json:"language"`
+    Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+    ID            string               `json:"id"`
+    URL           string               `json:"url"`
+    Title         string               `json:"title"`
+    ContentHTML   string               `json:"content_html"`
+    Summary       string               `json:"summary,omitempty"`
+    Image         string               `json:"image,omitempty"`
+    DatePublished string               `json:"date_published,omitempty"`
+    DateModified  string               `json:"date_modified,omitempty"`
+    Tags          []string             `json:"tags,omitempty"`
+    Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAttachment struct {
+    URL         string `json:"url"`
+    MimeType    string `json:"mime_type"`
+    SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+// JSONFeed genera el feed en JSON Feed 1.1. self es la URL del propio feed.
+func (f *Feed) JSONFeed(self string) ([]byte, error) {
+    feed := jsonFeed{
+        Version:     "https://jsonfeed.org/version/1.1",
+        Title:       f.Title,
+        HomePageURL: f.Link,
+        FeedURL:     self,
+        Description: f.Description,
+        Language:    f.Locale,
+        Items:       []jsonFeedItem{},
+    }
+    for _, item := range f.Items {
+        entry := jsonFeedItem{
+            ID:          item.URL,
+            URL:         item.URL,
+            Title:       item.Title,
+            ContentHTML: item.Content,
+            Summary:     item.Summary,
+            Tags:        item.Tags,
+        }
+        if !item.Published.IsZero() {
+            entry.DatePublished = item.Published.UTC().Format(time.RFC3339)
+        }
+        if !item.Updated.IsZero() {
+            entry.DateModified = item.Updated.UTC().Format(time.RFC3339)
+        }
+        if item.Enclosure != nil {
+            entry.Image = item.Enclosure.URL
+            entry.Attachments = []jsonFeedAttachment{{
+                URL:         item.Enclosure.URL,
+                MimeType:    item.Enclosure.Type,
+                SizeInBytes: item.Enclosure.Length,
+            }}
+        }
+        feed.Items = append(feed.Items, entry)
+    }
+    return json.MarshalIndent(feed, "", "  ")
+}
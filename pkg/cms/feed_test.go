@@ -0,0 +1,96 @@
+// pkg/cms/feed_test.go
+package cms
+
+import (
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func testFeed() *Feed {
+    published := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+    return &Feed{
+        Title:   "example.com - post",
+        Link:    "https://example.com/",
+        Locale:  "en",
+        Updated: published.Add(time.Hour),
+        Items: []FeedItem{{
+            URL:       "https://example.com/hello/",
+            Title:     "Hello & welcome",
+            Summary:   "First post",
+            Content:   "<p>Body</p>",
+            Published: published,
+            Updated:   published.Add(time.Hour),
+            Tags:      []string{"go", "cms"},
+            Enclosure: &FeedEnclosure{URL: "https://cdn.test/og.png", Type: "image/png", Length: 1024},
+        }},
+    }
+}
+
+func TestFeedRSS(t *testing.T) {
+    body, err := testFeed().RSS("https://example.com/feeds/post.rss")
+    require.NoError(t, err)
+
+    rss := string(body)
+    assert.True(t, strings.HasPrefix(rss, `<?xml version="1.0" encoding="UTF-8"?>`))
+    assert.Contains(t, rss, `<rss version="2.0"`)
+    assert.Contains(t, rss, `<atom:link href="https://example.com/feeds/post.rss" rel="self" type="application/rss+xml"></atom:link>`)
+    assert.Contains(t, rss, "<title>Hello &amp; welcome</title>")
+    assert.Contains(t, rss, `<guid isPermaLink="true">https://example.com/hello/</guid>`)
+    assert.Contains(t, rss, "<pubDate>Fri, 01 Mar 2024 10:00:00 +0000</pubDate>")
+    assert.Contains(t, rss, "<content:encoded><![CDATA[<p>Body</p>]]></content:encoded>")
+    assert.Contains(t, rss, "<category>go</category>")
+    assert.Contains(t, rss, "<category>cms</category>")
+    assert.Contains(t, rss, `<enclosure url="https://cdn.test/og.png" length="1024" type="image/png"></enclosure>`)
+    // Sin descripción se usa el título
+    assert.Contains(t, rss, "<description>example.com - post</description>")
+}
+
+func TestFeedAtom(t *testing.T) {
+    body, err := testFeed().Atom("https://example.com/feeds/post.atom")
+    require.NoError(t, err)
+
+    atom := string(body)
+    assert.Contains(t, atom, `<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en">`)
+    assert.Contains(t, atom, "<id>https://example.com/feeds/post.atom</id>")
+    assert.Contains(t, atom, "<updated>2024-03-01T11:00:00Z</updated>")
+    assert.Contains(t, atom, "<published>2024-03-01T10:00:00Z</published>")
+    assert.Contains(t, atom, `<content type="html">&lt;p&gt;Body&lt;/p&gt;</content>`)
+    assert.Contains(t, atom, `<category term="go"></category>`)
+    assert.Contains(t, atom, `<link href="https://cdn.test/og.png" rel="enclosure" type="image/png" length="1024"></link>`)
+
+    // Un feed vacío sigue teniendo updated
+    body, err = (&Feed{Title: "empty"}).Atom("https://example.com/feeds/all.atom")
+    require.NoError(t, err)
+    assert.Contains(t, string(body), "<updated>1970-01-01T00:00:00Z</updated>")
+    assert.NotContains(t, string(body), "<entry>")
+}
+
+func TestFeedJSON(t *testing.T) {
+    body, err := testFeed().JSONFeed("https://example.com/feeds/post.json")
+    require.NoError(t, err)
+
+    var feed map[string]interface{}
+    require.NoError(t, json.Unmarshal(body, &feed))
+    assert.Equal(t, "https://jsonfeed.org/version/1.1", feed["version"])
+    assert.Equal(t, "https://example.com/feeds/post.json", feed["feed_url"])
+
+    items := feed["items"].([]interface{})
+    require.Len(t, items, 1)
+    item := items[0].(map[string]interface{})
+    assert.Equal(t, "https://example.com/hello/", item["id"])
+    assert.Equal(t, "2024-03-01T10:00:00Z", item["date_published"])
+    assert.Equal(t, []interface{}{"go", "cms"}, item["tags"])
+    assert.Equal(t, []interface{}{map[string]interface{}{
+        "url":           "https://cdn.test/og.png",
+        "mime_type":     "image/png",
+        "size_in_bytes": float64(1024),
+    }}, item["attachments"])
+
+    body, err = (&Feed{}).JSONFeed("")
+    require.NoError(t, err)
+    assert.Contains(t, string(body), `"items": []`)
+}
@@ -0,0 +1,304 @@
+// pkg/cms/locale.go
+package cms
+
+import (
+    "context"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+    "ezzygo/pkg/query"
+    "gorm.io/gorm"
+)
+
+// LocaleConfig define los idiomas soportados y las cadenas de fallback
+// usadas por la API de entrega (por ejemplo es-MX -> es -> en).
+type LocaleConfig struct {
+    Default   string
+    Supported []string
+    Fallbacks map[string][]string
+}
+
+type MissingTranslation struct {
+    TranslationGroupID uint     `json:"translation_group_id"`
+    Title              string   `json:"title"`
+    Locales            []string `json:"locales"`
+    Missing            []string `json:"missing"`
+}
+
+// NewLocaleConfig lee la configuración de idiomas del entorno:
+//   CMS_DEFAULT_LOCALE=en
+//   CMS_LOCALES=en,es,es-MX
+//   CMS_LOCALE_FALLBACKS=es-MX:es,en;pt:es
+func NewLocaleConfig() *LocaleConfig {
+    config := &LocaleConfig{
+        Default:   "en",
+        Supported: []string{"en", "es"},
+        Fallbacks: map[string][]string{},
+    }
+
+    if def := os.Getenv("CMS_DEFAULT_LOCALE"); def != "" {
+        config.Default = config.Normalize(def)
+    }
+
+    if locales := os.Getenv("CMS_LOCALES"); locales != "" {
+        config.Supported = nil
+        for _, locale := range strings.Split(locales, ",") {
+            if locale = strings.TrimSpace(locale); locale != "" {
+                config.Supported = append(config.Supported, config.Normalize(locale))
+            }
+        }
+    }
+
+    if fallbacks := os.Getenv("CMS_LOCALE_FALLBACKS"); fallbacks != "" {
+        for _, rule := range strings.Split(fallbacks, ";") {
+            parts := strings.SplitN(rule, ":", 2)
+            if len(parts) != 2 {
+                continue
+            }
+            locale := config.Normalize(strings.TrimSpace(parts[0]))
+            for _, fallback := range strings.Split(parts[1], ",") {
+                if fallback = strings.TrimSpace(fallback); fallback != "" {
+                    config.Fallbacks[locale] = append(config.Fallbacks[locale], config.Normalize(fallback))
+                }
+            }
+        }
+    }
+
+    if !contains(config.Supported, config.Default) {
+        config.Supported = append(config.Supported, config.Default)
+    }
+
+    return config
+}
+
+// Normalize convierte "es_mx" o "ES-mx" en "es-MX".
+func (c *LocaleConfig) Normalize(locale string) string {
+    locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+    parts := strings.Split(locale, "-")
+    parts[0] = strings.ToLower(parts[0])
+    for i := 1; i < len(parts); i++ {
+        parts[i] = strings.ToUpper(parts[i])
+    }
+    return strings.Join(parts, "-")
+}
+
+func (c *LocaleConfig) IsSupported(locale string) bool {
+    return contains(c.Supported, c.Normalize(locale))
+}
+
+// FallbackChain devuelve el orden en que se buscan traducciones para un idioma.
+// Si no hay una cadena configurada se usa el idioma base y luego el idioma por defecto.
+func (c *LocaleConfig) FallbackChain(locale string) []string {
+    return unique(append(c.chain(locale), c.Default))
+}
+
+// chain es la cadena de fallback sin el idioma por defecto del final.
+func (c *LocaleConfig) chain(locale string) []string {
+    locale = c.Normalize(locale)
+    chain := []string{locale}
+
+    if fallbacks, ok := c.Fallbacks[locale]; ok {
+        chain = append(chain, fallbacks...)
+    } else {
+        parts := strings.Split(locale, "-")
+        for i := len(parts) - 1; i > 0; i-- {
+            chain = append(chain, strings.Join(parts[:i], "-"))
+        }
+    }
+    return chain
+}
+
+// Negotiate elige el idioma soportado preferido de una cabecera
+// Accept-Language: recorre las etiquetas por q (a igual q, en el orden de la
+// cabecera) y de cada una su cadena de fallback, sin el idioma por defecto,
+// que solo se usa si ninguna etiqueta encaja.
+func (c *LocaleConfig) Negotiate(acceptLanguage string) string {
+    type weighted struct {
+        locale string
+        q      float64
+    }
+    var tags []weighted
+    for _, tag := range strings.Split(acceptLanguage, ",") {
+        parts := strings.Split(tag, ";")
+        locale := c.Normalize(parts[0])
+        if locale == "" || locale == "*" {
+            continue
+        }
+        q := 1.0
+        for _, param := range parts[1:] {
+            name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+            if strings.TrimSpace(name) != "q" {
+                continue
+            }
+            parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+            if err != nil {
+                parsed = 0
+            }
+            q = parsed
+        }
+        // q=0 significa "no aceptable"
+        if q > 0 {
+            tags = append(tags, weighted{locale: locale, q: q})
+        }
+    }
+    sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+    for _, tag := range tags {
+        for _, candidate := range c.chain(tag.locale) {
+            if c.IsSupported(candidate) {
+                return candidate
+            }
+        }
+    }
+    return c.Default
+}
+
+// Métodos de traducción del ContentService
+
+func (s *ContentService) Translations(ctx context.Context, id uint) ([]Content, error) {
+    var content Content
+    if err := s.db.WithContext(ctx).First(&content, id).Error; err != nil {
+        return nil, err
+    }
+
+    var translations []Content
+    err := s.db.WithContext(ctx).
+        Where("translation_group_id = ?", content.TranslationGroupID).
+        Order("locale").
+        Find(&translations).Error
+    return translations, err
+}
+
+func (s *ContentService) CreateTranslation(ctx context.Context, sourceID uint, translation *Content) error {
+    var source Content
+    if err := s.db.WithContext(ctx).First(&source, sourceID).Error; err != nil {
+        return err
+    }
+
+    translation.Locale = s.locales.Normalize(translation.Locale)
+    if !s.locales.IsSupported(translation.Locale) {
+        return ErrUnsupportedLocale
+    }
+
+    var count int64
+    if err := s.db.WithContext(ctx).Model(&Content{}).
+        Where("translation_group_id = ? AND locale = ?", source.TranslationGroupID, translation.Locale).
+        Count(&count).Error; err != nil {
+        return err
+    }
+    if count > 0 {
+        return ErrTranslationExists
+    }
+
+    translation.ID = 0
+    translation.TranslationGroupID = source.TranslationGroupID
+    if translation.TemplateID == 0 {
+        translation.TemplateID = source.TemplateID
+    }
+    // La imagen para redes no depende del idioma
+    if translation.OGImageID == nil {
+        translation.OGImageID = source.OGImageID
+    }
+
+    return s.Create(ctx, translation)
+}
+
+// Resolve busca un contenido publicado (y fuera de embargo) por slug siguiendo
+// la cadena de fallback del idioma pedido. El slug puede pertenecer a cualquier
+// idioma de la cadena; se devuelve la mejor traducción disponible de su grupo.
+// Con fields solo se cargan esas columnas, además de las que usa la entrega.
+func (s *ContentService) Resolve(ctx context.Context, slug, locale string, fields query.Fieldset) (*Content, error) {
+    chain := s.locales.FallbackChain(locale)
+    now := time.Now()
+    columns := fields.Scope(&Content{}, "locale", "translation_group_id", "template_id", "og_image_id", "expires_at")
+
+    var matches []Content
+    if err := s.db.WithContext(ctx).
+        Scopes(Delivered(now), columns).
+        Where("slug = ? AND locale IN ?", slug, chain).
+        Find(&matches).Error; err != nil {
+        return nil, err
+    }
+
+    match := pickByChain(matches, chain)
+    if match == nil {
+        return nil, gorm.ErrRecordNotFound
+    }
+
+    var translations []Content
+    if err := s.db.WithContext(ctx).
+        Scopes(Delivered(now), columns).
+        Where("translation_group_id = ? AND locale IN ?", match.TranslationGroupID, chain).
+        Find(&translations).Error; err != nil {
+        return nil, err
+    }
+
+    if best := pickByChain(translations, chain); best != nil {
+        return best, nil
+    }
+    return match, nil
+}
+
+// MissingTranslations lista los grupos de traducción a los que les falta alguno
+// de los idiomas pedidos (todos los soportados si no se indica ninguno).
+func (s *ContentService) MissingTranslations(ctx context.Context, locales []string) ([]MissingTranslation, error) {
+    required := s.locales.Supported
+    if len(locales) > 0 {
+        required = make([]string, len(locales))
+        for i, locale := range locales {
+            required[i] = s.locales.Normalize(locale)
+        }
+    }
+
+    var contents []Content
+    if err := s.db.WithContext(ctx).
+        Select("id", "title", "locale", "translation_group_id").
+        Order("translation_group_id, id").
+        Find(&contents).Error; err != nil {
+        return nil, err
+    }
+
+    groups := make(map[uint]*MissingTranslation)
+    var order []uint
+    for _, content := range contents {
+        group, ok := groups[content.TranslationGroupID]
+        if !ok {
+            group = &MissingTranslation{TranslationGroupID: content.TranslationGroupID, Title: content.Title}
+            groups[content.TranslationGroupID] = group
+            order = append(order, content.TranslationGroupID)
+        }
+        if content.Locale == s.locales.Default {
+            group.Title = content.Title
+        }
+        group.Locales = append(group.Locales, content.Locale)
+    }
+
+    var report []MissingTranslation
+    for _, id := range order {
+        group := groups[id]
+        for _, locale := range required {
+            if !contains(group.Locales, locale) {
+                group.Missing = append(group.Missing, locale)
+            }
+        }
+        if len(group.Missing) > 0 {
+            sort.Strings(group.Locales)
+            report = append(report, *group)
+        }
+    }
+
+    return report, nil
+}
+
+func pickByChain(contents []Content, chain []string) *Content {
+    for _, locale := range chain {
+        for i := range contents {
+            if contents[i].Locale == locale {
+                return &contents[i]
+            }
+        }
+    }
+    return nil
+}
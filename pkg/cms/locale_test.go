@@ -0,0 +1,50 @@
+// pkg/cms/locale_test.go
+package cms
+
+import (
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestLocaleFallbackChain(t *testing.T) {
+    config := &LocaleConfig{
+        Default:   "en",
+        Supported: []string{"en", "es", "es-MX"},
+        Fallbacks: map[string][]string{},
+    }
+
+    assert.Equal(t, []string{"es-MX", "es", "en"}, config.FallbackChain("es_mx"))
+    assert.Equal(t, []string{"es", "en"}, config.FallbackChain("es"))
+    assert.Equal(t, []string{"en"}, config.FallbackChain("en"))
+
+    config.Fallbacks["pt"] = []string{"es"}
+    assert.Equal(t, []string{"pt", "es", "en"}, config.FallbackChain("pt"))
+}
+
+func TestLocaleNegotiate(t *testing.T) {
+    config := &LocaleConfig{
+        Default:   "en",
+        Supported: []string{"en", "es"},
+    }
+
+    assert.Equal(t, "es", config.Negotiate("es-MX,es;q=0.9,en;q=0.8"))
+    assert.Equal(t, "en", config.Negotiate("fr-FR"))
+    assert.Equal(t, "en", config.Negotiate(""))
+
+    // Las etiquetas siguientes y q también cuentan
+    assert.Equal(t, "es", config.Negotiate("xx, es;q=0.8"))
+    assert.Equal(t, "es", config.Negotiate("en;q=0.5, es-AR;q=0.9"))
+    assert.Equal(t, "en", config.Negotiate("es;q=0, fr"))
+    assert.Equal(t, "en", config.Negotiate("*, fr;q=0.7"))
+}
+
+func TestNewLocaleConfigFromEnv(t *testing.T) {
+    t.Setenv("CMS_DEFAULT_LOCALE", "en")
+    t.Setenv("CMS_LOCALES", "en, es, es-mx")
+    t.Setenv("CMS_LOCALE_FALLBACKS", "es-MX:es;pt:es,en")
+
+    config := NewLocaleConfig()
+    assert.Equal(t, []string{"en", "es", "es-MX"}, config.Supported)
+    assert.Equal(t, []string{"es"}, config.Fallbacks["es-MX"])
+    assert.Equal(t, []string{"es", "en"}, config.Fallbacks["pt"])
+}
@@ -0,0 +1,370 @@
+// pkg/cms/lock_service.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "sort"
+    "strconv"
+    "time"
+    "ezzygo/pkg/models"
+    "github.com/redis/go-redis/v9"
+    "gorm.io/gorm"
+)
+
+const (
+    PresenceViewing = "viewing"
+    PresenceEditing = "editing"
+
+    EventPresence = "presence"
+    EventLeave    = "leave"
+    EventLock     = "lock"
+    EventUnlock   = "unlock"
+    EventTakeover = "takeover"
+
+    presenceChannel = "cms:presence"
+)
+
+var (
+    ErrContentLocked     = errors.New("content is locked by another user")
+    ErrLockNotHeld       = errors.New("edit lock is not held by this user")
+    ErrTakeoverForbidden = errors.New("only admins can take over an edit lock")
+    ErrInvalidPresence   = errors.New("invalid presence mode")
+)
+
+// Los scripts comparan el dueño y modifican el lock en una sola operación
+var acquireEditLock = redis.NewScript(`
+local owner = redis.call("HGET", KEYS[1], "username")
+if owner and owner ~= ARGV[1] then
+    return owner
+end
+if not owner then
+    redis.call("HSET", KEYS[1], "username", ARGV[1], "acquired_at", ARGV[2])
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return ARGV[1]
+`)
+
+var heartbeatEditLock = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "username") == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseEditLock = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "username") == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+var takeoverEditLock = redis.NewScript(`
+local previous = redis.call("HGET", KEYS[1], "username")
+redis.call("DEL", KEYS[1])
+redis.call("HSET", KEYS[1], "username", ARGV[1], "acquired_at", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return previous or ""
+`)
+
+type EditLock struct {
+    ContentID  uint      `json:"content_id"`
+    Username   string    `json:"username"`
+    AcquiredAt time.Time `json:"acquired_at"`
+    ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// LockedError indica quién tiene el lock para que la UI pueda mostrarlo.
+type LockedError struct {
+    Lock *EditLock
+}
+
+func (e *LockedError) Error() string {
+    return fmt.Sprintf("content is locked by %s", e.Lock.Username)
+}
+
+func (e *LockedError) Is(target error) bool {
+    return target == ErrContentLocked
+}
+
+type Presence struct {
+    Username string    `json:"username"`
+    Mode     string    `json:"mode"` // viewing, editing
+    LastSeen time.Time `json:"last_seen"`
+}
+
+type PresenceEvent struct {
+    Type      string    `json:"type"` // presence, leave, lock, unlock, takeover
+    ContentID uint      `json:"content_id"`
+    Username  string    `json:"username"`
+    Mode      string    `json:"mode,omitempty"`
+    Previous  string    `json:"previous,omitempty"` // dueño anterior en un takeover
+    At        time.Time `json:"at"`
+}
+
+// LockService gestiona los locks de edición (blandos: no bloquean las
+// escrituras, de eso se encarga la revisión) y la presencia de usuarios en
+// cada contenido. Los cambios se publican en Redis para el feed en vivo.
+type LockService struct {
+    db     *gorm.DB
+    client *redis.Client
+    ttl    time.Duration
+}
+
+// NewLockService usa CMS_LOCK_TTL (por defecto 60s) como duración de los
+// locks y de la presencia sin heartbeat.
+func NewLockService(db *gorm.DB, client *redis.Client) *LockService {
+    ttl := 60 * time.Second
+    if value := os.Getenv("CMS_LOCK_TTL"); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+            ttl = parsed
+        }
+    }
+
+    return &LockService{
+        db:     db,
+        client: client,
+        ttl:    ttl,
+    }
+}
+
+func lockKey(contentID uint) string {
+    return fmt.Sprintf("cms:lock:content:%d", contentID)
+}
+
+func presenceKey(contentID uint) string {
+    return fmt.Sprintf("cms:presence:content:%d", contentID)
+}
+
+// Acquire toma el lock o lo renueva si ya es del usuario. Si lo tiene otro
+// devuelve LockedError.
+func (s *LockService) Acquire(ctx context.Context, contentID uint, username string) (*EditLock, error) {
+    now := time.Now()
+    owner, err := acquireEditLock.Run(ctx, s.client, []string{lockKey(contentID)},
+        username, now.UnixMilli(), s.ttl.Milliseconds()).Text()
+    if err != nil {
+        return nil, err
+    }
+
+    lock, err := s.Get(ctx, contentID)
+    if err != nil {
+        return nil, err
+    }
+    if lock == nil {
+        return nil, ErrLockNotHeld
+    }
+    if owner != username {
+        return nil, &LockedError{Lock: lock}
+    }
+
+    if err := s.touch(ctx, contentID, username, PresenceEditing); err != nil {
+        return nil, err
+    }
+    s.publish(ctx, PresenceEvent{Type: EventLock, ContentID: contentID, Username: username, Mode: PresenceEditing, At: now})
+    return lock, nil
+}
+
+// Heartbeat extiende el lock y la presencia del usuario.
+func (s *LockService) Heartbeat(ctx context.Context, contentID uint, username string) (*EditLock, error) {
+    extended, err := heartbeatEditLock.Run(ctx, s.client, []string{lockKey(contentID)},
+        username, s.ttl.Milliseconds()).Int()
+    if err != nil {
+        return nil, err
+    }
+    if extended == 0 {
+        return nil, ErrLockNotHeld
+    }
+
+    if err := s.touch(ctx, contentID, username, PresenceEditing); err != nil {
+        return nil, err
+    }
+    return s.Get(ctx, contentID)
+}
+
+func (s *LockService) Release(ctx context.Context, contentID uint, username string) error {
+    released, err := releaseEditLock.Run(ctx, s.client, []string{lockKey(contentID)}, username).Int()
+    if err != nil {
+        return err
+    }
+    if released == 0 {
+        return ErrLockNotHeld
+    }
+
+    // Sigue viendo el contenido aunque ya no lo edite
+    if err := s.touch(ctx, contentID, username, PresenceViewing); err != nil {
+        return err
+    }
+    s.publish(ctx, PresenceEvent{Type: EventUnlock, ContentID: contentID, Username: username, At: time.Now()})
+    return nil
+}
+
+// Takeover quita el lock a su dueño actual. Solo para admins.
+func (s *LockService) Takeover(ctx context.Context, contentID uint, username string) (*EditLock, error) {
+    var user models.User
+    if err := s.db.WithContext(ctx).Select("role").Where("username = ?", username).First(&user).Error; err != nil {
+        return nil, err
+    }
+    if user.Role != models.RoleAdmin {
+        return nil, ErrTakeoverForbidden
+    }
+
+    now := time.Now()
+    previous, err := takeoverEditLock.Run(ctx, s.client, []string{lockKey(contentID)},
+        username, now.UnixMilli(), s.ttl.Milliseconds()).Text()
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.touch(ctx, contentID, username, PresenceEditing); err != nil {
+        return nil, err
+    }
+    s.publish(ctx, PresenceEvent{Type: EventTakeover, ContentID: contentID, Username: username, Mode: PresenceEditing, Previous: previous, At: now})
+    return s.Get(ctx, contentID)
+}
+
+// Get devuelve el lock actual o nil si el contenido está libre.
+func (s *LockService) Get(ctx context.Context, contentID uint) (*EditLock, error) {
+    key := lockKey(contentID)
+    values, err := s.client.HGetAll(ctx, key).Result()
+    if err != nil {
+        return nil, err
+    }
+    if values["username"] == "" {
+        return nil, nil
+    }
+
+    ttl, err := s.client.PTTL(ctx, key).Result()
+    if err != nil {
+        return nil, err
+    }
+    acquiredAt, _ := strconv.ParseInt(values["acquired_at"], 10, 64)
+
+    return &EditLock{
+        ContentID:  contentID,
+        Username:   values["username"],
+        AcquiredAt: time.UnixMilli(acquiredAt),
+        ExpiresAt:  time.Now().Add(ttl),
+    }, nil
+}
+
+// Touch registra que el usuario está viendo o editando el contenido. Los
+// clientes lo llaman periódicamente mientras tengan la pantalla abierta.
+func (s *LockService) Touch(ctx context.Context, contentID uint, username, mode string) error {
+    if mode != PresenceViewing && mode != PresenceEditing {
+        return ErrInvalidPresence
+    }
+    return s.touch(ctx, contentID, username, mode)
+}
+
+func (s *LockService) touch(ctx context.Context, contentID uint, username, mode string) error {
+    now := time.Now()
+    data, err := json.Marshal(Presence{Username: username, Mode: mode, LastSeen: now})
+    if err != nil {
+        return err
+    }
+
+    key := presenceKey(contentID)
+    previous, err := s.client.HGet(ctx, key, username).Result()
+    if err != nil && err != redis.Nil {
+        return err
+    }
+
+    pipe := s.client.TxPipeline()
+    pipe.HSet(ctx, key, username, data)
+    pipe.PExpire(ctx, key, s.ttl)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return err
+    }
+
+    // Solo se notifica cuando el usuario llega o cambia de modo, no en cada heartbeat
+    var last Presence
+    if previous == "" || json.Unmarshal([]byte(previous), &last) != nil || last.Mode != mode || now.Sub(last.LastSeen) > s.ttl {
+        s.publish(ctx, PresenceEvent{Type: EventPresence, ContentID: contentID, Username: username, Mode: mode, At: now})
+    }
+    return nil
+}
+
+// Leave quita la presencia del usuario y libera su lock si lo tenía.
+func (s *LockService) Leave(ctx context.Context, contentID uint, username string) error {
+    if _, err := releaseEditLock.Run(ctx, s.client, []string{lockKey(contentID)}, username).Result(); err != nil {
+        return err
+    }
+    if err := s.client.HDel(ctx, presenceKey(contentID), username).Err(); err != nil {
+        return err
+    }
+
+    s.publish(ctx, PresenceEvent{Type: EventLeave, ContentID: contentID, Username: username, At: time.Now()})
+    return nil
+}
+
+// Presence lista los usuarios activos en el contenido y limpia los que
+// dejaron de enviar heartbeats.
+func (s *LockService) Presence(ctx context.Context, contentID uint) ([]Presence, error) {
+    key := presenceKey(contentID)
+    values, err := s.client.HGetAll(ctx, key).Result()
+    if err != nil {
+        return nil, err
+    }
+
+    active, stale := activePresence(values, time.Now().Add(-s.ttl))
+    if len(stale) > 0 {
+        s.client.HDel(ctx, key, stale...)
+    }
+    return active, nil
+}
+
+func activePresence(values map[string]string, since time.Time) ([]Presence, []string) {
+    active := []Presence{}
+    var stale []string
+    for username, value := range values {
+        var presence Presence
+        if err := json.Unmarshal([]byte(value), &presence); err != nil || presence.LastSeen.Before(since) {
+            stale = append(stale, username)
+            continue
+        }
+        active = append(active, presence)
+    }
+    sort.Slice(active, func(i, j int) bool { return active[i].Username < active[j].Username })
+    return active, stale
+}
+
+// Subscribe devuelve los eventos de presencia de todas las réplicas. El
+// canal se cierra al llamar a la función devuelta o al cancelar ctx.
+func (s *LockService) Subscribe(ctx context.Context) (<-chan PresenceEvent, func(), error) {
+    pubsub := s.client.Subscribe(ctx, presenceChannel)
+    if _, err := pubsub.Receive(ctx); err != nil {
+        pubsub.Close()
+        return nil, nil, err
+    }
+
+    events := make(chan PresenceEvent)
+    go func() {
+        defer close(events)
+        for message := range pubsub.Channel() {
+            var event PresenceEvent
+            if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+                continue
+            }
+            select {
+            case events <- event:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+
+    return events, func() { pubsub.Close() }, nil
+}
+
+// publish es best effort: perder un evento solo retrasa el indicador hasta
+// la siguiente consulta de presencia
+func (s *LockService) publish(ctx context.Context, event PresenceEvent) {
+    data, err := json.Marshal(event)
+    if err != nil {
+        return
+    }
+    s.client.Publish(ctx, presenceChannel, data)
+}
@@ -0,0 +1,28 @@
+// pkg/cms/lock_service_test.go
+package cms
+
+import (
+    "encoding/json"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestActivePresence(t *testing.T) {
+    now := time.Now()
+    fresh, _ := json.Marshal(Presence{Username: "editor", Mode: PresenceEditing, LastSeen: now})
+    viewer, _ := json.Marshal(Presence{Username: "author", Mode: PresenceViewing, LastSeen: now.Add(-10 * time.Second)})
+    old, _ := json.Marshal(Presence{Username: "gone", Mode: PresenceViewing, LastSeen: now.Add(-5 * time.Minute)})
+
+    active, stale := activePresence(map[string]string{
+        "editor":  string(fresh),
+        "author":  string(viewer),
+        "gone":    string(old),
+        "corrupt": "{",
+    }, now.Add(-time.Minute))
+
+    assert.Len(t, active, 2)
+    assert.Equal(t, "author", active[0].Username)
+    assert.Equal(t, "editor", active[1].Username)
+    assert.ElementsMatch(t, []string{"gone", "corrupt"}, stale)
+}
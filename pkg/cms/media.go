@@ -1,11 +1,112 @@
+// pkg/cms/media.go
 package cms
 
+import (
+    "context"
+    "io"
+    "path/filepath"
+    "gorm.io/gorm"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/query"
+    "ezzygo/pkg/storage"
+)
+
+type MediaService struct {
+    db      *gorm.DB
+    storage *storage.S3Storage
+    outbox  *events.Outbox
+}
+
+func NewMediaService(db *gorm.DB, storage *storage.S3Storage, outbox *events.Outbox) *MediaService {
+    return &MediaService{
+        db:      db,
+        storage: storage,
+        outbox:  outbox,
+    }
+}
+
+func (s *MediaService) Upload(ctx context.Context, media *Media, file io.Reader) error {
+    url, err := s.storage.Upload(ctx, media.Name, file, media.MimeType)
+    if err != nil {
+        return err
+    }
+
+    media.URL = url
+    media.Path = filepath.Base(url)
+
+    var event *events.Event
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        if err := tx.Create(media).Error; err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, EventMediaCreated, media.ID, media)
+        return err
+    })
+    if err != nil {
+        return err
+    }
+
+    s.outbox.Relay(ctx, event)
+    return nil
+}
+
+func (s *MediaService) Get(ctx context.Context, id uint) (*Media, error) {
+    var media Media
+    if err := s.db.WithContext(ctx).First(&media, id).Error; err != nil {
+        return nil, err
+    }
+    return &media, nil
+}
+
+func (s *MediaService) List(ctx context.Context, params query.Params) (*query.Page[Media], error) {
+    return query.Find[Media](ctx, s.db, params, MediaQuery)
+}
+
+func (s *MediaService) Delete(ctx context.Context, id uint) error {
+    var media Media
+    if err := s.db.WithContext(ctx).First(&media, id).Error; err != nil {
+        return err
+    }
+
+    if err := s.storage.Delete(ctx, media.Path); err != nil {
+        return err
+    }
+
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        if err := tx.Delete(&media).Error; err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, EventMediaDeleted, media.ID, &media)
+        return err
+    })
+    if err != nil {
+        return err
+    }
+
+    s.outbox.Relay(ctx, event)
+    return nil
+}
+
 type Media struct {
     gorm.Model
-    Name      string `json:"name"`
-    Type      string `json:"type"`
-    URL       string `json:"url"`
-    Size      int64  `json:"size"`
-    Path      string `json:"path"`
-    MimeType  string `json:"mime_type"`
+    Name     string `json:"name"`
+    Type     string `json:"type"`
+    URL      string `json:"url"`
+    Size     int64  `json:"size"`
+    Path     string `json:"path"`
+    MimeType string `json:"mime_type"`
+}
+
+// Campos por los que se puede filtrar y ordenar el listado de medios
+var MediaQuery = query.Schema{
+    Fields: map[string]query.Field{
+        "id":         {Column: "id", Type: query.Int, Sortable: true},
+        "name":       {Column: "name", Type: query.String, Sortable: true},
+        "type":       {Column: "type", Type: query.String},
+        "mime_type":  {Column: "mime_type", Type: query.String},
+        "size":       {Column: "size", Type: query.Int, Sortable: true},
+        "created_at": {Column: "created_at", Type: query.Time, Sortable: true},
+    },
+    DefaultSort: []query.Sort{{Field: "created_at", Desc: true}},
 }
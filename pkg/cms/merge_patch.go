@@ -0,0 +1,130 @@
+// pkg/cms/merge_patch.go
+package cms
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+)
+
+var (
+    ErrInvalidPatch  = errors.New("invalid merge patch")
+    ErrStaleRevision = errors.New("stale revision")
+)
+
+// RevisionMismatchError se devuelve cuando el cliente edita sobre una revisión
+// que ya no es la actual. Current permite al cliente recargar y reintentar.
+type RevisionMismatchError struct {
+    Current int
+}
+
+func (e *RevisionMismatchError) Error() string {
+    return fmt.Sprintf("stale revision, current revision is %d", e.Current)
+}
+
+func (e *RevisionMismatchError) Is(target error) bool {
+    return target == ErrStaleRevision
+}
+
+// MergePatch aplica un JSON Merge Patch (RFC 7396) sobre un documento JSON:
+// los objetos se combinan recursivamente, null borra la clave y cualquier
+// otro valor reemplaza al original.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+    var target interface{}
+    if err := decodeJSON(doc, &target); err != nil {
+        return nil, err
+    }
+
+    var changes interface{}
+    if err := decodeJSON(patch, &changes); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+
+    return json.Marshal(mergeValue(target, changes))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+    patchObject, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+
+    targetObject, ok := target.(map[string]interface{})
+    if !ok {
+        targetObject = map[string]interface{}{}
+    }
+
+    for key, value := range patchObject {
+        if value == nil {
+            delete(targetObject, key)
+            continue
+        }
+        targetObject[key] = mergeValue(targetObject[key], value)
+    }
+    return targetObject
+}
+
+// decodeJSON conserva los números tal cual para no perder precisión en IDs
+func decodeJSON(data []byte, v interface{}) error {
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.UseNumber()
+    return decoder.Decode(v)
+}
+
+// applyMergePatch aplica el patch sobre entity (un puntero a un modelo) y
+// deja el resultado en entity.
+func applyMergePatch(entity interface{}, patch []byte) error {
+    doc, err := json.Marshal(entity)
+    if err != nil {
+        return err
+    }
+
+    patched, err := MergePatch(doc, patch)
+    if err != nil {
+        return err
+    }
+
+    // Se decodifica sobre un valor nuevo para que las claves borradas queden
+    // a cero en lugar de conservar el valor anterior
+    result := reflect.New(reflect.TypeOf(entity).Elem())
+    if err := json.Unmarshal(patched, result.Interface()); err != nil {
+        return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+    reflect.ValueOf(entity).Elem().Set(result.Elem())
+    return nil
+}
+
+// applyReplace aplica el cuerpo de un PUT sobre entity: cada clave que trae
+// sustituye entera a la guardada, sin mezclar objetos anidados como un merge
+// patch, y las que no trae se mantienen.
+func applyReplace(entity interface{}, body []byte) error {
+    var fields map[string]json.RawMessage
+    if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+        return fmt.Errorf("%w: body must be a JSON object", ErrInvalidPatch)
+    }
+
+    doc, err := json.Marshal(entity)
+    if err != nil {
+        return err
+    }
+    var current map[string]json.RawMessage
+    if err := json.Unmarshal(doc, &current); err != nil {
+        return err
+    }
+    for key, value := range fields {
+        current[key] = value
+    }
+    replaced, err := json.Marshal(current)
+    if err != nil {
+        return err
+    }
+
+    result := reflect.New(reflect.TypeOf(entity).Elem())
+    if err := json.Unmarshal(replaced, result.Interface()); err != nil {
+        return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+    reflect.ValueOf(entity).Elem().Set(result.Elem())
+    return nil
+}
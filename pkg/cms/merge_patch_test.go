@@ -0,0 +1,64 @@
+// pkg/cms/merge_patch_test.go
+package cms
+
+import (
+    "errors"
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+    doc := []byte(`{"title":"Hello","meta":{"a":1,"b":2},"tags":["x","y"]}`)
+    patch := []byte(`{"title":"Bye","meta":{"b":null,"c":3},"tags":["z"]}`)
+
+    result, err := MergePatch(doc, patch)
+
+    assert.NoError(t, err)
+    assert.JSONEq(t, `{"title":"Bye","meta":{"a":1,"c":3},"tags":["z"]}`, string(result))
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+    _, err := MergePatch([]byte(`{}`), []byte(`{"title":`))
+
+    assert.True(t, errors.Is(err, ErrInvalidPatch))
+}
+
+func TestApplyMergePatch(t *testing.T) {
+    content := Content{Title: "Hello", Slug: "hello", Content: "body", Revision: 2}
+    content.ID = 7
+
+    err := applyMergePatch(&content, []byte(`{"title":"Patched","content":null}`))
+
+    assert.NoError(t, err)
+    assert.Equal(t, "Patched", content.Title)
+    assert.Equal(t, "hello", content.Slug)
+    assert.Equal(t, "", content.Content)
+    assert.Equal(t, uint(7), content.ID)
+
+    err = applyMergePatch(&content, []byte(`{"revision":"x"}`))
+    assert.True(t, errors.Is(err, ErrInvalidPatch))
+}
+
+func TestApplyReplace(t *testing.T) {
+    template := Template{Name: "post", Type: "post", Fields: []byte(`{"a":1,"b":2}`), Version: 4}
+    template.ID = 3
+
+    // Los objetos anidados se sustituyen enteros y lo que no viene se mantiene
+    err := applyReplace(&template, []byte(`{"fields":{"c":3}}`))
+
+    assert.NoError(t, err)
+    assert.JSONEq(t, `{"c":3}`, string(template.Fields))
+    assert.Equal(t, "post", template.Name)
+    assert.Equal(t, 4, template.Version)
+    assert.Equal(t, uint(3), template.ID)
+
+    err = applyReplace(&template, []byte(`["fields"]`))
+    assert.True(t, errors.Is(err, ErrInvalidPatch))
+}
+
+func TestRevisionMismatchError(t *testing.T) {
+    var err error = &RevisionMismatchError{Current: 3}
+
+    assert.True(t, errors.Is(err, ErrStaleRevision))
+    assert.Contains(t, err.Error(), "3")
+}
@@ -0,0 +1,72 @@
+// pkg/cms/migrate.go
+package cms
+
+import (
+    "ezzygo/pkg/events"
+    "gorm.io/gorm"
+)
+
+// searchMigrations crea las columnas tsvector y los índices de búsqueda y
+// autocompletado, que AutoMigrate no sabe declarar. Son idempotentes.
+var searchMigrations = []string{
+    // Configuración de text search según el idioma del contenido. Se declara
+    // IMMUTABLE para poder usarla en columnas generadas.
+    `CREATE OR REPLACE FUNCTION cms_search_config(locale text) RETURNS regconfig AS $$
+        SELECT CASE split_part(lower(coalesce(locale, '')), '-', 1)
+            WHEN 'en' THEN 'english'::regconfig
+            WHEN 'es' THEN 'spanish'::regconfig
+            WHEN 'pt' THEN 'portuguese'::regconfig
+            WHEN 'fr' THEN 'french'::regconfig
+            WHEN 'de' THEN 'german'::regconfig
+            WHEN 'it' THEN 'italian'::regconfig
+            WHEN 'nl' THEN 'dutch'::regconfig
+            ELSE 'simple'::regconfig
+        END
+    $$ LANGUAGE sql IMMUTABLE`,
+    `ALTER TABLE contents ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector(cms_search_config(locale), coalesce(title, '')), 'A') ||
+        setweight(to_tsvector(cms_search_config(locale), coalesce(content, '')), 'B')
+    ) STORED`,
+    `CREATE INDEX IF NOT EXISTS idx_contents_search_vector ON contents USING GIN (search_vector)`,
+    `CREATE INDEX IF NOT EXISTS idx_contents_tags ON contents USING GIN (tags)`,
+    `ALTER TABLE templates ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+    ) STORED`,
+    `CREATE INDEX IF NOT EXISTS idx_templates_search_vector ON templates USING GIN (search_vector)`,
+    // Índices de trigramas para el autocompletado con tolerancia a erratas
+    `CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+    `CREATE INDEX IF NOT EXISTS idx_contents_title_trgm ON contents USING GIN (title gin_trgm_ops)`,
+    `CREATE INDEX IF NOT EXISTS idx_templates_name_trgm ON templates USING GIN (name gin_trgm_ops)`,
+}
+
+// AutoMigrate crea o actualiza las tablas del CMS.
+func AutoMigrate(db *gorm.DB) error {
+    err := db.AutoMigrate(
+        &Content{},
+        &Template{},
+        &Media{},
+        &Version{},
+        &ScheduledPublication{},
+        &ContentTransition{},
+        &SearchSynonym{},
+        &Webhook{},
+        &WebhookDelivery{},
+        &events.Event{},
+        &StaticPage{},
+        &Redirect{},
+        &WXRImportJob{},
+        &WXRImportItem{},
+        &Promotion{},
+    )
+    if err != nil {
+        return err
+    }
+
+    for _, statement := range searchMigrations {
+        if err := db.Exec(statement).Error; err != nil {
+            return err
+        }
+    }
+    return nil
+}
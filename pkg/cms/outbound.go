@@ -0,0 +1,96 @@
+// pkg/cms/outbound.go
+package cms
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "net/netip"
+    "net/url"
+    "os"
+    "strings"
+    "syscall"
+    "time"
+)
+
+// ErrPrivateAddress es el error de una petición saliente (webhooks, adjuntos
+// de WordPress) a una dirección que no es pública: loopback, red privada,
+// link-local (metadatos de la nube) o reservada.
+var ErrPrivateAddress = errors.New("destination address is not public")
+
+// Rangos reservados que netip no clasifica
+var reservedPrefixes = []netip.Prefix{
+    netip.MustParsePrefix("0.0.0.0/8"),
+    netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+    netip.MustParsePrefix("192.0.0.0/24"),
+    netip.MustParsePrefix("198.18.0.0/15"),
+    netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+    addr = addr.Unmap()
+    if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+        addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsMulticast() {
+        return false
+    }
+    for _, prefix := range reservedPrefixes {
+        if prefix.Contains(addr) {
+            return false
+        }
+    }
+    return true
+}
+
+// allowPrivateURLs lee CMS_ALLOW_PRIVATE_URLS, para desarrollo local con
+// receptores en localhost.
+func allowPrivateURLs() bool {
+    return os.Getenv("CMS_ALLOW_PRIVATE_URLS") == "true"
+}
+
+// checkPublicURL rechaza al guardarla una URL cuyo host es una IP no pública
+// o localhost. Un nombre puede resolver a otra dirección en cada consulta, así
+// que la comprobación que cuenta es la del dial (newOutboundClient).
+func checkPublicURL(target *url.URL) error {
+    if allowPrivateURLs() {
+        return nil
+    }
+    host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+    if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+        return ErrPrivateAddress
+    }
+    if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+        return ErrPrivateAddress
+    }
+    return nil
+}
+
+// newOutboundClient es el cliente HTTP de las peticiones a URLs que da un
+// usuario. Comprueba la IP ya resuelta de cada conexión, también las de las
+// redirecciones, así que un DNS que cambia tras validar la URL no sirve para
+// llegar a la red interna. No usa el proxy del entorno: la conexión sería con
+// él y la comprobación no vería el destino.
+func newOutboundClient(timeout time.Duration) *http.Client {
+    allowPrivate := allowPrivateURLs()
+    dialer := &net.Dialer{
+        Timeout: 10 * time.Second,
+        Control: func(network, address string, _ syscall.RawConn) error {
+            if allowPrivate {
+                return nil
+            }
+            host, _, err := net.SplitHostPort(address)
+            if err != nil {
+                return err
+            }
+            if addr, err := netip.ParseAddr(host); err != nil || !isPublicAddr(addr) {
+                return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+            }
+            return nil
+        },
+    }
+
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.Proxy = nil
+    transport.DialContext = dialer.DialContext
+    return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,33 @@
+// pkg/cms/outbound_test.go
+package cms
+
+import (
+    "net/netip"
+    "net/url"
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+    for _, ip := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946", "8.8.8.8"} {
+        assert.True(t, isPublicAddr(netip.MustParseAddr(ip)), ip)
+    }
+    for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0",
+        "100.64.0.1", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::"} {
+        assert.False(t, isPublicAddr(netip.MustParseAddr(ip)), ip)
+    }
+}
+
+func TestCheckPublicURL(t *testing.T) {
+    parse := func(raw string) *url.URL {
+        target, _ := url.Parse(raw)
+        return target
+    }
+    assert.NoError(t, checkPublicURL(parse("https://example.com/hook")))
+    assert.ErrorIs(t, checkPublicURL(parse("http://LOCALHOST./")), ErrPrivateAddress)
+    assert.ErrorIs(t, checkPublicURL(parse("http://api.localhost/")), ErrPrivateAddress)
+    assert.ErrorIs(t, checkPublicURL(parse("http://[::ffff:10.0.0.1]:8080/")), ErrPrivateAddress)
+
+    t.Setenv("CMS_ALLOW_PRIVATE_URLS", "true")
+    assert.NoError(t, checkPublicURL(parse("http://localhost:8080/")))
+}
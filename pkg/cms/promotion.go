@@ -0,0 +1,428 @@
+// pkg/cms/promotion.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "ezzygo/pkg/auth"
+    "gorm.io/gorm"
+)
+
+// Resultado de una promoción en el registro de auditoría.
+const (
+    PromotionCompleted = "completed"
+    PromotionFailed    = "failed"
+)
+
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
+// PromotionRequest es lo que se promociona de un entorno a otro, por ids del
+// origen. Sus dependencias (plantillas y medios) se añaden solas.
+type PromotionRequest struct {
+    Source       string `json:"source"`
+    Target       string `json:"target"`
+    ContentIDs   []uint `json:"content_ids"`
+    TemplateIDs  []uint `json:"template_ids"`
+    MediaIDs     []uint `json:"media_ids"`
+    Translations bool   `json:"translations"` // incluye las demás traducciones del contenido
+    Conflict     string `json:"conflict"`     // overwrite (por defecto), skip o fail
+}
+
+// PromotionDependency es un elemento que no se pidió pero se promociona
+// porque lo necesita lo seleccionado. RequiredBy son las claves (tipo:clave)
+// de quien lo usa.
+type PromotionDependency struct {
+    Kind       string   `json:"kind"`
+    SourceID   uint     `json:"source_id"`
+    Key        string   `json:"key"`
+    RequiredBy []string `json:"required_by"`
+}
+
+// PromotionPreview es el diff de una promoción: lo que se crearía o
+// actualizaría en destino, sin escribir nada.
+type PromotionPreview struct {
+    Source       string                `json:"source"`
+    Target       string                `json:"target"`
+    Dependencies []PromotionDependency `json:"dependencies"`
+    MediaStorage string                `json:"media_storage,omitempty"` // donde siguen los ficheros de los medios promocionados
+    Report       *ImportReport         `json:"report"`
+}
+
+// Promotion es el registro de auditoría de una promoción: quién movió qué y
+// de dónde a dónde. Se guarda en la base de datos de este despliegue y, si
+// el destino es otro entorno, también en la suya, para que su registro
+// muestre lo que recibió. Se guarda también cuando falla.
+type Promotion struct {
+    ID           uint            `json:"id" gorm:"primarykey"`
+    Source       string          `json:"source" gorm:"size:64;index"`
+    Target       string          `json:"target" gorm:"size:64;index"`
+    Status       string          `json:"status" gorm:"size:16"`
+    Request      json.RawMessage `json:"request" gorm:"type:jsonb"`
+    Dependencies json.RawMessage `json:"dependencies" gorm:"type:jsonb"`
+    Created      int             `json:"created"`
+    Updated      int             `json:"updated"`
+    Skipped      int             `json:"skipped"`
+    Unchanged    int             `json:"unchanged"`
+    Report       json.RawMessage `json:"report,omitempty" gorm:"type:jsonb"` // el ImportReport en destino
+    Error        string          `json:"error,omitempty"`
+    PromotedBy   string          `json:"promoted_by" gorm:"size:255;index"`
+    CreatedAt    time.Time       `json:"created_at"`
+}
+
+// PromotionFilter filtra el registro por entorno (origen o destino) y
+// usuario.
+type PromotionFilter struct {
+    Environment string
+    PromotedBy  string
+}
+
+// PromotionService mueve contenido, plantillas y medios entre entornos con
+// un bundle: exporta la selección y sus dependencias del origen y la importa
+// en destino. De los medios solo se copian los metadatos: sus ficheros tienen
+// que estar en el almacenamiento compartido (CMS_SHARED_MEDIA_URL).
+type PromotionService struct {
+    db           *gorm.DB // el de este despliegue, para la auditoría
+    environments *Environments
+}
+
+func NewPromotionService(db *gorm.DB, environments *Environments) *PromotionService {
+    return &PromotionService{db: db, environments: environments}
+}
+
+func (s *PromotionService) Environments() []Environment {
+    return s.environments.List()
+}
+
+// Preview importa en destino con dry-run: el informe trae el diff de cada
+// elemento frente a lo que hay allí.
+func (s *PromotionService) Preview(ctx context.Context, req PromotionRequest) (*PromotionPreview, error) {
+    bundle, dependencies, target, err := s.prepare(ctx, &req)
+    if err != nil {
+        return nil, err
+    }
+    report, err := target.Import(ctx, bundle, s.importOptions(&req, true))
+    if err != nil {
+        return nil, err
+    }
+    preview := &PromotionPreview{Source: req.Source, Target: req.Target, Dependencies: dependencies, Report: report}
+    if len(bundle.Media) > 0 {
+        preview.MediaStorage = s.environments.SharedMedia()
+    }
+    return preview, nil
+}
+
+// Promote aplica la promoción y la registra. Un fallo de la importación
+// también queda registrado antes de devolver el error; los de validación
+// no.
+func (s *PromotionService) Promote(ctx context.Context, req PromotionRequest) (*Promotion, error) {
+    bundle, dependencies, target, err := s.prepare(ctx, &req)
+    if err != nil {
+        return nil, err
+    }
+
+    promotion := &Promotion{
+        Source:     req.Source,
+        Target:     req.Target,
+        PromotedBy: auth.UsernameFromContext(ctx),
+    }
+    if promotion.Request, err = json.Marshal(req); err != nil {
+        return nil, err
+    }
+    if promotion.Dependencies, err = json.Marshal(dependencies); err != nil {
+        return nil, err
+    }
+
+    report, importErr := target.Import(ctx, bundle, s.importOptions(&req, false))
+    if importErr != nil {
+        promotion.Status = PromotionFailed
+        promotion.Error = importErr.Error()
+    } else {
+        promotion.Status = PromotionCompleted
+        promotion.Created = report.Created
+        promotion.Updated = report.Updated
+        promotion.Skipped = report.Skipped
+        promotion.Unchanged = report.Unchanged
+        if promotion.Report, err = json.Marshal(report); err != nil {
+            return nil, err
+        }
+    }
+
+    if err := s.record(ctx, req.Target, target, promotion); err != nil {
+        return nil, errors.Join(importErr, err)
+    }
+    if importErr != nil {
+        return nil, importErr
+    }
+    return promotion, nil
+}
+
+// record guarda la auditoría aquí y en el destino si es otro entorno. Los ids
+// de cada base de datos son independientes; la promoción devuelta es la de
+// este despliegue.
+func (s *PromotionService) record(ctx context.Context, name string, target *BundleService, promotion *Promotion) error {
+    if err := s.db.WithContext(ctx).Create(promotion).Error; err != nil {
+        return err
+    }
+    if name == s.environments.current {
+        return nil
+    }
+    remote := *promotion
+    remote.ID = 0
+    if err := target.db.WithContext(ctx).Create(&remote).Error; err != nil {
+        return fmt.Errorf("record promotion in %s: %w", name, err)
+    }
+    return nil
+}
+
+func (s *PromotionService) List(ctx context.Context, filter PromotionFilter) ([]Promotion, error) {
+    scope := s.db.WithContext(ctx).Order("id DESC").Limit(100)
+    if filter.Environment != "" {
+        scope = scope.Where("source = ? OR target = ?", filter.Environment, filter.Environment)
+    }
+    if filter.PromotedBy != "" {
+        scope = scope.Where("promoted_by = ?", filter.PromotedBy)
+    }
+    var promotions []Promotion
+    err := scope.Find(&promotions).Error
+    return promotions, err
+}
+
+func (s *PromotionService) Get(ctx context.Context, id uint) (*Promotion, error) {
+    var promotion Promotion
+    if err := s.db.WithContext(ctx).First(&promotion, id).Error; err != nil {
+        return nil, err
+    }
+    return &promotion, nil
+}
+
+// prepare valida la petición y exporta del origen la selección con sus
+// dependencias.
+func (s *PromotionService) prepare(ctx context.Context, req *PromotionRequest) (*Bundle, []PromotionDependency, *BundleService, error) {
+    if req.Source == "" || req.Target == "" {
+        return nil, nil, nil, fmt.Errorf("%w: source and target are required", ErrInvalidPromotion)
+    }
+    if req.Source == req.Target {
+        return nil, nil, nil, fmt.Errorf("%w: source and target are the same environment", ErrInvalidPromotion)
+    }
+    if len(req.ContentIDs) == 0 && len(req.TemplateIDs) == 0 && len(req.MediaIDs) == 0 {
+        return nil, nil, nil, fmt.Errorf("%w: nothing selected", ErrInvalidPromotion)
+    }
+    if req.Conflict == "" {
+        req.Conflict = ConflictOverwrite
+    }
+
+    source, err := s.environments.Bundles(req.Source)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+    target, err := s.environments.Bundles(req.Target)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+
+    bundle, err := source.exportSelection(ctx, req)
+    if err != nil {
+        return nil, nil, nil, err
+    }
+    if err := checkSharedMedia(s.environments.SharedMedia(), bundle.Media); err != nil {
+        return nil, nil, nil, err
+    }
+    return bundle, promotionDependencies(req, bundle), target, nil
+}
+
+// checkSharedMedia exige que los ficheros de los medios estén en el
+// almacenamiento compartido: la promoción copia sus metadatos, así que su URL
+// tiene que seguir valiendo en destino.
+func checkSharedMedia(prefix string, media []Media) error {
+    if len(media) == 0 {
+        return nil
+    }
+    if prefix == "" {
+        return fmt.Errorf("%w: media can only be promoted between environments with shared storage (CMS_SHARED_MEDIA_URL)", ErrInvalidPromotion)
+    }
+    var outside []string
+    for _, item := range media {
+        if !strings.HasPrefix(item.URL, prefix) {
+            outside = append(outside, item.URL)
+        }
+    }
+    if len(outside) > 0 {
+        return fmt.Errorf("%w: media outside the shared storage %s: %s", ErrInvalidPromotion, prefix, strings.Join(outside, ", "))
+    }
+    return nil
+}
+
+func (s *PromotionService) importOptions(req *PromotionRequest, dryRun bool) ImportOptions {
+    return ImportOptions{
+        DryRun:   dryRun,
+        Conflict: req.Conflict,
+        Comment:  "promotion from " + req.Source,
+    }
+}
+
+// exportSelection exporta lo seleccionado más su cierre de dependencias: la
+// plantilla y la imagen og del contenido, los medios cuya URL aparece en el
+// contenido o en las plantillas y, con Translations, el resto del grupo de
+// traducciones.
+func (s *BundleService) exportSelection(ctx context.Context, req *PromotionRequest) (*Bundle, error) {
+    db := s.db.WithContext(ctx)
+    bundle := &Bundle{Version: BundleVersion, ExportedAt: time.Now().UTC()}
+
+    if len(req.ContentIDs) > 0 {
+        if err := db.Where("id IN ?", req.ContentIDs).Find(&bundle.Content).Error; err != nil {
+            return nil, err
+        }
+        if err := checkSelected(BundleContent, req.ContentIDs, bundle.Content, func(c Content) uint { return c.ID }); err != nil {
+            return nil, err
+        }
+    }
+    if req.Translations && len(bundle.Content) > 0 {
+        var groups []uint
+        for _, content := range bundle.Content {
+            if content.TranslationGroupID != 0 {
+                groups = append(groups, content.TranslationGroupID)
+            }
+        }
+        var translations []Content
+        if len(groups) > 0 {
+            if err := db.Where("translation_group_id IN ? AND id NOT IN ?", groups, req.ContentIDs).Find(&translations).Error; err != nil {
+                return nil, err
+            }
+        }
+        bundle.Content = append(bundle.Content, translations...)
+    }
+    sort.Slice(bundle.Content, func(i, j int) bool { return bundle.Content[i].ID < bundle.Content[j].ID })
+    authors, err := bundleAuthors(db, bundle.Content)
+    if err != nil {
+        return nil, err
+    }
+    bundle.Authors = authors
+
+    templateIDs := append([]uint{}, req.TemplateIDs...)
+    for _, content := range bundle.Content {
+        if content.TemplateID != 0 {
+            templateIDs = append(templateIDs, content.TemplateID)
+        }
+    }
+    if len(templateIDs) > 0 {
+        if err := db.Where("id IN ?", templateIDs).Order("id").Find(&bundle.Templates).Error; err != nil {
+            return nil, err
+        }
+        if err := checkSelected(BundleTemplates, req.TemplateIDs, bundle.Templates, func(t Template) uint { return t.ID }); err != nil {
+            return nil, err
+        }
+    }
+
+    mediaIDs := append([]uint{}, req.MediaIDs...)
+    var texts []string
+    for _, content := range bundle.Content {
+        if content.OGImageID != nil {
+            mediaIDs = append(mediaIDs, *content.OGImageID)
+        }
+        texts = append(texts, content.Content, string(content.MetaData))
+    }
+    for _, template := range bundle.Templates {
+        texts = append(texts, template.Content)
+    }
+    if text := strings.Join(texts, "\n"); strings.TrimSpace(text) != "" {
+        var referenced []uint
+        if err := db.Model(&Media{}).Where("url <> '' AND strpos(?, url) > 0", text).Pluck("id", &referenced).Error; err != nil {
+            return nil, err
+        }
+        mediaIDs = append(mediaIDs, referenced...)
+    }
+    if len(mediaIDs) > 0 {
+        if err := db.Where("id IN ?", mediaIDs).Order("id").Find(&bundle.Media).Error; err != nil {
+            return nil, err
+        }
+        if err := checkSelected(BundleMedia, req.MediaIDs, bundle.Media, func(m Media) uint { return m.ID }); err != nil {
+            return nil, err
+        }
+    }
+    return bundle, nil
+}
+
+// checkSelected comprueba que todos los ids pedidos existen en el origen.
+func checkSelected[T any](kind string, ids []uint, items []T, id func(T) uint) error {
+    found := map[uint]bool{}
+    for _, item := range items {
+        found[id(item)] = true
+    }
+    for _, wanted := range ids {
+        if !found[wanted] {
+            return fmt.Errorf("%w: %s %d not found in source", ErrInvalidPromotion, kind, wanted)
+        }
+    }
+    return nil
+}
+
+// promotionDependencies lista lo que el bundle lleva sin haberse pedido y
+// quién lo necesita.
+func promotionDependencies(req *PromotionRequest, bundle *Bundle) []PromotionDependency {
+    selected := func(ids []uint, id uint) bool {
+        for _, candidate := range ids {
+            if candidate == id {
+                return true
+            }
+        }
+        return false
+    }
+    contentKey := func(content *Content) string {
+        return BundleContent + ":" + content.Locale + "/" + content.Slug
+    }
+
+    dependencies := []PromotionDependency{}
+    for _, template := range bundle.Templates {
+        if selected(req.TemplateIDs, template.ID) {
+            continue
+        }
+        dependency := PromotionDependency{Kind: BundleTemplates, SourceID: template.ID, Key: template.Name, RequiredBy: []string{}}
+        for n := range bundle.Content {
+            if bundle.Content[n].TemplateID == template.ID {
+                dependency.RequiredBy = append(dependency.RequiredBy, contentKey(&bundle.Content[n]))
+            }
+        }
+        dependencies = append(dependencies, dependency)
+    }
+
+    for _, media := range bundle.Media {
+        if selected(req.MediaIDs, media.ID) {
+            continue
+        }
+        dependency := PromotionDependency{Kind: BundleMedia, SourceID: media.ID, Key: media.URL, RequiredBy: []string{}}
+        for n := range bundle.Content {
+            content := &bundle.Content[n]
+            if (content.OGImageID != nil && *content.OGImageID == media.ID) || (media.URL != "" &&
+                (strings.Contains(content.Content, media.URL) || strings.Contains(string(content.MetaData), media.URL))) {
+                dependency.RequiredBy = append(dependency.RequiredBy, contentKey(content))
+            }
+        }
+        for _, template := range bundle.Templates {
+            if media.URL != "" && strings.Contains(template.Content, media.URL) {
+                dependency.RequiredBy = append(dependency.RequiredBy, BundleTemplates+":"+template.Name)
+            }
+        }
+        dependencies = append(dependencies, dependency)
+    }
+
+    for n := range bundle.Content {
+        translation := &bundle.Content[n]
+        if selected(req.ContentIDs, translation.ID) {
+            continue
+        }
+        dependency := PromotionDependency{Kind: BundleContent, SourceID: translation.ID, Key: translation.Locale + "/" + translation.Slug, RequiredBy: []string{}}
+        for m := range bundle.Content {
+            content := &bundle.Content[m]
+            if selected(req.ContentIDs, content.ID) && content.TranslationGroupID == translation.TranslationGroupID {
+                dependency.RequiredBy = append(dependency.RequiredBy, contentKey(content))
+            }
+        }
+        dependencies = append(dependencies, dependency)
+    }
+    return dependencies
+}
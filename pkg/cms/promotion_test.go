@@ -0,0 +1,103 @@
+// pkg/cms/promotion_test.go
+package cms
+
+import (
+    "errors"
+    "testing"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "gorm.io/gorm"
+)
+
+func TestParseEnvironments(t *testing.T) {
+    dsns, err := parseEnvironments(" staging=postgres://u:p@staging/cms?sslmode=disable ; production=postgres://u:p@prod/cms;")
+    require.NoError(t, err)
+    assert.Equal(t, map[string]string{
+        "staging":    "postgres://u:p@staging/cms?sslmode=disable",
+        "production": "postgres://u:p@prod/cms",
+    }, dsns)
+
+    dsns, err = parseEnvironments("")
+    require.NoError(t, err)
+    assert.Empty(t, dsns)
+
+    for _, value := range []string{"staging", "=postgres://x", "staging=", "a=x;a=y"} {
+        _, err := parseEnvironments(value)
+        assert.Error(t, err, value)
+    }
+}
+
+func TestEnvironments(t *testing.T) {
+    t.Setenv("CMS_ENVIRONMENT", "staging")
+    t.Setenv("CMS_ENVIRONMENTS", "staging=postgres://staging/cms;production=postgres://prod/cms")
+    t.Setenv("CMS_SHARED_MEDIA_URL", "https://media.test")
+    bundles := &BundleService{}
+    environments, err := NewEnvironmentsFromEnv(bundles, &LocaleConfig{}, nil)
+    require.NoError(t, err)
+
+    assert.Equal(t, "staging", environments.Current())
+    assert.Equal(t, "https://media.test/", environments.SharedMedia())
+    assert.Equal(t, []Environment{{Name: "production"}, {Name: "staging", Current: true}}, environments.List())
+
+    // El propio entorno usa su BundleService aunque tenga DSN
+    current, err := environments.Bundles("staging")
+    require.NoError(t, err)
+    assert.Same(t, bundles, current)
+
+    _, err = environments.Bundles("qa")
+    assert.True(t, errors.Is(err, ErrUnknownEnvironment))
+}
+
+func TestCheckSelected(t *testing.T) {
+    templates := []Template{{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}}
+    id := func(template Template) uint { return template.ID }
+    assert.NoError(t, checkSelected(BundleTemplates, []uint{2}, templates, id))
+
+    err := checkSelected(BundleTemplates, []uint{2, 3}, templates, id)
+    assert.True(t, errors.Is(err, ErrInvalidPromotion))
+    assert.Contains(t, err.Error(), "templates 3")
+}
+
+func TestCheckSharedMedia(t *testing.T) {
+    media := []Media{{URL: "https://media.test/a.png"}, {URL: "https://media.test.evil/b.png"}}
+    assert.NoError(t, checkSharedMedia("", nil))
+    assert.NoError(t, checkSharedMedia("https://media.test/", media[:1]))
+
+    // Sin almacenamiento compartido no se promocionan medios
+    err := checkSharedMedia("", media[:1])
+    assert.True(t, errors.Is(err, ErrInvalidPromotion))
+
+    err = checkSharedMedia("https://media.test/", media)
+    assert.True(t, errors.Is(err, ErrInvalidPromotion))
+    assert.Contains(t, err.Error(), "https://media.test.evil/b.png")
+    assert.NotContains(t, err.Error(), "a.png")
+}
+
+func TestPromotionDependencies(t *testing.T) {
+    ogImage := uint(11)
+    bundle := &Bundle{
+        Templates: []Template{
+            {Model: gorm.Model{ID: 1}, Name: "post", Content: `<img src="https://cdn.test/logo.png">`},
+        },
+        Media: []Media{
+            {Model: gorm.Model{ID: 10}, URL: "https://cdn.test/logo.png"},
+            {Model: gorm.Model{ID: 11}, URL: "https://cdn.test/og.jpg"},
+            {Model: gorm.Model{ID: 12}, URL: "https://cdn.test/body.jpg"},
+            {Model: gorm.Model{ID: 13}, URL: "https://cdn.test/picked.jpg"},
+        },
+        Content: []Content{
+            {Model: gorm.Model{ID: 3}, Slug: "hello", Locale: "en", TranslationGroupID: 3, TemplateID: 1, OGImageID: &ogImage,
+                Content: `<p><img src="https://cdn.test/body.jpg"></p>`},
+            {Model: gorm.Model{ID: 4}, Slug: "hola", Locale: "es", TranslationGroupID: 3, TemplateID: 1},
+        },
+    }
+    req := &PromotionRequest{ContentIDs: []uint{3}, MediaIDs: []uint{13}, Translations: true}
+
+    assert.Equal(t, []PromotionDependency{
+        {Kind: BundleTemplates, SourceID: 1, Key: "post", RequiredBy: []string{"content:en/hello", "content:es/hola"}},
+        {Kind: BundleMedia, SourceID: 10, Key: "https://cdn.test/logo.png", RequiredBy: []string{"templates:post"}},
+        {Kind: BundleMedia, SourceID: 11, Key: "https://cdn.test/og.jpg", RequiredBy: []string{"content:en/hello"}},
+        {Kind: BundleMedia, SourceID: 12, Key: "https://cdn.test/body.jpg", RequiredBy: []string{"content:en/hello"}},
+        {Kind: BundleContent, SourceID: 4, Key: "es/hola", RequiredBy: []string{"content:en/hello"}},
+    }, promotionDependencies(req, bundle))
+}
@@ -0,0 +1,44 @@
+// pkg/cms/redirect.go
+package cms
+
+import (
+    "context"
+    "strings"
+    "time"
+    "gorm.io/gorm"
+)
+
+// Redirect lleva una ruta antigua del sitio público a la nueva, por ejemplo
+// los permalinks de un blog importado de WordPress.
+type Redirect struct {
+    ID         uint      `json:"id" gorm:"primarykey"`
+    FromPath   string    `json:"from" gorm:"not null;uniqueIndex"`
+    ToPath     string    `json:"to" gorm:"not null"`
+    StatusCode int       `json:"status" gorm:"not null;default:301"`
+    Source     string    `json:"source"` // quién la creó, p. ej. "wordpress:<job>"
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+type RedirectService struct {
+    db *gorm.DB
+}
+
+func NewRedirectService(db *gorm.DB) *RedirectService {
+    return &RedirectService{db: db}
+}
+
+// Resolve busca la redirección de una ruta, con y sin la barra final.
+func (s *RedirectService) Resolve(ctx context.Context, path string) (*Redirect, error) {
+    candidates := []string{path}
+    if trimmed := strings.TrimSuffix(path, "/"); trimmed != path && trimmed != "" {
+        candidates = append(candidates, trimmed)
+    } else if !strings.Contains(path, "?") {
+        candidates = append(candidates, path+"/")
+    }
+
+    var redirect Redirect
+    if err := s.db.WithContext(ctx).Where("from_path IN ?", candidates).First(&redirect).Error; err != nil {
+        return nil, err
+    }
+    return &redirect, nil
+}
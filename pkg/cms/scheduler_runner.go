@@ -0,0 +1,101 @@
+// pkg/cms/scheduler_runner.go
+package cms
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "os"
+    "time"
+    "github.com/redis/go-redis/v9"
+    "go.uber.org/zap"
+)
+
+const schedulerLockKey = "cms:scheduler:lock"
+
+// releaseLock borra el lock solo si sigue siendo nuestro.
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// SchedulerRunner llama periódicamente a ProcessScheduled. Un lock en Redis
+// evita que todas las réplicas sondeen en cada ciclo; que una acción no se
+// ejecute dos veces si el lock caduca a mitad lo garantiza el reclamo de
+// ProcessScheduled.
+type SchedulerRunner struct {
+    scheduler *SchedulerService
+    client    *redis.Client
+    logger    *zap.Logger
+    interval  time.Duration
+    lockTTL   time.Duration
+    token     string
+}
+
+// NewSchedulerRunner usa CMS_SCHEDULER_INTERVAL (por defecto 30s) como
+// intervalo de sondeo.
+func NewSchedulerRunner(scheduler *SchedulerService, client *redis.Client, logger *zap.Logger) *SchedulerRunner {
+    interval := 30 * time.Second
+    if value := os.Getenv("CMS_SCHEDULER_INTERVAL"); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+            interval = parsed
+        } else {
+            logger.Warn("Invalid CMS_SCHEDULER_INTERVAL, using default", zap.String("value", value))
+        }
+    }
+
+    return &SchedulerRunner{
+        scheduler: scheduler,
+        client:    client,
+        logger:    logger,
+        interval:  interval,
+        lockTTL:   2 * interval,
+        token:     newLockToken(),
+    }
+}
+
+// Run bloquea hasta que se cancela el contexto.
+func (r *SchedulerRunner) Run(ctx context.Context) {
+    ticker := time.NewTicker(r.interval)
+    defer ticker.Stop()
+
+    for {
+        r.tick(ctx)
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (r *SchedulerRunner) tick(ctx context.Context) {
+    acquired, err := r.client.SetNX(ctx, schedulerLockKey, r.token, r.lockTTL).Result()
+    if err != nil {
+        r.logger.Error("Failed to acquire scheduler lock", zap.Error(err))
+        return
+    }
+    if !acquired {
+        return
+    }
+    defer func() {
+        if err := releaseLock.Run(context.Background(), r.client, []string{schedulerLockKey}, r.token).Err(); err != nil {
+            r.logger.Error("Failed to release scheduler lock", zap.Error(err))
+        }
+    }()
+
+    if err := r.scheduler.ProcessScheduled(ctx); err != nil {
+        r.logger.Error("Failed to process scheduled actions", zap.Error(err))
+    }
+}
+
+func newLockToken() string {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return time.Now().String()
+    }
+    return hex.EncodeToString(b)
+}
@@ -3,51 +3,124 @@ package cms
 
 import (
     "context"
+    "errors"
+    "fmt"
     "time"
+    "ezzygo/pkg/auth"
+    "ezzygo/pkg/models"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    ScheduleActionPublish     = "publish"
+    ScheduleActionUnpublish   = "unpublish"
+    ScheduleActionArchive     = "archive"
+    ScheduleActionSwapVersion = "swap_version"
+)
+
+// Estado al que lleva cada acción programada. swap_version es una edición y
+// no cambia el estado (ver authorize).
+var scheduleTargets = map[string]string{
+    ScheduleActionPublish:   StatusPublished,
+    ScheduleActionUnpublish: StatusDraft,
+    ScheduleActionArchive:   StatusArchived,
+}
+
+var (
+    ErrInvalidScheduleAction = errors.New("invalid schedule action")
+    ErrScheduleInPast        = errors.New("schedule time must be in the future")
+    ErrScheduleNotPending    = errors.New("only pending schedules can be cancelled")
+    ErrScheduleForbidden     = errors.New("only the creator, editors and admins can cancel a schedule")
+)
+
+const (
+    // scheduleBatchSize limita las acciones que reclama cada ciclo
+    scheduleBatchSize = 50
+    // scheduleStaleAfter es cuánto puede seguir en running una acción antes
+    // de darla por interrumpida
+    scheduleStaleAfter = 10 * time.Minute
 )
 
 type SchedulerService struct {
-    db      *gorm.DB
-    content *ContentService
+    db       *gorm.DB
+    content  *ContentService
+    versions *VersionService
 }
 
 type ScheduledPublication struct {
     gorm.Model
-    ContentID    uint      `json:"content_id"`
-    PublishAt    time.Time `json:"publish_at"`
-    Status       string    `json:"status"` // pending, completed, failed
+    ContentID    uint      `json:"content_id" gorm:"index"`
+    Action       string    `json:"action" gorm:"default:'publish'"` // publish, unpublish, archive, swap_version
+    Version      int       `json:"version,omitempty"`               // versión a restaurar en swap_version
+    PublishAt    time.Time `json:"publish_at" gorm:"index"`         // momento en que se ejecuta la acción
+    Status       string    `json:"status"`                          // pending, running, completed, failed, cancelled
     ErrorMessage string    `json:"error_message,omitempty"`
+    CreatedBy    string    `json:"created_by"`
 }
 
-func NewSchedulerService(db *gorm.DB, content *ContentService) *SchedulerService {
+func NewSchedulerService(db *gorm.DB, content *ContentService, versions *VersionService) *SchedulerService {
     return &SchedulerService{
-        db:      db,
-        content: content,
+        db:       db,
+        content:  content,
+        versions: versions,
     }
 }
 
-func (s *SchedulerService) Schedule(ctx context.Context, contentID uint, publishAt time.Time) error {
-    schedule := &ScheduledPublication{
-        ContentID: contentID,
-        PublishAt: publishAt,
-        Status:    "pending",
+func (s *SchedulerService) Schedule(ctx context.Context, schedule *ScheduledPublication) error {
+    if schedule.Action == "" {
+        schedule.Action = ScheduleActionPublish
+    }
+
+    switch schedule.Action {
+    case ScheduleActionPublish, ScheduleActionUnpublish, ScheduleActionArchive:
+    case ScheduleActionSwapVersion:
+        if schedule.Version <= 0 {
+            return fmt.Errorf("%w: swap_version requires a version", ErrInvalidScheduleAction)
+        }
+    default:
+        return ErrInvalidScheduleAction
+    }
+
+    if !schedule.PublishAt.After(time.Now()) {
+        return ErrScheduleInPast
+    }
+
+    var content Content
+    if err := s.db.WithContext(ctx).First(&content, schedule.ContentID).Error; err != nil {
+        return err
+    }
+    if err := s.authorize(ctx, schedule, &content); err != nil {
+        return err
+    }
+
+    if schedule.Action == ScheduleActionSwapVersion {
+        if _, err := s.versions.GetVersion(ctx, EntityContent, schedule.ContentID, schedule.Version); err != nil {
+            return err
+        }
     }
+
+    schedule.ID = 0
+    schedule.Status = "pending"
+    schedule.ErrorMessage = ""
     return s.db.WithContext(ctx).Create(schedule).Error
 }
 
+// ProcessScheduled ejecuta las acciones vencidas. Cada acción se reclama
+// (pending -> running) antes de ejecutarla, así que dos réplicas a la vez no
+// ejecutan la misma aunque el lock del SchedulerRunner caduque.
 func (s *SchedulerService) ProcessScheduled(ctx context.Context) error {
-    var schedules []ScheduledPublication
-    err := s.db.WithContext(ctx).
-        Where("status = ? AND publish_at <= ?", "pending", time.Now()).
-        Find(&schedules).Error
+    if err := s.failStale(ctx); err != nil {
+        return err
+    }
+
+    schedules, err := s.claim(ctx)
     if err != nil {
         return err
     }
 
     for _, schedule := range schedules {
-        err := s.content.Publish(ctx, schedule.ContentID)
-        if err != nil {
+        if err := s.run(ctx, &schedule); err != nil {
             s.updateStatus(ctx, schedule.ID, "failed", err.Error())
             continue
         }
@@ -56,26 +129,156 @@ func (s *SchedulerService) ProcessScheduled(ctx context.Context) error {
     return nil
 }
 
+// claim marca como running hasta scheduleBatchSize acciones vencidas y las
+// devuelve. SKIP LOCKED deja las que otra réplica está reclamando.
+func (s *SchedulerService) claim(ctx context.Context) ([]ScheduledPublication, error) {
+    var schedules []ScheduledPublication
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+            Where("status = ? AND publish_at <= ?", "pending", time.Now()).
+            Order("publish_at asc").
+            Limit(scheduleBatchSize).
+            Find(&schedules).Error
+        if err != nil || len(schedules) == 0 {
+            return err
+        }
+
+        ids := make([]uint, len(schedules))
+        for n := range schedules {
+            ids[n] = schedules[n].ID
+            schedules[n].Status = "running"
+        }
+        return tx.Model(&ScheduledPublication{}).
+            Where("id IN ? AND status = ?", ids, "pending").
+            Update("status", "running").Error
+    })
+    return schedules, err
+}
+
+// failStale marca como fallidas las acciones que llevan demasiado en running:
+// el proceso que las reclamó murió a medias. No se reintentan porque pueden
+// haberse aplicado ya.
+func (s *SchedulerService) failStale(ctx context.Context) error {
+    return s.db.WithContext(ctx).Model(&ScheduledPublication{}).
+        Where("status = ? AND updated_at < ?", "running", time.Now().Add(-scheduleStaleAfter)).
+        Updates(map[string]interface{}{
+            "status":        "failed",
+            "error_message": "interrupted while running",
+        }).Error
+}
+
+func (s *SchedulerService) run(ctx context.Context, schedule *ScheduledPublication) error {
+    // El estado o el rol pueden haber cambiado desde que se programó
+    var content Content
+    if err := s.db.WithContext(ctx).First(&content, schedule.ContentID).Error; err != nil {
+        return err
+    }
+    if err := s.authorize(ctx, schedule, &content); err != nil {
+        return err
+    }
+
+    switch schedule.Action {
+    case ScheduleActionPublish, "":
+        return s.content.Publish(ctx, schedule.ContentID)
+    case ScheduleActionUnpublish:
+        return s.content.Unpublish(ctx, schedule.ContentID)
+    case ScheduleActionArchive:
+        return s.content.Archive(ctx, schedule.ContentID)
+    case ScheduleActionSwapVersion:
+        return s.versions.Restore(ctx, EntityContent, schedule.ContentID, schedule.Version)
+    }
+    return ErrInvalidScheduleAction
+}
+
+// authorize aplica a una acción programada las reglas de una transición
+// manual: quien la programa debe poder hacerla desde el estado actual. Las
+// transiciones que piden comentario o varias aprobaciones no se programan.
+// swap_version sobre contenido publicado cambia lo que se ve sin revisión,
+// así que pide un rol que pueda publicar.
+func (s *SchedulerService) authorize(ctx context.Context, schedule *ScheduledPublication, content *Content) error {
+    var user models.User
+    if err := s.db.WithContext(ctx).Select("role").Where("username = ?", schedule.CreatedBy).First(&user).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return ErrTransitionForbidden
+        }
+        return err
+    }
+
+    to, ok := scheduleTargets[schedule.Action]
+    if !ok {
+        if content.Status == StatusPublished && !s.content.workflow.CanReach(StatusPublished, user.Role) {
+            return ErrTransitionForbidden
+        }
+        return nil
+    }
+
+    transition, err := s.content.workflow.Check(content.Status, to, user.Role)
+    if err != nil {
+        return err
+    }
+    if transition.RequireComment || transition.RequiredReviewers > 1 {
+        return fmt.Errorf("%w: %s needs a comment or several approvals", ErrInvalidScheduleAction, to)
+    }
+    return nil
+}
+
 func (s *SchedulerService) updateStatus(ctx context.Context, id uint, status, errorMsg string) error {
     return s.db.WithContext(ctx).Model(&ScheduledPublication{}).
-        Where("id = ?", id).
+        Where("id = ? AND status = ?", id, "running").
         Updates(map[string]interface{}{
             "status":        status,
             "error_message": errorMsg,
         }).Error
 }
 
+// Cancel cancela una acción pendiente. Solo puede hacerlo quien la programó
+// o un editor o administrador.
 func (s *SchedulerService) Cancel(ctx context.Context, scheduleID uint) error {
-    return s.db.WithContext(ctx).Delete(&ScheduledPublication{}, scheduleID).Error
+    var schedule ScheduledPublication
+    if err := s.db.WithContext(ctx).First(&schedule, scheduleID).Error; err != nil {
+        return err
+    }
+    username := auth.UsernameFromContext(ctx)
+    if schedule.CreatedBy != username {
+        var user models.User
+        err := s.db.WithContext(ctx).Select("role").Where("username = ?", username).First(&user).Error
+        if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+            return err
+        }
+        if user.Role != models.RoleEditor && user.Role != models.RoleAdmin {
+            return ErrScheduleForbidden
+        }
+    }
+
+    result := s.db.WithContext(ctx).Model(&ScheduledPublication{}).
+        Where("id = ? AND status = ?", scheduleID, "pending").
+        Update("status", "cancelled")
+    if result.Error != nil {
+        return result.Error
+    }
+
+    if result.RowsAffected == 0 {
+        return ErrScheduleNotPending
+    }
+    return nil
 }
 
-func (s *SchedulerService) List(ctx context.Context, status string) ([]ScheduledPublication, error) {
+func (s *SchedulerService) List(ctx context.Context, filter ScheduleFilter) ([]ScheduledPublication, error) {
     var schedules []ScheduledPublication
     query := s.db.WithContext(ctx)
-    
-    if status != "" {
-        query = query.Where("status = ?", status)
+
+    if filter.Status != "" {
+        query = query.Where("status = ?", filter.Status)
     }
-    
-    return schedules, query.Find(&schedules).Error
+
+    if filter.ContentID != 0 {
+        query = query.Where("content_id = ?", filter.ContentID)
+    }
+
+    return schedules, query.Order("publish_at asc").Find(&schedules).Error
+}
+
+type ScheduleFilter struct {
+    Status    string
+    ContentID uint
 }
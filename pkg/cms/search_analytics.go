@@ -0,0 +1,195 @@
+// pkg/cms/search_analytics.go
+package cms
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "time"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.uber.org/zap"
+)
+
+const (
+    defaultReportLimit = 20
+    maxReportLimit     = 100
+    // Clics guardados por búsqueda; click_count sigue contando todos
+    maxSearchClicks = 50
+)
+
+var (
+    ErrSearchQueryNotFound = errors.New("search query not found")
+    ErrInvalidSearchClick  = errors.New("invalid search click")
+)
+
+// SearchQueryLog es una búsqueda pública registrada en Mongo.
+type SearchQueryLog struct {
+    ID         primitive.ObjectID `bson:"_id"`
+    Query      string             `bson:"query"` // normalizada, para agrupar
+    Raw        string             `bson:"raw"`
+    Locale     string             `bson:"locale,omitempty"`
+    Results    int64              `bson:"results"`
+    Clicks     []SearchClick      `bson:"clicks"`
+    ClickCount int                `bson:"click_count"`
+    CreatedAt  time.Time          `bson:"created_at"`
+}
+
+// SearchClick es un resultado que el visitante abrió desde la búsqueda.
+type SearchClick struct {
+    Type      string    `json:"type" bson:"type"`
+    ID        uint      `json:"id" bson:"id"`
+    Position  int       `json:"position" bson:"position"` // 1 es el primer resultado
+    ClickedAt time.Time `json:"clicked_at" bson:"clicked_at"`
+}
+
+// SearchReportFilter limita los informes a [From, To): To queda fuera.
+type SearchReportFilter struct {
+    From  *time.Time
+    To    *time.Time
+    Limit int
+}
+
+// SearchQueryReport agrupa las búsquedas de una misma consulta.
+type SearchQueryReport struct {
+    Query      string  `json:"query" bson:"_id"`
+    Searches   int64   `json:"searches" bson:"searches"`
+    AvgResults float64 `json:"avg_results" bson:"avg_results"`
+    Clicks     int64   `json:"clicks" bson:"clicks"`
+    // Proporción de búsquedas con al menos un clic
+    ClickThrough float64   `json:"click_through" bson:"click_through"`
+    LastSearch   time.Time `json:"last_search" bson:"last_search"`
+}
+
+// SearchAnalytics registra las búsquedas públicas junto a los logs de
+// peticiones, en la colección search_queries de la misma base de Mongo.
+type SearchAnalytics struct {
+    collection *mongo.Collection
+    logger     *zap.Logger
+}
+
+func NewSearchAnalytics(logs *mongo.Collection, logger *zap.Logger) *SearchAnalytics {
+    return &SearchAnalytics{
+        collection: logs.Database().Collection("search_queries"),
+        logger:     logger,
+    }
+}
+
+// LogQuery registra la búsqueda en segundo plano y devuelve su id, que el
+// cliente envía después con LogClick. Admite un SearchAnalytics nil.
+func (a *SearchAnalytics) LogQuery(query, locale string, results int64) string {
+    if a == nil {
+        return ""
+    }
+
+    entry := SearchQueryLog{
+        ID:        primitive.NewObjectID(),
+        Query:     normalizeSearchQuery(query),
+        Raw:       query,
+        Locale:    locale,
+        Results:   results,
+        Clicks:    []SearchClick{},
+        CreatedAt: time.Now(),
+    }
+
+    go func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        if _, err := a.collection.InsertOne(ctx, entry); err != nil {
+            a.logger.Error("Failed to log search query", zap.Error(err))
+        }
+    }()
+    return entry.ID.Hex()
+}
+
+// LogClick añade un clic a una búsqueda registrada. Solo se guardan los
+// últimos maxSearchClicks para que el documento no crezca sin límite.
+func (a *SearchAnalytics) LogClick(ctx context.Context, queryID string, click SearchClick) error {
+    id, err := primitive.ObjectIDFromHex(queryID)
+    if err != nil || click.ID == 0 || click.Type == "" {
+        return ErrInvalidSearchClick
+    }
+    click.ClickedAt = time.Now()
+
+    result, err := a.collection.UpdateByID(ctx, id, bson.M{
+        "$push": bson.M{"clicks": bson.M{"$each": bson.A{click}, "$slice": -maxSearchClicks}},
+        "$inc":  bson.M{"click_count": 1},
+    })
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrSearchQueryNotFound
+    }
+    return nil
+}
+
+// TopQueries devuelve las consultas más buscadas en el periodo.
+func (a *SearchAnalytics) TopQueries(ctx context.Context, filter SearchReportFilter) ([]SearchQueryReport, error) {
+    return a.report(ctx, searchReportMatch(filter, false), filter.Limit)
+}
+
+// ZeroResultQueries devuelve las consultas más buscadas que no encontraron
+// nada: contenido que falta o sinónimos que añadir.
+func (a *SearchAnalytics) ZeroResultQueries(ctx context.Context, filter SearchReportFilter) ([]SearchQueryReport, error) {
+    return a.report(ctx, searchReportMatch(filter, true), filter.Limit)
+}
+
+func (a *SearchAnalytics) report(ctx context.Context, match bson.M, limit int) ([]SearchQueryReport, error) {
+    if limit < 1 || limit > maxReportLimit {
+        limit = defaultReportLimit
+    }
+
+    pipeline := mongo.Pipeline{
+        {{Key: "$match", Value: match}},
+        {{Key: "$group", Value: bson.M{
+            "_id":         "$query",
+            "searches":    bson.M{"$sum": 1},
+            "avg_results": bson.M{"$avg": "$results"},
+            "clicks":      bson.M{"$sum": "$click_count"},
+            "clicked":     bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$click_count", 0}}, 1, 0}}},
+            "last_search": bson.M{"$max": "$created_at"},
+        }}},
+        {{Key: "$addFields", Value: bson.M{
+            "click_through": bson.M{"$divide": bson.A{"$clicked", "$searches"}},
+        }}},
+        {{Key: "$sort", Value: bson.D{{Key: "searches", Value: -1}, {Key: "_id", Value: 1}}}},
+        {{Key: "$limit", Value: limit}},
+    }
+
+    cursor, err := a.collection.Aggregate(ctx, pipeline)
+    if err != nil {
+        return nil, err
+    }
+
+    reports := []SearchQueryReport{}
+    if err := cursor.All(ctx, &reports); err != nil {
+        return nil, err
+    }
+    return reports, nil
+}
+
+func searchReportMatch(filter SearchReportFilter, zeroResults bool) bson.M {
+    match := bson.M{}
+    createdAt := bson.M{}
+    if filter.From != nil {
+        createdAt["$gte"] = *filter.From
+    }
+    if filter.To != nil {
+        createdAt["$lt"] = *filter.To
+    }
+    if len(createdAt) > 0 {
+        match["created_at"] = createdAt
+    }
+    if zeroResults {
+        match["results"] = 0
+    }
+    return match
+}
+
+// normalizeSearchQuery agrupa consultas que solo difieren en mayúsculas o
+// espacios.
+func normalizeSearchQuery(query string) string {
+    return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
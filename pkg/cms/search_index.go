@@ -0,0 +1,362 @@
+// pkg/cms/search_index.go
+package cms
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "os"
+    "strings"
+    "time"
+    "gorm.io/gorm"
+)
+
+// Opciones de ts_headline: fragmentos cortos con las coincidencias marcadas
+const headlineOptions = "MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=\" … \", StartSel=<mark>, StopSel=</mark>"
+
+// SearchIndex es el motor que resuelve las búsquedas. Postgres es el de
+// por defecto; HTTPSearchIndex permite usar un motor externo con tolerancia
+// a erratas y sinónimos.
+type SearchIndex interface {
+    Search(ctx context.Context, query string, filter SearchFilter) (*SearchResponse, error)
+    // Index añade o reemplaza documentos
+    Index(ctx context.Context, docs ...SearchDocument) error
+    Delete(ctx context.Context, docType string, id uint) error
+    // Configure aplica la configuración del índice antes de una
+    // reindexación completa
+    Configure(ctx context.Context) error
+    // Prune borra los documentos cuyo id no está en keep, al terminar una
+    // reindexación completa
+    Prune(ctx context.Context, keep map[string]bool) error
+}
+
+// SearchDocument es la representación indexada de un contenido o plantilla.
+type SearchDocument struct {
+    ID           string   `json:"id"` // <type>-<id>, único entre tipos
+    Type         string   `json:"type"`
+    EntityID     uint     `json:"entity_id"`
+    Title        string   `json:"title"`
+    Content      string   `json:"content"`
+    Status       string   `json:"status"`
+    Locale       string   `json:"locale"`
+    AuthorID     uint     `json:"author_id"`
+    Tags         []string `json:"tags"`
+    Kind         string   `json:"kind"` // tipo de plantilla
+    CreatedAt    int64    `json:"created_at"`
+    EmbargoUntil int64    `json:"embargo_until"` // 0 si no hay embargo
+    ExpiresAt    int64    `json:"expires_at"`    // math.MaxInt64 si no caduca
+}
+
+func documentID(docType string, id uint) string {
+    return fmt.Sprintf("%s-%d", docType, id)
+}
+
+func contentDocument(content *Content) SearchDocument {
+    doc := SearchDocument{
+        ID:        documentID(EntityContent, content.ID),
+        Type:      EntityContent,
+        EntityID:  content.ID,
+        Title:     content.Title,
+        Content:   content.Content,
+        Status:    content.Status,
+        Locale:    content.Locale,
+        AuthorID:  content.AuthorID,
+        Tags:      []string(content.Tags),
+        CreatedAt: content.CreatedAt.Unix(),
+        ExpiresAt: math.MaxInt64,
+    }
+    if doc.Tags == nil {
+        doc.Tags = []string{}
+    }
+    if content.EmbargoUntil != nil {
+        doc.EmbargoUntil = content.EmbargoUntil.Unix()
+    }
+    if content.ExpiresAt != nil {
+        doc.ExpiresAt = content.ExpiresAt.Unix()
+    }
+    return doc
+}
+
+func templateDocument(template *Template) SearchDocument {
+    return SearchDocument{
+        ID:        documentID(EntityTemplate, template.ID),
+        Type:      EntityTemplate,
+        EntityID:  template.ID,
+        Title:     template.Name,
+        Content:   template.Content,
+        Tags:      []string{},
+        Kind:      template.Type,
+        CreatedAt: template.CreatedAt.Unix(),
+        ExpiresAt: math.MaxInt64,
+    }
+}
+
+// NewSearchIndex elige el motor según CMS_SEARCH_ENGINE: "postgres" (por
+// defecto) o "meilisearch", que usa CMS_SEARCH_URL, CMS_SEARCH_API_KEY y
+// CMS_SEARCH_INDEX.
+func NewSearchIndex(db *gorm.DB) (SearchIndex, error) {
+    switch engine := os.Getenv("CMS_SEARCH_ENGINE"); engine {
+    case "", "postgres":
+        return NewPostgresIndex(db), nil
+    case "meilisearch":
+        url := os.Getenv("CMS_SEARCH_URL")
+        if url == "" {
+            return nil, fmt.Errorf("CMS_SEARCH_URL is required for the %s search engine", engine)
+        }
+        name := os.Getenv("CMS_SEARCH_INDEX")
+        if name == "" {
+            name = "cms"
+        }
+        return NewHTTPSearchIndex(url, os.Getenv("CMS_SEARCH_API_KEY"), name), nil
+    default:
+        return nil, fmt.Errorf("unknown search engine %q", engine)
+    }
+}
+
+// PostgresIndex busca con tsvector sobre las propias tablas. Las columnas
+// generadas se mantienen solas, así que Index, Delete, Configure y Prune no
+// hacen nada.
+type PostgresIndex struct {
+    db *gorm.DB
+}
+
+func NewPostgresIndex(db *gorm.DB) *PostgresIndex {
+    return &PostgresIndex{db: db}
+}
+
+func (i *PostgresIndex) Index(ctx context.Context, docs ...SearchDocument) error {
+    return nil
+}
+
+func (i *PostgresIndex) Delete(ctx context.Context, docType string, id uint) error {
+    return nil
+}
+
+func (i *PostgresIndex) Configure(ctx context.Context) error {
+    return nil
+}
+
+func (i *PostgresIndex) Prune(ctx context.Context, keep map[string]bool) error {
+    return nil
+}
+
+// searchRow es la fila común que devuelve la unión de contenidos y plantillas
+type searchRow struct {
+    Type      string
+    ID        uint
+    Title     string
+    Content   string
+    Highlight string
+    Score     float64
+    Status    string
+    AuthorID  uint
+    Locale    string
+    Kind      string
+}
+
+// Search usa los índices de text search (ver searchMigrations):
+// websearch_to_tsquery admite comillas, OR y -exclusiones, los resultados se
+// ordenan por ts_rank y se resaltan con ts_headline.
+func (i *PostgresIndex) Search(ctx context.Context, query string, filter SearchFilter) (*SearchResponse, error) {
+    response := emptySearchResponse(filter)
+
+    includeContent := len(filter.Types) == 0 || contains(filter.Types, "content")
+    // La búsqueda pública nunca incluye plantillas
+    includeTemplates := !filter.PublicOnly && (len(filter.Types) == 0 || contains(filter.Types, "template"))
+
+    var parts []interface{}
+    if includeContent {
+        tsquery, args := tsQuery("cms_search_config(locale)", query, filter.Variants)
+        parts = append(parts, i.contentMatches(ctx, query, filter).Select(fmt.Sprintf(`'content' AS type, id, title,
+            left(content, 200) AS content,
+            ts_headline(cms_search_config(locale), content, %[1]s, ?) AS highlight,
+            ts_rank(search_vector, %[1]s) AS score,
+            status, author_id, locale, '' AS kind`, tsquery), headlineArgs(args)...))
+    }
+    if includeTemplates {
+        tsquery, args := tsQuery("'simple'", query, filter.Variants)
+        parts = append(parts, i.templateMatches(ctx, query, filter).Select(fmt.Sprintf(`'template' AS type, id, name AS title,
+            left(content, 200) AS content,
+            ts_headline('simple', content, %[1]s, ?) AS highlight,
+            ts_rank(search_vector, %[1]s) AS score,
+            '' AS status, 0 AS author_id, '' AS locale, type AS kind`, tsquery), headlineArgs(args)...))
+    }
+    if len(parts) == 0 {
+        return response, nil
+    }
+
+    union := "(?)"
+    if len(parts) == 2 {
+        union = "(?) UNION ALL (?)"
+    }
+
+    if err := i.db.WithContext(ctx).Table("("+union+") AS results", parts...).Count(&response.Total).Error; err != nil {
+        return nil, err
+    }
+
+    var rows []searchRow
+    err := i.db.WithContext(ctx).Table("("+union+") AS results", parts...).
+        Order("score DESC, id DESC").
+        Limit(filter.PageSize).
+        Offset((filter.Page - 1) * filter.PageSize).
+        Scan(&rows).Error
+    if err != nil {
+        return nil, err
+    }
+
+    for _, row := range rows {
+        result := SearchResult{
+            Type:      row.Type,
+            ID:        row.ID,
+            Title:     row.Title,
+            Content:   truncateContent(row.Content),
+            Highlight: row.Highlight,
+            Score:     row.Score,
+        }
+        if row.Type == "content" {
+            result.Metadata = map[string]interface{}{
+                "status": row.Status,
+                "author": row.AuthorID,
+                "locale": row.Locale,
+            }
+        } else {
+            result.Metadata = map[string]interface{}{
+                "type": row.Kind,
+            }
+        }
+        response.Results = append(response.Results, result)
+    }
+
+    facets, err := i.facets(ctx, query, filter, includeContent, includeTemplates)
+    if err != nil {
+        return nil, err
+    }
+    response.Facets = facets
+    return response, nil
+}
+
+// tsQuery une la consulta y sus variantes por sinónimos en una sola tsquery
+// con OR (||). Devuelve la expresión y sus argumentos.
+func tsQuery(config, query string, variants []string) (string, []interface{}) {
+    queries := append([]string{query}, variants...)
+    parts := make([]string, len(queries))
+    args := make([]interface{}, len(queries))
+    for n, q := range queries {
+        parts[n] = fmt.Sprintf("websearch_to_tsquery(%s, ?)", config)
+        args[n] = q
+    }
+    return "(" + strings.Join(parts, " || ") + ")", args
+}
+
+// headlineArgs ordena los argumentos de la select de resultados: la tsquery
+// de ts_headline, sus opciones y la tsquery de ts_rank.
+func headlineArgs(args []interface{}) []interface{} {
+    values := append([]interface{}{}, args...)
+    values = append(values, headlineOptions)
+    return append(values, args...)
+}
+
+// contentMatches devuelve una consulta nueva sobre los contenidos que
+// coinciden con la búsqueda y los filtros
+func (i *PostgresIndex) contentMatches(ctx context.Context, query string, filter SearchFilter) *gorm.DB {
+    tsquery, args := tsQuery("cms_search_config(locale)", query, filter.Variants)
+    q := i.db.WithContext(ctx).Model(&Content{}).
+        Where("search_vector @@ "+tsquery, args...)
+
+    if filter.PublicOnly {
+        q = q.Scopes(Delivered(time.Now()))
+    } else if filter.Status != "" {
+        q = q.Where("status = ?", filter.Status)
+    }
+
+    if filter.Locale != "" {
+        q = q.Where("locale = ?", filter.Locale)
+    }
+
+    if filter.AuthorID != 0 {
+        q = q.Where("author_id = ?", filter.AuthorID)
+    }
+
+    if len(filter.Tags) > 0 {
+        q = q.Where("tags @> ?", StringArray(filter.Tags))
+    }
+
+    if filter.FromDate != nil {
+        q = q.Where("created_at >= ?", filter.FromDate)
+    }
+
+    if filter.ToDate != nil {
+        q = q.Where("created_at <= ?", filter.ToDate)
+    }
+    return q
+}
+
+func (i *PostgresIndex) templateMatches(ctx context.Context, query string, filter SearchFilter) *gorm.DB {
+    tsquery, args := tsQuery("'simple'", query, filter.Variants)
+    q := i.db.WithContext(ctx).Model(&Template{}).
+        Where("search_vector @@ "+tsquery, args...)
+
+    if filter.FromDate != nil {
+        q = q.Where("created_at >= ?", filter.FromDate)
+    }
+
+    if filter.ToDate != nil {
+        q = q.Where("created_at <= ?", filter.ToDate)
+    }
+    return q
+}
+
+// facets cuenta las coincidencias por tipo, estado, tag y autor sobre el
+// conjunto filtrado completo, no solo sobre la página devuelta.
+func (i *PostgresIndex) facets(ctx context.Context, query string, filter SearchFilter, includeContent, includeTemplates bool) (map[string][]FacetCount, error) {
+    facets := map[string][]FacetCount{
+        "type":   {},
+        "status": {},
+        "tag":    {},
+        "author": {},
+    }
+
+    if includeContent {
+        var count int64
+        if err := i.contentMatches(ctx, query, filter).Count(&count).Error; err != nil {
+            return nil, err
+        }
+        if count > 0 {
+            facets["type"] = append(facets["type"], FacetCount{Value: "content", Count: count})
+        }
+
+        groups := map[string]string{
+            "status": "status",
+            "author": "author_id::text",
+            "tag":    "tag",
+        }
+        for name, expression := range groups {
+            var counts []FacetCount
+            q := i.contentMatches(ctx, query, filter)
+            if name == "tag" {
+                q = q.Joins("CROSS JOIN LATERAL unnest(contents.tags) AS tag")
+            }
+            err := q.Select(expression + " AS value, count(*) AS count").
+                Group(expression).
+                Order("count DESC, value").
+                Scan(&counts).Error
+            if err != nil {
+                return nil, err
+            }
+            if counts != nil {
+                facets[name] = counts
+            }
+        }
+    }
+
+    if includeTemplates {
+        var count int64
+        if err := i.templateMatches(ctx, query, filter).Count(&count).Error; err != nil {
+            return nil, err
+        }
+        if count > 0 {
+            facets["type"] = append(facets["type"], FacetCount{Value: "template", Count: count})
+        }
+    }
+    return facets, nil
+}
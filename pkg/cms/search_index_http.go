@@ -0,0 +1,293 @@
+// pkg/cms/search_index_http.go
+package cms
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// pruneBatch es el tamaño de página al listar los ids del índice en Prune
+const pruneBatch = 1000
+
+// Atributos filtrables que se configuran en el índice externo
+var searchFilterableAttributes = []string{
+    "type", "status", "locale", "author_id", "tags", "created_at", "embargo_until", "expires_at",
+}
+
+// Facetas del motor externo y su nombre en SearchResponse
+var searchFacetNames = map[string]string{
+    "type":      "type",
+    "status":    "status",
+    "tags":      "tag",
+    "author_id": "author",
+}
+
+// HTTPSearchIndex habla con un motor compatible con la API de Meilisearch
+// (/indexes/:uid/documents, /search y /settings).
+type HTTPSearchIndex struct {
+    baseURL string
+    apiKey  string
+    index   string
+    client  *http.Client
+}
+
+func NewHTTPSearchIndex(baseURL, apiKey, index string) *HTTPSearchIndex {
+    return &HTTPSearchIndex{
+        baseURL: strings.TrimRight(baseURL, "/"),
+        apiKey:  apiKey,
+        index:   index,
+        client:  &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+type httpSearchRequest struct {
+    Q                     string   `json:"q"`
+    Offset                int      `json:"offset"`
+    Limit                 int      `json:"limit"`
+    Filter                string   `json:"filter,omitempty"`
+    Facets                []string `json:"facets"`
+    AttributesToCrop      []string `json:"attributesToCrop"`
+    CropLength            int      `json:"cropLength"`
+    AttributesToHighlight []string `json:"attributesToHighlight"`
+    HighlightPreTag       string   `json:"highlightPreTag"`
+    HighlightPostTag      string   `json:"highlightPostTag"`
+    ShowRankingScore      bool     `json:"showRankingScore"`
+}
+
+type httpSearchHit struct {
+    SearchDocument
+    Formatted    SearchDocument `json:"_formatted"`
+    RankingScore float64        `json:"_rankingScore"`
+}
+
+type httpSearchResponse struct {
+    Hits               []httpSearchHit             `json:"hits"`
+    EstimatedTotalHits int64                       `json:"estimatedTotalHits"`
+    FacetDistribution  map[string]map[string]int64 `json:"facetDistribution"`
+}
+
+func (i *HTTPSearchIndex) Search(ctx context.Context, query string, filter SearchFilter) (*SearchResponse, error) {
+    request := httpSearchRequest{
+        Q:                     query,
+        Offset:                (filter.Page - 1) * filter.PageSize,
+        Limit:                 filter.PageSize,
+        Filter:                httpSearchFilter(filter, time.Now()),
+        Facets:                []string{"type", "status", "tags", "author_id"},
+        AttributesToCrop:      []string{"content"},
+        CropLength:            30,
+        AttributesToHighlight: []string{"content"},
+        HighlightPreTag:       "<mark>",
+        HighlightPostTag:      "</mark>",
+        ShowRankingScore:      true,
+    }
+
+    var result httpSearchResponse
+    if err := i.do(ctx, http.MethodPost, "/search", request, &result); err != nil {
+        return nil, err
+    }
+
+    response := emptySearchResponse(filter)
+    response.Total = result.EstimatedTotalHits
+    for _, hit := range result.Hits {
+        item := SearchResult{
+            Type:      hit.Type,
+            ID:        hit.EntityID,
+            Title:     hit.Title,
+            Content:   truncateContent(hit.Content),
+            Highlight: hit.Formatted.Content,
+            Score:     hit.RankingScore,
+        }
+        if hit.Type == EntityContent {
+            item.Metadata = map[string]interface{}{
+                "status": hit.Status,
+                "author": hit.AuthorID,
+                "locale": hit.Locale,
+            }
+        } else {
+            item.Metadata = map[string]interface{}{
+                "type": hit.Kind,
+            }
+        }
+        response.Results = append(response.Results, item)
+    }
+
+    for engineName, name := range searchFacetNames {
+        counts := []FacetCount{}
+        for value, count := range result.FacetDistribution[engineName] {
+            // Las plantillas no tienen estado ni autor
+            if value == "" || (name == "author" && value == "0") {
+                continue
+            }
+            counts = append(counts, FacetCount{Value: value, Count: count})
+        }
+        sort.Slice(counts, func(a, b int) bool {
+            if counts[a].Count != counts[b].Count {
+                return counts[a].Count > counts[b].Count
+            }
+            return counts[a].Value < counts[b].Value
+        })
+        response.Facets[name] = counts
+    }
+    return response, nil
+}
+
+// httpSearchFilter traduce SearchFilter a la sintaxis de filtros del motor.
+// Igual que en Postgres, los filtros propios del contenido no excluyen
+// plantillas.
+func httpSearchFilter(filter SearchFilter, now time.Time) string {
+    var conditions, contentConditions []string
+
+    types := filter.Types
+    if filter.PublicOnly {
+        types = []string{EntityContent}
+        contentConditions = append(contentConditions,
+            fmt.Sprintf("status = %s", strconv.Quote(StatusPublished)),
+            fmt.Sprintf("embargo_until <= %d", now.Unix()),
+            fmt.Sprintf("expires_at > %d", now.Unix()))
+    } else if filter.Status != "" {
+        contentConditions = append(contentConditions, fmt.Sprintf("status = %s", strconv.Quote(filter.Status)))
+    }
+    if len(types) > 0 {
+        quoted := make([]string, len(types))
+        for n, t := range types {
+            quoted[n] = strconv.Quote(t)
+        }
+        conditions = append(conditions, fmt.Sprintf("type IN [%s]", strings.Join(quoted, ", ")))
+    }
+
+    if filter.Locale != "" {
+        contentConditions = append(contentConditions, fmt.Sprintf("locale = %s", strconv.Quote(filter.Locale)))
+    }
+    if filter.AuthorID != 0 {
+        contentConditions = append(contentConditions, fmt.Sprintf("author_id = %d", filter.AuthorID))
+    }
+    for _, tag := range filter.Tags {
+        contentConditions = append(contentConditions, fmt.Sprintf("tags = %s", strconv.Quote(tag)))
+    }
+
+    if len(contentConditions) > 0 {
+        contentFilter := strings.Join(contentConditions, " AND ")
+        if len(types) == 1 && types[0] == EntityContent {
+            conditions = append(conditions, contentFilter)
+        } else {
+            conditions = append(conditions, fmt.Sprintf(`(type = "%s" OR (%s))`, EntityTemplate, contentFilter))
+        }
+    }
+
+    if filter.FromDate != nil {
+        conditions = append(conditions, fmt.Sprintf("created_at >= %d", filter.FromDate.Unix()))
+    }
+    if filter.ToDate != nil {
+        conditions = append(conditions, fmt.Sprintf("created_at <= %d", filter.ToDate.Unix()))
+    }
+    return strings.Join(conditions, " AND ")
+}
+
+func (i *HTTPSearchIndex) Index(ctx context.Context, docs ...SearchDocument) error {
+    if len(docs) == 0 {
+        return nil
+    }
+    return i.do(ctx, http.MethodPost, "/documents?primaryKey=id", docs, nil)
+}
+
+func (i *HTTPSearchIndex) Delete(ctx context.Context, docType string, id uint) error {
+    return i.do(ctx, http.MethodDelete, "/documents/"+url.PathEscape(documentID(docType, id)), nil, nil)
+}
+
+// Configure aplica la configuración de atributos buscables y filtrables.
+func (i *HTTPSearchIndex) Configure(ctx context.Context) error {
+    settings := map[string]interface{}{
+        "searchableAttributes": []string{"title", "content", "tags"},
+        "filterableAttributes": searchFilterableAttributes,
+    }
+    return i.do(ctx, http.MethodPatch, "/settings", settings, nil)
+}
+
+// Prune recorre los ids del índice por páginas y borra de una vez los que no
+// están en keep.
+func (i *HTTPSearchIndex) Prune(ctx context.Context, keep map[string]bool) error {
+    stale := []string{}
+    for offset := 0; ; offset += pruneBatch {
+        var page struct {
+            Results []struct {
+                ID string `json:"id"`
+            } `json:"results"`
+            Total int `json:"total"`
+        }
+        path := fmt.Sprintf("/documents?fields=id&limit=%d&offset=%d", pruneBatch, offset)
+        if err := i.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+            return err
+        }
+        for _, doc := range page.Results {
+            if !keep[doc.ID] {
+                stale = append(stale, doc.ID)
+            }
+        }
+        if len(page.Results) < pruneBatch || offset+pruneBatch >= page.Total {
+            break
+        }
+    }
+
+    if len(stale) == 0 {
+        return nil
+    }
+    return i.do(ctx, http.MethodPost, "/documents/delete-batch", stale, nil)
+}
+
+// SetDictionary reemplaza los sinónimos y stop words del motor, que los
+// aplica él mismo al buscar.
+func (i *HTTPSearchIndex) SetDictionary(ctx context.Context, synonyms map[string][]string, stopWords []string) error {
+    settings := map[string]interface{}{
+        "synonyms":  synonyms,
+        "stopWords": stopWords,
+    }
+    return i.do(ctx, http.MethodPatch, "/settings", settings, nil)
+}
+
+func (i *HTTPSearchIndex) do(ctx context.Context, method, path string, body, out interface{}) error {
+    var reader io.Reader
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return err
+        }
+        reader = bytes.NewReader(data)
+    }
+
+    endpoint := fmt.Sprintf("%s/indexes/%s%s", i.baseURL, url.PathEscape(i.index), path)
+    req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+    if err != nil {
+        return err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    if i.apiKey != "" {
+        req.Header.Set("Authorization", "Bearer "+i.apiKey)
+    }
+
+    resp, err := i.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode >= 300 {
+        message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("search engine %s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+    }
+
+    if out == nil {
+        return nil
+    }
+    return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,178 @@
+// pkg/cms/search_index_http_test.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// fakeSearchEngine imita lo mínimo de la API de Meilisearch: guarda los
+// documentos en memoria, busca por subcadena y registra las peticiones.
+type fakeSearchEngine struct {
+    mu       sync.Mutex
+    docs     map[string]SearchDocument
+    requests []string
+    settings map[string]interface{}
+    search   httpSearchRequest
+}
+
+func newFakeSearchEngine() (*fakeSearchEngine, *httptest.Server) {
+    engine := &fakeSearchEngine{docs: map[string]SearchDocument{}}
+    return engine, httptest.NewServer(engine)
+}
+
+func (f *fakeSearchEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+    if r.Header.Get("Authorization") != "Bearer secret" {
+        w.WriteHeader(http.StatusUnauthorized)
+        return
+    }
+
+    path := strings.TrimPrefix(r.URL.Path, "/indexes/cms")
+    switch {
+    case r.Method == http.MethodPost && path == "/documents":
+        var docs []SearchDocument
+        json.NewDecoder(r.Body).Decode(&docs)
+        for _, doc := range docs {
+            f.docs[doc.ID] = doc
+        }
+    case r.Method == http.MethodGet && path == "/documents":
+        results := []map[string]string{}
+        for id := range f.docs {
+            results = append(results, map[string]string{"id": id})
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"results": results, "total": len(results)})
+        return
+    case r.Method == http.MethodPost && path == "/documents/delete-batch":
+        var ids []string
+        json.NewDecoder(r.Body).Decode(&ids)
+        for _, id := range ids {
+            delete(f.docs, id)
+        }
+    case r.Method == http.MethodDelete && strings.HasPrefix(path, "/documents/"):
+        delete(f.docs, strings.TrimPrefix(path, "/documents/"))
+    case r.Method == http.MethodPatch && path == "/settings":
+        json.NewDecoder(r.Body).Decode(&f.settings)
+    case r.Method == http.MethodPost && path == "/search":
+        json.NewDecoder(r.Body).Decode(&f.search)
+        var hits []map[string]interface{}
+        facets := map[string]map[string]int64{"type": {}, "tags": {}}
+        for _, doc := range f.docs {
+            if !strings.Contains(strings.ToLower(doc.Title+" "+doc.Content), strings.ToLower(f.search.Q)) {
+                continue
+            }
+            formatted := doc
+            formatted.Content = strings.ReplaceAll(doc.Content, f.search.Q, "<mark>"+f.search.Q+"</mark>")
+            hits = append(hits, map[string]interface{}{
+                "id": doc.ID, "type": doc.Type, "entity_id": doc.EntityID, "title": doc.Title,
+                "content": doc.Content, "status": doc.Status, "locale": doc.Locale,
+                "author_id": doc.AuthorID, "kind": doc.Kind,
+                "_formatted": formatted, "_rankingScore": 0.9,
+            })
+            facets["type"][doc.Type]++
+            for _, tag := range doc.Tags {
+                facets["tags"][tag]++
+            }
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "hits":               hits,
+            "estimatedTotalHits": len(hits),
+            "facetDistribution":  facets,
+        })
+        return
+    default:
+        w.WriteHeader(http.StatusNotFound)
+        return
+    }
+    w.WriteHeader(http.StatusAccepted)
+    w.Write([]byte(`{"taskUid":1}`))
+}
+
+func TestHTTPSearchIndex(t *testing.T) {
+    engine, server := newFakeSearchEngine()
+    defer server.Close()
+
+    index := NewHTTPSearchIndex(server.URL+"/", "secret", "cms")
+    ctx := context.Background()
+
+    require.NoError(t, index.Configure(ctx))
+    assert.Equal(t, searchFilterableAttributes, toStrings(engine.settings["filterableAttributes"]))
+
+    content := &Content{Title: "Go tips", Content: "Learn golang fast", Status: StatusPublished, Locale: "en", AuthorID: 7, Tags: StringArray{"go", "tips"}}
+    content.ID = 1
+    template := &Template{Name: "Golang page", Content: "layout", Type: "page"}
+    template.ID = 2
+    require.NoError(t, index.Index(ctx, contentDocument(content), templateDocument(template)))
+    assert.Len(t, engine.docs, 2)
+
+    response, err := index.Search(ctx, "golang", SearchFilter{Page: 2, PageSize: 10})
+    require.NoError(t, err)
+    assert.Equal(t, 10, engine.search.Offset)
+    assert.Equal(t, 10, engine.search.Limit)
+    assert.Equal(t, int64(2), response.Total)
+    assert.Len(t, response.Results, 2)
+    assert.Equal(t, []FacetCount{{Value: "content", Count: 1}, {Value: "template", Count: 1}}, response.Facets["type"])
+    assert.Equal(t, []FacetCount{{Value: "go", Count: 1}, {Value: "tips", Count: 1}}, response.Facets["tag"])
+
+    for _, result := range response.Results {
+        if result.Type == EntityContent {
+            assert.Equal(t, uint(1), result.ID)
+            assert.Equal(t, "Learn <mark>golang</mark> fast", result.Highlight)
+            assert.Equal(t, uint(7), result.Metadata.(map[string]interface{})["author"])
+        }
+    }
+
+    require.NoError(t, index.Delete(ctx, EntityTemplate, 2))
+    assert.NotContains(t, engine.docs, "template-2")
+    assert.Contains(t, engine.requests, "DELETE /indexes/cms/documents/template-2")
+
+    // Prune deja solo lo reindexado
+    require.NoError(t, index.Index(ctx, templateDocument(template)))
+    require.NoError(t, index.Prune(ctx, map[string]bool{"content-1": true}))
+    assert.Contains(t, engine.docs, "content-1")
+    assert.NotContains(t, engine.docs, "template-2")
+}
+
+func TestHTTPSearchIndexError(t *testing.T) {
+    _, server := newFakeSearchEngine()
+    defer server.Close()
+
+    index := NewHTTPSearchIndex(server.URL, "wrong", "cms")
+    _, err := index.Search(context.Background(), "golang", SearchFilter{Page: 1, PageSize: 10})
+    assert.Error(t, err)
+}
+
+func TestHTTPSearchFilter(t *testing.T) {
+    now := time.Unix(1000, 0)
+    from := time.Unix(500, 0)
+
+    assert.Equal(t, "", httpSearchFilter(SearchFilter{}, now))
+    assert.Equal(t,
+        `(type = "template" OR (status = "draft" AND tags = "go")) AND created_at >= 500`,
+        httpSearchFilter(SearchFilter{Status: "draft", Tags: []string{"go"}, FromDate: &from}, now))
+    assert.Equal(t,
+        `type IN ["content"] AND status = "published" AND embargo_until <= 1000 AND expires_at > 1000 AND locale = "es"`,
+        httpSearchFilter(SearchFilter{Types: []string{"template"}, Locale: "es", PublicOnly: true}, now))
+    assert.Equal(t,
+        `type IN ["content"] AND author_id = 3`,
+        httpSearchFilter(SearchFilter{Types: []string{"content"}, AuthorID: 3}, now))
+}
+
+func toStrings(value interface{}) []string {
+    var items []string
+    for _, item := range value.([]interface{}) {
+        items = append(items, item.(string))
+    }
+    return items
+}
@@ -0,0 +1,147 @@
+// pkg/cms/search_indexer.go
+package cms
+
+import (
+    "context"
+    "errors"
+    "ezzygo/pkg/events"
+    "go.uber.org/zap"
+    "gorm.io/gorm"
+)
+
+const (
+    searchQueueSize = 1024
+    reindexBatch    = 500
+)
+
+type indexJob struct {
+    entityType string
+    id         uint
+}
+
+// SearchIndexer mantiene el índice al día fuera de la petición: recibe los
+// eventos de cada escritura (o avisos con Notify) y Run recarga la entidad y
+// la indexa.
+// Si la cola se llena se descarta el aviso; Reindex lo repara.
+type SearchIndexer struct {
+    db     *gorm.DB
+    index  SearchIndex
+    logger *zap.Logger
+    jobs   chan indexJob
+}
+
+func NewSearchIndexer(db *gorm.DB, index SearchIndex, logger *zap.Logger) *SearchIndexer {
+    return &SearchIndexer{
+        db:     db,
+        index:  index,
+        logger: logger,
+        jobs:   make(chan indexJob, searchQueueSize),
+    }
+}
+
+// Notify encola la entidad para reindexarla. No bloquea y admite un indexer
+// nil, para los servicios creados sin índice.
+func (x *SearchIndexer) Notify(entityType string, id uint) {
+    if x == nil {
+        return
+    }
+
+    select {
+    case x.jobs <- indexJob{entityType: entityType, id: id}:
+    default:
+        x.logger.Warn("Search index queue full, dropping update",
+            zap.String("type", entityType), zap.Uint("id", id))
+    }
+}
+
+// HandleEvent encola la entidad de un evento de dominio.
+func (x *SearchIndexer) HandleEvent(ctx context.Context, event events.Event) error {
+    x.Notify(event.Aggregate(), event.AggregateID)
+    return nil
+}
+
+// Run bloquea hasta que se cancela el contexto.
+func (x *SearchIndexer) Run(ctx context.Context) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case job := <-x.jobs:
+            if err := x.sync(ctx, job); err != nil {
+                x.logger.Error("Failed to update search index",
+                    zap.String("type", job.entityType), zap.Uint("id", job.id), zap.Error(err))
+            }
+        }
+    }
+}
+
+// sync indexa el estado actual de la entidad, o la quita del índice si ya no
+// existe.
+func (x *SearchIndexer) sync(ctx context.Context, job indexJob) error {
+    var doc SearchDocument
+    var err error
+
+    switch job.entityType {
+    case EntityContent:
+        var content Content
+        if err = x.db.WithContext(ctx).First(&content, job.id).Error; err == nil {
+            doc = contentDocument(&content)
+        }
+    case EntityTemplate:
+        var template Template
+        if err = x.db.WithContext(ctx).First(&template, job.id).Error; err == nil {
+            doc = templateDocument(&template)
+        }
+    default:
+        return nil
+    }
+
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return x.index.Delete(ctx, job.entityType, job.id)
+    }
+    if err != nil {
+        return err
+    }
+    return x.index.Index(ctx, doc)
+}
+
+// Reindex vuelve a indexar todos los contenidos y plantillas por lotes y
+// después borra los documentos que ya no existen. El índice no se vacía
+// antes, así que la búsqueda sigue respondiendo durante la reindexación.
+// Devuelve el número de documentos indexados.
+func (x *SearchIndexer) Reindex(ctx context.Context) (int, error) {
+    if err := x.index.Configure(ctx); err != nil {
+        return 0, err
+    }
+
+    total := 0
+    keep := map[string]bool{}
+    var contents []Content
+    err := x.db.WithContext(ctx).FindInBatches(&contents, reindexBatch, func(tx *gorm.DB, batch int) error {
+        docs := make([]SearchDocument, len(contents))
+        for n := range contents {
+            docs[n] = contentDocument(&contents[n])
+            keep[docs[n].ID] = true
+        }
+        total += len(docs)
+        return x.index.Index(ctx, docs...)
+    }).Error
+    if err != nil {
+        return total, err
+    }
+
+    var templates []Template
+    err = x.db.WithContext(ctx).FindInBatches(&templates, reindexBatch, func(tx *gorm.DB, batch int) error {
+        docs := make([]SearchDocument, len(templates))
+        for n := range templates {
+            docs[n] = templateDocument(&templates[n])
+            keep[docs[n].ID] = true
+        }
+        total += len(docs)
+        return x.index.Index(ctx, docs...)
+    }).Error
+    if err != nil {
+        return total, err
+    }
+    return total, x.index.Prune(ctx, keep)
+}
@@ -3,12 +3,27 @@ package cms
 
 import (
     "context"
+    "os"
     "strings"
+    "time"
+    "ezzygo/pkg/cache"
     "gorm.io/gorm"
 )
 
+const (
+    defaultSearchPageSize = 20
+    maxSearchPageSize     = 100
+)
+
 type SearchService struct {
-    db *gorm.DB
+    db            *gorm.DB
+    index         SearchIndex
+    cache         *cache.Store
+    locales       *LocaleConfig
+    synonyms      *SynonymService
+    analytics     *SearchAnalytics
+    suggestTTL    time.Duration
+    suggestBudget time.Duration
 }
 
 type SearchResult struct {
@@ -16,141 +31,125 @@ type SearchResult struct {
     ID        uint        `json:"id"`
     Title     string      `json:"title"`
     Content   string      `json:"content"`
+    Highlight string      `json:"highlight"` // fragmentos con las coincidencias entre <mark>
     Score     float64     `json:"score"`
     Metadata  interface{} `json:"metadata"`
 }
 
 type SearchFilter struct {
-    Types     []string    `json:"types"`     // filtrar por tipo
-    Status    string      `json:"status"`    // draft, published
-    FromDate  *time.Time  `json:"from_date"`
-    ToDate    *time.Time  `json:"to_date"`
+    Types      []string   `json:"types"`     // filtrar por tipo
+    Status     string     `json:"status"`    // draft, published
+    Tags       []string   `json:"tags"`
+    AuthorID   uint       `json:"author_id"`
+    Locale     string     `json:"locale"`
+    FromDate   *time.Time `json:"from_date"`
+    ToDate     *time.Time `json:"to_date"`
+    Page       int        `json:"page"`
+    PageSize   int        `json:"page_size"`
+    PublicOnly bool       `json:"-"` // solo contenido entregable, para la búsqueda pública
+    Variants   []string   `json:"-"` // consultas alternativas generadas por sinónimos
 }
 
-func NewSearchService(db *gorm.DB) *SearchService {
-    return &SearchService{db: db}
+type FacetCount struct {
+    Value string `json:"value"`
+    Count int64  `json:"count"`
 }
 
-func (s *SearchService) Search(ctx context.Context, query string, filter SearchFilter) ([]SearchResult, error) {
-    var results []SearchResult
-
-    // Búsqueda en contenido
-    if len(filter.Types) == 0 || contains(filter.Types, "content") {
-        var contents []Content
-        contentQuery := s.db.WithContext(ctx).
-            Where("LOWER(title) LIKE LOWER(?) OR LOWER(content) LIKE LOWER(?)", 
-                "%"+query+"%", "%"+query+"%")
+type SearchResponse struct {
+    Results  []SearchResult          `json:"results"`
+    Total    int64                   `json:"total"`
+    Page     int                     `json:"page"`
+    PageSize int                     `json:"page_size"`
+    Facets   map[string][]FacetCount `json:"facets"` // type, status, tag, author
+    QueryID  string                  `json:"query_id,omitempty"` // para registrar clics, solo en la búsqueda pública
+}
 
-        if filter.Status != "" {
-            contentQuery = contentQuery.Where("status = ?", filter.Status)
-        }
-        
-        if filter.FromDate != nil {
-            contentQuery = contentQuery.Where("created_at >= ?", filter.FromDate)
-        }
-        
-        if filter.ToDate != nil {
-            contentQuery = contentQuery.Where("created_at <= ?", filter.ToDate)
-        }
+// NewSearchService usa CMS_SUGGEST_CACHE_TTL (por defecto 5m) para cachear
+// sugerencias y CMS_SUGGEST_BUDGET (por defecto 150ms) como tiempo máximo de
+// una consulta de autocompletado.
+func NewSearchService(db *gorm.DB, index SearchIndex, store *cache.Store, locales *LocaleConfig, synonyms *SynonymService, analytics *SearchAnalytics) *SearchService {
+    return &SearchService{
+        db:            db,
+        index:         index,
+        cache:         store,
+        locales:       locales,
+        synonyms:      synonyms,
+        analytics:     analytics,
+        suggestTTL:    durationEnv("CMS_SUGGEST_CACHE_TTL", 5*time.Minute),
+        suggestBudget: durationEnv("CMS_SUGGEST_BUDGET", 150*time.Millisecond),
+    }
+}
 
-        if err := contentQuery.Find(&contents).Error; err != nil {
-            return nil, err
-        }
+// Search normaliza la paginación, aplica el diccionario de sinónimos y stop
+// words y delega en el índice configurado. Las búsquedas públicas se
+// registran para los informes de analítica.
+func (s *SearchService) Search(ctx context.Context, query string, filter SearchFilter) (*SearchResponse, error) {
+    filter.Page, filter.PageSize = normalizePage(filter.Page, filter.PageSize)
 
-        for _, content := range contents {
-            score := calculateScore(query, content.Title, content.Content)
-            results = append(results, SearchResult{
-                Type:    "content",
-                ID:      content.ID,
-                Title:   content.Title,
-                Content: truncateContent(content.Content),
-                Score:   score,
-                Metadata: map[string]interface{}{
-                    "status": content.Status,
-                    "author": content.AuthorID,
-                },
-            })
-        }
+    raw := strings.TrimSpace(query)
+    if raw == "" {
+        return emptySearchResponse(filter), nil
     }
 
-    // Búsqueda en plantillas
-    if len(filter.Types) == 0 || contains(filter.Types, "template") {
-        var templates []Template
-        templateQuery := s.db.WithContext(ctx).
-            Where("LOWER(name) LIKE LOWER(?) OR LOWER(content) LIKE LOWER(?)", 
-                "%"+query+"%", "%"+query+"%")
-
-        if err := templateQuery.Find(&templates).Error; err != nil {
+    query = raw
+    if s.synonyms != nil {
+        dictionary, err := s.synonyms.Dictionary(ctx, filter.Locale)
+        if err != nil {
             return nil, err
         }
-
-        for _, template := range templates {
-            score := calculateScore(query, template.Name, template.Content)
-            results = append(results, SearchResult{
-                Type:    "template",
-                ID:      template.ID,
-                Title:   template.Name,
-                Content: truncateContent(template.Content),
-                Score:   score,
-                Metadata: map[string]interface{}{
-                    "type":    template.Type,
-                    "version": template.Version,
-                },
-            })
-        }
+        query, filter.Variants = dictionary.Apply(raw)
     }
 
-    // Ordenar resultados por score
-    sort.Slice(results, func(i, j int) bool {
-        return results[i].Score > results[j].Score
-    })
-
-    return results, nil
-}
-
-func (s *SearchService) Suggest(ctx context.Context, prefix string) ([]string, error) {
-    var suggestions []string
-    
-    // Sugerencias de títulos de contenido
-    var contentTitles []string
-    if err := s.db.WithContext(ctx).Model(&Content{}).
-        Where("LOWER(title) LIKE LOWER(?)", prefix+"%").
-        Pluck("title", &contentTitles).Error; err != nil {
+    response, err := s.index.Search(ctx, query, filter)
+    if err != nil {
         return nil, err
     }
-    suggestions = append(suggestions, contentTitles...)
 
-    // Sugerencias de nombres de plantillas
-    var templateNames []string
-    if err := s.db.WithContext(ctx).Model(&Template{}).
-        Where("LOWER(name) LIKE LOWER(?)", prefix+"%").
-        Pluck("name", &templateNames).Error; err != nil {
-        return nil, err
+    if filter.PublicOnly {
+        response.QueryID = s.analytics.LogQuery(raw, filter.Locale, response.Total)
     }
-    suggestions = append(suggestions, templateNames...)
-
-    return unique(suggestions), nil
+    return response, nil
 }
 
 // Funciones auxiliares
-func calculateScore(query, title, content string) float64 {
-    query = strings.ToLower(query)
-    title = strings.ToLower(title)
-    content = strings.ToLower(content)
+func emptySearchResponse(filter SearchFilter) *SearchResponse {
+    return &SearchResponse{
+        Results:  []SearchResult{},
+        Page:     filter.Page,
+        PageSize: filter.PageSize,
+        Facets:   map[string][]FacetCount{},
+    }
+}
 
-    titleScore := countOccurrences(query, title) * 2.0  // Mayor peso para coincidencias en título
-    contentScore := countOccurrences(query, content)
+func durationEnv(name string, fallback time.Duration) time.Duration {
+    if value := os.Getenv(name); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+            return parsed
+        }
+    }
+    return fallback
+}
 
-    return titleScore + contentScore
+func normalizePage(page, pageSize int) (int, int) {
+    if page < 1 {
+        page = 1
+    }
+    if pageSize < 1 {
+        pageSize = defaultSearchPageSize
+    }
+    if pageSize > maxSearchPageSize {
+        pageSize = maxSearchPageSize
+    }
+    return page, pageSize
 }
 
-func countOccurrences(substr, str string) float64 {
-    return float64(strings.Count(str, substr))
+func escapeLike(value string) string {
+    return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
 }
 
 func truncateContent(content string) string {
-    if len(content) > 200 {
-        return content[:200] + "..."
+    if len([]rune(content)) > 200 {
+        return string([]rune(content)[:200]) + "..."
     }
     return content
 }
@@ -166,7 +165,7 @@ func contains(slice []string, str string) bool {
 
 func unique(slice []string) []string {
     keys := make(map[string]bool)
-    var list []string
+    list := []string{}
     for _, entry := range slice {
         if _, value := keys[entry]; !value {
             keys[entry] = true
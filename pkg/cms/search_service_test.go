@@ -0,0 +1,64 @@
+// pkg/cms/search_service_test.go
+package cms
+
+import (
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePage(t *testing.T) {
+    page, size := normalizePage(0, 0)
+    assert.Equal(t, 1, page)
+    assert.Equal(t, defaultSearchPageSize, size)
+
+    page, size = normalizePage(3, 500)
+    assert.Equal(t, 3, page)
+    assert.Equal(t, maxSearchPageSize, size)
+}
+
+func TestEscapeLike(t *testing.T) {
+    assert.Equal(t, `100\% \_off\\`, escapeLike(`100% _off\`))
+}
+
+func TestTruncateContent(t *testing.T) {
+    long := ""
+    for i := 0; i < 250; i++ {
+        long += "ñ"
+    }
+
+    truncated := truncateContent(long)
+
+    assert.Equal(t, 203, len([]rune(truncated)))
+    assert.Equal(t, "short", truncateContent("short"))
+}
+
+func TestRankSuggestions(t *testing.T) {
+    ranked := rankSuggestions([]Suggestion{
+        {Text: "Go tips", Type: "content", ID: 1, Score: 0.5},
+        {Text: "go tips", Type: "content", ID: 2, Score: 0.9},
+        {Text: "Golang", Type: "template", ID: 3, Score: 0.7},
+        {Text: "Gopher", Type: "content", ID: 4, Score: 0.1},
+    }, 2)
+
+    assert.Len(t, ranked, 2)
+    assert.Equal(t, uint(2), ranked[0].ID)
+    assert.Equal(t, uint(3), ranked[1].ID)
+}
+
+func TestSuggestCacheKey(t *testing.T) {
+    assert.Equal(t, "cms:suggest:public:es:10:go", suggestCacheKey("Go", SuggestFilter{Locale: "es", Limit: 10, PublicOnly: true}))
+    assert.Equal(t, "cms:suggest:all::5:go", suggestCacheKey("go", SuggestFilter{Limit: 5}))
+}
+
+func TestSuggestMatch(t *testing.T) {
+    assert.Equal(t, "title ILIKE @pattern", suggestMatch("title", false))
+    assert.Equal(t, "(name ILIKE @pattern OR @prefix <% name)", suggestMatch("name", true))
+}
+
+func TestTsQuery(t *testing.T) {
+    expression, args := tsQuery("'simple'", "car", []string{"auto"})
+
+    assert.Equal(t, "(websearch_to_tsquery('simple', ?) || websearch_to_tsquery('simple', ?))", expression)
+    assert.Equal(t, []interface{}{"car", "auto"}, args)
+    assert.Equal(t, []interface{}{"car", "auto", headlineOptions, "car", "auto"}, headlineArgs(args))
+}
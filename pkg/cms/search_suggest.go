@@ -0,0 +1,224 @@
+// pkg/cms/search_suggest.go
+package cms
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "unicode/utf8"
+)
+
+const (
+    defaultSuggestLimit = 10
+    maxSuggestLimit     = 20
+    // Con menos caracteres los trigramas no discriminan y solo se usa el prefijo
+    minFuzzyPrefix      = 3
+)
+
+// Puntuación de una sugerencia: coincidencia de prefijo más similitud de
+// trigramas, multiplicada por la popularidad (lecturas, en escala
+// logarítmica) y por la recencia (el extra por recencia se reduce a la mitad
+// a los 30 días).
+const (
+    matchScore      = `(CASE WHEN %[1]s ILIKE @pattern THEN 1 ELSE 0 END + word_similarity(@prefix, %[1]s))`
+    recencyScore    = `(1 + 1 / (1 + extract(epoch FROM now() - updated_at) / 2592000))`
+    popularityScore = `(1 + ln(1 + view_count) / 10)`
+)
+
+type SuggestFilter struct {
+    Locale     string
+    Limit      int
+    PublicOnly bool // solo contenido entregable, para el endpoint público
+}
+
+type Suggestion struct {
+    Text   string  `json:"text"`
+    Type   string  `json:"type"` // content, template
+    ID     uint    `json:"id"`
+    Locale string  `json:"locale,omitempty"`
+    Score  float64 `json:"score"`
+}
+
+// Suggest autocompleta títulos de contenido y nombres de plantilla. Tolera
+// erratas con pg_trgm, puntúa por popularidad y recencia y cachea en Redis
+// los prefijos consultados. Si la consulta supera el presupuesto de latencia
+// devuelve una lista vacía en lugar de un error.
+func (s *SearchService) Suggest(ctx context.Context, prefix string, filter SuggestFilter) ([]Suggestion, error) {
+    prefix = strings.TrimSpace(prefix)
+    if prefix == "" {
+        return []Suggestion{}, nil
+    }
+    if filter.Locale != "" {
+        filter.Locale = s.locales.Normalize(filter.Locale)
+    }
+    if filter.Limit < 1 || filter.Limit > maxSuggestLimit {
+        filter.Limit = defaultSuggestLimit
+    }
+
+    key := suggestCacheKey(prefix, filter)
+    var cached []Suggestion
+    if err := s.cache.Get(ctx, key, &cached); err == nil {
+        return cached, nil
+    }
+
+    budget, cancel := context.WithTimeout(ctx, s.suggestBudget)
+    defer cancel()
+
+    suggestions, err := s.suggest(budget, prefix, filter)
+    if err != nil {
+        if budget.Err() == context.DeadlineExceeded {
+            return []Suggestion{}, nil
+        }
+        return nil, err
+    }
+
+    ttl := s.suggestTTL
+    if filter.PublicOnly {
+        // Nada purga la entrada cuando una sugerencia caduca o se levanta un
+        // embargo, así que no se cachea más allá
+        until, err := s.suggestUntil(budget, prefix, filter, suggestions)
+        if err != nil {
+            return suggestions, nil
+        }
+        if !until.IsZero() {
+            ttl = min(ttl, time.Until(until))
+        }
+    }
+    if ttl > 0 {
+        s.cache.Set(ctx, key, suggestions, ttl)
+    }
+    return suggestions, nil
+}
+
+// suggestUntil es el próximo momento en que cambia lo que el endpoint
+// público sugiere para el prefijo: la caducidad de una de las sugerencias o
+// el fin del embargo de un contenido que coincide. Cero si no hay ninguno.
+func (s *SearchService) suggestUntil(ctx context.Context, prefix string, filter SuggestFilter, suggestions []Suggestion) (time.Time, error) {
+    now := time.Now()
+    var boundaries []*time.Time
+
+    var ids []uint
+    for _, suggestion := range suggestions {
+        if suggestion.Type == EntityContent {
+            ids = append(ids, suggestion.ID)
+        }
+    }
+    if len(ids) > 0 {
+        var expiry *time.Time
+        err := s.db.WithContext(ctx).Model(&Content{}).
+            Where("id IN ? AND expires_at > ?", ids, now).
+            Select("MIN(expires_at)").
+            Scan(&expiry).Error
+        if err != nil {
+            return time.Time{}, err
+        }
+        boundaries = append(boundaries, expiry)
+    }
+
+    args := map[string]interface{}{
+        "prefix":  prefix,
+        "pattern": escapeLike(prefix) + "%",
+    }
+    embargoQuery := s.db.WithContext(ctx).Model(&Content{}).
+        Where("status = ? AND embargo_until > ?", StatusPublished, now).
+        Where(suggestMatch("title", utf8.RuneCountInString(prefix) >= minFuzzyPrefix), args)
+    if filter.Locale != "" {
+        embargoQuery = embargoQuery.Where("locale = ?", filter.Locale)
+    }
+    var embargo *time.Time
+    if err := embargoQuery.Select("MIN(embargo_until)").Scan(&embargo).Error; err != nil {
+        return time.Time{}, err
+    }
+    boundaries = append(boundaries, embargo)
+
+    var until time.Time
+    for _, boundary := range boundaries {
+        if boundary != nil && (until.IsZero() || boundary.Before(until)) {
+            until = *boundary
+        }
+    }
+    return until, nil
+}
+
+func (s *SearchService) suggest(ctx context.Context, prefix string, filter SuggestFilter) ([]Suggestion, error) {
+    args := map[string]interface{}{
+        "prefix":  prefix,
+        "pattern": escapeLike(prefix) + "%",
+    }
+    fuzzy := utf8.RuneCountInString(prefix) >= minFuzzyPrefix
+
+    var suggestions []Suggestion
+    contentQuery := s.db.WithContext(ctx).Model(&Content{}).
+        Select(fmt.Sprintf(`'content' AS type, id, title AS text, locale, %s * %s * %s AS score`,
+            fmt.Sprintf(matchScore, "title"), popularityScore, recencyScore), args).
+        Where(suggestMatch("title", fuzzy), args)
+    if filter.PublicOnly {
+        contentQuery = contentQuery.Scopes(Delivered(time.Now()))
+    }
+    if filter.Locale != "" {
+        contentQuery = contentQuery.Where("locale = ?", filter.Locale)
+    }
+    if err := contentQuery.Order("score DESC").Limit(filter.Limit).Scan(&suggestions).Error; err != nil {
+        return nil, err
+    }
+
+    // Las plantillas no tienen idioma ni se muestran en el endpoint público
+    if !filter.PublicOnly && filter.Locale == "" {
+        var templates []Suggestion
+        err := s.db.WithContext(ctx).Model(&Template{}).
+            Select(fmt.Sprintf(`'template' AS type, id, name AS text, %s * %s AS score`,
+                fmt.Sprintf(matchScore, "name"), recencyScore), args).
+            Where(suggestMatch("name", fuzzy), args).
+            Order("score DESC").
+            Limit(filter.Limit).
+            Scan(&templates).Error
+        if err != nil {
+            return nil, err
+        }
+        suggestions = append(suggestions, templates...)
+    }
+
+    return rankSuggestions(suggestions, filter.Limit), nil
+}
+
+// suggestMatch filtra por prefijo y, con prefijos suficientemente largos,
+// también por similitud de palabras (operador <% de pg_trgm).
+func suggestMatch(column string, fuzzy bool) string {
+    if !fuzzy {
+        return column + " ILIKE @pattern"
+    }
+    return fmt.Sprintf("(%[1]s ILIKE @pattern OR @prefix <%% %[1]s)", column)
+}
+
+// rankSuggestions ordena por puntuación y quita textos repetidos, quedándose
+// con la aparición mejor puntuada.
+func rankSuggestions(suggestions []Suggestion, limit int) []Suggestion {
+    sort.SliceStable(suggestions, func(i, j int) bool {
+        return suggestions[i].Score > suggestions[j].Score
+    })
+
+    seen := make(map[string]bool)
+    ranked := []Suggestion{}
+    for _, suggestion := range suggestions {
+        text := strings.ToLower(suggestion.Text)
+        if seen[text] {
+            continue
+        }
+        seen[text] = true
+        ranked = append(ranked, suggestion)
+        if len(ranked) == limit {
+            break
+        }
+    }
+    return ranked
+}
+
+func suggestCacheKey(prefix string, filter SuggestFilter) string {
+    scope := "all"
+    if filter.PublicOnly {
+        scope = "public"
+    }
+    return fmt.Sprintf("cms:suggest:%s:%s:%d:%s", scope, filter.Locale, filter.Limit, strings.ToLower(prefix))
+}
@@ -0,0 +1,303 @@
+// pkg/cms/search_synonyms.go
+package cms
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "time"
+    "ezzygo/pkg/cache"
+    "gorm.io/gorm"
+)
+
+const (
+    dictionaryCacheKey = "search:dictionary"
+    // Máximo de consultas alternativas generadas por sinónimos
+    maxQueryVariants = 10
+)
+
+var (
+    ErrInvalidSynonym = errors.New("term requires synonyms or stop_word")
+    ErrSynonymExists  = errors.New("term already exists for locale")
+)
+
+// SearchSynonym es una entrada del diccionario de búsqueda: un término con sus
+// sinónimos o, con StopWord, una palabra que se ignora al buscar. Locale vacío
+// aplica a todos los idiomas.
+type SearchSynonym struct {
+    gorm.Model
+    Term     string      `json:"term" gorm:"not null;uniqueIndex:idx_search_synonym_term"`
+    Locale   string      `json:"locale" gorm:"size:16;not null;default:'';uniqueIndex:idx_search_synonym_term"`
+    Synonyms StringArray `json:"synonyms" gorm:"type:text[];default:'{}'"`
+    StopWord bool        `json:"stop_word"`
+}
+
+// DictionaryIndex lo implementan los motores que gestionan sinónimos y stop
+// words por su cuenta, como Meilisearch.
+type DictionaryIndex interface {
+    SetDictionary(ctx context.Context, synonyms map[string][]string, stopWords []string) error
+}
+
+type SynonymService struct {
+    db      *gorm.DB
+    cache   *cache.Store
+    index   SearchIndex
+    locales *LocaleConfig
+}
+
+func NewSynonymService(db *gorm.DB, store *cache.Store, index SearchIndex, locales *LocaleConfig) *SynonymService {
+    return &SynonymService{
+        db:      db,
+        cache:   store,
+        index:   index,
+        locales: locales,
+    }
+}
+
+func (s *SynonymService) List(ctx context.Context, locale string) ([]SearchSynonym, error) {
+    var entries []SearchSynonym
+    query := s.db.WithContext(ctx).Order("term")
+    if locale != "" {
+        query = query.Where("locale = ?", s.locales.Normalize(locale))
+    }
+    return entries, query.Find(&entries).Error
+}
+
+func (s *SynonymService) Create(ctx context.Context, entry *SearchSynonym) error {
+    if err := s.normalize(entry); err != nil {
+        return err
+    }
+    if err := s.checkUnique(ctx, entry); err != nil {
+        return err
+    }
+    if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+        return err
+    }
+    return s.changed(ctx)
+}
+
+func (s *SynonymService) Update(ctx context.Context, entry *SearchSynonym) error {
+    if err := s.normalize(entry); err != nil {
+        return err
+    }
+
+    var current SearchSynonym
+    if err := s.db.WithContext(ctx).First(&current, entry.ID).Error; err != nil {
+        return err
+    }
+    if err := s.checkUnique(ctx, entry); err != nil {
+        return err
+    }
+    if err := s.db.WithContext(ctx).Model(&current).
+        Select("term", "locale", "synonyms", "stop_word").
+        Updates(entry).Error; err != nil {
+        return err
+    }
+    if err := s.db.WithContext(ctx).First(entry, entry.ID).Error; err != nil {
+        return err
+    }
+    return s.changed(ctx)
+}
+
+// Delete borra la entrada de verdad: con borrado lógico la fila seguiría
+// ocupando el índice único y no se podría volver a crear el término.
+func (s *SynonymService) Delete(ctx context.Context, id uint) error {
+    result := s.db.WithContext(ctx).Unscoped().Delete(&SearchSynonym{}, id)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return s.changed(ctx)
+}
+
+// Dictionary devuelve las entradas que aplican a un idioma, cacheadas hasta
+// el siguiente cambio.
+func (s *SynonymService) Dictionary(ctx context.Context, locale string) (*SearchDictionary, error) {
+    entries, err := cache.GetOrLoad(ctx, s.cache, dictionaryCacheKey, cache.Options[[]SearchSynonym]{TTL: time.Hour},
+        func(ctx context.Context) ([]SearchSynonym, error) {
+            var entries []SearchSynonym
+            err := s.db.WithContext(ctx).Order("term").Find(&entries).Error
+            return entries, err
+        })
+    if err != nil {
+        return nil, err
+    }
+
+    if locale != "" {
+        locale = s.locales.Normalize(locale)
+    }
+    return NewSearchDictionary(entries, locale), nil
+}
+
+// Sync envía el diccionario completo a los motores que lo gestionan. Esos
+// motores no distinguen idiomas, así que se envían todas las entradas.
+func (s *SynonymService) Sync(ctx context.Context) error {
+    index, ok := s.index.(DictionaryIndex)
+    if !ok {
+        return nil
+    }
+
+    var entries []SearchSynonym
+    if err := s.db.WithContext(ctx).Order("term").Find(&entries).Error; err != nil {
+        return err
+    }
+
+    dictionary := NewSearchDictionary(entries, "")
+    stopWords := []string{}
+    for word := range dictionary.StopWords {
+        stopWords = append(stopWords, word)
+    }
+    return index.SetDictionary(ctx, dictionary.Synonyms, stopWords)
+}
+
+func (s *SynonymService) changed(ctx context.Context) error {
+    s.cache.Delete(ctx, dictionaryCacheKey)
+    return s.Sync(ctx)
+}
+
+func (s *SynonymService) checkUnique(ctx context.Context, entry *SearchSynonym) error {
+    var count int64
+    err := s.db.WithContext(ctx).Model(&SearchSynonym{}).
+        Where("term = ? AND locale = ? AND id <> ?", entry.Term, entry.Locale, entry.ID).
+        Count(&count).Error
+    if err != nil {
+        return err
+    }
+    if count > 0 {
+        return ErrSynonymExists
+    }
+    return nil
+}
+
+func (s *SynonymService) normalize(entry *SearchSynonym) error {
+    entry.Term = strings.ToLower(strings.TrimSpace(entry.Term))
+    if entry.Locale != "" {
+        entry.Locale = s.locales.Normalize(entry.Locale)
+    }
+
+    var synonyms []string
+    for _, synonym := range entry.Synonyms {
+        synonym = strings.ToLower(strings.TrimSpace(synonym))
+        if synonym != "" && synonym != entry.Term && !contains(synonyms, synonym) {
+            synonyms = append(synonyms, synonym)
+        }
+    }
+    entry.Synonyms = StringArray(synonyms)
+    if entry.Synonyms == nil {
+        entry.Synonyms = StringArray{}
+    }
+
+    if entry.Term == "" || (len(entry.Synonyms) == 0 && !entry.StopWord) {
+        return ErrInvalidSynonym
+    }
+    return nil
+}
+
+// SearchDictionary es el diccionario ya resuelto para un idioma. Los
+// sinónimos son simétricos: cada palabra del grupo apunta a las demás.
+type SearchDictionary struct {
+    Synonyms  map[string][]string
+    StopWords map[string]bool
+}
+
+// NewSearchDictionary combina las entradas globales con las del idioma
+// indicado. Con locale vacío se usan todas.
+func NewSearchDictionary(entries []SearchSynonym, locale string) *SearchDictionary {
+    dictionary := &SearchDictionary{
+        Synonyms:  map[string][]string{},
+        StopWords: map[string]bool{},
+    }
+
+    for _, entry := range entries {
+        if locale != "" && entry.Locale != "" && entry.Locale != locale {
+            continue
+        }
+        if entry.StopWord {
+            dictionary.StopWords[entry.Term] = true
+            continue
+        }
+
+        group := append([]string{entry.Term}, entry.Synonyms...)
+        for _, word := range group {
+            for _, other := range group {
+                if other != word && !contains(dictionary.Synonyms[word], other) {
+                    dictionary.Synonyms[word] = append(dictionary.Synonyms[word], other)
+                }
+            }
+        }
+    }
+    return dictionary
+}
+
+// Apply quita las stop words de la consulta y devuelve, además, las
+// variantes que resultan de cambiar cada término por uno de sus sinónimos.
+// Respeta la sintaxis de websearch: las frases entre comillas, OR y las
+// exclusiones con - no se tocan.
+func (d *SearchDictionary) Apply(query string) (string, []string) {
+    if d == nil {
+        return query, nil
+    }
+
+    var tokens []string
+    for _, token := range splitQuery(query) {
+        if isQueryOperator(token) || !d.StopWords[strings.ToLower(token)] {
+            tokens = append(tokens, token)
+        }
+    }
+    // Una consulta hecha solo de stop words se busca tal cual
+    if len(tokens) == 0 {
+        return query, nil
+    }
+
+    var variants []string
+    for i, token := range tokens {
+        if isQueryOperator(token) {
+            continue
+        }
+        for _, synonym := range d.Synonyms[strings.ToLower(token)] {
+            if len(variants) == maxQueryVariants {
+                break
+            }
+            if strings.Contains(synonym, " ") {
+                synonym = `"` + synonym + `"`
+            }
+            variant := append([]string{}, tokens...)
+            variant[i] = synonym
+            variants = append(variants, strings.Join(variant, " "))
+        }
+    }
+    return strings.Join(tokens, " "), variants
+}
+
+// splitQuery separa la consulta por espacios manteniendo juntas las frases
+// entre comillas.
+func splitQuery(query string) []string {
+    var tokens []string
+    var current strings.Builder
+    quoted := false
+
+    for _, r := range query {
+        switch {
+        case r == '"':
+            quoted = !quoted
+            current.WriteRune(r)
+        case r == ' ' && !quoted:
+            if current.Len() > 0 {
+                tokens = append(tokens, current.String())
+                current.Reset()
+            }
+        default:
+            current.WriteRune(r)
+        }
+    }
+    if current.Len() > 0 {
+        tokens = append(tokens, current.String())
+    }
+    return tokens
+}
+
+func isQueryOperator(token string) bool {
+    return strings.EqualFold(token, "or")
+}
@@ -0,0 +1,40 @@
+// pkg/cms/search_synonyms_test.go
+package cms
+
+import (
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSearchDictionaryApply(t *testing.T) {
+    dictionary := NewSearchDictionary([]SearchSynonym{
+        {Term: "car", Synonyms: StringArray{"auto", "motor car"}},
+        {Term: "the", StopWord: true},
+        {Term: "or", StopWord: true},
+        {Term: "coche", Locale: "es", Synonyms: StringArray{"auto"}},
+    }, "en")
+
+    query, variants := dictionary.Apply(`The car OR "the bike"`)
+    assert.Equal(t, `car OR "the bike"`, query)
+    assert.Equal(t, []string{`auto OR "the bike"`, `"motor car" OR "the bike"`}, variants)
+
+    // Los sinónimos son simétricos
+    _, variants = dictionary.Apply("auto")
+    assert.Equal(t, []string{"car", `"motor car"`}, variants)
+
+    // Las entradas de otro idioma no aplican
+    _, variants = dictionary.Apply("coche")
+    assert.Empty(t, variants)
+
+    // Una consulta de solo stop words se mantiene
+    query, _ = dictionary.Apply("the")
+    assert.Equal(t, "the", query)
+}
+
+func TestSplitQuery(t *testing.T) {
+    assert.Equal(t, []string{"go", `"web framework"`, "-java"}, splitQuery(`go  "web framework" -java`))
+}
+
+func TestNormalizeSearchQuery(t *testing.T) {
+    assert.Equal(t, "go cms", normalizeSearchQuery("  Go   CMS "))
+}
@@ -0,0 +1,413 @@
+// pkg/cms/seo.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "html"
+    "net/url"
+    "os"
+    "path"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+    "gorm.io/gorm"
+)
+
+var ErrInvalidSEO = errors.New("invalid seo fields")
+
+const (
+    maxSitemapURLs    = 50000 // límite del protocolo por fichero
+    descriptionLength = 160
+)
+
+// Tipo schema.org del JSON-LD según el tipo de plantilla. El resto son
+// WebPage.
+var structuredDataTypes = map[string]string{
+    "post":    "BlogPosting",
+    "article": "Article",
+    "news":    "NewsArticle",
+}
+
+// SEO son los datos de cabecera de una página ya resueltos: los campos SEO
+// del contenido o, si están vacíos, los que se deducen de él.
+type SEO struct {
+    Title       string          `json:"title"`
+    Description string          `json:"description"`
+    URL         string          `json:"url"`
+    Canonical   string          `json:"canonical"`
+    Image       string          `json:"image,omitempty"`
+    Robots      string          `json:"robots"`
+    Locale      string          `json:"locale"`
+    Alternates  []SEOAlternate  `json:"alternates,omitempty"` // hreflang
+    JSONLD      json.RawMessage `json:"json_ld"`
+}
+
+type SEOAlternate struct {
+    Hreflang string `json:"hreflang"`
+    URL      string `json:"url"`
+}
+
+// SEOService genera los datos SEO, el sitemap y robots.txt del sitio público.
+type SEOService struct {
+    db          *gorm.DB
+    locales     *LocaleConfig
+    siteURL     string
+    sitemapSize int
+    disallow    []string
+}
+
+// NewSEOService lee la configuración del entorno:
+//   CMS_SITE_URL=https://www.example.com  (URL pública del frontend)
+//   CMS_SITEMAP_SIZE=50000                (URLs por fichero de sitemap)
+//   CMS_ROBOTS_DISALLOW=/search,/preview  ("/" bloquea todo el sitio)
+func NewSEOService(db *gorm.DB, locales *LocaleConfig) *SEOService {
+    service := &SEOService{
+        db:          db,
+        locales:     locales,
+        siteURL:     strings.TrimSuffix(os.Getenv("CMS_SITE_URL"), "/"),
+        sitemapSize: maxSitemapURLs,
+    }
+    if size, err := strconv.Atoi(os.Getenv("CMS_SITEMAP_SIZE")); err == nil && size > 0 && size < maxSitemapURLs {
+        service.sitemapSize = size
+    }
+    for _, rule := range strings.Split(os.Getenv("CMS_ROBOTS_DISALLOW"), ",") {
+        if rule = strings.TrimSpace(rule); rule != "" {
+            service.disallow = append(service.disallow, rule)
+        }
+    }
+    return service
+}
+
+// ForSite devuelve el servicio con la misma configuración para otra URL
+// pública, p. ej. la del sitio estático. Con siteURL vacía devuelve s.
+func (s *SEOService) ForSite(siteURL string) *SEOService {
+    if siteURL == "" || siteURL == s.siteURL {
+        return s
+    }
+    site := *s
+    site.siteURL = siteURL
+    return &site
+}
+
+// ContentPath es la ruta pública de un contenido: /<slug>/ en el idioma por
+// defecto y /<locale>/<slug>/ en los demás. El slug "index" es la portada.
+func ContentPath(locales *LocaleConfig, content *Content) string {
+    dir := strings.Trim(path.Clean("/"+content.Slug), "/")
+    if dir == "index" {
+        dir = ""
+    }
+    if content.Locale != locales.Default {
+        dir = path.Join(content.Locale, dir)
+    }
+    if dir == "" {
+        return "/"
+    }
+    return "/" + dir + "/"
+}
+
+// ForContent resuelve los datos SEO de un contenido publicado, con sus
+// traducciones publicadas como alternativas hreflang.
+func (s *SEOService) ForContent(ctx context.Context, content *Content) (*SEO, error) {
+    db := s.db.WithContext(ctx)
+
+    var templateType string
+    if content.TemplateID != 0 {
+        var template Template
+        err := db.Select("id", "type").First(&template, content.TemplateID).Error
+        if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, err
+        }
+        templateType = template.Type
+    }
+
+    var image *Media
+    if content.OGImageID != nil {
+        var media Media
+        err := db.First(&media, *content.OGImageID).Error
+        if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, err
+        }
+        if err == nil {
+            image = &media
+        }
+    }
+
+    var translations []Content
+    if content.TranslationGroupID != 0 {
+        if err := db.Scopes(Delivered(time.Now()), indexable).
+            Select("id", "slug", "locale").
+            Where("translation_group_id = ?", content.TranslationGroupID).
+            Order("locale").
+            Find(&translations).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    return buildSEO(s.siteURL, s.locales, content, templateType, image, translations)
+}
+
+// buildSEO completa los campos vacíos y genera el JSON-LD.
+func buildSEO(siteURL string, locales *LocaleConfig, content *Content, templateType string, image *Media, translations []Content) (*SEO, error) {
+    seo := &SEO{
+        Title:       content.MetaTitle,
+        Description: content.MetaDescription,
+        URL:         siteURL + ContentPath(locales, content),
+        Canonical:   content.CanonicalURL,
+        Robots:      "index, follow",
+        Locale:      content.Locale,
+    }
+    if seo.Title == "" {
+        seo.Title = content.Title
+    }
+    if seo.Description == "" {
+        seo.Description = excerpt(content.Content, descriptionLength)
+    }
+    if seo.Canonical == "" {
+        seo.Canonical = seo.URL
+    }
+    if image != nil {
+        seo.Image = image.URL
+    }
+    if content.NoIndex {
+        seo.Robots = "noindex, follow"
+    } else {
+        seo.Alternates = alternates(siteURL, locales, translations)
+    }
+
+    data, err := json.Marshal(structuredData(seo, content, templateType))
+    if err != nil {
+        return nil, err
+    }
+    seo.JSONLD = data
+    return seo, nil
+}
+
+// alternates son los enlaces hreflang de un grupo de traducciones, más
+// x-default para el idioma por defecto. Con una sola traducción no hay.
+func alternates(siteURL string, locales *LocaleConfig, translations []Content) []SEOAlternate {
+    if len(translations) < 2 {
+        return nil
+    }
+    var links []SEOAlternate
+    var fallback string
+    for n := range translations {
+        link := siteURL + ContentPath(locales, &translations[n])
+        links = append(links, SEOAlternate{Hreflang: translations[n].Locale, URL: link})
+        if translations[n].Locale == locales.Default {
+            fallback = link
+        }
+    }
+    if fallback != "" {
+        links = append(links, SEOAlternate{Hreflang: "x-default", URL: fallback})
+    }
+    return links
+}
+
+// structuredData es el JSON-LD schema.org de la página: un artículo para las
+// plantillas de tipo post, article o news y WebPage para el resto.
+func structuredData(seo *SEO, content *Content, templateType string) map[string]interface{} {
+    kind, ok := structuredDataTypes[templateType]
+    if !ok {
+        kind = "WebPage"
+    }
+
+    data := map[string]interface{}{
+        "@context":   "https://schema.org",
+        "@type":      kind,
+        "url":        seo.Canonical,
+        "inLanguage": seo.Locale,
+    }
+    if seo.Description != "" {
+        data["description"] = seo.Description
+    }
+    if !content.UpdatedAt.IsZero() {
+        data["dateModified"] = content.UpdatedAt.UTC().Format(time.RFC3339)
+    }
+
+    if kind == "WebPage" {
+        data["name"] = seo.Title
+        if seo.Image != "" {
+            data["primaryImageOfPage"] = map[string]interface{}{"@type": "ImageObject", "url": seo.Image}
+        }
+        return data
+    }
+
+    // Google recorta headline a 110 caracteres
+    data["headline"] = truncate(seo.Title, 110)
+    data["mainEntityOfPage"] = seo.Canonical
+    if !content.PublishedAt.IsZero() {
+        data["datePublished"] = content.PublishedAt.UTC().Format(time.RFC3339)
+    }
+    if seo.Image != "" {
+        data["image"] = []string{seo.Image}
+    }
+    if len(content.Tags) > 0 {
+        data["keywords"] = strings.Join(content.Tags, ", ")
+    }
+    return data
+}
+
+// Sitemap devuelve un fichero del sitemap. La página 0 es /sitemap.xml: el
+// sitemap completo o, si no cabe en uno, el índice de /sitemaps/<n>.xml.
+// Solo incluye el contenido publicado, indexable y sin canonical a otra URL.
+func (s *SEOService) Sitemap(ctx context.Context, page int) ([]byte, error) {
+    db := s.db.WithContext(ctx)
+    now := time.Now()
+
+    var total int64
+    if err := db.Model(&Content{}).Scopes(Delivered(now), indexable).Count(&total).Error; err != nil {
+        return nil, err
+    }
+    files := int((total + int64(s.sitemapSize) - 1) / int64(s.sitemapSize))
+
+    if page == 0 && files > 1 {
+        index := sitemapIndex{Xmlns: sitemapNamespace}
+        for n := 1; n <= files; n++ {
+            index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: fmt.Sprintf("%s/sitemaps/%d.xml", s.siteURL, n)})
+        }
+        return marshalXML(index)
+    }
+    if page == 0 {
+        page = 1
+    }
+    if page < 1 || page > max(files, 1) {
+        return nil, gorm.ErrRecordNotFound
+    }
+
+    var contents []Content
+    if err := db.Scopes(Delivered(now), indexable).
+        Select("id", "slug", "locale", "translation_group_id", "canonical_url", "updated_at").
+        Order("id").
+        Offset((page - 1) * s.sitemapSize).
+        Limit(s.sitemapSize).
+        Find(&contents).Error; err != nil {
+        return nil, err
+    }
+
+    groups := make([]uint, 0, len(contents))
+    for _, content := range contents {
+        groups = append(groups, content.TranslationGroupID)
+    }
+    byGroup := map[uint][]Content{}
+    if len(groups) > 0 {
+        var translations []Content
+        if err := db.Scopes(Delivered(now), indexable).
+            Select("id", "slug", "locale", "translation_group_id").
+            Where("translation_group_id IN ?", groups).
+            Order("locale").
+            Find(&translations).Error; err != nil {
+            return nil, err
+        }
+        for _, translation := range translations {
+            byGroup[translation.TranslationGroupID] = append(byGroup[translation.TranslationGroupID], translation)
+        }
+    }
+
+    return marshalXML(s.urlSet(contents, byGroup))
+}
+
+func (s *SEOService) urlSet(contents []Content, byGroup map[uint][]Content) sitemapURLSet {
+    set := sitemapURLSet{Xmlns: sitemapNamespace, XmlnsXHTML: xhtmlNamespace}
+    for n := range contents {
+        content := &contents[n]
+        loc := s.siteURL + ContentPath(s.locales, content)
+        if content.CanonicalURL != "" && content.CanonicalURL != loc {
+            continue
+        }
+
+        entry := sitemapURL{Loc: loc, LastMod: content.UpdatedAt.UTC().Format(time.RFC3339)}
+        for _, alternate := range alternates(s.siteURL, s.locales, byGroup[content.TranslationGroupID]) {
+            entry.Links = append(entry.Links, sitemapLink{Rel: "alternate", Hreflang: alternate.Hreflang, Href: alternate.URL})
+        }
+        set.URLs = append(set.URLs, entry)
+    }
+    return set
+}
+
+// Robots devuelve robots.txt: las rutas de CMS_ROBOTS_DISALLOW y el sitemap.
+func (s *SEOService) Robots() string {
+    var b strings.Builder
+    b.WriteString("User-agent: *\n")
+    if len(s.disallow) == 0 {
+        b.WriteString("Disallow:\n")
+    }
+    for _, rule := range s.disallow {
+        fmt.Fprintf(&b, "Disallow: %s\n", rule)
+    }
+    if s.siteURL != "" {
+        fmt.Fprintf(&b, "\nSitemap: %s/sitemap.xml\n", s.siteURL)
+    }
+    return b.String()
+}
+
+// validateSEO comprueba los campos SEO antes de guardar un contenido.
+func (s *ContentService) validateSEO(ctx context.Context, content *Content) error {
+    if err := checkSEOFields(content); err != nil {
+        return err
+    }
+    if content.OGImageID == nil {
+        return nil
+    }
+
+    var media Media
+    err := s.db.WithContext(ctx).Select("id", "mime_type").First(&media, *content.OGImageID).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return fmt.Errorf("%w: og_image_id: media not found", ErrInvalidSEO)
+    }
+    if err != nil {
+        return err
+    }
+    if !strings.HasPrefix(media.MimeType, "image/") {
+        return fmt.Errorf("%w: og_image_id: media is not an image", ErrInvalidSEO)
+    }
+    return nil
+}
+
+func checkSEOFields(content *Content) error {
+    if utf8.RuneCountInString(content.MetaTitle) > 255 {
+        return fmt.Errorf("%w: meta_title is longer than 255 characters", ErrInvalidSEO)
+    }
+    if utf8.RuneCountInString(content.MetaDescription) > 500 {
+        return fmt.Errorf("%w: meta_description is longer than 500 characters", ErrInvalidSEO)
+    }
+    if content.CanonicalURL != "" {
+        parsed, err := url.Parse(content.CanonicalURL)
+        if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+            return fmt.Errorf("%w: canonical_url must be an absolute http(s) URL", ErrInvalidSEO)
+        }
+    }
+    return nil
+}
+
+func indexable(db *gorm.DB) *gorm.DB {
+    return db.Where("no_index = ?", false)
+}
+
+var (
+    tagPattern   = regexp.MustCompile(`<[^>]*>`)
+    spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// excerpt es el texto del cuerpo HTML, recortado a n caracteres sin partir
+// palabras.
+func excerpt(body string, n int) string {
+    text := html.UnescapeString(tagPattern.ReplaceAllString(body, " "))
+    return truncate(strings.TrimSpace(spacePattern.ReplaceAllString(text, " ")), n)
+}
+
+func truncate(text string, n int) string {
+    if utf8.RuneCountInString(text) <= n {
+        return text
+    }
+    runes := []rune(text)[:n-1]
+    cut := string(runes)
+    if i := strings.LastIndex(cut, " "); i > 0 {
+        cut = cut[:i]
+    }
+    return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -0,0 +1,165 @@
+// pkg/cms/seo_test.go
+package cms
+
+import (
+    "encoding/json"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+var seoLocales = &LocaleConfig{Default: "en", Supported: []string{"en", "es"}}
+
+func seoContent(id uint, slug, locale string) Content {
+    content := Content{Slug: slug, Locale: locale, TranslationGroupID: 1}
+    content.ID = id
+    return content
+}
+
+func TestContentPath(t *testing.T) {
+    assert.Equal(t, "/about/", ContentPath(seoLocales, &Content{Slug: "about", Locale: "en"}))
+    assert.Equal(t, "/es/about/", ContentPath(seoLocales, &Content{Slug: "about", Locale: "es"}))
+    assert.Equal(t, "/", ContentPath(seoLocales, &Content{Slug: "index", Locale: "en"}))
+    assert.Equal(t, "/es/", ContentPath(seoLocales, &Content{Slug: "index", Locale: "es"}))
+}
+
+func TestBuildSEODefaults(t *testing.T) {
+    content := &Content{
+        Title:   "Hello",
+        Slug:    "hello",
+        Locale:  "en",
+        Content: "<p>First   paragraph &amp; more</p><p>Second</p>",
+    }
+
+    seo, err := buildSEO("https://example.com", seoLocales, content, "page", nil, nil)
+    require.NoError(t, err)
+    assert.Equal(t, "Hello", seo.Title)
+    assert.Equal(t, "First paragraph & more Second", seo.Description)
+    assert.Equal(t, "https://example.com/hello/", seo.URL)
+    assert.Equal(t, "https://example.com/hello/", seo.Canonical)
+    assert.Equal(t, "index, follow", seo.Robots)
+    assert.Empty(t, seo.Image)
+    assert.Empty(t, seo.Alternates)
+
+    var data map[string]interface{}
+    require.NoError(t, json.Unmarshal(seo.JSONLD, &data))
+    assert.Equal(t, "https://schema.org", data["@context"])
+    assert.Equal(t, "WebPage", data["@type"])
+    assert.Equal(t, "Hello", data["name"])
+    assert.Equal(t, "en", data["inLanguage"])
+}
+
+func TestBuildSEOFields(t *testing.T) {
+    imageID := uint(3)
+    content := &Content{
+        Title:           "Hello",
+        Slug:            "hola",
+        Locale:          "es",
+        MetaTitle:       "Hola, mundo",
+        MetaDescription: "Descripción",
+        CanonicalURL:    "https://example.com/hello/",
+        OGImageID:       &imageID,
+        PublishedAt:     time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+        Tags:            StringArray{"go", "cms"},
+    }
+    image := &Media{URL: "https://cdn.test/og.png"}
+    translations := []Content{seoContent(1, "hello", "en"), seoContent(2, "hola", "es")}
+
+    seo, err := buildSEO("https://example.com", seoLocales, content, "post", image, translations)
+    require.NoError(t, err)
+    assert.Equal(t, "Hola, mundo", seo.Title)
+    assert.Equal(t, "Descripción", seo.Description)
+    assert.Equal(t, "https://example.com/es/hola/", seo.URL)
+    assert.Equal(t, "https://example.com/hello/", seo.Canonical)
+    assert.Equal(t, "https://cdn.test/og.png", seo.Image)
+    assert.Equal(t, []SEOAlternate{
+        {Hreflang: "en", URL: "https://example.com/hello/"},
+        {Hreflang: "es", URL: "https://example.com/es/hola/"},
+        {Hreflang: "x-default", URL: "https://example.com/hello/"},
+    }, seo.Alternates)
+
+    var data map[string]interface{}
+    require.NoError(t, json.Unmarshal(seo.JSONLD, &data))
+    assert.Equal(t, "BlogPosting", data["@type"])
+    assert.Equal(t, "Hola, mundo", data["headline"])
+    assert.Equal(t, "2024-03-01T10:00:00Z", data["datePublished"])
+    assert.Equal(t, []interface{}{"https://cdn.test/og.png"}, data["image"])
+    assert.Equal(t, "go, cms", data["keywords"])
+
+    content.NoIndex = true
+    seo, err = buildSEO("https://example.com", seoLocales, content, "post", image, translations)
+    require.NoError(t, err)
+    assert.Equal(t, "noindex, follow", seo.Robots)
+    assert.Empty(t, seo.Alternates)
+}
+
+func TestExcerpt(t *testing.T) {
+    assert.Equal(t, "Short text", excerpt("<p>Short <b>text</b></p>", 160))
+    assert.Equal(t, "one two…", excerpt("one two three four", 12))
+    assert.Equal(t, "ñandú…", truncate("ñandú ñandú", 8))
+}
+
+func TestCheckSEOFields(t *testing.T) {
+    assert.NoError(t, checkSEOFields(&Content{CanonicalURL: "https://example.com/a/"}))
+    assert.NoError(t, checkSEOFields(&Content{}))
+
+    for _, content := range []*Content{
+        {CanonicalURL: "/relative"},
+        {CanonicalURL: "ftp://example.com/a"},
+        {MetaTitle: strings.Repeat("a", 256)},
+        {MetaDescription: strings.Repeat("a", 501)},
+    } {
+        err := checkSEOFields(content)
+        assert.True(t, errors.Is(err, ErrInvalidSEO), "%+v", content)
+    }
+}
+
+func TestSitemapURLSet(t *testing.T) {
+    service := &SEOService{locales: seoLocales, siteURL: "https://example.com"}
+    updated := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    en := seoContent(1, "hello", "en")
+    en.UpdatedAt = updated
+    es := seoContent(2, "hola", "es")
+    es.UpdatedAt = updated
+    duplicate := seoContent(3, "copy", "en")
+    duplicate.TranslationGroupID = 3
+    duplicate.CanonicalURL = "https://example.com/hello/"
+
+    body, err := marshalXML(service.urlSet(
+        []Content{en, es, duplicate},
+        map[uint][]Content{1: {en, es}, 3: {duplicate}},
+    ))
+    require.NoError(t, err)
+
+    sitemap := string(body)
+    assert.Contains(t, sitemap, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">`)
+    assert.Contains(t, sitemap, "<loc>https://example.com/hello/</loc>")
+    assert.Contains(t, sitemap, "<loc>https://example.com/es/hola/</loc>")
+    assert.Contains(t, sitemap, "<lastmod>2024-03-01T10:00:00Z</lastmod>")
+    assert.Contains(t, sitemap, `<xhtml:link rel="alternate" hreflang="es" href="https://example.com/es/hola/"></xhtml:link>`)
+    assert.Contains(t, sitemap, `<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/hello/"></xhtml:link>`)
+    // Con canonical a otra URL no entra en el sitemap
+    assert.NotContains(t, sitemap, "copy")
+}
+
+func TestSitemapIndex(t *testing.T) {
+    body, err := marshalXML(sitemapIndex{
+        Xmlns:    sitemapNamespace,
+        Sitemaps: []sitemapRef{{Loc: "https://example.com/sitemaps/1.xml"}},
+    })
+    require.NoError(t, err)
+    assert.Contains(t, string(body), `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+    assert.Contains(t, string(body), "<loc>https://example.com/sitemaps/1.xml</loc>")
+}
+
+func TestRobots(t *testing.T) {
+    service := &SEOService{siteURL: "https://example.com"}
+    assert.Equal(t, "User-agent: *\nDisallow:\n\nSitemap: https://example.com/sitemap.xml\n", service.Robots())
+
+    service = &SEOService{disallow: []string{"/search", "/preview"}}
+    assert.Equal(t, "User-agent: *\nDisallow: /search\nDisallow: /preview\n", service.Robots())
+}
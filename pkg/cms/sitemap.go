@@ -0,0 +1,54 @@
+// pkg/cms/sitemap.go
+package cms
+
+import (
+    "encoding/xml"
+)
+
+// Formato sitemaps.org, compartido por el sitemap de la API de entrega y el
+// del sitio estático.
+
+const (
+    sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+    xhtmlNamespace   = "http://www.w3.org/1999/xhtml"
+)
+
+type sitemapURLSet struct {
+    XMLName    xml.Name     `xml:"urlset"`
+    Xmlns      string       `xml:"xmlns,attr"`
+    XmlnsXHTML string       `xml:"xmlns:xhtml,attr,omitempty"`
+    URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+    Loc     string        `xml:"loc"`
+    LastMod string        `xml:"lastmod,omitempty"`
+    Links   []sitemapLink `xml:"xhtml:link"`
+}
+
+// sitemapLink es una alternativa hreflang de la URL.
+type sitemapLink struct {
+    Rel      string `xml:"rel,attr"`
+    Hreflang string `xml:"hreflang,attr"`
+    Href     string `xml:"href,attr"`
+}
+
+type sitemapIndex struct {
+    XMLName  xml.Name     `xml:"sitemapindex"`
+    Xmlns    string       `xml:"xmlns,attr"`
+    Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+    Loc string `xml:"loc"`
+}
+
+// marshalXML serializa con la declaración XML, como esperan los lectores de
+// sitemaps y feeds.
+func marshalXML(v interface{}) ([]byte, error) {
+    body, err := xml.MarshalIndent(v, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,681 @@
+// pkg/cms/static_export.go
+package cms
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "html"
+    "html/template"
+    "net/http"
+    "os"
+    "path"
+    "regexp"
+    "strings"
+    "sync"
+    "time"
+    "ezzygo/pkg/events"
+    "go.uber.org/zap"
+    "gorm.io/gorm"
+)
+
+var (
+    ErrStaticExportDisabled = errors.New("static export is not configured")
+    ErrStaticExportRunning  = errors.New("another static export is running")
+)
+
+const (
+    staticDebounce = 2 * time.Second
+    staticRetry    = 30 * time.Second // espera si otra réplica está exportando
+)
+
+// staticExportLock es la clave del advisory lock de Postgres que comparten
+// todas las réplicas y el comando export.
+const staticExportLock int64 = 0x7374617469630001
+
+// StaticPage registra cada página exportada y de qué depende (plantilla y
+// medios), para que la exportación incremental regenere solo las afectadas.
+type StaticPage struct {
+    ContentID    uint        `json:"content_id" gorm:"primaryKey;autoIncrement:false"`
+    Path         string      `json:"path" gorm:"index;not null"`
+    TemplateID   uint        `json:"template_id" gorm:"index"` // 0 = plantilla integrada
+    Assets       StringArray `json:"assets" gorm:"type:text[];default:'{}'"`
+    Hash         string      `json:"hash" gorm:"size:64"`
+    LastModified time.Time   `json:"last_modified"`
+    ExportedAt   time.Time   `json:"exported_at"` // cero si hay que reintentarla
+}
+
+// StaticPageData es lo que recibe la plantilla de cada página.
+type StaticPageData struct {
+    Content *Content
+    Body    template.HTML          // Content.Content sin escapar: es HTML del editor
+    Meta    map[string]interface{} // MetaData decodificado
+    URL     string                 // URL pública de la página
+    SiteURL string
+    SEO     *SEO
+
+    StructuredData template.JS // SEO.JSONLD, para <script type="application/ld+json">
+}
+
+type StaticExportResult struct {
+    Pages     int      `json:"pages"` // páginas publicadas
+    Written   int      `json:"written"`
+    Unchanged int      `json:"unchanged"`
+    Removed   int      `json:"removed"`
+    Assets    int      `json:"assets"`
+    Errors    []string `json:"errors,omitempty"`
+}
+
+// StaticExporter genera el sitio estático: una página HTML por contenido
+// publicado, renderizada con su plantilla, los medios que enlaza en assets/
+// y sitemap.xml.
+// La exportación incremental compara las fechas de contenido, plantilla y
+// medios con las de la última exportación de cada página, así que no depende
+// de no haberse perdido ningún evento.
+type StaticExporter struct {
+    db       *gorm.DB
+    target   SiteTarget
+    locales  *LocaleConfig
+    siteURL  string
+    seo      *SEOService
+    client   *http.Client
+    logger   *zap.Logger
+    interval time.Duration
+    wake     chan struct{}
+    mu       sync.Mutex
+}
+
+// NewStaticExporter escribe en target. La URL pública del sitio se lee de
+// STATIC_SITE_URL (CMS_SITE_URL si no se define), y STATIC_EXPORT_INTERVAL (10m por
+// defecto) fija cada cuánto Run exporta aunque no haya cambios, p. ej. para
+// los embargos que vencen. Las cabeceras SEO y el sitemap usan la
+// configuración de seo con la URL del sitio estático.
+func NewStaticExporter(db *gorm.DB, target SiteTarget, locales *LocaleConfig, seo *SEOService, logger *zap.Logger) *StaticExporter {
+    interval := 10 * time.Minute
+    if value := os.Getenv("STATIC_EXPORT_INTERVAL"); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+            interval = parsed
+        } else {
+            logger.Warn("Invalid STATIC_EXPORT_INTERVAL, using default", zap.String("value", value))
+        }
+    }
+
+    siteURL := os.Getenv("STATIC_SITE_URL")
+    if siteURL == "" {
+        siteURL = os.Getenv("CMS_SITE_URL")
+    }
+    siteURL = strings.TrimSuffix(siteURL, "/")
+
+    return &StaticExporter{
+        db:       db,
+        target:   target,
+        locales:  locales,
+        siteURL:  siteURL,
+        seo:      seo.ForSite(siteURL),
+        client:   &http.Client{Timeout: 30 * time.Second},
+        logger:   logger,
+        interval: interval,
+        wake:     make(chan struct{}, 1),
+    }
+}
+
+// Notify pide una exportación incremental. No bloquea y admite un exporter
+// nil, cuando no hay destino configurado.
+func (x *StaticExporter) Notify() {
+    if x == nil {
+        return
+    }
+    select {
+    case x.wake <- struct{}{}:
+    default:
+    }
+}
+
+// HandleEvent lanza la exportación incremental tras los cambios de
+// contenido, plantillas y medios.
+func (x *StaticExporter) HandleEvent(ctx context.Context, event events.Event) error {
+    switch event.Aggregate() {
+    case EntityContent, EntityTemplate, EntityMedia:
+        x.Notify()
+    }
+    return nil
+}
+
+// Run bloquea hasta que se cancela el contexto. Al arrancar exporta lo que
+// haya cambiado mientras estaba parado. Si otra réplica está exportando, un
+// aviso se reintenta pasado staticRetry; el sondeo periódico simplemente se
+// salta.
+func (x *StaticExporter) Run(ctx context.Context) {
+    ticker := time.NewTicker(x.interval)
+    defer ticker.Stop()
+    x.Notify()
+
+    for {
+        woken := false
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        case <-x.wake:
+            woken = true
+            // Agrupa las escrituras seguidas en una sola exportación
+            select {
+            case <-ctx.Done():
+                return
+            case <-time.After(staticDebounce):
+            }
+        }
+
+        result, err := x.Export(ctx, false)
+        if errors.Is(err, ErrStaticExportRunning) {
+            if woken {
+                time.AfterFunc(staticRetry, x.Notify)
+            }
+            continue
+        }
+        if err != nil {
+            x.logger.Error("Failed to export static site", zap.Error(err))
+            continue
+        }
+        if result.Written > 0 || result.Removed > 0 || len(result.Errors) > 0 {
+            x.logger.Info("Static site exported",
+                zap.Int("written", result.Written),
+                zap.Int("removed", result.Removed),
+                zap.Int("assets", result.Assets),
+                zap.Strings("errors", result.Errors))
+        }
+    }
+}
+
+// Export regenera las páginas cuyo contenido, plantilla o medios cambiaron
+// desde su última exportación y quita las que ya no están publicadas. Con
+// full regenera todas. Los fallos de una página no paran el resto: se
+// devuelven en Errors y la página se reintenta en la siguiente exportación.
+// Solo exporta una réplica a la vez: el resto recibe ErrStaticExportRunning.
+func (x *StaticExporter) Export(ctx context.Context, full bool) (*StaticExportResult, error) {
+    if x == nil || x.target == nil {
+        return nil, ErrStaticExportDisabled
+    }
+
+    x.mu.Lock()
+    defer x.mu.Unlock()
+
+    // El advisory lock es de la sesión: se toma y se suelta en la misma
+    // conexión, que queda reservada mientras dura la exportación
+    var result *StaticExportResult
+    err := x.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+        var acquired bool
+        if err := conn.Raw("SELECT pg_try_advisory_lock(?)", staticExportLock).Scan(&acquired).Error; err != nil {
+            return err
+        }
+        if !acquired {
+            return ErrStaticExportRunning
+        }
+        defer func() {
+            // Se suelta aunque ctx se haya cancelado: la conexión vuelve al pool
+            if err := conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", staticExportLock).Error; err != nil {
+                x.logger.Error("Failed to release static export lock", zap.Error(err))
+            }
+        }()
+
+        var err error
+        result, err = x.export(ctx, full)
+        return err
+    })
+    return result, err
+}
+
+// export hace la exportación con el lock ya tomado.
+func (x *StaticExporter) export(ctx context.Context, full bool) (*StaticExportResult, error) {
+    // Lo que cambie a partir de aquí se verá en la siguiente exportación
+    started := time.Now()
+    db := x.db.WithContext(ctx)
+
+    var contents []Content
+    if err := db.Scopes(Delivered(started)).
+        Select("id", "slug", "locale", "template_id", "updated_at").
+        Order("id").Find(&contents).Error; err != nil {
+        return nil, err
+    }
+
+    var templates []Template
+    if err := db.Select("id", "is_default", "updated_at").Order("id").Find(&templates).Error; err != nil {
+        return nil, err
+    }
+    templateUpdated := make(map[uint]time.Time, len(templates))
+    var defaultTemplate uint
+    for _, t := range templates {
+        templateUpdated[t.ID] = t.UpdatedAt
+        if t.IsDefault && defaultTemplate == 0 {
+            defaultTemplate = t.ID
+        }
+    }
+
+    var pages []StaticPage
+    if err := db.Find(&pages).Error; err != nil {
+        return nil, err
+    }
+    previous := make(map[uint]StaticPage, len(pages))
+    knownAssets := map[string]bool{}
+    for _, page := range pages {
+        previous[page.ContentID] = page
+        for _, asset := range page.Assets {
+            knownAssets[asset] = true
+        }
+    }
+
+    mediaUpdated, err := x.mediaUpdated(ctx, knownAssets)
+    if err != nil {
+        return nil, err
+    }
+
+    run := &staticRun{
+        exporter:  x,
+        ctx:       ctx,
+        full:      full,
+        templates: map[uint]*template.Template{},
+        known:     knownAssets,
+        written:   map[string]bool{},
+        result:    &StaticExportResult{Pages: len(contents)},
+    }
+
+    published := make(map[uint]bool, len(contents))
+    for _, content := range contents {
+        published[content.ID] = true
+
+        templateID := content.TemplateID
+        if _, ok := templateUpdated[templateID]; !ok {
+            templateID = defaultTemplate
+        }
+
+        page, exists := previous[content.ID]
+        stale := full || !exists ||
+            page.Path != x.pagePath(&content) ||
+            page.TemplateID != templateID ||
+            content.UpdatedAt.After(page.ExportedAt) ||
+            templateUpdated[templateID].After(page.ExportedAt)
+        for _, asset := range page.Assets {
+            updated, ok := mediaUpdated[asset]
+            stale = stale || !ok || updated.After(page.ExportedAt)
+        }
+        if !stale {
+            run.result.Unchanged++
+            continue
+        }
+
+        if err := run.exportPage(content.ID, templateID, page, exists, started); err != nil {
+            run.fail(fmt.Errorf("content %d: %w", content.ID, err))
+        }
+    }
+
+    // Páginas despublicadas, borradas o fuera de su ventana de embargo
+    for id, page := range previous {
+        if published[id] {
+            continue
+        }
+        if err := x.target.Delete(ctx, page.Path); err != nil {
+            run.fail(fmt.Errorf("remove %s: %w", page.Path, err))
+            continue
+        }
+        if err := db.Delete(&StaticPage{}, id).Error; err != nil {
+            return run.result, err
+        }
+        run.result.Removed++
+    }
+
+    if full || run.result.Written > 0 || run.result.Removed > 0 {
+        if err := x.writeSitemap(ctx); err != nil {
+            run.fail(fmt.Errorf("sitemap: %w", err))
+        }
+        if err := x.removeOrphanAssets(ctx, knownAssets); err != nil {
+            run.fail(fmt.Errorf("assets: %w", err))
+        }
+    }
+
+    return run.result, nil
+}
+
+// staticRun es el estado de una exportación.
+type staticRun struct {
+    exporter  *StaticExporter
+    ctx       context.Context
+    full      bool
+    templates map[uint]*template.Template
+    known     map[string]bool // assets de la exportación anterior
+    written   map[string]bool // assets escritos en esta
+    result    *StaticExportResult
+}
+
+func (r *staticRun) fail(err error) {
+    r.result.Errors = append(r.result.Errors, err.Error())
+}
+
+// exportPage renderiza y escribe una página, y guarda de qué depende.
+func (r *staticRun) exportPage(id, templateID uint, previous StaticPage, exists bool, started time.Time) error {
+    x := r.exporter
+    db := x.db.WithContext(r.ctx)
+
+    var content Content
+    if err := db.First(&content, id).Error; err != nil {
+        return err
+    }
+    tmpl, err := r.template(templateID)
+    if err != nil {
+        return err
+    }
+
+    pagePath := x.pagePath(&content)
+    seo, err := x.seo.ForContent(r.ctx, &content)
+    if err != nil {
+        return err
+    }
+    body, err := renderStaticPage(tmpl, &content, x.siteURL, seo)
+    if err != nil {
+        return err
+    }
+
+    // Los medios enlazados se copian a assets/ y se enlazan desde ahí
+    page := StaticPage{
+        ContentID:    content.ID,
+        Path:         pagePath,
+        TemplateID:   templateID,
+        Assets:       StringArray{},
+        LastModified: content.UpdatedAt,
+        ExportedAt:   started,
+    }
+    media, err := x.linkedMedia(r.ctx, body)
+    if err != nil {
+        return err
+    }
+    for _, m := range media {
+        asset := assetPath(&m)
+        if err := r.writeAsset(&m, asset, previous); err != nil {
+            // Se deja el enlace original y se reintenta la próxima vez
+            r.fail(fmt.Errorf("content %d: asset %s: %w", content.ID, m.URL, err))
+            page.ExportedAt = time.Time{}
+            continue
+        }
+        for _, link := range []string{m.URL, html.EscapeString(m.URL)} {
+            body = bytes.ReplaceAll(body, []byte(`"`+link+`"`), []byte(`"/`+asset+`"`))
+        }
+        page.Assets = append(page.Assets, asset)
+    }
+
+    sum := sha256.Sum256(body)
+    page.Hash = hex.EncodeToString(sum[:])
+
+    if r.full || !exists || page.Hash != previous.Hash || page.Path != previous.Path {
+        if err := x.target.Put(r.ctx, pagePath, bytes.NewReader(body), "text/html; charset=utf-8"); err != nil {
+            return err
+        }
+        r.result.Written++
+    } else {
+        r.result.Unchanged++
+    }
+
+    // Cambio de slug o de idioma: la página vieja ya no existe
+    if exists && previous.Path != pagePath {
+        if err := x.target.Delete(r.ctx, previous.Path); err != nil {
+            r.fail(fmt.Errorf("remove %s: %w", previous.Path, err))
+        }
+    }
+
+    return db.Save(&page).Error
+}
+
+// template devuelve la plantilla compilada, o la integrada si id es 0.
+func (r *staticRun) template(id uint) (*template.Template, error) {
+    if tmpl, ok := r.templates[id]; ok {
+        return tmpl, nil
+    }
+
+    source := defaultStaticTemplate
+    name := "default"
+    if id != 0 {
+        var t Template
+        if err := r.exporter.db.WithContext(r.ctx).First(&t, id).Error; err != nil {
+            return nil, err
+        }
+        source, name = t.Content, t.Name
+    }
+
+    tmpl, err := template.New(name).Parse(source)
+    if err != nil {
+        return nil, fmt.Errorf("template %d: %w", id, err)
+    }
+    r.templates[id] = tmpl
+    return tmpl, nil
+}
+
+// writeAsset copia un medio a assets/ una vez por exportación, y solo si es
+// nuevo o cambió desde la exportación anterior de la página.
+func (r *staticRun) writeAsset(media *Media, asset string, previous StaticPage) error {
+    if r.written[asset] {
+        return nil
+    }
+    if !r.full && r.known[asset] && !media.UpdatedAt.After(previous.ExportedAt) {
+        return nil
+    }
+
+    x := r.exporter
+    req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, media.URL, nil)
+    if err != nil {
+        return err
+    }
+    resp, err := x.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("unexpected status %d", resp.StatusCode)
+    }
+
+    if err := x.target.Put(r.ctx, asset, resp.Body, media.MimeType); err != nil {
+        return err
+    }
+    r.written[asset] = true
+    r.known[asset] = true
+    r.result.Assets++
+    return nil
+}
+
+// pagePath es el fichero de la página de un contenido, en su ruta pública.
+func (x *StaticExporter) pagePath(content *Content) string {
+    return path.Join(strings.TrimPrefix(ContentPath(x.locales, content), "/"), "index.html")
+}
+
+// pageURL es la URL pública de una página, sin index.html.
+func pageURL(siteURL, pagePath string) string {
+    return siteURL + "/" + strings.TrimSuffix(pagePath, "index.html")
+}
+
+func assetPath(media *Media) string {
+    name := path.Base(media.URL)
+    if media.Name != "" {
+        name = path.Base("/" + media.Name)
+    }
+    return fmt.Sprintf("assets/%d/%s", media.ID, name)
+}
+
+// assetMediaID es el id de medio de una ruta de assetPath.
+func assetMediaID(asset string) (uint, bool) {
+    var id uint
+    _, err := fmt.Sscanf(asset, "assets/%d/", &id)
+    return id, err == nil
+}
+
+func renderStaticPage(tmpl *template.Template, content *Content, siteURL string, seo *SEO) ([]byte, error) {
+    data := StaticPageData{
+        Content:        content,
+        Body:           template.HTML(content.Content),
+        Meta:           map[string]interface{}{},
+        URL:            seo.URL,
+        SiteURL:        siteURL,
+        SEO:            seo,
+        StructuredData: template.JS(seo.JSONLD),
+    }
+    if len(content.MetaData) > 0 {
+        if err := json.Unmarshal(content.MetaData, &data.Meta); err != nil {
+            return nil, fmt.Errorf("meta_data: %w", err)
+        }
+    }
+
+    var buf bytes.Buffer
+    if err := tmpl.Execute(&buf, data); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+var linkPattern = regexp.MustCompile(`(?:src|href)="([^"]+)"`)
+
+// linkedURLs devuelve las URLs de los atributos src y href de una página.
+func linkedURLs(body []byte) []string {
+    seen := map[string]bool{}
+    var urls []string
+    for _, match := range linkPattern.FindAllSubmatch(body, -1) {
+        url := html.UnescapeString(string(match[1]))
+        if !seen[url] {
+            seen[url] = true
+            urls = append(urls, url)
+        }
+    }
+    return urls
+}
+
+// linkedMedia devuelve los medios de la biblioteca que enlaza una página.
+func (x *StaticExporter) linkedMedia(ctx context.Context, body []byte) ([]Media, error) {
+    urls := linkedURLs(body)
+    if len(urls) == 0 {
+        return nil, nil
+    }
+    var media []Media
+    err := x.db.WithContext(ctx).Where("url IN ?", urls).Order("id").Find(&media).Error
+    return media, err
+}
+
+// mediaUpdated devuelve la fecha de cambio de los medios de cada asset. Los
+// medios borrados no aparecen.
+func (x *StaticExporter) mediaUpdated(ctx context.Context, assets map[string]bool) (map[string]time.Time, error) {
+    ids := make([]uint, 0, len(assets))
+    for asset := range assets {
+        if id, ok := assetMediaID(asset); ok {
+            ids = append(ids, id)
+        }
+    }
+    updated := map[string]time.Time{}
+    if len(ids) == 0 {
+        return updated, nil
+    }
+
+    var media []Media
+    if err := x.db.WithContext(ctx).Select("id", "name", "url", "updated_at").
+        Where("id IN ?", ids).Find(&media).Error; err != nil {
+        return nil, err
+    }
+    for n := range media {
+        updated[assetPath(&media[n])] = media[n].UpdatedAt
+    }
+    return updated, nil
+}
+
+// removeOrphanAssets borra los assets de la exportación anterior que ya no
+// enlaza ninguna página.
+func (x *StaticExporter) removeOrphanAssets(ctx context.Context, before map[string]bool) error {
+    var current []string
+    if err := x.db.WithContext(ctx).Model(&StaticPage{}).
+        Distinct().Pluck("unnest(assets)", &current).Error; err != nil {
+        return err
+    }
+    inUse := make(map[string]bool, len(current))
+    for _, asset := range current {
+        inUse[asset] = true
+    }
+
+    var errs []error
+    for asset := range before {
+        if !inUse[asset] {
+            errs = append(errs, x.target.Delete(ctx, asset))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+func (x *StaticExporter) writeSitemap(ctx context.Context) error {
+    db := x.db.WithContext(ctx)
+    var pages []StaticPage
+    if err := db.Order("path").Find(&pages).Error; err != nil {
+        return err
+    }
+
+    // Como el sitemap del frontend: sin noindex ni canonical a otra URL
+    var contents []Content
+    if err := db.Scopes(indexable).Select("id", "canonical_url").
+        Where("id IN (?)", db.Model(&StaticPage{}).Select("content_id")).
+        Find(&contents).Error; err != nil {
+        return err
+    }
+    canonicals := make(map[uint]string, len(contents))
+    for _, content := range contents {
+        canonicals[content.ID] = content.CanonicalURL
+    }
+
+    body, err := staticSitemap(x.siteURL, pages, canonicals)
+    if err != nil {
+        return err
+    }
+    return x.target.Put(ctx, "sitemap.xml", bytes.NewReader(body), "application/xml")
+}
+
+// staticSitemap incluye las páginas cuyo contenido está en canonicals (los
+// indexables) y cuyo canonical, si tiene, es la propia página.
+func staticSitemap(siteURL string, pages []StaticPage, canonicals map[uint]string) ([]byte, error) {
+    set := sitemapURLSet{Xmlns: sitemapNamespace}
+    for _, page := range pages {
+        loc := pageURL(siteURL, page.Path)
+        canonical, ok := canonicals[page.ContentID]
+        if !ok || (canonical != "" && canonical != loc) {
+            continue
+        }
+
+        entry := sitemapURL{Loc: loc}
+        if !page.LastModified.IsZero() {
+            entry.LastMod = page.LastModified.UTC().Format(time.RFC3339)
+        }
+        set.URLs = append(set.URLs, entry)
+    }
+    return marshalXML(set)
+}
+
+// defaultStaticTemplate se usa para el contenido sin plantilla cuando no hay
+// ninguna marcada como is_default.
+const defaultStaticTemplate = `<!DOCTYPE html>
+<html lang="{{.Content.Locale}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.SEO.Title}}</title>
+<meta name="description" content="{{.SEO.Description}}">
+<meta name="robots" content="{{.SEO.Robots}}">
+<link rel="canonical" href="{{.SEO.Canonical}}">
+{{range .SEO.Alternates}}<link rel="alternate" hreflang="{{.Hreflang}}" href="{{.URL}}">
+{{end}}<meta property="og:type" content="website">
+<meta property="og:title" content="{{.SEO.Title}}">
+<meta property="og:description" content="{{.SEO.Description}}">
+<meta property="og:url" content="{{.SEO.Canonical}}">
+{{if .SEO.Image}}<meta property="og:image" content="{{.SEO.Image}}">
+{{end}}<script type="application/ld+json">{{.StructuredData}}</script>
+</head>
+<body>
+<main>
+<h1>{{.Content.Title}}</h1>
+{{.Body}}
+</main>
+</body>
+</html>
+`
@@ -0,0 +1,144 @@
+// pkg/cms/static_export_test.go
+package cms
+
+import (
+    "context"
+    "html/template"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestStaticPagePath(t *testing.T) {
+    exporter := &StaticExporter{locales: &LocaleConfig{Default: "en"}}
+
+    assert.Equal(t, "about/index.html", exporter.pagePath(&Content{Slug: "about", Locale: "en"}))
+    assert.Equal(t, "es/about/index.html", exporter.pagePath(&Content{Slug: "about", Locale: "es"}))
+    assert.Equal(t, "index.html", exporter.pagePath(&Content{Slug: "index", Locale: "en"}))
+    assert.Equal(t, "es/index.html", exporter.pagePath(&Content{Slug: "index", Locale: "es"}))
+    assert.Equal(t, "etc/index.html", exporter.pagePath(&Content{Slug: "../../etc", Locale: "en"}))
+
+    assert.Equal(t, "https://example.com/es/about/", pageURL("https://example.com", "es/about/index.html"))
+    assert.Equal(t, "https://example.com/", pageURL("https://example.com", "index.html"))
+}
+
+func TestStaticAssetPath(t *testing.T) {
+    media := &Media{Name: "logo.png", URL: "https://bucket.s3.amazonaws.com/2024/01/02/123.png"}
+    media.ID = 7
+
+    asset := assetPath(media)
+    assert.Equal(t, "assets/7/logo.png", asset)
+    id, ok := assetMediaID(asset)
+    assert.True(t, ok)
+    assert.Equal(t, uint(7), id)
+
+    media.Name = ""
+    assert.Equal(t, "assets/7/123.png", assetPath(media))
+
+    _, ok = assetMediaID("sitemap.xml")
+    assert.False(t, ok)
+}
+
+func TestStaticLinkedURLs(t *testing.T) {
+    body := []byte(`<img src="https://cdn.test/a.png"><a href="/about/">About</a>` +
+        `<img src="https://cdn.test/a.png"><a href="https://cdn.test/b.pdf?x=1&amp;y=2">PDF</a>`)
+
+    assert.Equal(t, []string{
+        "https://cdn.test/a.png",
+        "/about/",
+        "https://cdn.test/b.pdf?x=1&y=2",
+    }, linkedURLs(body))
+}
+
+func TestRenderStaticPage(t *testing.T) {
+    tmpl := template.Must(template.New("default").Parse(defaultStaticTemplate))
+    content := &Content{
+        Title:    "Hello <World>",
+        Locale:   "es",
+        Content:  "<p>Body</p>",
+        MetaData: JSON(`{"description":"Desc"}`),
+    }
+    seo := &SEO{
+        Title:       "Hello <World>",
+        Description: "Body",
+        URL:         "https://example.com/es/hello/",
+        Canonical:   "https://example.com/es/hello/",
+        Robots:      "index, follow",
+        Alternates:  []SEOAlternate{{Hreflang: "en", URL: "https://example.com/hello/"}},
+        JSONLD:      []byte(`{"@type":"WebPage","name":"Hello \u003cWorld\u003e"}`),
+    }
+
+    body, err := renderStaticPage(tmpl, content, "https://example.com", seo)
+    require.NoError(t, err)
+    html := string(body)
+    assert.Contains(t, html, `<html lang="es">`)
+    assert.Contains(t, html, "<title>Hello &lt;World&gt;</title>")
+    assert.Contains(t, html, "<p>Body</p>")
+    assert.Contains(t, html, `<meta name="description" content="Body">`)
+    assert.Contains(t, html, `<link rel="canonical" href="https://example.com/es/hello/">`)
+    assert.Contains(t, html, `<link rel="alternate" hreflang="en" href="https://example.com/hello/">`)
+    assert.Contains(t, html, `<script type="application/ld+json">{"@type":"WebPage","name":"Hello \u003cWorld\u003e"}</script>`)
+    assert.NotContains(t, html, "og:image")
+
+    custom := template.Must(template.New("custom").Parse(`{{.Meta.description}}|{{.SiteURL}}|{{.URL}}`))
+    body, err = renderStaticPage(custom, content, "https://example.com", seo)
+    require.NoError(t, err)
+    assert.Equal(t, "Desc|https://example.com|https://example.com/es/hello/", string(body))
+
+    content.MetaData = JSON(`not json`)
+    _, err = renderStaticPage(custom, content, "", seo)
+    assert.Error(t, err)
+}
+
+func TestStaticSitemap(t *testing.T) {
+    modified := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+    body, err := staticSitemap("https://example.com", []StaticPage{
+        {ContentID: 1, Path: "index.html", LastModified: modified},
+        {ContentID: 2, Path: "es/about/index.html"},
+        {ContentID: 3, Path: "hidden/index.html"},
+        {ContentID: 4, Path: "copy/index.html"},
+        {ContentID: 5, Path: "self/index.html"},
+    }, map[uint]string{
+        1: "",
+        2: "",
+        4: "https://example.com/original/",
+        5: "https://example.com/self/",
+    })
+    require.NoError(t, err)
+
+    sitemap := string(body)
+    assert.True(t, strings.HasPrefix(sitemap, `<?xml version="1.0" encoding="UTF-8"?>`))
+    assert.Contains(t, sitemap, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+    assert.Contains(t, sitemap, "<loc>https://example.com/</loc>")
+    assert.Contains(t, sitemap, "<lastmod>2024-03-01T10:00:00Z</lastmod>")
+    assert.Contains(t, sitemap, "<loc>https://example.com/es/about/</loc>")
+    assert.Contains(t, sitemap, "<loc>https://example.com/self/</loc>")
+    // noindex (fuera de canonicals) y canonical a otra URL
+    assert.NotContains(t, sitemap, "hidden")
+    assert.NotContains(t, sitemap, "copy")
+}
+
+func TestDirTarget(t *testing.T) {
+    dir := t.TempDir()
+    target := NewDirTarget(dir)
+    ctx := context.Background()
+
+    require.NoError(t, target.Put(ctx, "es/about/index.html", strings.NewReader("hola"), "text/html"))
+    data, err := os.ReadFile(filepath.Join(dir, "es", "about", "index.html"))
+    require.NoError(t, err)
+    assert.Equal(t, "hola", string(data))
+
+    // No se sale del directorio
+    require.NoError(t, target.Put(ctx, "../outside.html", strings.NewReader("x"), "text/html"))
+    _, err = os.Stat(filepath.Join(dir, "outside.html"))
+    assert.NoError(t, err)
+
+    require.NoError(t, target.Delete(ctx, "es/about/index.html"))
+    _, err = os.Stat(filepath.Join(dir, "es", "about", "index.html"))
+    assert.True(t, os.IsNotExist(err))
+    assert.NoError(t, target.Delete(ctx, "missing.html"))
+}
@@ -0,0 +1,104 @@
+// pkg/cms/static_target.go
+package cms
+
+import (
+    "context"
+    "errors"
+    "io"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+    "ezzygo/pkg/storage"
+)
+
+// SiteTarget es el destino de la exportación estática. Las claves son rutas
+// relativas con "/", como sitemap.xml o es/about/index.html.
+type SiteTarget interface {
+    Put(ctx context.Context, key string, body io.Reader, contentType string) error
+    Delete(ctx context.Context, key string) error
+}
+
+// NewSiteTargetFromEnv devuelve el destino configurado: el directorio
+// STATIC_EXPORT_DIR o, con STATIC_EXPORT_PREFIX, el storage bajo ese prefijo.
+// Sin ninguno la exportación está desactivada y devuelve nil.
+func NewSiteTargetFromEnv(store *storage.S3Storage) SiteTarget {
+    if dir := os.Getenv("STATIC_EXPORT_DIR"); dir != "" {
+        return NewDirTarget(dir)
+    }
+    if prefix := os.Getenv("STATIC_EXPORT_PREFIX"); prefix != "" && store != nil {
+        return NewStorageTarget(store, prefix)
+    }
+    return nil
+}
+
+// DirTarget escribe el sitio en un directorio local.
+type DirTarget struct {
+    dir string
+}
+
+func NewDirTarget(dir string) *DirTarget {
+    return &DirTarget{dir: dir}
+}
+
+// Put escribe en un fichero temporal y lo renombra, para que un servidor que
+// sirva el directorio nunca vea una página a medias.
+func (t *DirTarget) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+    name := t.path(key)
+    if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(name), ".export-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := io.Copy(tmp, body); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), name)
+}
+
+func (t *DirTarget) Delete(ctx context.Context, key string) error {
+    err := os.Remove(t.path(key))
+    if errors.Is(err, os.ErrNotExist) {
+        return nil
+    }
+    return err
+}
+
+// path no deja salir del directorio con claves como ../x.
+func (t *DirTarget) path(key string) string {
+    return filepath.Join(t.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// StorageTarget escribe el sitio en el storage de medios, bajo un prefijo.
+type StorageTarget struct {
+    storage *storage.S3Storage
+    prefix  string
+}
+
+func NewStorageTarget(storage *storage.S3Storage, prefix string) *StorageTarget {
+    return &StorageTarget{storage: storage, prefix: strings.Trim(prefix, "/")}
+}
+
+func (t *StorageTarget) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+    return t.storage.Put(ctx, t.key(key), body, contentType)
+}
+
+func (t *StorageTarget) Delete(ctx context.Context, key string) error {
+    return t.storage.Delete(ctx, t.key(key))
+}
+
+func (t *StorageTarget) key(key string) string {
+    return path.Join(t.prefix, path.Clean("/" + key)[1:])
+}
@@ -0,0 +1,103 @@
+// pkg/cms/string_array.go
+package cms
+
+import (
+    "database/sql/driver"
+    "errors"
+    "strings"
+)
+
+// StringArray mapea una columna text[] de Postgres.
+type StringArray []string
+
+func (a StringArray) Value() (driver.Value, error) {
+    if a == nil {
+        return "{}", nil
+    }
+
+    var b strings.Builder
+    b.WriteByte('{')
+    for i, item := range a {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+        b.WriteByte('"')
+        b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item))
+        b.WriteByte('"')
+    }
+    b.WriteByte('}')
+    return b.String(), nil
+}
+
+func (a *StringArray) Scan(src interface{}) error {
+    var literal string
+    switch value := src.(type) {
+    case nil:
+        *a = nil
+        return nil
+    case string:
+        literal = value
+    case []byte:
+        literal = string(value)
+    default:
+        return errors.New("unsupported type for text[]")
+    }
+
+    items, err := parseArrayLiteral(literal)
+    if err != nil {
+        return err
+    }
+    *a = items
+    return nil
+}
+
+func (StringArray) GormDataType() string {
+    return "text[]"
+}
+
+// parseArrayLiteral interpreta un array de una dimensión como {a,"b c",NULL}.
+// Los NULL se descartan.
+func parseArrayLiteral(literal string) (StringArray, error) {
+    if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+        return nil, errors.New("invalid array literal")
+    }
+
+    items := StringArray{}
+    body := literal[1 : len(literal)-1]
+    if body == "" {
+        return items, nil
+    }
+
+    var current strings.Builder
+    quoted, inQuotes, escaped := false, false, false
+    flush := func() {
+        item := current.String()
+        if quoted || item != "NULL" {
+            items = append(items, item)
+        }
+        current.Reset()
+        quoted = false
+    }
+
+    for _, r := range body {
+        switch {
+        case escaped:
+            current.WriteRune(r)
+            escaped = false
+        case r == '\\':
+            escaped = true
+        case r == '"':
+            inQuotes = !inQuotes
+            quoted = true
+        case r == ',' && !inQuotes:
+            flush()
+        default:
+            current.WriteRune(r)
+        }
+    }
+    if inQuotes || escaped {
+        return nil, errors.New("invalid array literal")
+    }
+    flush()
+    return items, nil
+}
@@ -0,0 +1,30 @@
+// pkg/cms/string_array_test.go
+package cms
+
+import (
+    "testing"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestStringArrayValue(t *testing.T) {
+    value, err := StringArray{"go", `say "hi"`, `a\b`}.Value()
+
+    assert.NoError(t, err)
+    assert.Equal(t, `{"go","say \"hi\"","a\\b"}`, value)
+
+    value, _ = StringArray(nil).Value()
+    assert.Equal(t, "{}", value)
+}
+
+func TestStringArrayScan(t *testing.T) {
+    var tags StringArray
+
+    assert.NoError(t, tags.Scan(`{go,"hello world","say \"hi\"",NULL,"NULL"}`))
+    assert.Equal(t, StringArray{"go", "hello world", `say "hi"`, "NULL"}, tags)
+
+    assert.NoError(t, tags.Scan([]byte("{}")))
+    assert.Equal(t, StringArray{}, tags)
+
+    assert.Error(t, tags.Scan(`{"unterminated}`))
+    assert.Error(t, tags.Scan(42))
+}
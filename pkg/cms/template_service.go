@@ -4,14 +4,20 @@ package cms
 import (
     "context"
     "encoding/json"
-    "errors"
+    "fmt"
     "time"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/query"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type TemplateService struct {
-    db    *gorm.DB
-    cache CacheService
+    db       *gorm.DB
+    cache    *cache.Store
+    versions *VersionService
+    outbox   *events.Outbox
 }
 
 type Template struct {
@@ -20,8 +26,21 @@ type Template struct {
     Content   string          `json:"content"`
     Type      string          `json:"type"` // page, post, section
     Fields    json.RawMessage `json:"fields"`
-    Version   int            `json:"version"`
-    IsDefault bool           `json:"is_default"`
+    Version   int             `json:"version"` // se incrementa en cada escritura, base del ETag
+    IsDefault bool            `json:"is_default"`
+}
+
+// Campos por los que se puede filtrar y ordenar el listado de plantillas
+var TemplateQuery = query.Schema{
+    Fields: map[string]query.Field{
+        "id":         {Column: "id", Type: query.Int, Sortable: true},
+        "name":       {Column: "name", Type: query.String, Sortable: true},
+        "type":       {Column: "type", Type: query.String},
+        "is_default": {Column: "is_default", Type: query.Bool},
+        "created_at": {Column: "created_at", Type: query.Time, Sortable: true},
+        "updated_at": {Column: "updated_at", Type: query.Time, Sortable: true},
+    },
+    DefaultSort: []query.Sort{{Field: "name"}},
 }
 
 type TemplateField struct {
@@ -30,113 +49,163 @@ type TemplateField struct {
     Required bool   `json:"required"`
 }
 
-type TemplateVersion struct {
-    gorm.Model
-    TemplateID uint   `json:"template_id"`
-    Content    string `json:"content"`
-    Version    int    `json:"version"`
-}
-
-func NewTemplateService(db *gorm.DB, cache CacheService) *TemplateService {
+func NewTemplateService(db *gorm.DB, store *cache.Store, versions *VersionService, outbox *events.Outbox) *TemplateService {
     return &TemplateService{
-        db:    db,
-        cache: cache,
+        db:       db,
+        cache:    store,
+        versions: versions,
+        outbox:   outbox,
     }
 }
 
 func (s *TemplateService) Create(ctx context.Context, template *Template) error {
     template.Version = 1
-    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
         if err := tx.Create(template).Error; err != nil {
             return err
         }
 
-        version := &TemplateVersion{
-            TemplateID: template.ID,
-            Content:    template.Content,
-            Version:    template.Version,
+        if err := s.versions.Record(ctx, tx, EntityTemplate, template.ID, template, ""); err != nil {
+            return err
         }
-        return tx.Create(version).Error
+        event, err = recordEvent(ctx, tx, s.outbox, EventTemplateCreated, template.ID, template)
+        return err
     })
 
     if err != nil {
         return err
     }
 
-    s.cache.Delete("templates:list")
+    s.outbox.Relay(ctx, event)
     return nil
 }
 
 func (s *TemplateService) Get(ctx context.Context, id uint) (*Template, error) {
     cacheKey := fmt.Sprintf("template:%d", id)
-    
-    if cached, err := s.cache.Get(cacheKey); err == nil {
-        return cached.(*Template), nil
-    }
 
-    var template Template
-    if err := s.db.WithContext(ctx).First(&template, id).Error; err != nil {
+    template, err := cache.GetOrLoad(ctx, s.cache, cacheKey, cache.Options[Template]{
+        TTL:  time.Hour,
+        Tags: []string{TemplateTag(id)},
+    }, func(ctx context.Context) (Template, error) {
+        var template Template
+        err := s.db.WithContext(ctx).First(&template, id).Error
+        return template, err
+    })
+    if err != nil {
         return nil, err
     }
-
-    s.cache.Set(cacheKey, &template, time.Hour)
     return &template, nil
 }
 
+func (s *TemplateService) List(ctx context.Context, params query.Params) (*query.Page[Template], error) {
+    return query.Find[Template](ctx, s.db, params, TemplateQuery)
+}
+
+// Update guarda la plantilla. Si template.Version no es 0 debe coincidir con
+// la versión actual; si no, se devuelve RevisionMismatchError.
 func (s *TemplateService) Update(ctx context.Context, template *Template) error {
-    template.Version++
-    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-        if err := tx.Save(template).Error; err != nil {
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        var current Template
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "version").First(&current, template.ID).Error; err != nil {
             return err
         }
+        if template.Version != 0 && template.Version != current.Version {
+            return &RevisionMismatchError{Current: current.Version}
+        }
+        template.Version = current.Version + 1
 
-        version := &TemplateVersion{
-            TemplateID: template.ID,
-            Content:    template.Content,
-            Version:    template.Version,
+        if err := tx.Model(&Template{}).Where("id = ?", template.ID).
+            Select("*").
+            Omit("id", "created_at", "deleted_at").
+            Updates(template).Error; err != nil {
+            return err
+        }
+        if err := tx.First(template, template.ID).Error; err != nil {
+            return err
+        }
+
+        if err := s.versions.Record(ctx, tx, EntityTemplate, template.ID, template, ""); err != nil {
+            return err
         }
-        return tx.Create(version).Error
+        event, err = recordEvent(ctx, tx, s.outbox, EventTemplateUpdated, template.ID, template)
+        return err
     })
 
     if err != nil {
         return err
     }
 
-    s.cache.Delete(fmt.Sprintf("template:%d", template.ID))
-    s.cache.Delete("templates:list")
+    s.outbox.Relay(ctx, event)
     return nil
 }
 
+// Patch aplica un JSON Merge Patch sobre la plantilla. Si version no es 0
+// debe coincidir con la versión actual.
+func (s *TemplateService) Patch(ctx context.Context, id uint, patch []byte, version int) (*Template, error) {
+    var template Template
+    if err := s.db.WithContext(ctx).First(&template, id).Error; err != nil {
+        return nil, err
+    }
+    if version == 0 {
+        version = template.Version
+    }
+
+    if err := applyMergePatch(&template, patch); err != nil {
+        return nil, err
+    }
+    template.ID = id
+    template.Version = version
+
+    if err := s.Update(ctx, &template); err != nil {
+        return nil, err
+    }
+    return &template, nil
+}
+
+// Replace aplica el cuerpo de un PUT sobre la plantilla leída de la base de
+// datos, como ContentService.Replace.
+func (s *TemplateService) Replace(ctx context.Context, id uint, body []byte, version int) (*Template, error) {
+    var template Template
+    if err := s.db.WithContext(ctx).First(&template, id).Error; err != nil {
+        return nil, err
+    }
+    stored := template.Version
+
+    if err := applyReplace(&template, body); err != nil {
+        return nil, err
+    }
+    template.ID = id
+    if version != 0 {
+        template.Version = version
+    } else if template.Version == 0 {
+        template.Version = stored
+    }
+
+    if err := s.Update(ctx, &template); err != nil {
+        return nil, err
+    }
+    return &template, nil
+}
+
 func (s *TemplateService) Delete(ctx context.Context, id uint) error {
-    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
         if err := tx.Delete(&Template{}, id).Error; err != nil {
             return err
         }
-        return tx.Where("template_id = ?", id).Delete(&TemplateVersion{}).Error
+        if err := tx.Where("entity_type = ? AND entity_id = ?", EntityTemplate, id).Delete(&Version{}).Error; err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, s.outbox, EventTemplateDeleted, id, deletedEntity{ID: id})
+        return err
     })
 
     if err != nil {
         return err
     }
 
-    s.cache.Delete(fmt.Sprintf("template:%d", id))
-    s.cache.Delete("templates:list")
+    s.outbox.Relay(ctx, event)
     return nil
 }
-
-func (s *TemplateService) GetVersion(ctx context.Context, templateID uint, version int) (*TemplateVersion, error) {
-    var templateVersion TemplateVersion
-    err := s.db.WithContext(ctx).
-        Where("template_id = ? AND version = ?", templateID, version).
-        First(&templateVersion).Error
-    return &templateVersion, err
-}
-
-func (s *TemplateService) ListVersions(ctx context.Context, templateID uint) ([]TemplateVersion, error) {
-    var versions []TemplateVersion
-    err := s.db.WithContext(ctx).
-        Where("template_id = ?", templateID).
-        Order("version DESC").
-        Find(&versions).Error
-    return versions, err
-}
@@ -3,40 +3,138 @@ package cms
 
 import (
     "context"
-    "time"
+    "encoding/json"
+    "fmt"
+    "os"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+    "ezzygo/pkg/auth"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/models"
     "gorm.io/gorm"
 )
 
+const (
+    EntityContent  = "content"
+    EntityTemplate = "template"
+)
+
 type VersionService struct {
-    db *gorm.DB
+    db        *gorm.DB
+    retention map[string]int
+    outbox    *events.Outbox
 }
 
 type Version struct {
     gorm.Model
-    EntityID   uint        `json:"entity_id"`
-    EntityType string      `json:"entity_type"` // content, template
-    Data       JSON        `json:"data"`
-    Version    int         `json:"version"`
-    CreatedBy  uint        `json:"created_by"`
-    Comment    string      `json:"comment"`
+    EntityID      uint   `json:"entity_id" gorm:"index:idx_version_entity"`
+    EntityType    string `json:"entity_type" gorm:"index:idx_version_entity"` // content, template
+    Data          JSON   `json:"data"`
+    Version       int    `json:"version"`
+    CreatedBy     uint   `json:"created_by"`
+    CreatedByName string `json:"created_by_name"`
+    Comment       string `json:"comment"`
 }
 
-func NewVersionService(db *gorm.DB) *VersionService {
-    return &VersionService{db: db}
+// FieldDiff describe el cambio de un campo entre dos versiones. Los campos de
+// texto incluyen Ops con el diff por líneas o palabras.
+type FieldDiff struct {
+    Old interface{} `json:"old"`
+    New interface{} `json:"new"`
+    Ops []DiffOp    `json:"ops,omitempty"`
+}
+
+// Campos que cambian en cada guardado y no aportan al diff
+var ignoredDiffFields = map[string]bool{
+    "ID":         true,
+    "CreatedAt":  true,
+    "UpdatedAt":  true,
+    "DeletedAt":  true,
+    "version":    true,
+    "revision":   true,
+    "view_count": true,
+}
+
+// NewVersionService lee los límites de retención de CMS_VERSION_RETENTION,
+// por ejemplo "content=50,template=20". Sin límite se guardan todas.
+func NewVersionService(db *gorm.DB, outbox *events.Outbox) *VersionService {
+    retention := map[string]int{}
+    for _, rule := range strings.Split(os.Getenv("CMS_VERSION_RETENTION"), ",") {
+        parts := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+        if len(parts) != 2 {
+            continue
+        }
+        if limit, err := strconv.Atoi(parts[1]); err == nil && limit > 0 {
+            retention[parts[0]] = limit
+        }
+    }
+
+    return &VersionService{
+        db:        db,
+        retention: retention,
+        outbox:    outbox,
+    }
 }
 
 func (s *VersionService) CreateVersion(ctx context.Context, entityType string, entityID uint, data interface{}, createdBy uint, comment string) error {
-    lastVersion, _ := s.GetLastVersion(ctx, entityType, entityID)
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        return s.create(tx, entityType, entityID, data, createdBy, "", comment)
+    })
+}
+
+// Record guarda una versión dentro de la transacción del servicio que hace el
+// cambio, usando el usuario autenticado del contexto.
+func (s *VersionService) Record(ctx context.Context, tx *gorm.DB, entityType string, entityID uint, data interface{}, comment string) error {
+    var userID uint
+    username := auth.UsernameFromContext(ctx)
+    if username != "" {
+        var user models.User
+        if err := tx.Select("id").Where("username = ?", username).First(&user).Error; err == nil {
+            userID = user.ID
+        }
+    }
+    return s.create(tx, entityType, entityID, data, userID, username, comment)
+}
+
+func (s *VersionService) create(tx *gorm.DB, entityType string, entityID uint, data interface{}, createdBy uint, createdByName, comment string) error {
+    raw, err := json.Marshal(data)
+    if err != nil {
+        return err
+    }
+
+    var last Version
+    lastVersion := 0
+    err = tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+        Order("version desc").
+        First(&last).Error
+    if err == nil {
+        lastVersion = last.Version
+    } else if err != gorm.ErrRecordNotFound {
+        return err
+    }
+
     version := &Version{
-        EntityID:   entityID,
-        EntityType: entityType,
-        Data:       data,
-        Version:    lastVersion + 1,
-        CreatedBy:  createdBy,
-        Comment:    comment,
-    }
-    
-    return s.db.WithContext(ctx).Create(version).Error
+        EntityID:      entityID,
+        EntityType:    entityType,
+        Data:          JSON(raw),
+        Version:       lastVersion + 1,
+        CreatedBy:     createdBy,
+        CreatedByName: createdByName,
+        Comment:       comment,
+    }
+    if err := tx.Create(version).Error; err != nil {
+        return err
+    }
+
+    // Retención: se borran las versiones más antiguas que superan el límite
+    if limit := s.retention[entityType]; limit > 0 && version.Version > limit {
+        return tx.Unscoped().
+            Where("entity_type = ? AND entity_id = ? AND version <= ?", entityType, entityID, version.Version-limit).
+            Delete(&Version{}).Error
+    }
+    return nil
 }
 
 func (s *VersionService) GetVersion(ctx context.Context, entityType string, entityID uint, version int) (*Version, error) {
@@ -53,7 +151,7 @@ func (s *VersionService) GetLastVersion(ctx context.Context, entityType string,
         Where("entity_type = ? AND entity_id = ?", entityType, entityID).
         Order("version desc").
         First(&v).Error
-    
+
     if err == gorm.ErrRecordNotFound {
         return 0, nil
     }
@@ -63,38 +161,88 @@ func (s *VersionService) GetLastVersion(ctx context.Context, entityType string,
 func (s *VersionService) ListVersions(ctx context.Context, entityType string, entityID uint) ([]Version, error) {
     var versions []Version
     err := s.db.WithContext(ctx).
+        Omit("data").
         Where("entity_type = ? AND entity_id = ?", entityType, entityID).
         Order("version desc").
         Find(&versions).Error
     return versions, err
 }
 
+// Restore vuelve a aplicar una versión y guarda el resultado como una versión
+// nueva. El estado de publicación del contenido no se restaura: eso solo
+// cambia mediante el workflow.
 func (s *VersionService) Restore(ctx context.Context, entityType string, entityID uint, version int) error {
     v, err := s.GetVersion(ctx, entityType, entityID, version)
     if err != nil {
         return err
     }
 
-    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+    comment := fmt.Sprintf("restored from version %d", version)
+    var event *events.Event
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
         switch entityType {
-        case "content":
+        case EntityContent:
+            // La versión se mezcla sobre la fila actual: lo que no guardaba
+            // (versiones anteriores a un campo) se mantiene
             var content Content
+            if err := tx.First(&content, entityID).Error; err != nil {
+                return err
+            }
+            revision := content.Revision
             if err := json.Unmarshal(v.Data, &content); err != nil {
                 return err
             }
-            return tx.Model(&Content{}).Where("id = ?", entityID).Updates(content).Error
-        case "template":
+            content.Revision = revision + 1
+            if err := tx.Model(&Content{}).Where("id = ?", entityID).
+                Select("*").
+                Omit(contentProtectedFields...).
+                Updates(&content).Error; err != nil {
+                return err
+            }
+            if err := tx.First(&content, entityID).Error; err != nil {
+                return err
+            }
+            if err := s.Record(ctx, tx, entityType, entityID, &content, comment); err != nil {
+                return err
+            }
+            event, err = recordEvent(ctx, tx, s.outbox, EventContentUpdated, entityID, &content)
+            return err
+        case EntityTemplate:
             var template Template
+            if err := tx.First(&template, entityID).Error; err != nil {
+                return err
+            }
+            current := template.Version
             if err := json.Unmarshal(v.Data, &template); err != nil {
                 return err
             }
-            return tx.Model(&Template{}).Where("id = ?", entityID).Updates(template).Error
+            template.Version = current + 1
+            if err := tx.Model(&Template{}).Where("id = ?", entityID).
+                Select("*").
+                Omit("id", "created_at", "deleted_at").
+                Updates(&template).Error; err != nil {
+                return err
+            }
+            if err := tx.First(&template, entityID).Error; err != nil {
+                return err
+            }
+            if err := s.Record(ctx, tx, entityType, entityID, &template, comment); err != nil {
+                return err
+            }
+            event, err = recordEvent(ctx, tx, s.outbox, EventTemplateUpdated, entityID, &template)
+            return err
         }
-        return nil
+        return fmt.Errorf("unknown entity type %q", entityType)
     })
+    if err != nil {
+        return err
+    }
+
+    s.outbox.Relay(ctx, event)
+    return nil
 }
 
-func (s *VersionService) Compare(ctx context.Context, entityType string, entityID uint, v1, v2 int) (map[string]interface{}, error) {
+func (s *VersionService) Compare(ctx context.Context, entityType string, entityID uint, v1, v2 int) (map[string]FieldDiff, error) {
     version1, err := s.GetVersion(ctx, entityType, entityID, v1)
     if err != nil {
         return nil, err
@@ -106,18 +254,45 @@ func (s *VersionService) Compare(ctx context.Context, entityType string, entityI
     }
 
     var data1, data2 map[string]interface{}
-    json.Unmarshal(version1.Data, &data1)
-    json.Unmarshal(version2.Data, &data2)
-
-    diff := make(map[string]interface{})
-    for k, v := range data1 {
-        if data2[k] != v {
-            diff[k] = map[string]interface{}{
-                "old": v,
-                "new": data2[k],
-            }
+    if err := json.Unmarshal(version1.Data, &data1); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(version2.Data, &data2); err != nil {
+        return nil, err
+    }
+
+    return diffFields(data1, data2), nil
+}
+
+func diffFields(data1, data2 map[string]interface{}) map[string]FieldDiff {
+    keys := make([]string, 0, len(data1)+len(data2))
+    for k := range data1 {
+        keys = append(keys, k)
+    }
+    for k := range data2 {
+        if _, ok := data1[k]; !ok {
+            keys = append(keys, k)
         }
     }
+    sort.Strings(keys)
+
+    diff := make(map[string]FieldDiff)
+    for _, k := range keys {
+        if ignoredDiffFields[k] {
+            continue
+        }
+        oldValue, newValue := data1[k], data2[k]
+        if reflect.DeepEqual(oldValue, newValue) {
+            continue
+        }
 
-    return diff, nil
+        field := FieldDiff{Old: oldValue, New: newValue}
+        oldText, oldIsText := oldValue.(string)
+        newText, newIsText := newValue.(string)
+        if (oldIsText || oldValue == nil) && (newIsText || newValue == nil) {
+            field.Ops = DiffText(oldText, newText)
+        }
+        diff[k] = field
+    }
+    return diff
 }
@@ -0,0 +1,87 @@
+// pkg/cms/view_counter.go
+package cms
+
+import (
+    "context"
+    "sync"
+    "time"
+    "go.uber.org/zap"
+    "gorm.io/gorm"
+)
+
+const (
+    viewFlushInterval = 10 * time.Second
+    viewFlushTimeout  = 5 * time.Second
+)
+
+// ViewCounter acumula en memoria las lecturas de la API de entrega y Run las
+// escribe en lote cada viewFlushInterval, con un UPDATE por contenido, en vez
+// de uno por lectura. Las sumas son relativas, así que cada réplica lleva su
+// propio contador. Si el proceso muere se pierden las del último intervalo.
+type ViewCounter struct {
+    db     *gorm.DB
+    logger *zap.Logger
+
+    mu      sync.Mutex
+    pending map[uint]int64
+}
+
+func NewViewCounter(db *gorm.DB, logger *zap.Logger) *ViewCounter {
+    return &ViewCounter{
+        db:      db,
+        logger:  logger,
+        pending: map[uint]int64{},
+    }
+}
+
+// Add suma una lectura. No bloquea y admite un contador nil.
+func (v *ViewCounter) Add(id uint) {
+    if v == nil {
+        return
+    }
+
+    v.mu.Lock()
+    v.pending[id]++
+    v.mu.Unlock()
+}
+
+// Run bloquea hasta que se cancela el contexto y entonces escribe lo que
+// quede pendiente.
+func (v *ViewCounter) Run(ctx context.Context) {
+    ticker := time.NewTicker(viewFlushInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), viewFlushTimeout)
+            v.Flush(flushCtx)
+            cancel()
+            return
+        case <-ticker.C:
+            v.Flush(ctx)
+        }
+    }
+}
+
+// Flush escribe las lecturas acumuladas. Las que fallan se registran y
+// vuelven al buffer para el siguiente intento.
+func (v *ViewCounter) Flush(ctx context.Context) {
+    v.mu.Lock()
+    pending := v.pending
+    v.pending = map[uint]int64{}
+    v.mu.Unlock()
+
+    for id, count := range pending {
+        err := v.db.WithContext(ctx).Model(&Content{}).
+            Where("id = ?", id).
+            UpdateColumn("view_count", gorm.Expr("view_count + ?", count)).Error
+        if err != nil {
+            v.logger.Error("Failed to record content views",
+                zap.Uint("id", id), zap.Int64("views", count), zap.Error(err))
+            v.mu.Lock()
+            v.pending[id] += count
+            v.mu.Unlock()
+        }
+    }
+}
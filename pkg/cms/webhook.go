@@ -0,0 +1,534 @@
+// pkg/cms/webhook.go
+package cms
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/query"
+    "go.uber.org/zap"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    DeliveryPending   = "pending"
+    DeliverySucceeded = "succeeded"
+    DeliveryFailed    = "failed"
+)
+
+const (
+    webhookBatchSize   = 50
+    webhookTimeout     = 10 * time.Second
+    webhookLease       = time.Minute // mayor que webhookTimeout: cubre el envío de un lote
+    webhookMaxBackoff  = 12 * time.Hour
+    webhookMaxResponse = 1024 // bytes de la respuesta guardados en el log
+    webhookPruneEvery  = time.Hour
+)
+
+var ErrInvalidWebhook = errors.New("webhook requires an http(s) url and at least one known event")
+
+// Webhook es una suscripción de un sistema externo a eventos del CMS. Events
+// admite tipos concretos, comodines por entidad como "content.*" o "*".
+type Webhook struct {
+    gorm.Model
+    Name   string      `json:"name"`
+    URL    string      `json:"url" gorm:"not null"`
+    Secret string      `json:"secret,omitempty" gorm:"not null"` // solo se devuelve al crearlo
+    Events StringArray `json:"events" gorm:"type:text[];default:'{}'"`
+    Active bool        `json:"active" gorm:"not null"`
+}
+
+// WebhookDelivery es a la vez la cola persistente de envíos y su log: se
+// escribe en la misma transacción que el cambio que la origina y guarda el
+// resultado del último intento.
+type WebhookDelivery struct {
+    gorm.Model
+    WebhookID      uint       `json:"webhook_id" gorm:"index;not null"`
+    EventID        string     `json:"event_id" gorm:"size:32;index"` // igual en todos los envíos del evento
+    Event          string     `json:"event"`
+    Payload        JSON       `json:"payload"`
+    Status         string     `json:"status" gorm:"index;not null"` // pending, succeeded, failed
+    Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+    NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+    ResponseStatus int        `json:"response_status,omitempty"`
+    ResponseBody   string     `json:"response_body,omitempty"`
+    Error          string     `json:"error,omitempty"`
+    DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Campos por los que se puede filtrar y ordenar el log de entregas
+var WebhookDeliveryQuery = query.Schema{
+    Fields: map[string]query.Field{
+        "id":         {Column: "id", Type: query.Int, Sortable: true},
+        "event":      {Column: "event", Type: query.String},
+        "event_id":   {Column: "event_id", Type: query.String},
+        "status":     {Column: "status", Type: query.String},
+        "attempts":   {Column: "attempts", Type: query.Int, Sortable: true},
+        "created_at": {Column: "created_at", Type: query.Time, Sortable: true},
+    },
+    DefaultSort: []query.Sort{{Field: "created_at", Desc: true}},
+}
+
+// WebhookEvent es el cuerpo que recibe el webhook.
+type WebhookEvent struct {
+    ID         string      `json:"id"`
+    Type       string      `json:"type"`
+    OccurredAt time.Time   `json:"occurred_at"`
+    Data       interface{} `json:"data"`
+}
+
+type WebhookService struct {
+    db          *gorm.DB
+    client      *http.Client
+    logger      *zap.Logger
+    maxAttempts int
+    interval    time.Duration
+    retention   time.Duration
+}
+
+// NewWebhookService lee WEBHOOK_MAX_ATTEMPTS (por defecto 10),
+// WEBHOOK_DISPATCH_INTERVAL (por defecto 5s) y WEBHOOK_DELIVERY_RETENTION,
+// lo que se guardan las entregas terminadas (por defecto 720h, 0 las guarda
+// siempre).
+func NewWebhookService(db *gorm.DB, logger *zap.Logger) *WebhookService {
+    maxAttempts := 10
+    if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+        if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+            maxAttempts = parsed
+        } else {
+            logger.Warn("Invalid WEBHOOK_MAX_ATTEMPTS, using default", zap.String("value", value))
+        }
+    }
+    interval := 5 * time.Second
+    if value := os.Getenv("WEBHOOK_DISPATCH_INTERVAL"); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+            interval = parsed
+        } else {
+            logger.Warn("Invalid WEBHOOK_DISPATCH_INTERVAL, using default", zap.String("value", value))
+        }
+    }
+    retention := 30 * 24 * time.Hour
+    if value := os.Getenv("WEBHOOK_DELIVERY_RETENTION"); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+            retention = parsed
+        } else {
+            logger.Warn("Invalid WEBHOOK_DELIVERY_RETENTION, using default", zap.String("value", value))
+        }
+    }
+
+    return &WebhookService{
+        db:          db,
+        client:      newOutboundClient(webhookTimeout),
+        logger:      logger,
+        maxAttempts: maxAttempts,
+        interval:    interval,
+        retention:   retention,
+    }
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]Webhook, error) {
+    var hooks []Webhook
+    if err := s.db.WithContext(ctx).Order("id").Find(&hooks).Error; err != nil {
+        return nil, err
+    }
+    for i := range hooks {
+        hooks[i].Secret = ""
+    }
+    return hooks, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id uint) (*Webhook, error) {
+    var hook Webhook
+    if err := s.db.WithContext(ctx).First(&hook, id).Error; err != nil {
+        return nil, err
+    }
+    hook.Secret = ""
+    return &hook, nil
+}
+
+// Create guarda la suscripción. Sin secreto se genera uno, que solo se
+// devuelve en esta respuesta.
+func (s *WebhookService) Create(ctx context.Context, hook *Webhook) error {
+    if err := normalizeWebhook(hook); err != nil {
+        return err
+    }
+    if hook.Secret == "" {
+        hook.Secret = newWebhookSecret()
+    }
+    return s.db.WithContext(ctx).Create(hook).Error
+}
+
+// Update cambia todo salvo el secreto.
+func (s *WebhookService) Update(ctx context.Context, hook *Webhook) error {
+    if err := normalizeWebhook(hook); err != nil {
+        return err
+    }
+
+    var current Webhook
+    if err := s.db.WithContext(ctx).First(&current, hook.ID).Error; err != nil {
+        return err
+    }
+    if err := s.db.WithContext(ctx).Model(&current).
+        Select("name", "url", "events", "active").
+        Updates(hook).Error; err != nil {
+        return err
+    }
+    if err := s.db.WithContext(ctx).First(hook, hook.ID).Error; err != nil {
+        return err
+    }
+    hook.Secret = ""
+    return nil
+}
+
+// Delete borra la suscripción y da por fallidas sus entregas pendientes. El
+// log se conserva.
+func (s *WebhookService) Delete(ctx context.Context, id uint) error {
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        result := tx.Delete(&Webhook{}, id)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return gorm.ErrRecordNotFound
+        }
+        return tx.Model(&WebhookDelivery{}).
+            Where("webhook_id = ? AND status = ?", id, DeliveryPending).
+            Updates(map[string]interface{}{"status": DeliveryFailed, "error": "webhook deleted"}).Error
+    })
+}
+
+// Deliveries devuelve el log de entregas de un webhook.
+func (s *WebhookService) Deliveries(ctx context.Context, webhookID uint, params query.Params) (*query.Page[WebhookDelivery], error) {
+    if _, err := s.Get(ctx, webhookID); err != nil {
+        return nil, err
+    }
+    return query.Find[WebhookDelivery](ctx, s.db.Where("webhook_id = ?", webhookID), params, WebhookDeliveryQuery)
+}
+
+// Redeliver vuelve a encolar una entrega como una entrega nueva con el mismo
+// evento, sin tocar la original.
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID uint) (*WebhookDelivery, error) {
+    if _, err := s.Get(ctx, webhookID); err != nil {
+        return nil, err
+    }
+
+    var original WebhookDelivery
+    if err := s.db.WithContext(ctx).Where("webhook_id = ?", webhookID).First(&original, deliveryID).Error; err != nil {
+        return nil, err
+    }
+
+    delivery := &WebhookDelivery{
+        WebhookID:     original.WebhookID,
+        EventID:       original.EventID,
+        Event:         original.Event,
+        Payload:       original.Payload,
+        Status:        DeliveryPending,
+        NextAttemptAt: time.Now(),
+    }
+    if err := s.db.WithContext(ctx).Create(delivery).Error; err != nil {
+        return nil, err
+    }
+    return delivery, nil
+}
+
+// HandleEvent encola el evento del outbox para cada webhook activo suscrito.
+// El outbox puede repetir un evento, así que si ya tiene entregas no hace
+// nada.
+func (s *WebhookService) HandleEvent(ctx context.Context, event events.Event) error {
+    return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        var queued int64
+        if err := tx.Model(&WebhookDelivery{}).Where("event_id = ?", event.ID).Count(&queued).Error; err != nil {
+            return err
+        }
+        if queued > 0 {
+            return nil
+        }
+
+        var hooks []Webhook
+        if err := tx.Where("active = ?", true).Find(&hooks).Error; err != nil {
+            return err
+        }
+
+        var deliveries []WebhookDelivery
+        var payload []byte
+        for _, hook := range hooks {
+            if !hook.Subscribes(event.Type) {
+                continue
+            }
+            if payload == nil {
+                var err error
+                payload, err = json.Marshal(WebhookEvent{
+                    ID:         event.ID,
+                    Type:       event.Type,
+                    OccurredAt: event.OccurredAt,
+                    Data:       event.Data,
+                })
+                if err != nil {
+                    return err
+                }
+            }
+            deliveries = append(deliveries, WebhookDelivery{
+                WebhookID:     hook.ID,
+                EventID:       event.ID,
+                Event:         event.Type,
+                Payload:       JSON(payload),
+                Status:        DeliveryPending,
+                NextAttemptAt: time.Now(),
+            })
+        }
+        if len(deliveries) == 0 {
+            return nil
+        }
+        return tx.Create(&deliveries).Error
+    })
+}
+
+// Subscribes indica si el webhook recibe el evento.
+func (w *Webhook) Subscribes(event string) bool {
+    for _, pattern := range w.Events {
+        if events.Match(pattern, event) {
+            return true
+        }
+    }
+    return false
+}
+
+// Run entrega los avisos vencidos cada intervalo y, una vez por hora, borra
+// las entregas terminadas más antiguas que la retención. Bloquea hasta que se
+// cancela el contexto.
+func (s *WebhookService) Run(ctx context.Context) {
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+
+    var pruned time.Time
+    for {
+        if _, err := s.DeliverDue(ctx); err != nil {
+            s.logger.Error("Failed to deliver webhooks", zap.Error(err))
+        }
+        if time.Since(pruned) >= webhookPruneEvery {
+            if _, err := s.PruneDeliveries(ctx); err != nil {
+                s.logger.Error("Failed to prune webhook deliveries", zap.Error(err))
+            } else {
+                pruned = time.Now()
+            }
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// PruneDeliveries borra del todo las entregas que terminaron (con éxito o
+// agotando los intentos) antes del periodo de retención. Las pendientes se
+// conservan siempre. Devuelve el número de entregas borradas.
+func (s *WebhookService) PruneDeliveries(ctx context.Context) (int64, error) {
+    if s.retention <= 0 {
+        return 0, nil
+    }
+    result := s.db.WithContext(ctx).Unscoped().
+        Where("status <> ? AND updated_at < ?", DeliveryPending, time.Now().Add(-s.retention)).
+        Delete(&WebhookDelivery{})
+    return result.RowsAffected, result.Error
+}
+
+// DeliverDue reclama un lote de entregas vencidas y las envía. Reclamarlas
+// aplaza su next_attempt_at, así otras réplicas no las toman y, si el proceso
+// cae a mitad del envío, se reintentan al vencer el plazo. Devuelve el número
+// de entregas procesadas.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+    var deliveries []WebhookDelivery
+    now := time.Now()
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+            Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+            Order("next_attempt_at").
+            Limit(webhookBatchSize).
+            Find(&deliveries).Error; err != nil {
+            return err
+        }
+        if len(deliveries) == 0 {
+            return nil
+        }
+
+        ids := make([]uint, len(deliveries))
+        for i, delivery := range deliveries {
+            ids[i] = delivery.ID
+        }
+        return tx.Model(&WebhookDelivery{}).Where("id IN ?", ids).
+            Update("next_attempt_at", now.Add(webhookLease)).Error
+    })
+    if err != nil || len(deliveries) == 0 {
+        return 0, err
+    }
+
+    hookIDs := make([]uint, 0, len(deliveries))
+    for _, delivery := range deliveries {
+        hookIDs = append(hookIDs, delivery.WebhookID)
+    }
+    var list []Webhook
+    if err := s.db.WithContext(ctx).Where("id IN ?", hookIDs).Find(&list).Error; err != nil {
+        return 0, err
+    }
+    hooks := make(map[uint]Webhook, len(list))
+    for _, hook := range list {
+        hooks[hook.ID] = hook
+    }
+
+    var wg sync.WaitGroup
+    for i := range deliveries {
+        wg.Add(1)
+        go func(delivery *WebhookDelivery) {
+            defer wg.Done()
+
+            if hook, ok := hooks[delivery.WebhookID]; ok {
+                s.send(ctx, hook, delivery)
+            } else {
+                delivery.Status = DeliveryFailed
+                delivery.Error = "webhook deleted"
+            }
+
+            // El resultado se guarda aunque se cancele el contexto
+            if err := s.db.Model(delivery).
+                Select("status", "attempts", "next_attempt_at", "response_status", "response_body", "error", "delivered_at").
+                Updates(delivery).Error; err != nil {
+                s.logger.Error("Failed to record webhook delivery",
+                    zap.Uint("delivery", delivery.ID), zap.Error(err))
+            }
+        }(&deliveries[i])
+    }
+    wg.Wait()
+    return len(deliveries), nil
+}
+
+// send hace un intento de entrega y deja su resultado en delivery.
+func (s *WebhookService) send(ctx context.Context, hook Webhook, delivery *WebhookDelivery) {
+    delivery.Attempts++
+    delivery.ResponseStatus, delivery.ResponseBody = 0, ""
+
+    err := s.post(ctx, hook, delivery)
+    if err == nil {
+        now := time.Now()
+        delivery.Status = DeliverySucceeded
+        delivery.Error = ""
+        delivery.DeliveredAt = &now
+        return
+    }
+
+    delivery.Error = err.Error()
+    if delivery.Attempts >= s.maxAttempts {
+        delivery.Status = DeliveryFailed
+        return
+    }
+    delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+}
+
+func (s *WebhookService) post(ctx context.Context, hook Webhook, delivery *WebhookDelivery) error {
+    body := []byte(delivery.Payload)
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("User-Agent", "ezzygo-webhooks/1.0")
+    req.Header.Set("X-Webhook-Event", delivery.Event)
+    req.Header.Set("X-Webhook-Event-ID", delivery.EventID)
+    req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+    req.Header.Set("X-Webhook-Signature", SignWebhook(hook.Secret, time.Now(), body))
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    response, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponse))
+    delivery.ResponseStatus = resp.StatusCode
+    delivery.ResponseBody = string(response)
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("unexpected status %d", resp.StatusCode)
+    }
+    return nil
+}
+
+// SignWebhook firma el cuerpo de una entrega con el secreto del webhook:
+// "t=<unix>,v1=<hex>", donde v1 es HMAC-SHA256 de "<unix>.<cuerpo>". El
+// receptor recalcula la firma y descarta marcas de tiempo antiguas.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+    unix := strconv.FormatInt(timestamp.Unix(), 10)
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte(unix))
+    mac.Write([]byte("."))
+    mac.Write(body)
+    return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff es la espera tras el intento fallido número attempt: un
+// minuto que se duplica en cada intento, hasta webhookMaxBackoff.
+func webhookBackoff(attempt int) time.Duration {
+    if attempt > 10 {
+        return webhookMaxBackoff
+    }
+    return min(time.Minute<<(attempt-1), webhookMaxBackoff)
+}
+
+func normalizeWebhook(hook *Webhook) error {
+    hook.URL = strings.TrimSpace(hook.URL)
+    target, err := url.Parse(hook.URL)
+    if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+        return ErrInvalidWebhook
+    }
+    if err := checkPublicURL(target); err != nil {
+        return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+    }
+
+    if len(hook.Events) == 0 {
+        return ErrInvalidWebhook
+    }
+    for i, event := range hook.Events {
+        event = strings.TrimSpace(event)
+        if !isWebhookEvent(event) {
+            return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+        }
+        hook.Events[i] = event
+    }
+    return nil
+}
+
+func isWebhookEvent(pattern string) bool {
+    if pattern == "*" {
+        return true
+    }
+    for _, event := range EventTypes {
+        if pattern == event || pattern == event[:strings.Index(event, ".")]+".*" {
+            return true
+        }
+    }
+    return false
+}
+
+func newWebhookSecret() string {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        panic(err)
+    }
+    return "whsec_" + hex.EncodeToString(b)
+}
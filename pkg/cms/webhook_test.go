@@ -0,0 +1,140 @@
+// pkg/cms/webhook_test.go
+package cms
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "go.uber.org/zap"
+)
+
+func TestSignWebhook(t *testing.T) {
+    body := []byte(`{"type":"content.published"}`)
+    timestamp := time.Unix(1700000000, 0)
+
+    mac := hmac.New(sha256.New, []byte("secret"))
+    mac.Write([]byte("1700000000." + string(body)))
+    expected := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+    assert.Equal(t, expected, SignWebhook("secret", timestamp, body))
+    assert.NotEqual(t, expected, SignWebhook("other", timestamp, body))
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+    hook := Webhook{Events: StringArray{EventContentPublished, "media.*"}}
+    assert.True(t, hook.Subscribes(EventContentPublished))
+    assert.True(t, hook.Subscribes(EventMediaDeleted))
+    assert.False(t, hook.Subscribes(EventContentCreated))
+
+    all := Webhook{Events: StringArray{"*"}}
+    assert.True(t, all.Subscribes(EventTemplateUpdated))
+}
+
+func TestNormalizeWebhook(t *testing.T) {
+    hook := Webhook{URL: " https://example.com/hook ", Events: StringArray{" content.* ", EventMediaDeleted}}
+    assert.NoError(t, normalizeWebhook(&hook))
+    assert.Equal(t, "https://example.com/hook", hook.URL)
+    assert.Equal(t, StringArray{"content.*", EventMediaDeleted}, hook.Events)
+
+    assert.ErrorIs(t, normalizeWebhook(&Webhook{URL: "ftp://example.com", Events: StringArray{"*"}}), ErrInvalidWebhook)
+    assert.ErrorIs(t, normalizeWebhook(&Webhook{URL: "https://example.com"}), ErrInvalidWebhook)
+    assert.ErrorIs(t, normalizeWebhook(&Webhook{URL: "https://example.com", Events: StringArray{"content.moved"}}), ErrInvalidWebhook)
+    assert.ErrorIs(t, normalizeWebhook(&Webhook{URL: "https://example.com", Events: StringArray{"users.*"}}), ErrInvalidWebhook)
+
+    // Destinos de la red interna
+    for _, target := range []string{"http://127.0.0.1:8080/", "http://localhost/hook", "http://10.0.0.5/", "http://169.254.169.254/latest/meta-data/", "http://[::1]/"} {
+        assert.ErrorIs(t, normalizeWebhook(&Webhook{URL: target, Events: StringArray{"*"}}), ErrInvalidWebhook, target)
+    }
+}
+
+func TestWebhookBackoff(t *testing.T) {
+    assert.Equal(t, time.Minute, webhookBackoff(1))
+    assert.Equal(t, 2*time.Minute, webhookBackoff(2))
+    assert.Equal(t, 256*time.Minute, webhookBackoff(9))
+    assert.Equal(t, webhookMaxBackoff, webhookBackoff(11))
+    assert.Equal(t, webhookMaxBackoff, webhookBackoff(40))
+}
+
+func TestStatusEvent(t *testing.T) {
+    assert.Equal(t, EventContentPublished, statusEvent(StatusApproved, StatusPublished))
+    assert.Equal(t, EventContentUnpublished, statusEvent(StatusPublished, StatusDraft))
+    assert.Equal(t, EventContentArchived, statusEvent(StatusPublished, StatusArchived))
+    assert.Equal(t, EventContentTransitioned, statusEvent(StatusDraft, StatusInReview))
+}
+
+func TestWebhookSend(t *testing.T) {
+    var received *http.Request
+    var body []byte
+    status := http.StatusOK
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        received = r
+        body, _ = io.ReadAll(r.Body)
+        w.WriteHeader(status)
+        w.Write([]byte("ok"))
+    }))
+    defer server.Close()
+
+    t.Setenv("WEBHOOK_MAX_ATTEMPTS", "2")
+    // El receptor de prueba escucha en loopback
+    t.Setenv("CMS_ALLOW_PRIVATE_URLS", "true")
+    service := NewWebhookService(nil, zap.NewNop())
+    hook := Webhook{URL: server.URL, Secret: "secret"}
+    delivery := &WebhookDelivery{
+        EventID: "abc",
+        Event:   EventContentPublished,
+        Payload: JSON(`{"id":"abc"}`),
+        Status:  DeliveryPending,
+    }
+    delivery.ID = 7
+
+    service.send(context.Background(), hook, delivery)
+    assert.Equal(t, DeliverySucceeded, delivery.Status)
+    assert.Equal(t, 1, delivery.Attempts)
+    assert.NotNil(t, delivery.DeliveredAt)
+    assert.Equal(t, "ok", delivery.ResponseBody)
+    assert.Equal(t, `{"id":"abc"}`, string(body))
+    assert.Equal(t, EventContentPublished, received.Header.Get("X-Webhook-Event"))
+    assert.Equal(t, "7", received.Header.Get("X-Webhook-Delivery"))
+
+    // La firma se comprueba como lo haría el receptor
+    signature := received.Header.Get("X-Webhook-Signature")
+    timestamp := strings.TrimPrefix(strings.Split(signature, ",")[0], "t=")
+    mac := hmac.New(sha256.New, []byte("secret"))
+    mac.Write([]byte(timestamp + "." + string(body)))
+    assert.True(t, strings.HasSuffix(signature, ",v1="+hex.EncodeToString(mac.Sum(nil))))
+
+    // Un error se reintenta hasta WEBHOOK_MAX_ATTEMPTS
+    status = http.StatusBadGateway
+    failed := &WebhookDelivery{Payload: JSON(`{}`), Status: DeliveryPending}
+    service.send(context.Background(), hook, failed)
+    assert.Equal(t, DeliveryPending, failed.Status)
+    assert.Equal(t, http.StatusBadGateway, failed.ResponseStatus)
+    assert.Equal(t, "unexpected status 502", failed.Error)
+    assert.True(t, failed.NextAttemptAt.After(time.Now().Add(50*time.Second)))
+
+    service.send(context.Background(), hook, failed)
+    assert.Equal(t, DeliveryFailed, failed.Status)
+    assert.Equal(t, 2, failed.Attempts)
+}
+
+func TestWebhookSendPrivateAddress(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        t.Error("request reached a loopback address")
+    }))
+    defer server.Close()
+
+    // La IP se comprueba al conectar, aunque la URL se hubiera aceptado
+    service := NewWebhookService(nil, zap.NewNop())
+    delivery := &WebhookDelivery{Payload: JSON(`{}`), Status: DeliveryPending}
+    service.send(context.Background(), Webhook{URL: server.URL, Secret: "secret"}, delivery)
+    assert.Equal(t, DeliveryPending, delivery.Status)
+    assert.Contains(t, delivery.Error, ErrPrivateAddress.Error())
+}
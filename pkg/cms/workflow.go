@@ -0,0 +1,281 @@
+// pkg/cms/workflow.go
+package cms
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "os"
+    "time"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+var (
+    ErrIllegalTransition   = errors.New("illegal workflow transition")
+    ErrTransitionForbidden = errors.New("role not allowed to perform this transition")
+    ErrCommentRequired     = errors.New("a comment is required for this transition")
+)
+
+const (
+    StatusDraft     = "draft"
+    StatusInReview  = "in_review"
+    StatusApproved  = "approved"
+    StatusPublished = "published"
+    StatusArchived  = "archived"
+)
+
+// WorkflowTransition describe un paso permitido de la máquina de estados.
+// RequiredReviewers indica cuántas aprobaciones de usuarios distintos hacen
+// falta antes de que el cambio de estado se aplique.
+type WorkflowTransition struct {
+    From              string   `json:"from"`
+    To                string   `json:"to"`
+    Roles             []string `json:"roles"`
+    RequiredReviewers int      `json:"required_reviewers"`
+    RequireComment    bool     `json:"require_comment"`
+}
+
+type Workflow struct {
+    Initial     string               `json:"initial"`
+    States      []string             `json:"states"`
+    Transitions []WorkflowTransition `json:"transitions"`
+}
+
+// ContentTransition guarda el historial de transiciones de cada contenido.
+// Applied es false para las aprobaciones que todavía no completan el número
+// de revisores requerido.
+type ContentTransition struct {
+    gorm.Model
+    ContentID uint   `json:"content_id" gorm:"index"`
+    FromState string `json:"from_state"`
+    ToState   string `json:"to_state"`
+    UserID    uint   `json:"user_id"`
+    Username  string `json:"username"`
+    Role      string `json:"role"`
+    Comment   string `json:"comment"`
+    Applied   bool   `json:"applied"`
+}
+
+type TransitionResult struct {
+    Content   *Content `json:"content"`
+    Applied   bool     `json:"applied"`
+    Approvals int      `json:"approvals"`
+    Required  int      `json:"required"`
+}
+
+func DefaultWorkflow() *Workflow {
+    return &Workflow{
+        Initial: StatusDraft,
+        States:  []string{StatusDraft, StatusInReview, StatusApproved, StatusPublished, StatusArchived},
+        Transitions: []WorkflowTransition{
+            {From: StatusDraft, To: StatusInReview, Roles: []string{models.RoleAuthor, models.RoleEditor, models.RoleAdmin}},
+            {From: StatusInReview, To: StatusDraft, Roles: []string{models.RoleEditor, models.RoleReviewer, models.RoleAdmin}, RequireComment: true},
+            {From: StatusInReview, To: StatusApproved, Roles: []string{models.RoleReviewer, models.RoleAdmin}, RequiredReviewers: 1},
+            {From: StatusApproved, To: StatusPublished, Roles: []string{models.RoleEditor, models.RoleAdmin}},
+            {From: StatusApproved, To: StatusDraft, Roles: []string{models.RoleEditor, models.RoleAdmin}},
+            {From: StatusPublished, To: StatusDraft, Roles: []string{models.RoleEditor, models.RoleAdmin}},
+            {From: StatusPublished, To: StatusArchived, Roles: []string{models.RoleEditor, models.RoleAdmin}},
+            {From: StatusArchived, To: StatusDraft, Roles: []string{models.RoleAdmin}},
+        },
+    }
+}
+
+// NewWorkflow carga el workflow desde el fichero JSON indicado en
+// CMS_WORKFLOW_FILE o usa el workflow por defecto.
+func NewWorkflow() (*Workflow, error) {
+    path := os.Getenv("CMS_WORKFLOW_FILE")
+    if path == "" {
+        return DefaultWorkflow(), nil
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    var workflow Workflow
+    if err := json.Unmarshal(data, &workflow); err != nil {
+        return nil, err
+    }
+    if workflow.Initial == "" {
+        workflow.Initial = StatusDraft
+    }
+    return &workflow, nil
+}
+
+func (w *Workflow) Find(from, to string) (*WorkflowTransition, bool) {
+    for i := range w.Transitions {
+        if w.Transitions[i].From == from && w.Transitions[i].To == to {
+            return &w.Transitions[i], true
+        }
+    }
+    return nil, false
+}
+
+// Check valida que un rol pueda mover un contenido de from a to.
+func (w *Workflow) Check(from, to, role string) (*WorkflowTransition, error) {
+    if from == "" {
+        from = w.Initial
+    }
+
+    transition, ok := w.Find(from, to)
+    if !ok {
+        return nil, ErrIllegalTransition
+    }
+    if role != models.RoleAdmin && !contains(transition.Roles, role) {
+        return nil, ErrTransitionForbidden
+    }
+    return transition, nil
+}
+
+// CanReach indica si un rol puede hacer alguna transición que lleve a to.
+func (w *Workflow) CanReach(to, role string) bool {
+    if role == models.RoleAdmin {
+        return true
+    }
+    for _, transition := range w.Transitions {
+        if transition.To == to && contains(transition.Roles, role) {
+            return true
+        }
+    }
+    return false
+}
+
+type WorkflowService struct {
+    db       *gorm.DB
+    content  *ContentService
+    workflow *Workflow
+}
+
+func NewWorkflowService(db *gorm.DB, content *ContentService, workflow *Workflow) *WorkflowService {
+    return &WorkflowService{
+        db:       db,
+        content:  content,
+        workflow: workflow,
+    }
+}
+
+func (s *WorkflowService) Workflow() *Workflow {
+    return s.workflow
+}
+
+// Transition mueve un contenido al estado to en nombre del usuario indicado.
+func (s *WorkflowService) Transition(ctx context.Context, contentID uint, to, username, comment string) (*TransitionResult, error) {
+    var user models.User
+    if err := s.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, ErrTransitionForbidden
+        }
+        return nil, err
+    }
+
+    result := &TransitionResult{}
+    var event *events.Event
+    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+        var content Content
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&content, contentID).Error; err != nil {
+            return err
+        }
+
+        from := content.Status
+        if from == "" {
+            from = s.workflow.Initial
+        }
+
+        transition, err := s.workflow.Check(from, to, user.Role)
+        if err != nil {
+            return err
+        }
+        if transition.RequireComment && comment == "" {
+            return ErrCommentRequired
+        }
+
+        entry := &ContentTransition{
+            ContentID: content.ID,
+            FromState: from,
+            ToState:   to,
+            UserID:    user.ID,
+            Username:  user.Username,
+            Role:      user.Role,
+            Comment:   comment,
+        }
+
+        result.Content = &content
+        result.Required = transition.RequiredReviewers
+        if transition.RequiredReviewers > 1 {
+            approvals, err := s.countApprovals(tx, &content, from, to, user.ID)
+            if err != nil {
+                return err
+            }
+            result.Approvals = approvals + 1
+            if result.Approvals < transition.RequiredReviewers {
+                return tx.Create(entry).Error
+            }
+        } else {
+            result.Approvals = 1
+        }
+
+        updates := map[string]interface{}{
+            "status":   to,
+            "revision": gorm.Expr("revision + 1"),
+        }
+        if to == StatusPublished {
+            updates["published_at"] = time.Now()
+        }
+        if err := tx.Model(&content).Updates(updates).Error; err != nil {
+            return err
+        }
+        if err := tx.First(&content, content.ID).Error; err != nil {
+            return err
+        }
+        if event, err = recordEvent(ctx, tx, s.content.outbox, statusEvent(from, to), content.ID, &content); err != nil {
+            return err
+        }
+
+        entry.Applied = true
+        result.Applied = true
+        return tx.Create(entry).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    s.content.outbox.Relay(ctx, event)
+    return result, nil
+}
+
+// countApprovals cuenta los revisores distintos (sin contar al usuario actual)
+// que ya aprobaron el paso from -> to desde la última vez que el contenido
+// entró en el estado from.
+func (s *WorkflowService) countApprovals(tx *gorm.DB, content *Content, from, to string, userID uint) (int, error) {
+    var since time.Time
+    var last ContentTransition
+    err := tx.Where("content_id = ? AND to_state = ? AND applied = ?", content.ID, from, true).
+        Order("created_at desc").
+        First(&last).Error
+    if err == nil {
+        since = last.CreatedAt
+    } else if !errors.Is(err, gorm.ErrRecordNotFound) {
+        return 0, err
+    }
+
+    var count int64
+    err = tx.Model(&ContentTransition{}).
+        Where("content_id = ? AND from_state = ? AND to_state = ? AND applied = ? AND created_at > ? AND user_id <> ?",
+            content.ID, from, to, false, since, userID).
+        Distinct("user_id").
+        Count(&count).Error
+    return int(count), err
+}
+
+func (s *WorkflowService) History(ctx context.Context, contentID uint) ([]ContentTransition, error) {
+    var history []ContentTransition
+    err := s.db.WithContext(ctx).
+        Where("content_id = ?", contentID).
+        Order("created_at asc").
+        Find(&history).Error
+    return history, err
+}
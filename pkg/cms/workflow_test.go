@@ -0,0 +1,40 @@
+// pkg/cms/workflow_test.go
+package cms
+
+import (
+    "testing"
+    "ezzygo/pkg/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowCheck(t *testing.T) {
+    workflow := DefaultWorkflow()
+
+    _, err := workflow.Check(StatusDraft, StatusInReview, models.RoleAuthor)
+    assert.NoError(t, err)
+
+    _, err = workflow.Check("", StatusInReview, models.RoleAuthor)
+    assert.NoError(t, err)
+
+    _, err = workflow.Check(StatusDraft, StatusPublished, models.RoleEditor)
+    assert.ErrorIs(t, err, ErrIllegalTransition)
+
+    _, err = workflow.Check(StatusInReview, StatusApproved, models.RoleAuthor)
+    assert.ErrorIs(t, err, ErrTransitionForbidden)
+
+    transition, err := workflow.Check(StatusInReview, StatusDraft, models.RoleReviewer)
+    assert.NoError(t, err)
+    assert.True(t, transition.RequireComment)
+
+    _, err = workflow.Check(StatusArchived, StatusDraft, models.RoleAdmin)
+    assert.NoError(t, err)
+}
+
+func TestWorkflowCanReach(t *testing.T) {
+    workflow := DefaultWorkflow()
+
+    assert.True(t, workflow.CanReach(StatusPublished, models.RoleEditor))
+    assert.True(t, workflow.CanReach(StatusPublished, models.RoleAdmin))
+    assert.False(t, workflow.CanReach(StatusPublished, models.RoleAuthor))
+    assert.False(t, workflow.CanReach(StatusPublished, models.RoleReviewer))
+}
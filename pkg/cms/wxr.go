@@ -0,0 +1,187 @@
+// pkg/cms/wxr.go
+package cms
+
+import (
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "io"
+    "net/url"
+    "strings"
+    "time"
+)
+
+var ErrInvalidWXR = errors.New("invalid wordpress export")
+
+// wxrExport es lo que usamos de un fichero WXR (el export de WordPress).
+// Las etiquetas no llevan espacio de nombres porque la URL de wp: cambia con
+// la versión del formato (1.0, 1.1, 1.2); encoding/xml las casa por nombre.
+type wxrExport struct {
+    Channel wxrChannel `xml:"channel"`
+}
+
+type wxrChannel struct {
+    Title       string      `xml:"title"`
+    Link        string      `xml:"link"`
+    BaseSiteURL string      `xml:"base_site_url"`
+    Authors     []wxrAuthor `xml:"author"`
+    Items       []wxrItem   `xml:"item"`
+}
+
+type wxrAuthor struct {
+    ID          uint   `xml:"author_id"`
+    Login       string `xml:"author_login"`
+    Email       string `xml:"author_email"`
+    DisplayName string `xml:"author_display_name"`
+}
+
+type wxrItem struct {
+    Title         string        `xml:"title"`
+    Link          string        `xml:"link"`
+    Creator       string        `xml:"creator"`
+    Encoded       []wxrEncoded  `xml:"encoded"` // content:encoded y excerpt:encoded
+    PostID        uint          `xml:"post_id"`
+    PostDateGMT   string        `xml:"post_date_gmt"`
+    PostDate      string        `xml:"post_date"`
+    PostName      string        `xml:"post_name"`
+    Status        string        `xml:"status"`
+    PostParent    uint          `xml:"post_parent"`
+    PostType      string        `xml:"post_type"`
+    AttachmentURL string        `xml:"attachment_url"`
+    Categories    []wxrCategory `xml:"category"`
+    Meta          []wxrMeta     `xml:"postmeta"`
+}
+
+type wxrEncoded struct {
+    XMLName xml.Name
+    Value   string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+    Domain   string `xml:"domain,attr"` // category, post_tag u otra taxonomía
+    Nicename string `xml:"nicename,attr"`
+    Name     string `xml:",chardata"`
+}
+
+type wxrMeta struct {
+    Key   string `xml:"meta_key"`
+    Value string `xml:"meta_value"`
+}
+
+// parseWXR lee un export de WordPress. Solo comprueba que sea un RSS con
+// canal; los elementos que no sabemos importar se ignoran después.
+func parseWXR(r io.Reader) (*wxrExport, error) {
+    var export wxrExport
+    decoder := xml.NewDecoder(r)
+    decoder.Strict = false
+    if err := decoder.Decode(&export); err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidWXR, err)
+    }
+    if export.Channel.Title == "" && export.Channel.Link == "" && len(export.Channel.Items) == 0 {
+        return nil, fmt.Errorf("%w: no channel", ErrInvalidWXR)
+    }
+    return &export, nil
+}
+
+// attachments y posts separan los elementos importables: los adjuntos se
+// importan primero para poder reescribir sus URLs en el contenido.
+func (e *wxrExport) attachments() []wxrItem {
+    var items []wxrItem
+    for _, item := range e.Channel.Items {
+        if item.PostType == "attachment" && item.AttachmentURL != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+func (e *wxrExport) posts() []wxrItem {
+    var items []wxrItem
+    for _, item := range e.Channel.Items {
+        if (item.PostType == "post" || item.PostType == "page") && wxrStatus(item.Status) != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// encoded devuelve content:encoded o excerpt:encoded según el espacio de
+// nombres.
+func (item *wxrItem) encoded(space string) string {
+    for _, encoded := range item.Encoded {
+        if strings.Contains(encoded.XMLName.Space, space) {
+            return encoded.Value
+        }
+    }
+    return ""
+}
+
+func (item *wxrItem) meta(key string) string {
+    for _, meta := range item.Meta {
+        if meta.Key == key {
+            return meta.Value
+        }
+    }
+    return ""
+}
+
+// publishedAt es la fecha de publicación en UTC. Los borradores traen
+// 0000-00-00 00:00:00.
+func (item *wxrItem) publishedAt() time.Time {
+    for _, value := range []string{item.PostDateGMT, item.PostDate} {
+        if parsed, err := time.Parse("2006-01-02 15:04:05", strings.TrimSpace(value)); err == nil {
+            return parsed
+        }
+    }
+    return time.Time{}
+}
+
+// terms son las categorías y etiquetas del post, que aquí son tags, sin
+// repetir y sin "Uncategorized".
+func (item *wxrItem) terms() StringArray {
+    terms := StringArray{}
+    seen := map[string]bool{}
+    for _, category := range item.Categories {
+        name := strings.TrimSpace(category.Name)
+        if (category.Domain != "category" && category.Domain != "post_tag") || name == "" || category.Nicename == "uncategorized" {
+            continue
+        }
+        if !seen[name] {
+            seen[name] = true
+            terms = append(terms, name)
+        }
+    }
+    return terms
+}
+
+// wxrStatus traduce el estado de WordPress. Vacío significa que no se
+// importa (papelera, autoguardados, revisiones).
+func wxrStatus(status string) string {
+    switch status {
+    case "publish":
+        return StatusPublished
+    case "draft", "pending", "private", "future":
+        return StatusDraft
+    }
+    return ""
+}
+
+// wxrPath es la ruta del permalink antiguo, origen de la redirección. Con
+// permalinks por defecto (?p=123) la consulta forma parte de la ruta.
+func wxrPath(link string) string {
+    parsed, err := url.Parse(strings.TrimSpace(link))
+    if err != nil {
+        return ""
+    }
+    path := parsed.EscapedPath()
+    if path == "" {
+        path = "/"
+    }
+    if parsed.RawQuery != "" {
+        path += "?" + parsed.RawQuery
+    }
+    if path == "/" {
+        return ""
+    }
+    return path
+}
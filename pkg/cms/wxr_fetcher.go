@@ -0,0 +1,86 @@
+// pkg/cms/wxr_fetcher.go
+package cms
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "mime"
+    "net/http"
+    "net/url"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// AttachmentFetcher descarga los adjuntos de un import de WordPress. Devuelve
+// el contenido y su Content-Type.
+type AttachmentFetcher interface {
+    Fetch(ctx context.Context, url string) (io.ReadCloser, string, error)
+}
+
+// NewAttachmentFetcherFromEnv lee los adjuntos de WXR_UPLOADS_DIR (una copia
+// de wp-content/uploads) si está definido y, si no, del sitio original.
+func NewAttachmentFetcherFromEnv() AttachmentFetcher {
+    if dir := os.Getenv("WXR_UPLOADS_DIR"); dir != "" {
+        return NewDirFetcher(dir)
+    }
+    return NewHTTPFetcher()
+}
+
+// HTTPFetcher descarga los adjuntos de sus URLs. Las da el fichero subido,
+// así que solo se conecta a direcciones públicas, como los webhooks.
+type HTTPFetcher struct {
+    client *http.Client
+}
+
+func NewHTTPFetcher() *HTTPFetcher {
+    return &HTTPFetcher{client: newOutboundClient(time.Minute)}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, string, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+    if err != nil {
+        return nil, "", err
+    }
+    resp, err := f.client.Do(req)
+    if err != nil {
+        return nil, "", err
+    }
+    if resp.StatusCode != http.StatusOK {
+        resp.Body.Close()
+        return nil, "", fmt.Errorf("fetch %s: status %d", url, resp.StatusCode)
+    }
+    return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+// DirFetcher resuelve la URL de un adjunto a un fichero de dir: la ruta a
+// partir de wp-content/uploads/ o, si no la tiene, la ruta entera.
+type DirFetcher struct {
+    dir string
+}
+
+func NewDirFetcher(dir string) *DirFetcher {
+    return &DirFetcher{dir: dir}
+}
+
+func (f *DirFetcher) Fetch(ctx context.Context, rawURL string) (io.ReadCloser, string, error) {
+    parsed, err := url.Parse(rawURL)
+    if err != nil {
+        return nil, "", err
+    }
+    name := parsed.Path
+    if n := strings.Index(name, "/wp-content/uploads/"); n >= 0 {
+        name = name[n+len("/wp-content/uploads/"):]
+    }
+    // path.Clean con "/" delante impide salir de dir
+    name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+    file, err := os.Open(filepath.Join(f.dir, filepath.FromSlash(name)))
+    if err != nil {
+        return nil, "", err
+    }
+    return file, mime.TypeByExtension(path.Ext(name)), nil
+}
@@ -0,0 +1,678 @@
+// pkg/cms/wxr_import.go
+package cms
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "mime"
+    "net/url"
+    "os"
+    "path"
+    "strconv"
+    "strings"
+    "time"
+    "ezzygo/pkg/auth"
+    "ezzygo/pkg/cache"
+    "ezzygo/pkg/events"
+    "ezzygo/pkg/models"
+    "go.uber.org/zap"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// Estados de un trabajo de importación.
+const (
+    ImportJobPending   = "pending"
+    ImportJobRunning   = "running"
+    ImportJobCompleted = "completed"
+    ImportJobFailed    = "failed"
+)
+
+// Resultado de cada elemento de un trabajo.
+const (
+    importItemCreated = "created"
+    importItemSkipped = "skipped"
+    importItemFailed  = "failed"
+)
+
+const (
+    maxAttachmentSize = 100 << 20
+    wxrLeaseTTL       = 2 * time.Minute // se renueva con cada elemento
+    maxImportErrors   = 50
+)
+
+var ErrImportJobState = errors.New("import job is not failed")
+
+// WXRImportJob es una importación de WordPress en segundo plano. Guarda el
+// propio fichero para poder reanudarse: si la réplica que la ejecuta se
+// detiene, otra la retoma cuando vence su lease.
+type WXRImportJob struct {
+    ID         uint        `json:"id" gorm:"primarykey"`
+    FileName   string      `json:"file_name"`
+    Locale     string      `json:"locale" gorm:"size:16"`
+    Status     string      `json:"status" gorm:"size:16;index"`
+    Total      int         `json:"total"` // adjuntos, posts y páginas a importar
+    Processed  int         `json:"processed"`
+    Created    int         `json:"created"`
+    Skipped    int         `json:"skipped"` // importados ya por un trabajo anterior
+    Failed     int         `json:"failed"`
+    Authors    int         `json:"authors"` // usuarios creados
+    Redirects  int         `json:"redirects"`
+    Errors     StringArray `json:"errors" gorm:"type:text[];default:'{}'"` // los primeros errores por elemento
+    Error      string      `json:"error,omitempty"`                        // por qué falló el trabajo
+    CreatedBy  string      `json:"created_by"`
+    Source     []byte      `json:"-"`
+    LeaseUntil *time.Time  `json:"-"`
+    StartedAt  *time.Time  `json:"started_at"`
+    FinishedAt *time.Time  `json:"finished_at"`
+    CreatedAt  time.Time   `json:"created_at"`
+    UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// WXRImportItem es un elemento ya procesado de un trabajo. Al reanudar se
+// salta y de él se recuperan los medios creados. Source identifica el
+// elemento en WordPress para que otro trabajo del mismo sitio no lo duplique.
+type WXRImportItem struct {
+    JobID    uint   `gorm:"primaryKey;autoIncrement:false"`
+    PostID   uint   `gorm:"primaryKey;autoIncrement:false"` // wp:post_id
+    Source   string `gorm:"index"`                          // ver wxrSource
+    TargetID uint   // Media o Content
+    Result   string `gorm:"size:16"`
+}
+
+// MediaUploader sube un medio y guarda su registro; lo implementa
+// MediaService.
+type MediaUploader interface {
+    Upload(ctx context.Context, media *Media, file io.Reader) error
+}
+
+// WXRImporter ejecuta los trabajos de importación de WordPress: autores
+// (models.User), adjuntos (Media), posts y páginas (Content) con sus
+// categorías y etiquetas como tags, y redirecciones desde los permalinks
+// antiguos.
+type WXRImporter struct {
+    db       *gorm.DB
+    uploader MediaUploader
+    fetcher  AttachmentFetcher
+    versions *VersionService
+    outbox   *events.Outbox
+    cache    *cache.Store
+    locales  *LocaleConfig
+    logger   *zap.Logger
+    interval time.Duration
+    wake     chan struct{}
+}
+
+// NewWXRImporter busca trabajos pendientes cada CMS_IMPORT_INTERVAL (por
+// defecto 10s), además de al crear o reanudar uno.
+func NewWXRImporter(db *gorm.DB, uploader MediaUploader, fetcher AttachmentFetcher, versions *VersionService, outbox *events.Outbox, store *cache.Store, locales *LocaleConfig, logger *zap.Logger) *WXRImporter {
+    interval := 10 * time.Second
+    if value := os.Getenv("CMS_IMPORT_INTERVAL"); value != "" {
+        if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+            interval = parsed
+        } else {
+            logger.Warn("Invalid CMS_IMPORT_INTERVAL, using default", zap.String("value", value))
+        }
+    }
+
+    return &WXRImporter{
+        db:       db,
+        uploader: uploader,
+        fetcher:  fetcher,
+        versions: versions,
+        outbox:   outbox,
+        cache:    store,
+        locales:  locales,
+        logger:   logger,
+        interval: interval,
+        wake:     make(chan struct{}, 1),
+    }
+}
+
+// Create valida el fichero y encola el trabajo.
+func (i *WXRImporter) Create(ctx context.Context, fileName string, source []byte, locale string) (*WXRImportJob, error) {
+    if locale == "" {
+        locale = i.locales.Default
+    }
+    locale = i.locales.Normalize(locale)
+    if !i.locales.IsSupported(locale) {
+        return nil, ErrUnsupportedLocale
+    }
+
+    export, err := parseWXR(bytes.NewReader(source))
+    if err != nil {
+        return nil, err
+    }
+
+    job := &WXRImportJob{
+        FileName:  fileName,
+        Locale:    locale,
+        Status:    ImportJobPending,
+        Total:     len(export.attachments()) + len(export.posts()),
+        Errors:    StringArray{},
+        CreatedBy: auth.UsernameFromContext(ctx),
+        Source:    source,
+    }
+    if err := i.db.WithContext(ctx).Create(job).Error; err != nil {
+        return nil, err
+    }
+    i.Notify()
+    return job, nil
+}
+
+func (i *WXRImporter) Get(ctx context.Context, id uint) (*WXRImportJob, error) {
+    var job WXRImportJob
+    if err := i.db.WithContext(ctx).Omit("source").First(&job, id).Error; err != nil {
+        return nil, err
+    }
+    return &job, nil
+}
+
+func (i *WXRImporter) List(ctx context.Context) ([]WXRImportJob, error) {
+    var jobs []WXRImportJob
+    err := i.db.WithContext(ctx).Omit("source").Order("id DESC").Limit(100).Find(&jobs).Error
+    return jobs, err
+}
+
+// Resume vuelve a encolar un trabajo fallido. Continúa donde lo dejó: los
+// elementos ya procesados no se repiten.
+func (i *WXRImporter) Resume(ctx context.Context, id uint) (*WXRImportJob, error) {
+    job, err := i.Get(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    result := i.db.WithContext(ctx).Model(&WXRImportJob{}).
+        Where("id = ? AND status = ?", id, ImportJobFailed).
+        Updates(map[string]interface{}{"status": ImportJobPending, "error": "", "finished_at": nil})
+    if result.Error != nil {
+        return nil, result.Error
+    }
+    if result.RowsAffected == 0 {
+        return nil, fmt.Errorf("%w: %s", ErrImportJobState, job.Status)
+    }
+    i.Notify()
+    return i.Get(ctx, id)
+}
+
+// Notify despierta el bucle sin esperar al siguiente intervalo.
+func (i *WXRImporter) Notify() {
+    select {
+    case i.wake <- struct{}{}:
+    default:
+    }
+}
+
+// Run ejecuta los trabajos pendientes hasta que se cancela el contexto. Un
+// trabajo interrumpido se queda en running y se retoma al vencer su lease.
+func (i *WXRImporter) Run(ctx context.Context) {
+    ticker := time.NewTicker(i.interval)
+    defer ticker.Stop()
+
+    for {
+        i.runPending(ctx)
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        case <-i.wake:
+        }
+    }
+}
+
+func (i *WXRImporter) runPending(ctx context.Context) {
+    for ctx.Err() == nil {
+        job, err := i.claim(ctx)
+        if err != nil {
+            i.logger.Error("Failed to claim import job", zap.Error(err))
+            return
+        }
+        if job == nil {
+            return
+        }
+        i.runJob(ctx, job)
+    }
+}
+
+// claim toma el trabajo pendiente más antiguo, o uno en curso cuyo lease ha
+// vencido. La condición se repite en el UPDATE para que solo una réplica lo
+// consiga.
+func (i *WXRImporter) claim(ctx context.Context) (*WXRImportJob, error) {
+    db := i.db.WithContext(ctx)
+    now := time.Now()
+    claimable := func(db *gorm.DB) *gorm.DB {
+        return db.Where("status = ? OR (status = ? AND lease_until < ?)", ImportJobPending, ImportJobRunning, now)
+    }
+
+    var job WXRImportJob
+    err := db.Scopes(claimable).Select("id").Order("id").First(&job).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    result := db.Model(&WXRImportJob{}).Where("id = ?", job.ID).Scopes(claimable).Updates(map[string]interface{}{
+        "status":      ImportJobRunning,
+        "lease_until": now.Add(wxrLeaseTTL),
+        "started_at":  gorm.Expr("COALESCE(started_at, ?)", now),
+    })
+    if result.Error != nil || result.RowsAffected == 0 {
+        return nil, result.Error
+    }
+    if err := db.First(&job, job.ID).Error; err != nil {
+        return nil, err
+    }
+    return &job, nil
+}
+
+func (i *WXRImporter) runJob(ctx context.Context, job *WXRImportJob) {
+    i.logger.Info("Running WordPress import", zap.Uint("job", job.ID), zap.Int("processed", job.Processed), zap.Int("total", job.Total))
+
+    err := i.process(ctx, job)
+    if err != nil && ctx.Err() != nil {
+        return
+    }
+
+    now := time.Now()
+    updates := map[string]interface{}{"status": ImportJobCompleted, "lease_until": nil, "finished_at": now}
+    if err != nil {
+        i.logger.Error("WordPress import failed", zap.Uint("job", job.ID), zap.Error(err))
+        updates["status"] = ImportJobFailed
+        updates["error"] = err.Error()
+    }
+    if err := i.db.Model(&WXRImportJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+        i.logger.Error("Failed to record import job outcome", zap.Uint("job", job.ID), zap.Error(err))
+    }
+
+    // Las redirecciones no tienen eventos: se purgan al terminar
+    if err := i.cache.InvalidateTags(context.Background(), RedirectsTag); err != nil {
+        i.logger.Warn("Failed to invalidate redirects", zap.Error(err))
+    }
+}
+
+// wxrRun es el estado de una ejecución de un trabajo.
+type wxrRun struct {
+    importer  *WXRImporter
+    job       *WXRImportJob
+    done      map[uint]WXRImportItem
+    media     map[uint]*Media   // wp:post_id del adjunto -> medio
+    urls      map[string]string // URL del adjunto en WordPress -> URL del medio
+    authors   map[string]uint   // login -> usuario
+    templates map[string]uint   // tipo de post -> plantilla
+    site      string            // URL del sitio de WordPress
+}
+
+func (i *WXRImporter) process(ctx context.Context, job *WXRImportJob) error {
+    export, err := parseWXR(bytes.NewReader(job.Source))
+    if err != nil {
+        return err
+    }
+
+    run := &wxrRun{
+        importer:  i,
+        job:       job,
+        done:      map[uint]WXRImportItem{},
+        media:     map[uint]*Media{},
+        urls:      map[string]string{},
+        authors:   map[string]uint{},
+        templates: map[string]uint{},
+        site:      export.Channel.BaseSiteURL,
+    }
+    if run.site == "" {
+        run.site = export.Channel.Link
+    }
+    if err := run.restore(ctx, export); err != nil {
+        return err
+    }
+    if err := run.importAuthors(ctx, export.Channel.Authors); err != nil {
+        return err
+    }
+
+    for _, item := range export.attachments() {
+        if _, ok := run.done[item.PostID]; ok {
+            continue
+        }
+        targetID, result, itemErr := run.attachment(ctx, item)
+        if err := run.record(ctx, item, targetID, result, itemErr); err != nil {
+            return err
+        }
+    }
+    for _, item := range export.posts() {
+        if _, ok := run.done[item.PostID]; ok {
+            continue
+        }
+        targetID, result, itemErr := run.post(ctx, item)
+        if err := run.record(ctx, item, targetID, result, itemErr); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// restore recupera lo procesado en ejecuciones anteriores del trabajo.
+func (r *wxrRun) restore(ctx context.Context, export *wxrExport) error {
+    db := r.importer.db.WithContext(ctx)
+    var items []WXRImportItem
+    if err := db.Where("job_id = ?", r.job.ID).Find(&items).Error; err != nil {
+        return err
+    }
+    for _, item := range items {
+        r.done[item.PostID] = item
+    }
+
+    for _, item := range export.attachments() {
+        done, ok := r.done[item.PostID]
+        if !ok || done.TargetID == 0 {
+            continue
+        }
+        var media Media
+        err := db.First(&media, done.TargetID).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            continue
+        }
+        if err != nil {
+            return err
+        }
+        r.addMedia(item, &media)
+    }
+    return nil
+}
+
+// importAuthors crea los autores que no existen. Se crean sin contraseña, así
+// que no pueden entrar hasta que un admin les asigne una.
+func (r *wxrRun) importAuthors(ctx context.Context, authors []wxrAuthor) error {
+    db := r.importer.db.WithContext(ctx)
+    for _, author := range authors {
+        login := strings.TrimSpace(author.Login)
+        if login == "" {
+            continue
+        }
+        var user models.User
+        err := db.Select("id").Where("username = ?", login).First(&user).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            user = models.User{Username: login, Role: models.RoleAuthor}
+            if err := db.Create(&user).Error; err != nil {
+                return err
+            }
+            if err := db.Model(&WXRImportJob{}).Where("id = ?", r.job.ID).
+                UpdateColumn("authors", gorm.Expr("authors + 1")).Error; err != nil {
+                return err
+            }
+        } else if err != nil {
+            return err
+        }
+        r.authors[login] = user.ID
+    }
+    return nil
+}
+
+// imported carga en dest el medio o contenido que un trabajo anterior (o
+// este antes de un corte) importó del mismo elemento de WordPress. Devuelve
+// false si no hay ninguno o ya no existe.
+func (r *wxrRun) imported(ctx context.Context, item wxrItem, dest interface{}) (bool, error) {
+    db := r.importer.db.WithContext(ctx)
+    var previous []WXRImportItem
+    if err := db.Where("source = ? AND target_id <> 0 AND result <> ?", wxrSource(r.site, item), importItemFailed).
+        Order("job_id DESC").Find(&previous).Error; err != nil {
+        return false, err
+    }
+    for _, done := range previous {
+        err := db.First(dest, done.TargetID).Error
+        if err == nil {
+            return true, nil
+        }
+        if !errors.Is(err, gorm.ErrRecordNotFound) {
+            return false, err
+        }
+    }
+    return false, nil
+}
+
+func (r *wxrRun) attachment(ctx context.Context, item wxrItem) (uint, string, error) {
+    // Un reintento o una nueva importación del mismo sitio no duplica el medio
+    var existing Media
+    found, err := r.imported(ctx, item, &existing)
+    if err != nil {
+        return 0, importItemFailed, err
+    }
+    if found {
+        r.addMedia(item, &existing)
+        return existing.ID, importItemSkipped, nil
+    }
+
+    body, contentType, err := r.importer.fetcher.Fetch(ctx, item.AttachmentURL)
+    if err != nil {
+        return 0, importItemFailed, err
+    }
+    defer body.Close()
+
+    data, err := io.ReadAll(io.LimitReader(body, maxAttachmentSize+1))
+    if err != nil {
+        return 0, importItemFailed, err
+    }
+    if len(data) > maxAttachmentSize {
+        return 0, importItemFailed, fmt.Errorf("attachment is larger than %d bytes", maxAttachmentSize)
+    }
+
+    name := path.Base(item.AttachmentURL)
+    if parsed, err := url.Parse(item.AttachmentURL); err == nil {
+        name = path.Base(parsed.Path)
+    }
+    mimeType, _, _ := mime.ParseMediaType(contentType)
+    if mimeType == "" || mimeType == "application/octet-stream" {
+        if byExtension := mime.TypeByExtension(path.Ext(name)); byExtension != "" {
+            mimeType, _, _ = mime.ParseMediaType(byExtension)
+        }
+    }
+
+    media := &Media{
+        Name:     name,
+        Type:     strings.SplitN(mimeType, "/", 2)[0],
+        MimeType: mimeType,
+        Size:     int64(len(data)),
+    }
+    if err := r.importer.uploader.Upload(ctx, media, bytes.NewReader(data)); err != nil {
+        return 0, importItemFailed, err
+    }
+    r.addMedia(item, media)
+    return media.ID, importItemCreated, nil
+}
+
+func (r *wxrRun) addMedia(item wxrItem, media *Media) {
+    r.media[item.PostID] = media
+    r.urls[item.AttachmentURL] = media.URL
+}
+
+func (r *wxrRun) post(ctx context.Context, item wxrItem) (uint, string, error) {
+    slug, err := url.PathUnescape(item.PostName)
+    if err != nil || slug == "" {
+        slug = generateSlug(item.Title)
+    }
+    if slug == "" {
+        slug = fmt.Sprintf("wp-%d", item.PostID)
+    }
+
+    db := r.importer.db.WithContext(ctx)
+    content := &Content{
+        Title:           item.Title,
+        Slug:            slug,
+        Locale:          r.job.Locale,
+        Content:         rewriteAttachmentURLs(item.encoded("content"), r.urls),
+        Status:          wxrStatus(item.Status),
+        AuthorID:        r.authors[item.Creator],
+        Tags:            item.terms(),
+        Revision:        1,
+        MetaDescription: excerpt(item.encoded("excerpt"), 500),
+    }
+    if content.Status == StatusPublished {
+        content.PublishedAt = item.publishedAt()
+    }
+    if thumbnail, err := strconv.ParseUint(item.meta("_thumbnail_id"), 10, 64); err == nil {
+        if media, ok := r.media[uint(thumbnail)]; ok && strings.HasPrefix(media.MimeType, "image/") {
+            content.OGImageID = &media.ID
+        }
+    }
+
+    // Ya importado (por este trabajo antes de un corte o por otro del mismo
+    // sitio): solo falta la redirección
+    var existing Content
+    found, err := r.imported(ctx, item, &existing)
+    if err != nil {
+        return 0, importItemFailed, err
+    }
+    if found {
+        if err := r.redirect(db, item, &existing); err != nil {
+            return existing.ID, importItemFailed, err
+        }
+        return existing.ID, importItemSkipped, nil
+    }
+
+    // Otro contenido con el mismo slug no es este post: se importa con uno libre
+    if content.Slug, err = freeSlug(db, content.Locale, slug); err != nil {
+        return 0, importItemFailed, err
+    }
+
+    if content.TemplateID, err = r.template(ctx, item.PostType); err != nil {
+        return 0, importItemFailed, err
+    }
+
+    var event *events.Event
+    err = db.Transaction(func(tx *gorm.DB) (err error) {
+        if err := tx.Create(content).Error; err != nil {
+            return err
+        }
+        content.TranslationGroupID = content.ID
+        if err := tx.Model(content).Update("translation_group_id", content.ID).Error; err != nil {
+            return err
+        }
+        if err := r.redirect(tx, item, content); err != nil {
+            return err
+        }
+        if err := r.importer.versions.Record(ctx, tx, EntityContent, content.ID, content, "wordpress import"); err != nil {
+            return err
+        }
+        event, err = recordEvent(ctx, tx, r.importer.outbox, EventContentCreated, content.ID, content)
+        return err
+    })
+    if err != nil {
+        return 0, importItemFailed, err
+    }
+
+    r.importer.outbox.Relay(ctx, event)
+    return content.ID, importItemCreated, nil
+}
+
+// redirect crea la redirección del permalink antiguo a la ruta nueva, si
+// son distintas y no existe ya una desde esa ruta.
+func (r *wxrRun) redirect(tx *gorm.DB, item wxrItem, content *Content) error {
+    from := wxrPath(item.Link)
+    to := ContentPath(r.importer.locales, content)
+    if from == "" || from == to {
+        return nil
+    }
+
+    result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&Redirect{
+        FromPath:   from,
+        ToPath:     to,
+        StatusCode: 301,
+        Source:     fmt.Sprintf("wordpress:%d", r.job.ID),
+    })
+    if result.Error != nil || result.RowsAffected == 0 {
+        return result.Error
+    }
+    return tx.Model(&WXRImportJob{}).Where("id = ?", r.job.ID).
+        UpdateColumn("redirects", gorm.Expr("redirects + 1")).Error
+}
+
+// template es la plantilla del tipo del post (post o page), preferiblemente
+// la marcada por defecto. Sin plantilla de ese tipo, ninguna.
+func (r *wxrRun) template(ctx context.Context, postType string) (uint, error) {
+    if id, ok := r.templates[postType]; ok {
+        return id, nil
+    }
+    var template Template
+    err := r.importer.db.WithContext(ctx).Select("id").Where("type = ?", postType).
+        Order("is_default DESC").Order("id").First(&template).Error
+    if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+        return 0, err
+    }
+    r.templates[postType] = template.ID
+    return template.ID, nil
+}
+
+// record guarda el resultado de un elemento y el progreso del trabajo, y
+// renueva el lease. Solo devuelve error si no pudo guardarlo.
+func (r *wxrRun) record(ctx context.Context, item wxrItem, targetID uint, result string, itemErr error) error {
+    updates := map[string]interface{}{
+        "processed":   gorm.Expr("processed + 1"),
+        "lease_until": time.Now().Add(wxrLeaseTTL),
+    }
+    switch result {
+    case importItemCreated:
+        updates["created"] = gorm.Expr("created + 1")
+    case importItemSkipped:
+        updates["skipped"] = gorm.Expr("skipped + 1")
+    default:
+        updates["failed"] = gorm.Expr("failed + 1")
+        message := fmt.Sprintf("%s %d (%s): %v", item.PostType, item.PostID, item.Title, itemErr)
+        updates["errors"] = gorm.Expr("CASE WHEN cardinality(errors) < ? THEN array_append(errors, ?) ELSE errors END", maxImportErrors, message)
+        r.importer.logger.Warn("WordPress import item failed", zap.Uint("job", r.job.ID), zap.String("item", message))
+    }
+
+    return r.importer.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        row := &WXRImportItem{JobID: r.job.ID, PostID: item.PostID, Source: wxrSource(r.site, item), TargetID: targetID, Result: result}
+        if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(row).Error; err != nil {
+            return err
+        }
+        return tx.Model(&WXRImportJob{}).Where("id = ?", r.job.ID).Updates(updates).Error
+    })
+}
+
+// wxrSource identifica un elemento de WordPress: la URL del fichero de un
+// adjunto y el enlace corto (?p=<id>) de un post o página.
+func wxrSource(site string, item wxrItem) string {
+    if item.PostType == "attachment" {
+        return item.AttachmentURL
+    }
+    return fmt.Sprintf("%s/?p=%d", strings.TrimSuffix(site, "/"), item.PostID)
+}
+
+// freeSlug devuelve slug o, si otro contenido del idioma ya lo usa, el primer
+// slug-2, slug-3... libre. Cuenta los borrados: el índice único también.
+func freeSlug(db *gorm.DB, locale, slug string) (string, error) {
+    var taken []string
+    if err := db.Model(&Content{}).Unscoped().
+        Where("locale = ? AND (slug = ? OR slug LIKE ?)", locale, slug, escapeLike(slug)+"-%").
+        Pluck("slug", &taken).Error; err != nil {
+        return "", err
+    }
+    return nextSlug(slug, taken), nil
+}
+
+func nextSlug(slug string, taken []string) string {
+    used := make(map[string]bool, len(taken))
+    for _, value := range taken {
+        used[value] = true
+    }
+    candidate := slug
+    for n := 2; used[candidate]; n++ {
+        candidate = fmt.Sprintf("%s-%d", slug, n)
+    }
+    return candidate
+}
+
+// rewriteAttachmentURLs cambia en el cuerpo las URLs de los adjuntos de
+// WordPress por las de los medios importados.
+func rewriteAttachmentURLs(body string, urls map[string]string) string {
+    for from, to := range urls {
+        if from != "" && to != "" {
+            body = strings.ReplaceAll(body, from, to)
+        }
+    }
+    return body
+}
@@ -0,0 +1,177 @@
+// pkg/cms/wxr_test.go
+package cms
+
+import (
+    "context"
+    "errors"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"
+    xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+    xmlns:content="http://purl.org/rss/1.0/modules/content/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+    <title>My Blog</title>
+    <link>https://blog.example.com</link>
+    <wp:base_site_url>https://blog.example.com</wp:base_site_url>
+    <wp:author>
+        <wp:author_id>2</wp:author_id>
+        <wp:author_login><![CDATA[jane]]></wp:author_login>
+        <wp:author_email><![CDATA[jane@example.com]]></wp:author_email>
+        <wp:author_display_name><![CDATA[Jane]]></wp:author_display_name>
+    </wp:author>
+    <item>
+        <title>photo</title>
+        <link>https://blog.example.com/photo/</link>
+        <wp:post_id>10</wp:post_id>
+        <wp:status>inherit</wp:status>
+        <wp:post_type>attachment</wp:post_type>
+        <wp:attachment_url>https://blog.example.com/wp-content/uploads/2024/03/photo.jpg</wp:attachment_url>
+    </item>
+    <item>
+        <title>Hello world</title>
+        <link>https://blog.example.com/2024/03/hello-world/</link>
+        <dc:creator><![CDATA[jane]]></dc:creator>
+        <content:encoded><![CDATA[<p>Hi <img src="https://blog.example.com/wp-content/uploads/2024/03/photo.jpg"></p>]]></content:encoded>
+        <excerpt:encoded><![CDATA[<p>Short</p>]]></excerpt:encoded>
+        <wp:post_id>11</wp:post_id>
+        <wp:post_date>2024-03-01 12:00:00</wp:post_date>
+        <wp:post_date_gmt>2024-03-01 10:00:00</wp:post_date_gmt>
+        <wp:post_name><![CDATA[hello-world]]></wp:post_name>
+        <wp:status>publish</wp:status>
+        <wp:post_type>post</wp:post_type>
+        <category domain="category" nicename="news"><![CDATA[News]]></category>
+        <category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+        <category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+        <category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+        <category domain="post_format" nicename="post-format-aside"><![CDATA[Aside]]></category>
+        <wp:postmeta>
+            <wp:meta_key>_thumbnail_id</wp:meta_key>
+            <wp:meta_value>10</wp:meta_value>
+        </wp:postmeta>
+    </item>
+    <item>
+        <title>Draft</title>
+        <link>https://blog.example.com/?p=12</link>
+        <wp:post_id>12</wp:post_id>
+        <wp:post_date_gmt>0000-00-00 00:00:00</wp:post_date_gmt>
+        <wp:status>draft</wp:status>
+        <wp:post_type>page</wp:post_type>
+    </item>
+    <item>
+        <title>Trashed</title>
+        <wp:post_id>13</wp:post_id>
+        <wp:status>trash</wp:status>
+        <wp:post_type>post</wp:post_type>
+    </item>
+    <item>
+        <title>Menu</title>
+        <wp:post_id>14</wp:post_id>
+        <wp:status>publish</wp:status>
+        <wp:post_type>nav_menu_item</wp:post_type>
+    </item>
+</channel>
+</rss>`
+
+func TestParseWXR(t *testing.T) {
+    export, err := parseWXR(strings.NewReader(testWXR))
+    require.NoError(t, err)
+
+    assert.Equal(t, "My Blog", export.Channel.Title)
+    require.Len(t, export.Channel.Authors, 1)
+    assert.Equal(t, "jane", export.Channel.Authors[0].Login)
+
+    attachments := export.attachments()
+    require.Len(t, attachments, 1)
+    assert.Equal(t, uint(10), attachments[0].PostID)
+
+    // Sin papelera ni menús
+    posts := export.posts()
+    require.Len(t, posts, 2)
+    post := posts[0]
+    assert.Equal(t, "hello-world", post.PostName)
+    assert.Equal(t, "jane", post.Creator)
+    assert.Contains(t, post.encoded("content"), "<p>Hi <img")
+    assert.Equal(t, "<p>Short</p>", post.encoded("excerpt"))
+    assert.Equal(t, "10", post.meta("_thumbnail_id"))
+    assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), post.publishedAt())
+    assert.Equal(t, StringArray{"News", "Go"}, post.terms())
+
+    assert.True(t, posts[1].publishedAt().IsZero())
+}
+
+func TestParseWXRInvalid(t *testing.T) {
+    for _, body := range []string{"", "not xml", "<rss></rss>"} {
+        _, err := parseWXR(strings.NewReader(body))
+        assert.True(t, errors.Is(err, ErrInvalidWXR), body)
+    }
+}
+
+func TestWXRStatus(t *testing.T) {
+    assert.Equal(t, StatusPublished, wxrStatus("publish"))
+    assert.Equal(t, StatusDraft, wxrStatus("pending"))
+    assert.Equal(t, StatusDraft, wxrStatus("future"))
+    assert.Empty(t, wxrStatus("trash"))
+    assert.Empty(t, wxrStatus("auto-draft"))
+}
+
+func TestWXRPath(t *testing.T) {
+    assert.Equal(t, "/2024/03/hello-world/", wxrPath("https://blog.example.com/2024/03/hello-world/"))
+    assert.Equal(t, "/?p=12", wxrPath("https://blog.example.com/?p=12"))
+    assert.Equal(t, "/caf%C3%A9/", wxrPath("https://blog.example.com/caf%C3%A9/"))
+    assert.Empty(t, wxrPath("https://blog.example.com/"))
+    assert.Empty(t, wxrPath(""))
+}
+
+func TestWXRSource(t *testing.T) {
+    attachment := wxrItem{PostID: 7, PostType: "attachment", AttachmentURL: "https://blog.example.com/wp-content/uploads/a.jpg"}
+    assert.Equal(t, "https://blog.example.com/wp-content/uploads/a.jpg", wxrSource("https://blog.example.com", attachment))
+
+    post := wxrItem{PostID: 12, PostType: "post"}
+    assert.Equal(t, "https://blog.example.com/?p=12", wxrSource("https://blog.example.com/", post))
+}
+
+func TestNextSlug(t *testing.T) {
+    assert.Equal(t, "hello", nextSlug("hello", nil))
+    assert.Equal(t, "hello-2", nextSlug("hello", []string{"hello"}))
+    assert.Equal(t, "hello-4", nextSlug("hello", []string{"hello", "hello-2", "hello-3", "hello-world"}))
+}
+
+func TestRewriteAttachmentURLs(t *testing.T) {
+    body := `<img src="https://blog.example.com/wp-content/uploads/a.jpg"><a href="https://blog.example.com/other/">x</a>`
+    assert.Equal(t,
+        `<img src="https://cdn.test/1.jpg"><a href="https://blog.example.com/other/">x</a>`,
+        rewriteAttachmentURLs(body, map[string]string{"https://blog.example.com/wp-content/uploads/a.jpg": "https://cdn.test/1.jpg"}))
+}
+
+func TestDirFetcher(t *testing.T) {
+    dir := t.TempDir()
+    require.NoError(t, os.MkdirAll(filepath.Join(dir, "2024", "03"), 0o755))
+    require.NoError(t, os.WriteFile(filepath.Join(dir, "2024", "03", "photo.jpg"), []byte("jpeg"), 0o644))
+    fetcher := NewDirFetcher(dir)
+
+    body, contentType, err := fetcher.Fetch(context.Background(), "https://blog.example.com/wp-content/uploads/2024/03/photo.jpg")
+    require.NoError(t, err)
+    data, _ := io.ReadAll(body)
+    body.Close()
+    assert.Equal(t, "jpeg", string(data))
+    assert.Equal(t, "image/jpeg", contentType)
+
+    _, _, err = fetcher.Fetch(context.Background(), "https://blog.example.com/wp-content/uploads/../../etc/passwd")
+    assert.Error(t, err)
+}
+
+func TestHTTPFetcherPrivateAddress(t *testing.T) {
+    _, _, err := NewHTTPFetcher().Fetch(context.Background(), "http://169.254.169.254/latest/meta-data/")
+    assert.ErrorIs(t, err, ErrPrivateAddress)
+}
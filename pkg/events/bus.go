@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Handler reacts to an event. A returned error makes the outbox publish the
+// event again later, to every subscriber.
+type Handler func(ctx context.Context, event Event) error
+
+type subscription struct {
+	name    string
+	pattern string
+	handler Handler
+}
+
+// Bus dispatches events to in-process subscribers.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler for the events matching pattern (see Match).
+// The name identifies the subscriber in errors.
+func (b *Bus) Subscribe(name, pattern string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{name: name, pattern: pattern, handler: handler})
+}
+
+// Publish calls every matching subscriber in registration order, even if some
+// fail, and returns their errors joined.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, s := range subscriptions {
+		if !Match(s.pattern, event.Type) {
+			continue
+		}
+		if err := s.handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+// Package events carries domain events from the services that produce them
+// to the subscribers that react to them.
+//
+// Services write events to an outbox table inside the transaction of the
+// change that causes them, so an event exists if and only if its change was
+// committed. The Outbox then publishes them to the in-process Bus and,
+// optionally, to a Redis stream for other systems. Delivery is at least once:
+// subscribers must tolerate seeing an event again.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"ezzygo/pkg/auth"
+)
+
+// Event is a change to an aggregate, e.g. content.published for a content
+// entry. It is also the row of the outbox table.
+type Event struct {
+	ID          string          `json:"id" gorm:"primaryKey;size:32"`
+	Type        string          `json:"type" gorm:"index;not null"`
+	AggregateID uint            `json:"aggregate_id" gorm:"index"`
+	Actor       string          `json:"actor,omitempty"`
+	Data        json.RawMessage `json:"data" gorm:"type:jsonb"`
+	OccurredAt  time.Time       `json:"occurred_at" gorm:"index"`
+
+	// Outbox bookkeeping.
+	PublishedAt   *time.Time `json:"published_at,omitempty" gorm:"index"`
+	Attempts      int        `json:"attempts,omitempty" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"-" gorm:"index"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// New builds an event with data encoded as JSON. The actor is the username
+// stored in ctx by the authentication middleware, if any.
+func New(ctx context.Context, eventType string, aggregateID uint, data interface{}) (*Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Event{
+		ID:            newID(),
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Actor:         auth.UsernameFromContext(ctx),
+		Data:          encoded,
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Aggregate returns the aggregate type of the event, the part of its type
+// before the first dot.
+func (e *Event) Aggregate() string {
+	aggregate, _, _ := strings.Cut(e.Type, ".")
+	return aggregate
+}
+
+// Match reports whether an event type matches a subscription pattern: the
+// type itself, "<aggregate>.*" or "*".
+func Match(pattern, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	return ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(eventType, prefix)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
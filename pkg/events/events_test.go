@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ezzygo/pkg/auth"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, eventType string
+		want               bool
+	}{
+		{"*", "content.published", true},
+		{"content.published", "content.published", true},
+		{"content.*", "content.published", true},
+		{"content.*", "template.updated", false},
+		{"content.published", "content.updated", false},
+		{"cont*", "content.published", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.eventType), "%s %s", tt.pattern, tt.eventType)
+	}
+}
+
+func TestNew(t *testing.T) {
+	ctx := auth.WithUsername(context.Background(), "editor")
+	event, err := New(ctx, "content.published", 7, map[string]int{"id": 7})
+	require.NoError(t, err)
+
+	assert.Len(t, event.ID, 32)
+	assert.Equal(t, "content", event.Aggregate())
+	assert.Equal(t, uint(7), event.AggregateID)
+	assert.Equal(t, "editor", event.Actor)
+	assert.JSONEq(t, `{"id":7}`, string(event.Data))
+	assert.Equal(t, event.OccurredAt, event.NextAttemptAt)
+
+	other, err := New(context.Background(), "content.published", 7, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, event.ID, other.ID)
+	assert.Empty(t, other.Actor)
+}
+
+func TestBusPublish(t *testing.T) {
+	bus := NewBus()
+	var calls []string
+	handler := func(name string, err error) Handler {
+		return func(ctx context.Context, event Event) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+	bus.Subscribe("cache", "*", handler("cache", nil))
+	bus.Subscribe("search", "content.*", handler("search", errors.New("index down")))
+	bus.Subscribe("media", "media.*", handler("media", nil))
+	bus.Subscribe("webhooks", "content.published", handler("webhooks", nil))
+
+	err := bus.Publish(context.Background(), Event{Type: "content.published"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "search: index down")
+	// A failing subscriber does not stop the rest
+	assert.Equal(t, []string{"cache", "search", "webhooks"}, calls)
+
+	calls = nil
+	assert.NoError(t, bus.Publish(context.Background(), Event{Type: "media.created"}))
+	assert.Equal(t, []string{"cache", "media"}, calls)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, backoff(1))
+	assert.Equal(t, 2*time.Second, backoff(2))
+	assert.Equal(t, 8*time.Second, backoff(4))
+	assert.Equal(t, outboxMaxBackoff, backoff(13))
+	assert.Equal(t, outboxMaxBackoff, backoff(100))
+}
+
+func TestNilOutbox(t *testing.T) {
+	var outbox *Outbox
+	assert.NoError(t, outbox.Record(nil, &Event{}))
+	outbox.Relay(context.Background(), &Event{})
+	outbox.Notify()
+
+	// Without a bus events are left to the dispatcher, so nothing is claimed
+	recorder := &Outbox{}
+	recorder.Relay(context.Background(), &Event{ID: "1"})
+
+	// Without a retention period nothing is pruned
+	deleted, err := recorder.Prune(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+}
@@ -0,0 +1,227 @@
+package events
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	outboxBatchSize  = 100
+	outboxLease      = time.Minute
+	outboxMaxBackoff = time.Hour
+	outboxPruneEvery = time.Hour
+)
+
+// Outbox records events in the transaction of the change that causes them
+// and publishes them once committed.
+type Outbox struct {
+	db        *gorm.DB
+	bus       *Bus
+	stream    Stream
+	logger    *zap.Logger
+	interval  time.Duration
+	retention time.Duration
+	wake      chan struct{}
+}
+
+// NewOutbox publishes to bus and, if stream is not nil, to stream. Events
+// that could not be published are retried every EVENTS_DISPATCH_INTERVAL
+// (default 5s) with exponential backoff. Published events are deleted once
+// they are older than EVENTS_RETENTION (default 168h, 0 keeps them). With a
+// nil bus the outbox only records: command line tools use it to leave their
+// events to the server's dispatcher.
+func NewOutbox(db *gorm.DB, bus *Bus, stream Stream, logger *zap.Logger) *Outbox {
+	interval := 5 * time.Second
+	if value := os.Getenv("EVENTS_DISPATCH_INTERVAL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			interval = parsed
+		} else {
+			logger.Warn("Invalid EVENTS_DISPATCH_INTERVAL, using default", zap.String("value", value))
+		}
+	}
+	retention := 7 * 24 * time.Hour
+	if value := os.Getenv("EVENTS_RETENTION"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			retention = parsed
+		} else {
+			logger.Warn("Invalid EVENTS_RETENTION, using default", zap.String("value", value))
+		}
+	}
+	return &Outbox{
+		db:        db,
+		bus:       bus,
+		stream:    stream,
+		logger:    logger,
+		interval:  interval,
+		retention: retention,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Record writes events to the outbox with tx. A nil Outbox records nothing,
+// for services built without one.
+func (o *Outbox) Record(tx *gorm.DB, events ...*Event) error {
+	if o == nil || len(events) == 0 {
+		return nil
+	}
+	return tx.Create(events).Error
+}
+
+// Relay publishes events recorded by a transaction that has committed, so
+// that subscribers such as cache invalidation run before the write returns.
+// Events that fail, or that another replica already claimed, are left to the
+// dispatcher. Nil events are skipped.
+func (o *Outbox) Relay(ctx context.Context, events ...*Event) {
+	if o == nil || o.bus == nil {
+		return
+	}
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		claimed, err := o.claim(ctx, event.ID)
+		if err != nil {
+			o.logger.Error("Failed to claim event", zap.String("id", event.ID), zap.Error(err))
+			o.Notify()
+			continue
+		}
+		if claimed {
+			o.publish(ctx, event)
+		}
+	}
+}
+
+// Notify wakes the dispatcher without waiting for the next interval.
+func (o *Outbox) Notify() {
+	if o == nil {
+		return
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches pending events until ctx is done. Once an hour it also
+// prunes the events published before the retention period.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		if _, err := o.DispatchPending(ctx); err != nil {
+			o.logger.Error("Failed to dispatch outbox events", zap.Error(err))
+		}
+		if time.Since(pruned) >= outboxPruneEvery {
+			if _, err := o.Prune(ctx); err != nil {
+				o.logger.Error("Failed to prune outbox events", zap.Error(err))
+			} else {
+				pruned = time.Now()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// DispatchPending claims a batch of due events and publishes them in the
+// order they occurred. Claiming pushes next_attempt_at forward, so other
+// replicas skip them and, if this one stops halfway, they are retried once
+// the lease expires. It returns the number of events published or failed.
+func (o *Outbox) DispatchPending(ctx context.Context) (int, error) {
+	var events []Event
+	now := time.Now()
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Order("occurred_at").
+			Limit(outboxBatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		return tx.Model(&Event{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(outboxLease)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		o.publish(ctx, &events[i])
+	}
+	return len(events), nil
+}
+
+// Prune deletes the events published before the retention period and
+// returns how many were deleted. Unpublished events are always kept.
+func (o *Outbox) Prune(ctx context.Context) (int64, error) {
+	if o.retention <= 0 {
+		return 0, nil
+	}
+	result := o.db.WithContext(ctx).
+		Where("published_at IS NOT NULL AND published_at < ?", time.Now().Add(-o.retention)).
+		Delete(&Event{})
+	return result.RowsAffected, result.Error
+}
+
+// claim takes a single due event for this replica.
+func (o *Outbox) claim(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	result := o.db.WithContext(ctx).Model(&Event{}).
+		Where("id = ? AND published_at IS NULL AND next_attempt_at <= ?", id, now).
+		Update("next_attempt_at", now.Add(outboxLease))
+	return result.RowsAffected == 1, result.Error
+}
+
+// publish sends a claimed event to the bus and the stream and records the
+// outcome.
+func (o *Outbox) publish(ctx context.Context, event *Event) {
+	err := o.bus.Publish(ctx, *event)
+	if err == nil && o.stream != nil {
+		err = o.stream.Publish(ctx, *event)
+	}
+
+	updates := map[string]interface{}{"attempts": event.Attempts + 1}
+	if err == nil {
+		updates["published_at"] = time.Now()
+		updates["last_error"] = ""
+	} else {
+		o.logger.Error("Failed to publish event",
+			zap.String("id", event.ID), zap.String("type", event.Type), zap.Error(err))
+		updates["next_attempt_at"] = time.Now().Add(backoff(event.Attempts + 1))
+		updates["last_error"] = err.Error()
+	}
+
+	// The outcome is recorded even if ctx was canceled meanwhile
+	if err := o.db.Model(&Event{}).Where("id = ?", event.ID).Updates(updates).Error; err != nil {
+		o.logger.Error("Failed to record event outcome", zap.String("id", event.ID), zap.Error(err))
+	}
+}
+
+// backoff is the wait after the given failed attempt: a second, doubled on
+// each attempt, up to outboxMaxBackoff.
+func backoff(attempt int) time.Duration {
+	if attempt > 12 {
+		return outboxMaxBackoff
+	}
+	return min(time.Second<<(attempt-1), outboxMaxBackoff)
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stream publishes events outside the process.
+type Stream interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// RedisStream appends events to a Redis stream, trimmed to about maxLen
+// entries. Consumers read it with XREAD or consumer groups.
+type RedisStream struct {
+	client *redis.Client
+	name   string
+	maxLen int64
+}
+
+func NewRedisStream(client *redis.Client, name string, maxLen int64) *RedisStream {
+	return &RedisStream{client: client, name: name, maxLen: maxLen}
+}
+
+// NewRedisStreamFromEnv returns the stream named by EVENTS_REDIS_STREAM,
+// trimmed to EVENTS_REDIS_STREAM_MAXLEN entries (default 100000), or nil if
+// the variable is not set.
+func NewRedisStreamFromEnv(client *redis.Client) Stream {
+	name := os.Getenv("EVENTS_REDIS_STREAM")
+	if name == "" {
+		return nil
+	}
+	maxLen := int64(100000)
+	if value, err := strconv.ParseInt(os.Getenv("EVENTS_REDIS_STREAM_MAXLEN"), 10, 64); err == nil && value > 0 {
+		maxLen = value
+	}
+	return NewRedisStream(client, name, maxLen)
+}
+
+func (s *RedisStream) Publish(ctx context.Context, event Event) error {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.name,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":           event.ID,
+			"type":         event.Type,
+			"aggregate_id": event.AggregateID,
+			"actor":        event.Actor,
+			"occurred_at":  event.OccurredAt.UTC().Format(time.RFC3339Nano),
+			"data":         string(event.Data),
+		},
+	}).Err()
+}
@@ -1,6 +1,159 @@
+// pkg/frontend/api.go
 package frontend
 
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/middleware"
+    "ezzygo/pkg/query"
+)
+
+// FrontendAPI expone el contenido publicado para el frontend (Next.js).
 type FrontendAPI struct {
     contentService *cms.ContentService
-    cache         *cache.RedisClient
+    seoService     *cms.SEOService
+    locales        *cms.LocaleConfig
+}
+
+func NewFrontendAPI(contentService *cms.ContentService, seoService *cms.SEOService, locales *cms.LocaleConfig) *FrontendAPI {
+    return &FrontendAPI{
+        contentService: contentService,
+        seoService:     seoService,
+        locales:        locales,
+    }
+}
+
+func (api *FrontendAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/content/:slug", api.GetContent)
+    router.GET("/content/:slug/seo", api.GetSEO)
+}
+
+// RegisterSEORoutes registra sitemap.xml y robots.txt. Van en la raíz y sin
+// API key, porque los leen los buscadores; el frontend los sirve con un
+// rewrite a esta API.
+func (api *FrontendAPI) RegisterSEORoutes(router gin.IRoutes) {
+    router.GET("/sitemap.xml", api.Sitemap)
+    router.GET("/sitemaps/:file", api.Sitemap)
+    router.GET("/robots.txt", api.Robots)
+}
+
+// GetContent devuelve el contenido publicado para un slug, usando el idioma de
+// ?locale= o de Accept-Language y siguiendo la cadena de fallback configurada.
+// ?fields=title,slug limita la respuesta (y las columnas leídas) a esos campos.
+func (api *FrontendAPI) GetContent(c *gin.Context) {
+    locale := c.Query("locale")
+    if locale == "" {
+        locale = api.locales.Negotiate(c.GetHeader("Accept-Language"))
+    }
+    fields := query.ParseFieldset(c.Query("fields"))
+
+    content, err := api.contentService.Resolve(c.Request.Context(), c.Param("slug"), locale, fields)
+    if err != nil {
+        writeResolveError(c, err)
+        return
+    }
+
+    // La lectura alimenta la popularidad del autocompletado
+    api.contentService.RecordView(content.ID)
+
+    // Se purga de la CDN cuando cambian el contenido, su plantilla o su
+    // grupo de traducciones (puede ser el fallback de otro idioma)
+    cacheContent(c, content)
+
+    body, err := fields.Project(content)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.Header("Content-Language", content.Locale)
+    c.Header("Vary", "Accept-Language")
+    c.JSON(http.StatusOK, body)
+}
+
+// GetSEO devuelve los datos de cabecera de la página de un slug (título,
+// descripción, canonical, og:image, robots, hreflang y JSON-LD), con la misma
+// negociación de idioma que GetContent.
+func (api *FrontendAPI) GetSEO(c *gin.Context) {
+    locale := c.Query("locale")
+    if locale == "" {
+        locale = api.locales.Negotiate(c.GetHeader("Accept-Language"))
+    }
+
+    content, err := api.contentService.Resolve(c.Request.Context(), c.Param("slug"), locale, nil)
+    if err != nil {
+        writeResolveError(c, err)
+        return
+    }
+
+    seo, err := api.seoService.ForContent(c.Request.Context(), content)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    cacheContent(c, content)
+    if content.OGImageID != nil {
+        middleware.SurrogateKeys(c, cms.MediaTag(*content.OGImageID))
+    }
+    c.Header("Content-Language", content.Locale)
+    c.Header("Vary", "Accept-Language")
+    c.JSON(http.StatusOK, seo)
+}
+
+// cacheContent etiqueta la respuesta de un contenido para purgarla de la CDN
+// y la limita a su caducidad, que no genera ningún evento.
+func cacheContent(c *gin.Context, content *cms.Content) {
+    middleware.SurrogateKeys(c, cms.ContentTag(content.ID), cms.TemplateTag(content.TemplateID))
+    if content.TranslationGroupID != 0 {
+        middleware.SurrogateKeys(c, cms.TranslationTag(content.TranslationGroupID))
+    }
+    if content.ExpiresAt != nil {
+        middleware.CacheUntil(c, *content.ExpiresAt)
+    }
+}
+
+// Sitemap sirve /sitemap.xml y, si el sitemap se divide, /sitemaps/<n>.xml.
+func (api *FrontendAPI) Sitemap(c *gin.Context) {
+    page := 0
+    if file := c.Param("file"); file != "" {
+        n, err := strconv.Atoi(strings.TrimSuffix(file, ".xml"))
+        if err != nil || n < 1 || !strings.HasSuffix(file, ".xml") {
+            c.String(http.StatusNotFound, "not found")
+            return
+        }
+        page = n
+    }
+
+    body, err := api.seoService.Sitemap(c.Request.Context(), page)
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.String(http.StatusNotFound, "not found")
+            return
+        }
+        c.String(http.StatusInternalServerError, err.Error())
+        return
+    }
+
+    c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+func (api *FrontendAPI) Robots(c *gin.Context) {
+    c.String(http.StatusOK, api.seoService.Robots())
+}
+
+func writeResolveError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, query.ErrInvalidQuery):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
 }
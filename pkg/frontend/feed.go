@@ -0,0 +1,85 @@
+// pkg/frontend/feed.go
+package frontend
+
+import (
+    "errors"
+    "net/http"
+    "path"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/middleware"
+)
+
+var feedContentTypes = map[string]string{
+    "rss":  "application/rss+xml; charset=utf-8",
+    "atom": "application/atom+xml; charset=utf-8",
+    "json": "application/feed+json; charset=utf-8",
+}
+
+// FeedAPI sirve los feeds RSS, Atom y JSON Feed del contenido publicado.
+type FeedAPI struct {
+    service *cms.FeedService
+}
+
+func NewFeedAPI(service *cms.FeedService) *FeedAPI {
+    return &FeedAPI{service: service}
+}
+
+// RegisterRoutes registra /feeds/<tipo>.<rss|atom|json>. Como el sitemap, va
+// en la raíz y sin API key, porque lo leen los lectores de feeds.
+func (api *FeedAPI) RegisterRoutes(router gin.IRoutes) {
+    router.GET("/feeds/:file", api.GetFeed)
+}
+
+// GetFeed sirve el feed de un tipo de plantilla ("all" para todos), filtrado
+// opcionalmente por ?tag= y ?locale=. No envía Last-Modified: la fecha de
+// las entradas no cambia cuando una sale del feed, así que el GET condicional
+// va solo por ETag.
+func (api *FeedAPI) GetFeed(c *gin.Context) {
+    file := c.Param("file")
+    format := strings.TrimPrefix(path.Ext(file), ".")
+    contentType, ok := feedContentTypes[format]
+    kind := strings.TrimSuffix(file, path.Ext(file))
+    if !ok || kind == "" {
+        c.String(http.StatusNotFound, "not found")
+        return
+    }
+
+    feed, err := api.service.Feed(c.Request.Context(), cms.FeedQuery{
+        Type:   kind,
+        Tag:    c.Query("tag"),
+        Locale: c.Query("locale"),
+    })
+    if err != nil {
+        if errors.Is(err, cms.ErrUnsupportedLocale) {
+            c.String(http.StatusBadRequest, err.Error())
+            return
+        }
+        c.String(http.StatusInternalServerError, err.Error())
+        return
+    }
+
+    self := api.service.URL(c.Request.URL.RequestURI())
+    var body []byte
+    switch format {
+    case "rss":
+        body, err = feed.RSS(self)
+    case "atom":
+        body, err = feed.Atom(self)
+    default:
+        body, err = feed.JSONFeed(self)
+    }
+    if err != nil {
+        c.String(http.StatusInternalServerError, err.Error())
+        return
+    }
+
+    // Se purga de la CDN con cualquier cambio de contenido, pero los
+    // embargos y caducidades no generan ninguno
+    middleware.SurrogateKeys(c, cms.FeedsTag)
+    if !feed.Until.IsZero() {
+        middleware.CacheUntil(c, feed.Until)
+    }
+    c.Data(http.StatusOK, contentType, body)
+}
@@ -0,0 +1,47 @@
+// pkg/frontend/redirect.go
+package frontend
+
+import (
+    "errors"
+    "net/http"
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "ezzygo/pkg/cms"
+    "ezzygo/pkg/middleware"
+)
+
+// RedirectAPI resuelve las redirecciones de rutas antiguas. El frontend la
+// consulta cuando una ruta no existe.
+type RedirectAPI struct {
+    service *cms.RedirectService
+}
+
+func NewRedirectAPI(service *cms.RedirectService) *RedirectAPI {
+    return &RedirectAPI{service: service}
+}
+
+func (api *RedirectAPI) RegisterRoutes(router *gin.RouterGroup) {
+    router.GET("/redirects", api.Resolve)
+}
+
+// Resolve devuelve la redirección de ?path=, o 404 si no hay.
+func (api *RedirectAPI) Resolve(c *gin.Context) {
+    path := c.Query("path")
+    if path == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "path is required"})
+        return
+    }
+
+    redirect, err := api.service.Resolve(c.Request.Context(), path)
+    if err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": "redirect not found"})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    middleware.SurrogateKeys(c, cms.RedirectsTag)
+    c.JSON(http.StatusOK, redirect)
+}
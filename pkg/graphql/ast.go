@@ -0,0 +1,109 @@
+package graphql
+
+// Document is a parsed GraphQL request document.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	// Type is query, mutation or subscription.
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default interface{}
+}
+
+// TypeRef is a type as written in a variable definition, e.g. [ID!]!.
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	selection()
+}
+
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+// Key is the name of the field in the response.
+func (f *Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Location      Location
+}
+
+func (*Field) selection()          {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+type Argument struct {
+	Name  string
+	Value interface{}
+}
+
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+}
+
+// Values in the document are nil, bool, int64, float64, string, Variable,
+// Enum, []interface{} or map[string]interface{}.
+type (
+	Variable string
+	Enum     string
+)
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
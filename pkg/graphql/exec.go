@@ -0,0 +1,455 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Error is a GraphQL error as returned in the errors of a response.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Execute runs an operation of a validated document. Resolver errors are
+// reported in the response with the path of the field, which resolves to
+// null.
+func Execute(ctx context.Context, schema *Schema, doc *Document, operation *Operation, variables map[string]interface{}) *Response {
+	e := &executor{ctx: ctx, schema: schema, doc: doc}
+	var err *Error
+	if e.variables, err = coerceVariables(operation, variables); err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	results := e.selectionSet(schema.Query, []interface{}{nil}, [][]interface{}{nil}, operation.Selections)
+	return &Response{Data: results[0], Errors: e.errors}
+}
+
+type executor struct {
+	ctx       context.Context
+	schema    *Schema
+	doc       *Document
+	variables map[string]interface{}
+	errors    []*Error
+}
+
+// fieldGroup is the fields of a selection set with the same response key,
+// which are merged.
+type fieldGroup struct {
+	key    string
+	name   string
+	fields []*Field
+}
+
+// collect groups the fields of a selection set, expanding fragments and
+// applying @skip and @include.
+func (e *executor) collect(selections []Selection) []*fieldGroup {
+	var groups []*fieldGroup
+	byKey := map[string]*fieldGroup{}
+	visited := map[string]bool{}
+
+	var walk func(selections []Selection)
+	walk = func(selections []Selection) {
+		for _, selection := range selections {
+			switch s := selection.(type) {
+			case *Field:
+				if !e.included(s.Directives) {
+					continue
+				}
+				group, ok := byKey[s.Key()]
+				if !ok {
+					group = &fieldGroup{key: s.Key(), name: s.Name}
+					byKey[s.Key()] = group
+					groups = append(groups, group)
+				}
+				group.fields = append(group.fields, s)
+			case *InlineFragment:
+				if e.included(s.Directives) {
+					walk(s.Selections)
+				}
+			case *FragmentSpread:
+				if visited[s.Name] || !e.included(s.Directives) {
+					continue
+				}
+				visited[s.Name] = true
+				if fragment := e.doc.Fragments[s.Name]; fragment != nil {
+					walk(fragment.Selections)
+				}
+			}
+		}
+	}
+	walk(selections)
+	return groups
+}
+
+func (e *executor) included(directives []*Directive) bool {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+		value := true
+		for _, arg := range directive.Arguments {
+			if arg.Name == "if" {
+				resolved, _ := resolveValue(arg.Value, e.variables)
+				value, _ = resolved.(bool)
+			}
+		}
+		if value == (directive.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// selectionSet resolves the selection for a batch of objects of the same
+// type. Each field is resolved for every object before any thunk is called,
+// so the loads of sibling objects are batched.
+func (e *executor) selectionSet(object *Object, sources []interface{}, paths [][]interface{}, selections []Selection) []interface{} {
+	results := make([]*orderedMap, len(sources))
+	for i := range results {
+		results[i] = &orderedMap{}
+	}
+
+	type resolved struct {
+		group  *fieldGroup
+		field  *FieldDef
+		values []interface{}
+		failed []bool
+	}
+	var fields []*resolved
+	for _, group := range e.collect(selections) {
+		if group.name == "__typename" {
+			for _, result := range results {
+				result.set(group.key, object.Name)
+			}
+			continue
+		}
+		definition := object.Field(group.name)
+		if definition == nil {
+			continue
+		}
+		args, err := coerceArguments(definition, group.fields[0], e.variables)
+		r := &resolved{group: group, field: definition, values: make([]interface{}, len(sources)), failed: make([]bool, len(sources))}
+		fields = append(fields, r)
+		for i, source := range sources {
+			if err != nil {
+				r.failed[i] = e.fail(err, group.fields[0], appendPath(paths[i], group.key))
+				continue
+			}
+			value, err := definition.resolve(ResolveParams{Context: e.ctx, Source: source, Args: args, field: group.fields[0], exec: e})
+			if err != nil {
+				r.failed[i] = e.fail(err, group.fields[0], appendPath(paths[i], group.key))
+				continue
+			}
+			r.values[i] = value
+		}
+	}
+
+	for _, r := range fields {
+		for i, value := range r.values {
+			if thunk, ok := value.(Thunk); ok {
+				value, err := thunk()
+				if err != nil {
+					r.values[i], r.failed[i] = nil, e.fail(err, r.group.fields[0], appendPath(paths[i], r.group.key))
+					continue
+				}
+				r.values[i] = value
+			}
+		}
+	}
+
+	for _, r := range fields {
+		fieldPaths := make([][]interface{}, len(sources))
+		for i := range sources {
+			fieldPaths[i] = appendPath(paths[i], r.group.key)
+		}
+		completed := e.complete(r.field.Type, r.group.fields, r.values, r.failed, fieldPaths)
+		for i, result := range results {
+			result.set(r.group.key, completed[i])
+		}
+	}
+
+	out := make([]interface{}, len(results))
+	for i, result := range results {
+		out[i] = result
+	}
+	return out
+}
+
+// complete converts resolved values to their response values, resolving
+// the selections of objects in one batch per level.
+func (e *executor) complete(t Type, fields []*Field, values []interface{}, failed []bool, paths [][]interface{}) []interface{} {
+	out := make([]interface{}, len(values))
+	switch t := t.(type) {
+	case *NonNull:
+		out = e.complete(t.Of, fields, values, failed, paths)
+		for i := range out {
+			if out[i] == nil && !failed[i] {
+				e.fail(fmt.Errorf("cannot return null for non-nullable field"), fields[0], paths[i])
+			}
+		}
+	case *List:
+		var items []interface{}
+		var itemPaths [][]interface{}
+		bounds := make([][2]int, len(values))
+		for i, value := range values {
+			list := reflect.ValueOf(value)
+			if isNil(value) {
+				bounds[i] = [2]int{-1, -1}
+				continue
+			}
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				e.fail(fmt.Errorf("expected a list, got %T", value), fields[0], paths[i])
+				bounds[i] = [2]int{-1, -1}
+				continue
+			}
+			bounds[i][0] = len(items)
+			for j := 0; j < list.Len(); j++ {
+				items = append(items, list.Index(j).Interface())
+				itemPaths = append(itemPaths, appendPath(paths[i], j))
+			}
+			bounds[i][1] = len(items)
+		}
+		completed := e.complete(t.Of, fields, items, make([]bool, len(items)), itemPaths)
+		for i, bound := range bounds {
+			if bound[0] >= 0 {
+				out[i] = completed[bound[0]:bound[1]]
+			}
+		}
+	case *Scalar:
+		for i, value := range values {
+			if isNil(value) {
+				continue
+			}
+			serialized, err := t.Serialize(value)
+			if err != nil {
+				e.fail(err, fields[0], paths[i])
+				continue
+			}
+			out[i] = serialized
+		}
+	case *Object:
+		var sources []interface{}
+		var sourcePaths [][]interface{}
+		var positions []int
+		for i, value := range values {
+			if !isNil(value) {
+				sources = append(sources, value)
+				sourcePaths = append(sourcePaths, paths[i])
+				positions = append(positions, i)
+			}
+		}
+		if len(sources) > 0 {
+			results := e.selectionSet(t, sources, sourcePaths, mergeSelections(fields))
+			for j, position := range positions {
+				out[position] = results[j]
+			}
+		}
+	}
+	return out
+}
+
+func (e *executor) fail(err error, field *Field, path []interface{}) bool {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	} else {
+		copied := *gqlErr
+		gqlErr = &copied
+	}
+	gqlErr.Locations = []Location{field.Location}
+	gqlErr.Path = path
+	e.errors = append(e.errors, gqlErr)
+	return true
+}
+
+func mergeSelections(fields []*Field) []Selection {
+	var selections []Selection
+	for _, field := range fields {
+		selections = append(selections, field.Selections...)
+	}
+	return selections
+}
+
+func appendPath(path []interface{}, element interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(path)+1), path...), element)
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func coerceVariables(operation *Operation, values map[string]interface{}) (map[string]interface{}, *Error) {
+	variables := map[string]interface{}{}
+	for _, definition := range operation.Variables {
+		value, ok := values[definition.Name]
+		if !ok {
+			value = definition.Default
+		}
+		if value == nil {
+			if definition.Type.NonNull {
+				return nil, &Error{Message: fmt.Sprintf("variable $%s of type %s is required", definition.Name, definition.Type)}
+			}
+			variables[definition.Name] = nil
+			continue
+		}
+		variables[definition.Name] = value
+	}
+	return variables, nil
+}
+
+// coerceArguments resolves the variables of the arguments and parses them
+// with the argument types, applying defaults.
+func coerceArguments(definition *FieldDef, field *Field, variables map[string]interface{}) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, argDef := range definition.Args {
+		var value interface{}
+		present := false
+		for _, arg := range field.Arguments {
+			if arg.Name == argDef.Name {
+				var defined bool
+				value, defined = resolveValue(arg.Value, variables)
+				present = defined
+			}
+		}
+		if !present || value == nil {
+			value = argDef.Default
+		}
+		if value == nil {
+			if _, ok := argDef.Type.(*NonNull); ok {
+				return nil, fmt.Errorf("argument %s of type %s is required", argDef.Name, argDef.Type)
+			}
+			continue
+		}
+		parsed, err := parseInput(argDef.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %v", argDef.Name, err)
+		}
+		args[argDef.Name] = parsed
+	}
+	return args, nil
+}
+
+// resolveValue replaces the variables of a literal value. It reports false
+// for variables that were not provided.
+func resolveValue(value interface{}, variables map[string]interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case Variable:
+		resolved, ok := variables[string(v)]
+		return resolved, ok
+	case Enum:
+		return string(v), true
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i], _ = resolveValue(item, variables)
+		}
+		return list, true
+	case map[string]interface{}:
+		object := map[string]interface{}{}
+		for key, item := range v {
+			if resolved, ok := resolveValue(item, variables); ok {
+				object[key] = resolved
+			}
+		}
+		return object, true
+	}
+	return value, true
+}
+
+func parseInput(t Type, value interface{}) (interface{}, error) {
+	switch t := t.(type) {
+	case *NonNull:
+		if value == nil {
+			return nil, fmt.Errorf("expected a non-null %s", t.Of)
+		}
+		return parseInput(t.Of, value)
+	case *List:
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		parsed := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if parsed[i], err = parseInput(t.Of, item); err != nil {
+				return nil, err
+			}
+		}
+		return parsed, nil
+	case *Scalar:
+		if value == nil {
+			return nil, nil
+		}
+		// Variables come from JSON, where every number is a float64
+		if number, ok := value.(json.Number); ok {
+			if i, err := number.Int64(); err == nil {
+				value = i
+			} else if f, err := number.Float64(); err == nil {
+				value = f
+			}
+		}
+		return t.Parse(value)
+	}
+	return nil, fmt.Errorf("%s cannot be used as an input", t)
+}
+
+// orderedMap is a response object, which keeps the order of the selection.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ezzygo/pkg/cache"
+)
+
+type testAuthor struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type testBook struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	AuthorID uint   `json:"author_id"`
+	Secret   func() `json:"-"`
+}
+
+type loaderKey struct{}
+
+// testSchema serves three books whose authors are loaded through a loader
+// stored in the context, counting the fetches.
+func testSchema(fetches *int) *Schema {
+	author := FromStruct("Author", "", testAuthor{})
+	book := FromStruct("Book", "", testBook{})
+	book.AddField(&FieldDef{
+		Name: "author",
+		Type: author,
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			loader := p.Context.Value(loaderKey{}).(*Loader[uint, *testAuthor])
+			return loader.Load(p.Context, p.Source.(testBook).AuthorID), nil
+		},
+	})
+
+	query := NewObject("Query", "")
+	query.AddField(&FieldDef{
+		Name:       "books",
+		Type:       &NonNull{Of: &List{Of: &NonNull{Of: book}}},
+		Args:       []*ArgDef{{Name: "limit", Type: Int, Default: 10}},
+		Multiplier: func(args map[string]interface{}) int { return args["limit"].(int) },
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			books := []testBook{{ID: 1, Title: "Dune", AuthorID: 1}, {ID: 2, Title: "Emma", AuthorID: 2}, {ID: 3, Title: "Messiah", AuthorID: 1}}
+			return books[:min(p.Args["limit"].(int), len(books))], nil
+		},
+	})
+	query.AddField(&FieldDef{
+		Name: "book",
+		Type: book,
+		Args: []*ArgDef{{Name: "id", Type: &NonNull{Of: ID}}},
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			if p.Args["id"].(uint) == 2 {
+				return testBook{ID: 2, Title: "Emma", AuthorID: 2}, nil
+			}
+			return nil, fmt.Errorf("book not found")
+		},
+	})
+
+	*fetches = 0
+	return &Schema{Query: query}
+}
+
+func testContext(fetches *int) context.Context {
+	loader := NewLoader(func(ctx context.Context, ids []uint) (map[uint]*testAuthor, error) {
+		*fetches++
+		authors := map[uint]*testAuthor{}
+		for _, id := range ids {
+			authors[id] = &testAuthor{ID: id, Name: fmt.Sprintf("author %d", id)}
+		}
+		return authors, nil
+	})
+	return context.WithValue(context.Background(), loaderKey{}, loader)
+}
+
+func do(t *testing.T, server *Server, ctx context.Context, req *Request) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(server.Do(ctx, req))
+	require.NoError(t, err)
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# comment
+		query Books($limit: Int = 2, $ids: [ID!]!) {
+			first: books(limit: $limit) { ...BookFields @include(if: true) }
+		}
+		fragment BookFields on Book { id title author { name } }`)
+	require.NoError(t, err)
+	require.Len(t, doc.Operations, 1)
+
+	op := doc.Operations[0]
+	assert.Equal(t, "query", op.Type)
+	assert.Equal(t, "Books", op.Name)
+	assert.Equal(t, int64(2), op.Variables[0].Default)
+	assert.Equal(t, "[ID!]!", op.Variables[1].Type.String())
+
+	field := op.Selections[0].(*Field)
+	assert.Equal(t, "first", field.Key())
+	assert.Equal(t, Variable("limit"), field.Arguments[0].Value)
+	assert.Equal(t, Location{Line: 4, Column: 4}, field.Location)
+	assert.Equal(t, "Book", doc.Fragments["BookFields"].TypeCondition)
+
+	_, err = Parse(`{ books(limit: ) }`)
+	assert.Error(t, err)
+	_, err = Parse(`{ books { title }`)
+	assert.Error(t, err)
+}
+
+func TestExecute(t *testing.T) {
+	var fetches int
+	server := &Server{Schema: testSchema(&fetches)}
+
+	out := do(t, server, testContext(&fetches), &Request{
+		Query:     `query($n: Int) { list: books(limit: $n) { id ...F } one: book(id: 2) { title } }  fragment F on Book { title author { name } }`,
+		Variables: map[string]interface{}{"n": json.Number("2")},
+	})
+	assert.Nil(t, out["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"list": []interface{}{
+			map[string]interface{}{"id": float64(1), "title": "Dune", "author": map[string]interface{}{"name": "author 1"}},
+			map[string]interface{}{"id": float64(2), "title": "Emma", "author": map[string]interface{}{"name": "author 2"}},
+		},
+		"one": map[string]interface{}{"title": "Emma"},
+	}, out["data"])
+	// Both authors were loaded in one fetch
+	assert.Equal(t, 1, fetches)
+}
+
+func TestExecuteFieldError(t *testing.T) {
+	var fetches int
+	server := &Server{Schema: testSchema(&fetches)}
+
+	out := do(t, server, testContext(&fetches), &Request{Query: `{ book(id: 9) { title } books(limit: 1) { title } }`})
+	data := out["data"].(map[string]interface{})
+	assert.Nil(t, data["book"])
+	assert.Len(t, data["books"], 1)
+
+	errs := out["errors"].([]interface{})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "book not found", errs[0].(map[string]interface{})["message"])
+	assert.Equal(t, []interface{}{"book"}, errs[0].(map[string]interface{})["path"])
+}
+
+func TestValidate(t *testing.T) {
+	var fetches int
+	schema := testSchema(&fetches)
+	server := &Server{Schema: schema, Limits: Limits{MaxDepth: 2, MaxComplexity: 20}}
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"unknown field", `{ books { isbn } }`, ""},
+		{"unknown argument", `{ books(page: 2) { id } }`, ""},
+		{"missing subselection", `{ books }`, ""},
+		{"scalar subselection", `{ books { title { x } } }`, ""},
+		{"fragment cycle", `{ books { ...A } } fragment A on Book { author { ...B } } fragment B on Author { ...A }`, ""},
+		{"wrong fragment type", `{ books { ...A } } fragment A on Author { name }`, ""},
+		{"mutation", `mutation { books { id } }`, ""},
+		{"too deep", `{ books { author { name } } }`, "QUERY_TOO_DEEP"},
+		// 1 + 10 * (1 + 1)
+		{"too complex", `{ books(limit: 10) { id title } }`, "QUERY_TOO_COMPLEX"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := do(t, server, testContext(&fetches), &Request{Query: tt.query})
+			assert.Nil(t, out["data"])
+			errs, _ := out["errors"].([]interface{})
+			require.NotEmpty(t, errs)
+			if tt.code != "" {
+				assert.Equal(t, tt.code, errs[0].(map[string]interface{})["extensions"].(map[string]interface{})["code"])
+			}
+		})
+	}
+
+	doc, err := Parse(`{ books(limit: 3) { id title } }`)
+	require.NoError(t, err)
+	complexity, errs := Validate(schema, doc, doc.Operations[0], nil, Limits{})
+	assert.Empty(t, errs)
+	assert.Equal(t, 7, complexity)
+}
+
+func TestPersistedQueries(t *testing.T) {
+	var fetches int
+	server := &Server{Schema: testSchema(&fetches), Persisted: NewCachePersistedQueries(cache.NewStore(cache.NewMemoryBackend()))}
+	ctx := testContext(&fetches)
+
+	query := `{ books(limit: 1) { title } }`
+	sum := sha256.Sum256([]byte(query))
+	extensions := RequestExtensions{PersistedQuery: &PersistedQuery{Version: 1, SHA256Hash: hex.EncodeToString(sum[:])}}
+
+	out := do(t, server, ctx, &Request{Extensions: extensions})
+	assert.Equal(t, "PersistedQueryNotFound", out["errors"].([]interface{})[0].(map[string]interface{})["message"])
+
+	out = do(t, server, ctx, &Request{Query: `{ books { id } }`, Extensions: extensions})
+	assert.NotEmpty(t, out["errors"])
+
+	// A query that fails validation is not registered
+	invalid := `{ books { missing } }`
+	invalidSum := sha256.Sum256([]byte(invalid))
+	invalidExtensions := RequestExtensions{PersistedQuery: &PersistedQuery{Version: 1, SHA256Hash: hex.EncodeToString(invalidSum[:])}}
+	out = do(t, server, ctx, &Request{Query: invalid, Extensions: invalidExtensions})
+	assert.NotEmpty(t, out["errors"])
+	out = do(t, server, ctx, &Request{Extensions: invalidExtensions})
+	assert.Equal(t, "PersistedQueryNotFound", out["errors"].([]interface{})[0].(map[string]interface{})["message"])
+
+	out = do(t, server, ctx, &Request{Query: query, Extensions: extensions})
+	assert.Nil(t, out["errors"])
+
+	out = do(t, server, ctx, &Request{Extensions: extensions})
+	assert.Nil(t, out["errors"])
+	assert.Equal(t, map[string]interface{}{"books": []interface{}{map[string]interface{}{"title": "Dune"}}}, out["data"])
+}
+
+func TestSDL(t *testing.T) {
+	var fetches int
+	sdl := testSchema(&fetches).SDL()
+	assert.Contains(t, sdl, "type Book {")
+	assert.Contains(t, sdl, "author_id: Int!")
+	assert.Contains(t, sdl, "books(limit: Int = 10): [Book!]!")
+	assert.NotContains(t, sdl, "Secret")
+}
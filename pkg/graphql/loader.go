@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches the loads of a request. Load only registers the key; the
+// first thunk to be called fetches every pending key at once and the results
+// are cached for the rest of the request, so a Loader must not outlive it.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errors  map[K]error
+}
+
+// NewLoader returns a Loader that fetches with fetch. Keys missing from the
+// map returned by fetch resolve to the zero value of V.
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, queued: map[K]bool{}, results: map[K]V{}, errors: map[K]error{}}
+}
+
+// Load returns a thunk that resolves to the value of key.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		if _, failed := l.errors[key]; !failed && !l.queued[key] {
+			l.pending = append(l.pending, key)
+			l.queued[key] = true
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.queued[key] {
+			l.dispatch(ctx)
+		}
+		if err := l.errors[key]; err != nil {
+			return nil, err
+		}
+		return l.results[key], nil
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending, l.queued = nil, map[K]bool{}
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errors[key] = err
+			continue
+		}
+		l.results[key] = values[key]
+	}
+}
@@ -0,0 +1,651 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind     tokenKind
+	value    string
+	location Location
+}
+
+type lexer struct {
+	source string
+	pos    int
+	line   int
+	column int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	start := Location{Line: l.line, Column: l.column}
+	if l.pos >= len(l.source) {
+		return token{kind: tokenEOF, location: start}, nil
+	}
+
+	c := l.source[l.pos]
+	switch {
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(c), location: start}, nil
+	case c == '.':
+		if !strings.HasPrefix(l.source[l.pos:], "...") {
+			return token{}, syntaxError(start, "unexpected %q", ".")
+		}
+		l.advance(3)
+		return token{kind: tokenPunct, value: "...", location: start}, nil
+	case c == '_' || isLetter(c):
+		end := l.pos
+		for end < len(l.source) && (l.source[end] == '_' || isLetter(l.source[end]) || isDigit(l.source[end])) {
+			end++
+		}
+		value := l.source[l.pos:end]
+		l.advance(end - l.pos)
+		return token{kind: tokenName, value: value, location: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number(start)
+	case c == '"':
+		return l.string(start)
+	}
+	return token{}, syntaxError(start, "unexpected character %q", c)
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; {
+		case c == '\n':
+			l.pos++
+			l.line++
+			l.column = 1
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.source[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+// advance moves n bytes forward on the current line.
+func (l *lexer) advance(n int) {
+	l.column += utf8.RuneCountInString(l.source[l.pos : l.pos+n])
+	l.pos += n
+}
+
+func (l *lexer) number(start Location) (token, error) {
+	end := l.pos
+	if l.source[end] == '-' {
+		end++
+	}
+	digits := func() {
+		for end < len(l.source) && isDigit(l.source[end]) {
+			end++
+		}
+	}
+	digits()
+	kind := tokenInt
+	if end < len(l.source) && l.source[end] == '.' {
+		kind = tokenFloat
+		end++
+		digits()
+	}
+	if end < len(l.source) && (l.source[end] == 'e' || l.source[end] == 'E') {
+		kind = tokenFloat
+		end++
+		if end < len(l.source) && (l.source[end] == '+' || l.source[end] == '-') {
+			end++
+		}
+		digits()
+	}
+	value := l.source[l.pos:end]
+	if value == "-" {
+		return token{}, syntaxError(start, "invalid number")
+	}
+	l.advance(end - l.pos)
+	return token{kind: kind, value: value, location: start}, nil
+}
+
+func (l *lexer) string(start Location) (token, error) {
+	if strings.HasPrefix(l.source[l.pos:], `"""`) {
+		end := strings.Index(l.source[l.pos+3:], `"""`)
+		if end < 0 {
+			return token{}, syntaxError(start, "unterminated string")
+		}
+		raw := l.source[l.pos+3 : l.pos+3+end]
+		for _, c := range l.source[l.pos : l.pos+end+6] {
+			if c == '\n' {
+				l.line++
+				l.column = 0
+			}
+			l.column++
+		}
+		l.pos += end + 6
+		return token{kind: tokenString, value: blockString(raw), location: start}, nil
+	}
+
+	var b strings.Builder
+	i := l.pos + 1
+	for i < len(l.source) {
+		c := l.source[i]
+		switch {
+		case c == '"':
+			l.advance(i + 1 - l.pos)
+			return token{kind: tokenString, value: b.String(), location: start}, nil
+		case c == '\n':
+			return token{}, syntaxError(start, "unterminated string")
+		case c == '\\' && i+1 < len(l.source):
+			escape := l.source[i+1]
+			switch escape {
+			case 'u':
+				if i+6 > len(l.source) {
+					return token{}, syntaxError(start, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.source[i+2:i+6], 16, 32)
+				if err != nil {
+					return token{}, syntaxError(start, "invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				i += 6
+				continue
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			default:
+				return token{}, syntaxError(start, "invalid escape \\%c", escape)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, syntaxError(start, "unterminated string")
+}
+
+// blockString removes the common indentation and the blank first and last
+// lines of a """block string""".
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	lexer *lexer
+	token token
+}
+
+// Parse parses an executable document: operations and fragments. Type system
+// definitions are not supported.
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: &lexer{source: source, line: 1, column: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.token.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunct, "{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", Selections: selections})
+		case p.peek(tokenName, "fragment"):
+			fragment, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, syntaxError(fragment.Location, "there can be only one fragment named %q", fragment.Name)
+			}
+			doc.Fragments[fragment.Name] = fragment
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			operation, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, operation)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, syntaxError(Location{Line: 1, Column: 1}, "the document has no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	token, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = token
+	return nil
+}
+
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.token.kind == kind && p.token.value == value
+}
+
+// skip consumes the punctuator if it is next.
+func (p *parser) skip(value string) (bool, error) {
+	if !p.peek(tokenPunct, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(value string) error {
+	if !p.peek(tokenPunct, value) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.token.value
+	return name, p.advance()
+}
+
+func (p *parser) unexpected() error {
+	if p.token.kind == tokenEOF {
+		return syntaxError(p.token.location, "unexpected end of document")
+	}
+	return syntaxError(p.token.location, "unexpected %q", p.token.value)
+}
+
+func (p *parser) operation() (*Operation, error) {
+	operation := &Operation{Type: p.token.value, Location: p.token.location}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenName {
+		operation.Name = p.token.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(tokenPunct, ")") {
+			definition, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.Variables = append(operation.Variables, definition)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if operation.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if operation.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (p *parser) variableDefinition() (*VariableDefinition, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typeRef, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+
+	definition := &VariableDefinition{Name: name, Type: typeRef}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if definition.Default, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	return definition, nil
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	var typeRef *TypeRef
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		typeRef = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		typeRef = &TypeRef{Name: name}
+	}
+
+	nonNull, err := p.skip("!")
+	typeRef.NonNull = nonNull
+	return typeRef, err
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	fragment := &Fragment{Location: p.token.location}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var err error
+	if fragment.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Name == "on" {
+		return nil, syntaxError(fragment.Location, "a fragment cannot be named \"on\"")
+	}
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if fragment.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if fragment.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peek(tokenPunct, "}") {
+		selection, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, syntaxError(p.token.location, "a selection set cannot be empty")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	if !p.peek(tokenPunct, "...") {
+		return p.field()
+	}
+
+	location := p.token.location
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenName && p.token.value != "on" {
+		spread := &FragmentSpread{Name: p.token.value, Location: location}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+
+	fragment := &InlineFragment{}
+	if p.peek(tokenName, "on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if fragment.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if fragment.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	fragment.Selections, err = p.selectionSet()
+	return fragment, err
+}
+
+func (p *parser) field() (*Field, error) {
+	field := &Field{Location: p.token.location}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	field.Name = name
+
+	if field.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, "{") {
+		if field.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var arguments []*Argument
+	for !p.peek(tokenPunct, ")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, &Argument{Name: name, Value: value})
+	}
+	if len(arguments) == 0 {
+		return nil, p.unexpected()
+	}
+	return arguments, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek(tokenPunct, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &Directive{Name: name, Arguments: arguments})
+	}
+	return directives, nil
+}
+
+func (p *parser) value(constant bool) (interface{}, error) {
+	token := p.token
+	switch token.kind {
+	case tokenPunct:
+		switch token.value {
+		case "$":
+			if constant {
+				return nil, syntaxError(token.location, "unexpected variable in a constant value")
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			return Variable(name), err
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := []interface{}{}
+			for !p.peek(tokenPunct, "]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			return list, p.advance()
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			object := map[string]interface{}{}
+			for !p.peek(tokenPunct, "}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.value(constant); err != nil {
+					return nil, err
+				}
+			}
+			return object, p.advance()
+		}
+	case tokenInt:
+		value, err := strconv.ParseInt(token.value, 10, 64)
+		if err != nil {
+			return nil, syntaxError(token.location, "invalid integer %s", token.value)
+		}
+		return value, p.advance()
+	case tokenFloat:
+		value, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, syntaxError(token.location, "invalid number %s", token.value)
+		}
+		return value, p.advance()
+	case tokenString:
+		return token.value, p.advance()
+	case tokenName:
+		var value interface{}
+		switch token.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = Enum(token.value)
+		}
+		return value, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+func syntaxError(location Location, format string, args ...interface{}) *Error {
+	return &Error{
+		Message:   "Syntax error: " + fmt.Sprintf(format, args...),
+		Locations: []Location{location},
+	}
+}
@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ezzygo/pkg/cache"
+)
+
+// persistedQueryTTL keeps registered queries well beyond the life of a
+// client build; an expired query is simply registered again.
+const persistedQueryTTL = 30 * 24 * time.Hour
+
+// PersistedQueries stores the queries registered by automatic persisted
+// queries, by the hex SHA-256 of the query.
+type PersistedQueries interface {
+	// Get returns the query and false if the hash is unknown.
+	Get(ctx context.Context, hash string) (string, bool, error)
+	Set(ctx context.Context, hash, query string) error
+}
+
+// CachePersistedQueries keeps the persisted queries in the cache store, so
+// they are shared by every instance.
+type CachePersistedQueries struct {
+	store *cache.Store
+}
+
+func NewCachePersistedQueries(store *cache.Store) *CachePersistedQueries {
+	return &CachePersistedQueries{store: store}
+}
+
+func (q *CachePersistedQueries) Get(ctx context.Context, hash string) (string, bool, error) {
+	var query string
+	err := q.store.Get(ctx, "graphql:pq:"+hash, &query)
+	if errors.Is(err, cache.ErrMiss) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return query, true, nil
+}
+
+func (q *CachePersistedQueries) Set(ctx context.Context, hash, query string) error {
+	return q.store.Set(ctx, "graphql:pq:"+hash, query, persistedQueryTTL)
+}
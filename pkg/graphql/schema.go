@@ -0,0 +1,484 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Type is a *Scalar, *Object, *List or *NonNull.
+type Type interface {
+	String() string
+}
+
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved value to its JSON representation.
+	Serialize func(value interface{}) (interface{}, error)
+	// Parse converts an argument or variable value to the Go value given to
+	// resolvers.
+	Parse func(value interface{}) (interface{}, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+type NonNull struct {
+	Of Type
+}
+
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// Object is an object type. Fields keep the order they were added in.
+type Object struct {
+	Name        string
+	Description string
+	fields      []*FieldDef
+	byName      map[string]*FieldDef
+}
+
+func NewObject(name, description string) *Object {
+	return &Object{Name: name, Description: description, byName: map[string]*FieldDef{}}
+}
+
+func (o *Object) String() string { return o.Name }
+
+// AddField adds or replaces a field.
+func (o *Object) AddField(field *FieldDef) *Object {
+	if _, ok := o.byName[field.Name]; !ok {
+		o.fields = append(o.fields, field)
+	} else {
+		for i, existing := range o.fields {
+			if existing.Name == field.Name {
+				o.fields[i] = field
+			}
+		}
+	}
+	o.byName[field.Name] = field
+	return o
+}
+
+func (o *Object) Field(name string) *FieldDef {
+	return o.byName[name]
+}
+
+func (o *Object) Fields() []*FieldDef {
+	return o.fields
+}
+
+// ResolveFunc returns the value of a field, or a Thunk that loads it later.
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// Thunk defers the load of a value. The executor resolves a field for every
+// object of a list before calling the thunks, so loaders can batch the loads.
+type Thunk func() (interface{}, error)
+
+type ResolveParams struct {
+	Context context.Context
+	// Source is the parent object, nil for the root fields.
+	Source interface{}
+	Args   map[string]interface{}
+	field  *Field
+	exec   *executor
+}
+
+// Selected returns the names of the fields selected below the field, following
+// path, e.g. Selected("data") for the rows of a page. Resolvers use it to load
+// only the columns they need.
+func (p ResolveParams) Selected(path ...string) []string {
+	if p.exec == nil || p.field == nil {
+		return nil
+	}
+	fields := []*Field{p.field}
+	for _, name := range path {
+		var next []*Field
+		for _, group := range p.exec.collect(mergeSelections(fields)) {
+			if group.name == name {
+				next = append(next, group.fields...)
+			}
+		}
+		fields = next
+	}
+
+	var names []string
+	for _, group := range p.exec.collect(mergeSelections(fields)) {
+		names = append(names, group.name)
+	}
+	return names
+}
+
+type FieldDef struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*ArgDef
+	// Resolve defaults to reading the struct field at Index of the source.
+	Resolve ResolveFunc
+	// Index is the struct field the field was generated from.
+	Index []int
+	// Cost is the complexity of the field itself, 1 if zero.
+	Cost int
+	// Multiplier is how many times the selection below the field counts
+	// towards the complexity, usually the page size argument. Lists default
+	// to DefaultListSize, other fields to 1.
+	Multiplier func(args map[string]interface{}) int
+}
+
+type ArgDef struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     interface{}
+}
+
+// Schema is a read-only schema; only query operations are supported.
+type Schema struct {
+	Query *Object
+}
+
+var (
+	String = &Scalar{
+		Name: "String",
+		Serialize: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case string:
+				return v, nil
+			case fmt.Stringer:
+				return v.String(), nil
+			}
+			if rv := reflect.ValueOf(value); rv.Kind() == reflect.String {
+				return rv.String(), nil
+			}
+			return nil, fmt.Errorf("String cannot represent %v", value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			if v, ok := value.(string); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("String cannot represent %v", value)
+		},
+	}
+	Int = &Scalar{
+		Name: "Int",
+		Serialize: func(value interface{}) (interface{}, error) {
+			rv := reflect.ValueOf(value)
+			switch rv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return rv.Int(), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return int64(rv.Uint()), nil
+			}
+			return nil, fmt.Errorf("Int cannot represent %v", value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case int64:
+				return int(v), nil
+			case int:
+				return v, nil
+			case float64:
+				if v == float64(int(v)) {
+					return int(v), nil
+				}
+			}
+			return nil, fmt.Errorf("Int cannot represent %v", value)
+		},
+	}
+	Float = &Scalar{
+		Name: "Float",
+		Serialize: func(value interface{}) (interface{}, error) {
+			rv := reflect.ValueOf(value)
+			switch rv.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return rv.Float(), nil
+			}
+			return Int.Serialize(value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			switch v := value.(type) {
+			case float64:
+				return v, nil
+			case int64:
+				return float64(v), nil
+			}
+			return nil, fmt.Errorf("Float cannot represent %v", value)
+		},
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v, ok := value.(bool); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent %v", value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			if v, ok := value.(bool); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("Boolean cannot represent %v", value)
+		},
+	}
+	// ID is serialized as a string and parsed to a uint, the type of the
+	// primary keys.
+	ID = &Scalar{
+		Name: "ID",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v, err := Int.Serialize(value); err == nil {
+				return strconv.FormatInt(v.(int64), 10), nil
+			}
+			return String.Serialize(value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			var raw string
+			switch v := value.(type) {
+			case string:
+				raw = v
+			case int64:
+				raw = strconv.FormatInt(v, 10)
+			case float64:
+				raw = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			id, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("ID cannot represent %v", value)
+			}
+			return uint(id), nil
+		},
+	}
+	Time = &Scalar{
+		Name:        "Time",
+		Description: "An RFC 3339 timestamp",
+		Serialize: func(value interface{}) (interface{}, error) {
+			if v, ok := value.(time.Time); ok {
+				return v.Format(time.RFC3339Nano), nil
+			}
+			return nil, fmt.Errorf("Time cannot represent %v", value)
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			if v, ok := value.(string); ok {
+				return time.Parse(time.RFC3339Nano, v)
+			}
+			return nil, fmt.Errorf("Time cannot represent %v", value)
+		},
+	}
+	// JSON passes arbitrary JSON through, such as the metadata of content.
+	JSON = &Scalar{
+		Name:        "JSON",
+		Description: "Arbitrary JSON",
+		Serialize: func(value interface{}) (interface{}, error) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(data), nil
+		},
+		Parse: func(value interface{}) (interface{}, error) {
+			return value, nil
+		},
+	}
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// FromStruct generates an object type from a model. Fields are named like
+// their JSON names, so responses look like the REST ones; embedded structs
+// such as gorm.Model are flattened. Fields of types with no scalar
+// equivalent, like relations, are skipped and can be added with AddField.
+func FromStruct(name, description string, model interface{}) *Object {
+	object := NewObject(name, description)
+	addStructFields(object, reflect.TypeOf(model), nil)
+	return object
+}
+
+func addStructFields(object *Object, t reflect.Type, index []int) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addStructFields(object, field.Type, fieldIndex)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldType := typeOf(field.Type)
+		if fieldType == nil || !validName(name) {
+			continue
+		}
+		object.AddField(&FieldDef{Name: name, Type: fieldType, Index: fieldIndex})
+	}
+}
+
+func typeOf(t reflect.Type) Type {
+	if t.Kind() == reflect.Ptr {
+		inner := typeOf(t.Elem())
+		if nonNull, ok := inner.(*NonNull); ok {
+			return nonNull.Of
+		}
+		return inner
+	}
+	if t == timeType {
+		return &NonNull{Of: Time}
+	}
+	if t.Implements(marshalerType) || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+		return JSON
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &NonNull{Of: String}
+	case reflect.Bool:
+		return &NonNull{Of: Boolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &NonNull{Of: Int}
+	case reflect.Float32, reflect.Float64:
+		return &NonNull{Of: Float}
+	case reflect.Slice:
+		if elem := typeOf(t.Elem()); elem != nil {
+			return &List{Of: elem}
+		}
+	}
+	return nil
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		if r != '_' && !(r < unicode.MaxASCII && (unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)))) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// resolve runs the resolver of the field, or reads the struct field it was
+// generated from.
+func (f *FieldDef) resolve(p ResolveParams) (interface{}, error) {
+	if f.Resolve != nil {
+		return f.Resolve(p)
+	}
+	if f.Index == nil {
+		return nil, fmt.Errorf("field %s has no resolver", f.Name)
+	}
+	value := reflect.ValueOf(p.Source)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("field %s cannot be read from %T", f.Name, p.Source)
+	}
+	field, err := value.FieldByIndexErr(f.Index)
+	if err != nil {
+		return nil, nil
+	}
+	return field.Interface(), nil
+}
+
+// SDL prints the schema in the GraphQL schema definition language.
+func (s *Schema) SDL() string {
+	objects := map[string]*Object{}
+	scalars := map[string]*Scalar{}
+	var visit func(t Type)
+	visit = func(t Type) {
+		switch t := t.(type) {
+		case *NonNull:
+			visit(t.Of)
+		case *List:
+			visit(t.Of)
+		case *Scalar:
+			scalars[t.Name] = t
+		case *Object:
+			if _, ok := objects[t.Name]; ok {
+				return
+			}
+			objects[t.Name] = t
+			for _, field := range t.fields {
+				visit(field.Type)
+				for _, arg := range field.Args {
+					visit(arg.Type)
+				}
+			}
+		}
+	}
+	visit(s.Query)
+
+	var b strings.Builder
+	for _, name := range sortedKeys(scalars) {
+		if builtinScalars[name] {
+			continue
+		}
+		writeDescription(&b, "", scalars[name].Description)
+		fmt.Fprintf(&b, "scalar %s\n\n", name)
+	}
+	for _, name := range sortedKeys(objects) {
+		object := objects[name]
+		writeDescription(&b, "", object.Description)
+		fmt.Fprintf(&b, "type %s {\n", name)
+		for _, field := range object.fields {
+			writeDescription(&b, "  ", field.Description)
+			b.WriteString("  " + field.Name)
+			if len(field.Args) > 0 {
+				args := make([]string, len(field.Args))
+				for i, arg := range field.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						value, _ := json.Marshal(arg.Default)
+						args[i] += " = " + string(value)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + field.Type.String() + "\n")
+		}
+		b.WriteString("}\n\n")
+	}
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	return b.String()
+}
+
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s%q\n", indent, description)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
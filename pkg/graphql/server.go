@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    RequestExtensions      `json:"extensions"`
+}
+
+type RequestExtensions struct {
+	PersistedQuery *PersistedQuery `json:"persistedQuery,omitempty"`
+}
+
+// PersistedQuery is the automatic persisted query extension: the client sends
+// only the hash and, when the server does not know it yet, retries with the
+// query to register it.
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// Server parses, validates and executes requests against a schema.
+type Server struct {
+	Schema *Schema
+	Limits Limits
+	// Persisted enables automatic persisted queries when set.
+	Persisted PersistedQueries
+}
+
+// Do executes the request. Errors that prevent the execution are returned
+// in a response without data.
+func (s *Server) Do(ctx context.Context, req *Request) *Response {
+	query, register, err := s.query(ctx, req)
+	if err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+
+	doc, parseErr := Parse(query)
+	if parseErr != nil {
+		return &Response{Errors: []*Error{toError(parseErr)}}
+	}
+	operation, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{err}}
+	}
+	if _, errs := Validate(s.Schema, doc, operation, req.Variables, s.Limits); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	if register {
+		// A failure to register only costs the client the full query next time
+		_ = s.Persisted.Set(ctx, strings.ToLower(req.Extensions.PersistedQuery.SHA256Hash), query)
+	}
+	return Execute(ctx, s.Schema, doc, operation, req.Variables)
+}
+
+// query returns the query of the request, resolving persisted queries.
+// register reports a full query sent with its hash, which Do stores only once
+// it parses and validates so that invalid documents are never persisted.
+func (s *Server) query(ctx context.Context, req *Request) (query string, register bool, err *Error) {
+	pq := req.Extensions.PersistedQuery
+	if pq == nil {
+		if strings.TrimSpace(req.Query) == "" {
+			return "", false, &Error{Message: "missing query"}
+		}
+		return req.Query, false, nil
+	}
+	if s.Persisted == nil {
+		return "", false, &Error{Message: "PersistedQueryNotSupported", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_SUPPORTED"}}
+	}
+	if pq.Version != 1 {
+		return "", false, &Error{Message: fmt.Sprintf("unsupported persisted query version %d", pq.Version)}
+	}
+	hash := strings.ToLower(pq.SHA256Hash)
+
+	if req.Query == "" {
+		query, ok, getErr := s.Persisted.Get(ctx, hash)
+		if getErr != nil {
+			return "", false, &Error{Message: getErr.Error()}
+		}
+		if !ok {
+			return "", false, &Error{Message: "PersistedQueryNotFound", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"}}
+		}
+		return query, false, nil
+	}
+
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", false, &Error{Message: "provided sha does not match query", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_HASH_MISMATCH"}}
+	}
+	return req.Query, true, nil
+}
+
+func selectOperation(doc *Document, name string) (*Operation, *Error) {
+	if name == "" {
+		if len(doc.Operations) != 1 {
+			return nil, &Error{Message: "operationName is required when the document has several operations"}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, operation := range doc.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+func toError(err error) *Error {
+	if gqlErr, ok := err.(*Error); ok {
+		return gqlErr
+	}
+	return &Error{Message: err.Error()}
+}
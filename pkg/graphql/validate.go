@@ -0,0 +1,229 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// DefaultListSize is the number of items a list field counts as towards the
+// complexity when it has no Multiplier.
+const DefaultListSize = 10
+
+// Limits bound the cost of a query. Zero values disable a limit.
+type Limits struct {
+	// MaxDepth is the maximum nesting of fields, 1 for top-level fields.
+	MaxDepth int
+	// MaxComplexity is the maximum sum of the field costs, with the cost of
+	// the selection below a list multiplied by the size of the list.
+	MaxComplexity int
+}
+
+// Validate checks that the operation only selects fields and arguments of
+// the schema and stays within the limits. It returns the complexity of the
+// operation.
+func Validate(schema *Schema, doc *Document, operation *Operation, variables map[string]interface{}, limits Limits) (int, []*Error) {
+	if operation.Type != "query" {
+		return 0, []*Error{{Message: fmt.Sprintf("%s operations are not supported", operation.Type), Locations: []Location{operation.Location}}}
+	}
+
+	if err := fragmentCycle(doc); err != nil {
+		return 0, []*Error{err}
+	}
+	vars, err := coerceVariables(operation, variables)
+	if err != nil {
+		return 0, []*Error{err}
+	}
+	v := &validator{
+		executor: &executor{schema: schema, doc: doc, variables: vars},
+		limits:   limits,
+	}
+	complexity := v.selectionSet(schema.Query, operation.Selections, 1, map[string]bool{})
+	if len(v.errors) == 0 && limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		v.errors = append(v.errors, &Error{
+			Message:    fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, limits.MaxComplexity),
+			Extensions: map[string]interface{}{"code": "QUERY_TOO_COMPLEX"},
+		})
+	}
+	return complexity, v.errors
+}
+
+type validator struct {
+	*executor
+	limits      Limits
+	depthFailed bool
+}
+
+func (v *validator) selectionSet(object *Object, selections []Selection, depth int, fragments map[string]bool) int {
+	v.fragments(object, selections, fragments)
+
+	complexity := 0
+	for _, group := range v.collect(selections) {
+		if group.name == "__typename" {
+			continue
+		}
+		field := group.fields[0]
+		definition := object.Field(group.name)
+		if definition == nil {
+			v.errorf(field.Location, "cannot query field %q on type %q", group.name, object.Name)
+			continue
+		}
+		if v.limits.MaxDepth > 0 && depth > v.limits.MaxDepth {
+			if !v.depthFailed {
+				v.depthFailed = true
+				v.errors = append(v.errors, &Error{
+					Message:    fmt.Sprintf("query exceeds the maximum depth of %d", v.limits.MaxDepth),
+					Locations:  []Location{field.Location},
+					Extensions: map[string]interface{}{"code": "QUERY_TOO_DEEP"},
+				})
+			}
+			continue
+		}
+
+		for _, f := range group.fields {
+			for _, arg := range f.Arguments {
+				if !hasArg(definition, arg.Name) {
+					v.errorf(f.Location, "unknown argument %q on field %q", arg.Name, group.name)
+				}
+			}
+		}
+		args, err := coerceArguments(definition, field, v.variables)
+		if err != nil {
+			v.errorf(field.Location, "%v", err)
+			continue
+		}
+
+		cost := definition.Cost
+		if cost == 0 {
+			cost = 1
+		}
+		inner, isList := unwrap(definition.Type)
+		child, isObject := inner.(*Object)
+		subselections := mergeSelections(group.fields)
+		switch {
+		case isObject && len(subselections) == 0:
+			v.errorf(field.Location, "field %q of type %q must have a selection of subfields", group.name, definition.Type)
+			continue
+		case !isObject && len(subselections) > 0:
+			v.errorf(field.Location, "field %q of type %q cannot have a selection of subfields", group.name, definition.Type)
+			continue
+		}
+
+		if isObject {
+			multiplier := 1
+			if definition.Multiplier != nil {
+				multiplier = definition.Multiplier(args)
+			} else if isList {
+				multiplier = DefaultListSize
+			}
+			cost += multiplier * v.selectionSet(child, subselections, depth+1, fragments)
+		}
+		complexity += cost
+	}
+	return complexity
+}
+
+// fragments checks that the fragments spread in the selection exist and
+// apply to the object.
+func (v *validator) fragments(object *Object, selections []Selection, visited map[string]bool) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *InlineFragment:
+			if s.TypeCondition != "" && s.TypeCondition != object.Name {
+				v.errorf(Location{}, "fragment on %q cannot be spread on type %q", s.TypeCondition, object.Name)
+			}
+			v.fragments(object, s.Selections, visited)
+		case *FragmentSpread:
+			fragment := v.doc.Fragments[s.Name]
+			if fragment == nil {
+				v.errorf(s.Location, "unknown fragment %q", s.Name)
+				continue
+			}
+			if fragment.TypeCondition != object.Name {
+				v.errorf(s.Location, "fragment %q on %q cannot be spread on type %q", s.Name, fragment.TypeCondition, object.Name)
+			}
+			if visited[s.Name] {
+				continue
+			}
+			visited[s.Name] = true
+			v.fragments(object, fragment.Selections, visited)
+			delete(visited, s.Name)
+		}
+	}
+}
+
+// fragmentCycle reports fragments that spread themselves, which would expand
+// forever.
+func fragmentCycle(doc *Document) *Error {
+	state := map[string]int{} // 1 while visiting, 2 when done
+	var visit func(name string) *Error
+	var walk func(selections []Selection) *Error
+	walk = func(selections []Selection) *Error {
+		for _, selection := range selections {
+			var err *Error
+			switch s := selection.(type) {
+			case *Field:
+				err = walk(s.Selections)
+			case *InlineFragment:
+				err = walk(s.Selections)
+			case *FragmentSpread:
+				err = visit(s.Name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	visit = func(name string) *Error {
+		fragment := doc.Fragments[name]
+		if fragment == nil || state[name] == 2 {
+			return nil
+		}
+		if state[name] == 1 {
+			return &Error{Message: fmt.Sprintf("fragment %q spreads itself", name), Locations: []Location{fragment.Location}}
+		}
+		state[name] = 1
+		if err := walk(fragment.Selections); err != nil {
+			return err
+		}
+		state[name] = 2
+		return nil
+	}
+	for _, name := range sortedKeys(doc.Fragments) {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) errorf(location Location, format string, args ...interface{}) {
+	err := &Error{Message: fmt.Sprintf(format, args...)}
+	if location.Line > 0 {
+		err.Locations = []Location{location}
+	}
+	v.errors = append(v.errors, err)
+}
+
+func hasArg(definition *FieldDef, name string) bool {
+	for _, arg := range definition.Args {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrap returns the named type of t and whether it is a list.
+func unwrap(t Type) (Type, bool) {
+	isList := false
+	for {
+		switch wrapped := t.(type) {
+		case *NonNull:
+			t = wrapped.Of
+		case *List:
+			t, isList = wrapped.Of, true
+		default:
+			return t, isList
+		}
+	}
+}
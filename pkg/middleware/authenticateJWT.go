@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 )
 
 func JWTAuth() gin.HandlerFunc {
@@ -25,26 +24,15 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		tokenStr := header[len(BearerSchema):]
-		claims := &auth.Claims{}
-
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return auth.JwtKey, nil
-		})
-
+		claims, err := auth.ParseToken(header[len(BearerSchema):])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
 		c.Set("username", claims.Username)
+		c.Request = c.Request.WithContext(auth.WithUsername(c.Request.Context(), claims.Username))
 		c.Next()
 	}
 }
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"ezzygo/pkg/models"
+)
+
+// RequireRole lets the request through only if the user authenticated by
+// JWTAuth has one of roles. The role is read from the database on every
+// request, so a change of role applies without issuing a new token.
+func RequireRole(db *gorm.DB, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		err := db.WithContext(c.Request.Context()).
+			Select("role").
+			Where("username = ?", c.GetString("username")).
+			First(&user).Error
+		if err != nil || !slices.Contains(roles, user.Role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient role"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	surrogateKeysContextKey = "surrogateKeys"
+	cacheUntilContextKey    = "cacheUntil"
+)
+
+// CachePolicy controls the caching headers of public responses.
+type CachePolicy struct {
+	// MaxAge is how long browsers and CDNs may serve the response as fresh.
+	MaxAge time.Duration
+	// StaleWhileRevalidate is how long a stale response may be served while
+	// it is refetched in the background.
+	StaleWhileRevalidate time.Duration
+	// SurrogateMaxAge is how long the CDN keeps responses tagged with
+	// surrogate keys. It can be long because those are purged on change.
+	SurrogateMaxAge time.Duration
+}
+
+// CachePolicyFromEnv reads CACHE_MAX_AGE (default 1m),
+// CACHE_STALE_WHILE_REVALIDATE (default 5m) and CDN_MAX_AGE (default 24h).
+func CachePolicyFromEnv() CachePolicy {
+	return CachePolicy{
+		MaxAge:               durationEnv("CACHE_MAX_AGE", time.Minute),
+		StaleWhileRevalidate: durationEnv("CACHE_STALE_WHILE_REVALIDATE", 5*time.Minute),
+		SurrogateMaxAge:      durationEnv("CDN_MAX_AGE", 24*time.Hour),
+	}
+}
+
+// SurrogateKeys tags the current response with surrogate keys, so it can be
+// purged from the CDN when any of those entities change.
+func SurrogateKeys(c *gin.Context, keys ...string) {
+	existing := c.GetStringSlice(surrogateKeysContextKey)
+	c.Set(surrogateKeysContextKey, append(existing, keys...))
+}
+
+// CacheUntil limits how long the current response may be cached, for content
+// that stops being visible at a given time without a change that would purge
+// it. The earliest of several calls wins.
+func CacheUntil(c *gin.Context, until time.Time) {
+	if current, ok := c.Get(cacheUntilContextKey); ok && !until.Before(current.(time.Time)) {
+		return
+	}
+	c.Set(cacheUntilContextKey, until)
+}
+
+// ResponseCache makes successful GET responses cacheable. It sets
+// Cache-Control with stale-while-revalidate, a strong ETag computed from the
+// body unless the handler set one, Surrogate-Key/Surrogate-Control for
+// responses tagged with SurrogateKeys, and answers conditional requests with
+// 304: If-None-Match against the ETag or, when the request has no
+// If-None-Match, If-Modified-Since against a Last-Modified set by the handler.
+// Responses limited with CacheUntil are not cached past that time. Other
+// responses are marked no-store.
+func ResponseCache(policy CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		header := original.Header()
+		if writer.status != http.StatusOK {
+			header.Set("Cache-Control", "no-store")
+			original.WriteHeader(writer.status)
+			original.Write(writer.body.Bytes())
+			return
+		}
+
+		maxAge, stale, surrogateMaxAge := policy.MaxAge, policy.StaleWhileRevalidate, policy.SurrogateMaxAge
+		if until, ok := c.Get(cacheUntilContextKey); ok {
+			// Nothing purges the response when it expires, so no cache may
+			// keep it, fresh or stale, beyond that point
+			remaining := time.Until(until.(time.Time)).Truncate(time.Second)
+			if remaining < 0 {
+				remaining = 0
+			}
+			maxAge = min(maxAge, remaining)
+			surrogateMaxAge = min(surrogateMaxAge, remaining)
+			stale = min(stale, remaining-maxAge)
+		}
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d",
+				int(maxAge.Seconds()), int(stale.Seconds())))
+		}
+		if keys := c.GetStringSlice(surrogateKeysContextKey); len(keys) > 0 && !strings.Contains(header.Get("Cache-Control"), "no-store") {
+			header.Set("Surrogate-Key", strings.Join(dedupe(keys), " "))
+			header.Set("Surrogate-Control", fmt.Sprintf("max-age=%d", int(surrogateMaxAge.Seconds())))
+		}
+		if header.Get("ETag") == "" {
+			sum := sha256.Sum256(writer.body.Bytes())
+			header.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		}
+
+		if notModified(c.Request, header) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		original.WriteHeader(http.StatusOK)
+		original.Write(writer.body.Bytes())
+	}
+}
+
+// bufferedWriter holds the response until the handler returns, so headers
+// derived from the body can still be added.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// notModified evaluates the conditional request headers in the precedence
+// order of RFC 9110: If-Modified-Since is ignored when If-None-Match is sent.
+func notModified(r *http.Request, header http.Header) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, header.Get("ETag"))
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatches implements the weak comparison used by If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupResponseCache() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ResponseCache(CachePolicy{
+		MaxAge:               time.Minute,
+		StaleWhileRevalidate: 5 * time.Minute,
+		SurrogateMaxAge:      time.Hour,
+	}))
+	router.GET("/content/:slug", func(c *gin.Context) {
+		if c.Param("slug") == "missing" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "content not found"})
+			return
+		}
+		if c.Param("slug") == "expiring" {
+			CacheUntil(c, time.Now().Add(3*time.Minute+30*time.Second))
+			CacheUntil(c, time.Now().Add(2*time.Hour))
+		}
+		SurrogateKeys(c, "content:1", "template:5")
+		SurrogateKeys(c, "content:1")
+		c.JSON(http.StatusOK, gin.H{"title": "Hello"})
+	})
+	return router
+}
+
+func TestResponseCacheHeaders(t *testing.T) {
+	router := setupResponseCache()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/content/hello", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"title":"Hello"}`, w.Body.String())
+	assert.Equal(t, "public, max-age=60, stale-while-revalidate=300", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "content:1 template:5", w.Header().Get("Surrogate-Key"))
+	assert.Equal(t, "max-age=3600", w.Header().Get("Surrogate-Control"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+}
+
+func TestResponseCacheUntil(t *testing.T) {
+	router := setupResponseCache()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/content/expiring", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// max-age stays at 60s, but the stale window and the CDN stop at the
+	// expiry, about 210s away
+	assert.Regexp(t, `^public, max-age=60, stale-while-revalidate=1(49|50)$`, w.Header().Get("Cache-Control"))
+	assert.Regexp(t, `^max-age=2(09|10)$`, w.Header().Get("Surrogate-Control"))
+}
+
+func TestResponseCacheNotModified(t *testing.T) {
+	router := setupResponseCache()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/content/hello", nil)
+	router.ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/content/hello", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+}
+
+func TestResponseCacheErrors(t *testing.T) {
+	router := setupResponseCache()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/content/missing", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "content not found")
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestResponseCacheIfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	router := setupResponseCache()
+	router.GET("/feed", func(c *gin.Context) {
+		c.Header("Last-Modified", modified.Format(http.TimeFormat))
+		c.String(http.StatusOK, "feed")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/feed", nil)
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/feed", nil)
+	req.Header.Set("If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "feed", w.Body.String())
+
+	// If-None-Match takes precedence over If-Modified-Since
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/feed", nil)
+	req.Header.Set("If-None-Match", `"other"`)
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -2,6 +2,22 @@ package models
 
 import "time"
 
+// Editorial roles used by the CMS workflow
+const (
+	RoleAuthor   = "author"
+	RoleEditor   = "editor"
+	RoleReviewer = "reviewer"
+	RoleAdmin    = "admin"
+)
+
+// Roles lists every valid role
+var Roles = []string{RoleAuthor, RoleEditor, RoleReviewer, RoleAdmin}
+
+// UserRole is the body of a role change
+type UserRole struct {
+	Role string `json:"role" binding:"required"`
+}
+
 type LoginUser struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -11,6 +27,20 @@ type User struct {
 	ID        uint      `json:"id" gorm:"primary_key"`
 	Username  string    `json:"username" gorm:"unique"`
 	Password  string    `json:"password"`
+	Role      string    `json:"role" gorm:"default:'author'"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
+
+// UserSummary is a user without its password hash, as returned by the user list.
+type UserSummary struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserSummary) TableName() string {
+	return "users"
+}
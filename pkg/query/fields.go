@@ -0,0 +1,171 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Fieldset is a sparse fieldset, the JSON names from ?fields=title,slug. The
+// empty fieldset selects every field.
+//
+// Names are matched case-insensitively, like encoding/json does, so
+// fields=id works for models that embed gorm.Model and serialize it as "ID".
+type Fieldset []string
+
+// ParseFieldset splits the value of ?fields=. Names are validated when the
+// fieldset is resolved against a model.
+func ParseFieldset(raw string) Fieldset {
+	var fields Fieldset
+	seen := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// Columns returns the columns to load for the fieldset: the requested ones,
+// the primary key and always, which callers use for the columns they need
+// themselves. It fails with ErrInvalidQuery if a name is not a column of
+// model, e.g. a relation.
+func (f Fieldset) Columns(model interface{}, always ...string) ([]string, error) {
+	return f.columns(model, schema.NamingStrategy{}, always)
+}
+
+func (f Fieldset) columns(model interface{}, namer schema.Namer, always []string) ([]string, error) {
+	modelSchema, err := schema.Parse(model, &schemaCache, namer)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]string{}
+	for _, field := range modelSchema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		byName[strings.ToLower(name)] = field.DBName
+	}
+
+	var columns []string
+	if modelSchema.PrioritizedPrimaryField != nil {
+		columns = append(columns, modelSchema.PrioritizedPrimaryField.DBName)
+	}
+	columns = append(columns, always...)
+	for _, name := range f {
+		column, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, name)
+		}
+		columns = append(columns, column)
+	}
+	return dedupe(columns), nil
+}
+
+// Scope restricts a query on model to the columns of the fieldset, so
+// columns that were not requested are never loaded. It does nothing for the
+// empty fieldset; an invalid one fails the query with ErrInvalidQuery.
+func (f Fieldset) Scope(model interface{}, always ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(f) == 0 {
+			return db
+		}
+		columns, err := f.columns(model, db.NamingStrategy, always)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		return db.Select(columns)
+	}
+}
+
+// Project reduces the JSON of v, an object or an array of objects, to the
+// fields of the fieldset. The empty fieldset returns v as is.
+func (f Fieldset) Project(v interface{}) (interface{}, error) {
+	if len(f) == 0 {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("null")) {
+		return v, nil
+	}
+	if trimmed[0] == '[' {
+		var rows []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			rows[i] = f.keep(rows[i])
+		}
+		return rows, nil
+	}
+
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, err
+	}
+	return f.keep(row), nil
+}
+
+func (f Fieldset) keep(row map[string]json.RawMessage) map[string]json.RawMessage {
+	kept := make(map[string]json.RawMessage, len(f))
+	for key, value := range row {
+		for _, name := range f {
+			if strings.EqualFold(key, name) {
+				kept[key] = value
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// Project returns the page with its rows reduced to the fields of the
+// fieldset, see Fieldset.Project.
+func (p *Page[T]) Project(fields Fieldset) (interface{}, error) {
+	if len(fields) == 0 {
+		return p, nil
+	}
+	projected := Page[map[string]json.RawMessage]{Data: []map[string]json.RawMessage{}, Page: p.Page}
+	if len(p.Data) == 0 {
+		return projected, nil
+	}
+	data, err := fields.Project(p.Data)
+	if err != nil {
+		return nil, err
+	}
+	projected.Data = data.([]map[string]json.RawMessage)
+	return projected, nil
+}
+
+func dedupe(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,266 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Page is the envelope of every list response.
+type Page[T any] struct {
+	Data []T      `json:"data"`
+	Page PageInfo `json:"page"`
+}
+
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// cursor points at the first or last row of a page. It is bound to the sort
+// it was created with.
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+	// Prev cursors read the rows before this one
+	Prev bool `json:"p,omitempty"`
+}
+
+// sortKey is a resolved sort field.
+type sortKey struct {
+	Field
+	Desc bool
+}
+
+// keys resolves the sort of params, or the default one, and appends id as
+// the tiebreaker.
+func (s Schema) keys(sorts []Sort) []sortKey {
+	if len(sorts) == 0 {
+		sorts = s.DefaultSort
+	}
+
+	var keys []sortKey
+	hasID := false
+	for _, sort := range sorts {
+		field := s.Fields[sort.Field]
+		keys = append(keys, sortKey{Field: field, Desc: sort.Desc})
+		hasID = hasID || field.Column == "id"
+	}
+	if !hasID {
+		keys = append(keys, sortKey{Field: Field{Column: "id", Type: Int}})
+	}
+	return keys
+}
+
+func signature(keys []sortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.Column
+		if key.Desc {
+			parts[i] = "-" + key.Column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string, keys []sortKey) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&c); err != nil || len(c.Values) != len(keys) {
+		return c, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if c.Sort != signature(keys) {
+		return c, fmt.Errorf("%w: cursor was created with a different sort", ErrInvalidQuery)
+	}
+
+	for i, key := range keys {
+		value, err := cursorValue(c.Values[i], key.Type)
+		if err != nil {
+			return c, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+		}
+		c.Values[i] = value
+	}
+	return c, nil
+}
+
+func cursorValue(value interface{}, fieldType FieldType) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Int64()
+	case string:
+		if fieldType == Time {
+			return time.Parse(time.RFC3339Nano, v)
+		}
+		return v, nil
+	case bool:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected cursor value %v", value)
+}
+
+// seek returns the keyset condition for the rows after the cursor, e.g.
+// "(a > ?) OR (a = ? AND id > ?)". Directions are reversed for prev cursors.
+func seek(keys []sortKey, values []interface{}, prev bool) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if key.Desc != prev {
+			op = "<"
+		}
+		parts = append(parts, key.Column+" "+op+" ?")
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), args
+}
+
+func order(keys []sortKey, prev bool) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		direction := "ASC"
+		if key.Desc != prev {
+			direction = "DESC"
+		}
+		parts[i] = key.Column + " " + direction
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Apply adds the filters of params to db. Find calls it; it is exported for
+// queries that need the same filtering without pagination.
+func Apply(db *gorm.DB, params Params, s Schema) *gorm.DB {
+	for _, filter := range params.Filters {
+		condition, args := filter.condition(s.Fields[filter.Field])
+		db = db.Where(condition, args...)
+	}
+	return db
+}
+
+var schemaCache sync.Map
+
+// Find runs a paginated query. db may already be scoped (Model, Where); when
+// it has no model, T is used.
+func Find[T any](ctx context.Context, db *gorm.DB, params Params, s Schema) (*Page[T], error) {
+	keys := s.keys(params.Sort)
+	var after *cursor
+	if params.Cursor != "" {
+		c, err := decodeCursor(params.Cursor, keys)
+		if err != nil {
+			return nil, err
+		}
+		after = &c
+	}
+	if params.Limit < 1 {
+		params.Limit = DefaultLimit
+	}
+
+	db = db.WithContext(ctx)
+	if db.Statement.Model == nil {
+		db = db.Model(new(T))
+	}
+	// The sort columns are loaded even if not requested, for the cursors
+	sortColumns := make([]string, len(keys))
+	for i, key := range keys {
+		sortColumns[i] = key.Column
+	}
+	base := Apply(db, params, s).Session(&gorm.Session{})
+
+	page := &Page[T]{Data: []T{}, Page: PageInfo{Limit: params.Limit}}
+	if params.Total {
+		var total int64
+		if err := base.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Page.Total = &total
+	}
+
+	prev := after != nil && after.Prev
+	rows := base
+	if after != nil {
+		condition, args := seek(keys, after.Values, prev)
+		rows = rows.Where(condition, args...)
+	}
+	var data []T
+	rows = rows.Scopes(params.Fields.Scope(new(T), sortColumns...))
+	if err := rows.Order(order(keys, prev)).Limit(params.Limit + 1).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(data) > params.Limit
+	if more {
+		data = data[:params.Limit]
+	}
+	if prev {
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+	if len(data) == 0 {
+		return page, nil
+	}
+	page.Data = data
+
+	rowSchema, err := schema.Parse(new(T), &schemaCache, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	sig := signature(keys)
+	// Forward pages have more rows after them if the query overflowed, and
+	// rows before them if they were reached with a cursor; prev pages the
+	// other way round
+	if more || prev {
+		values, err := rowValues(ctx, rowSchema, data[len(data)-1], keys)
+		if err != nil {
+			return nil, err
+		}
+		page.Page.NextCursor = encodeCursor(cursor{Sort: sig, Values: values})
+	}
+	if (prev && more) || (!prev && after != nil) {
+		values, err := rowValues(ctx, rowSchema, data[0], keys)
+		if err != nil {
+			return nil, err
+		}
+		page.Page.PrevCursor = encodeCursor(cursor{Sort: sig, Values: values, Prev: true})
+	}
+	return page, nil
+}
+
+func rowValues[T any](ctx context.Context, rowSchema *schema.Schema, row T, keys []sortKey) ([]interface{}, error) {
+	value := reflect.ValueOf(row)
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		field := rowSchema.LookUpField(key.Column)
+		if field == nil {
+			return nil, fmt.Errorf("query: %s has no column %q", rowSchema.Name, key.Column)
+		}
+		values[i], _ = field.ValueOf(ctx, value)
+	}
+	return values, nil
+}
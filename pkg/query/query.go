@@ -0,0 +1,245 @@
+// Package query implements the list parameters shared by every list
+// endpoint: opaque cursor pagination, multi-field sorting, filter
+// expressions such as created_at[gte]=2024-01-01 or author_id[in]=1,2 and
+// sparse fieldsets.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidQuery is wrapped by every parameter error, which should be
+// reported to the client as a bad request.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// FieldType decides how filter values and cursors are parsed.
+type FieldType int
+
+const (
+	String FieldType = iota
+	Int
+	Time
+	Bool
+	// StringArray fields only support contains, which matches one element.
+	StringArray
+)
+
+// Field is a column clients may filter by and, if Sortable, sort by.
+type Field struct {
+	Column   string
+	Type     FieldType
+	Sortable bool
+}
+
+// Schema is the whitelist of fields of a list endpoint. Rows are always
+// ordered by id last so cursors are stable; the schema must include it.
+type Schema struct {
+	Fields      map[string]Field
+	DefaultSort []Sort
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+type Filter struct {
+	Field  string
+	Op     string
+	Values []interface{}
+}
+
+// Params are the parsed list parameters of a request.
+type Params struct {
+	Limit   int
+	Sort    []Sort
+	Filters []Filter
+	// Cursor is the opaque cursor from a previous page, validated when the
+	// query runs.
+	Cursor string
+	// Total asks for the number of rows matching the filters.
+	Total bool
+	// Fields is the sparse fieldset of the response; only those columns are
+	// loaded.
+	Fields Fieldset
+}
+
+// Reserved parameters that are never read as filters.
+var reservedParams = map[string]bool{"limit": true, "cursor": true, "sort": true, "total": true, "fields": true}
+
+var filterParam = regexp.MustCompile(`^(\w+)\[(\w+)\]$`)
+
+// Parse reads limit, cursor, sort (e.g. "-created_at,title"), total, fields
+// and the filter parameters. A bare field=value is an eq filter; other parameters
+// that do not name a field are ignored.
+func Parse(values url.Values, schema Schema) (Params, error) {
+	params := Params{
+		Limit:  DefaultLimit,
+		Cursor: values.Get("cursor"),
+		Fields: ParseFieldset(values.Get("fields")),
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return params, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidQuery)
+		}
+		params.Limit = min(limit, MaxLimit)
+	}
+
+	if raw := values.Get("total"); raw != "" {
+		total, err := strconv.ParseBool(raw)
+		if err != nil {
+			return params, fmt.Errorf("%w: total must be a boolean", ErrInvalidQuery)
+		}
+		params.Total = total
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			desc := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+			if field, ok := schema.Fields[name]; !ok || !field.Sortable {
+				return params, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, name)
+			}
+			params.Sort = append(params.Sort, Sort{Field: name, Desc: desc})
+		}
+	}
+
+	// Sorted so the filters, and the SQL they produce, are deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if reservedParams[key] {
+			continue
+		}
+		name, op := key, "eq"
+		if match := filterParam.FindStringSubmatch(key); match != nil {
+			name, op = match[1], match[2]
+			if _, ok := schema.Fields[name]; !ok {
+				return params, fmt.Errorf("%w: cannot filter by %q", ErrInvalidQuery, name)
+			}
+		}
+		field, ok := schema.Fields[name]
+		if !ok {
+			continue
+		}
+		for _, raw := range values[key] {
+			filter, err := parseFilter(name, op, raw, field)
+			if err != nil {
+				return params, err
+			}
+			params.Filters = append(params.Filters, filter)
+		}
+	}
+	return params, nil
+}
+
+// Key identifies the params, for use in cache keys.
+func (p Params) Key() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "l=%d;c=%s;t=%t;p=%s;s=", p.Limit, p.Cursor, p.Total, strings.Join(p.Fields, ","))
+	for _, s := range p.Sort {
+		fmt.Fprintf(&b, "%s:%t,", s.Field, s.Desc)
+	}
+	b.WriteString(";f=")
+	for _, f := range p.Filters {
+		fmt.Fprintf(&b, "%s[%s]=%v,", f.Field, f.Op, f.Values)
+	}
+	return b.String()
+}
+
+var opsByType = map[FieldType][]string{
+	String:      {"eq", "ne", "in", "gt", "gte", "lt", "lte", "contains"},
+	Int:         {"eq", "ne", "in", "gt", "gte", "lt", "lte"},
+	Time:        {"eq", "ne", "gt", "gte", "lt", "lte"},
+	Bool:        {"eq", "ne"},
+	StringArray: {"contains"},
+}
+
+func parseFilter(name, op, raw string, field Field) (Filter, error) {
+	allowed := false
+	for _, candidate := range opsByType[field.Type] {
+		allowed = allowed || candidate == op
+	}
+	if !allowed {
+		return Filter{}, fmt.Errorf("%w: operator %q is not supported for %q", ErrInvalidQuery, op, name)
+	}
+
+	raws := []string{raw}
+	if op == "in" {
+		raws = strings.Split(raw, ",")
+	}
+
+	filter := Filter{Field: name, Op: op}
+	for _, raw := range raws {
+		value, err := parseValue(strings.TrimSpace(raw), field.Type)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: invalid value %q for %q", ErrInvalidQuery, raw, name)
+		}
+		filter.Values = append(filter.Values, value)
+	}
+	return filter, nil
+}
+
+func parseValue(raw string, fieldType FieldType) (interface{}, error) {
+	switch fieldType {
+	case Int:
+		return strconv.ParseInt(raw, 10, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Time:
+		if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", raw)
+	default:
+		return raw, nil
+	}
+}
+
+// condition returns the SQL condition of the filter.
+func (f Filter) condition(field Field) (string, []interface{}) {
+	switch f.Op {
+	case "ne":
+		return field.Column + " <> ?", f.Values
+	case "in":
+		return field.Column + " IN ?", []interface{}{f.Values}
+	case "gt":
+		return field.Column + " > ?", f.Values
+	case "gte":
+		return field.Column + " >= ?", f.Values
+	case "lt":
+		return field.Column + " < ?", f.Values
+	case "lte":
+		return field.Column + " <= ?", f.Values
+	case "contains":
+		if field.Type == StringArray {
+			return "? = ANY(" + field.Column + ")", f.Values
+		}
+		return field.Column + " ILIKE ?", []interface{}{"%" + escapeLike(f.Values[0].(string)) + "%"}
+	default:
+		return field.Column + " = ?", f.Values
+	}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var testSchema = Schema{
+	Fields: map[string]Field{
+		"id":         {Column: "id", Type: Int, Sortable: true},
+		"title":      {Column: "title", Type: String, Sortable: true},
+		"author_id":  {Column: "author_id", Type: Int},
+		"created_at": {Column: "created_at", Type: Time, Sortable: true},
+		"tags":       {Column: "tags", Type: StringArray},
+	},
+	DefaultSort: []Sort{{Field: "created_at", Desc: true}},
+}
+
+type testRow struct {
+	gorm.Model
+	Title string
+}
+
+func TestParse(t *testing.T) {
+	values, _ := url.ParseQuery("limit=500&sort=-created_at,title&total=true&fields=title,tags&author_id[in]=1,2&title[contains]=go&created_at[gte]=2024-01-01&locale=es")
+	params, err := Parse(values, testSchema)
+	require.NoError(t, err)
+
+	assert.Equal(t, MaxLimit, params.Limit)
+	assert.True(t, params.Total)
+	assert.Equal(t, Fieldset{"title", "tags"}, params.Fields)
+	assert.Equal(t, []Sort{{Field: "created_at", Desc: true}, {Field: "title"}}, params.Sort)
+	assert.Equal(t, []Filter{
+		{Field: "author_id", Op: "in", Values: []interface{}{int64(1), int64(2)}},
+		{Field: "created_at", Op: "gte", Values: []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{Field: "title", Op: "contains", Values: []interface{}{"go"}},
+	}, params.Filters)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, raw := range []string{
+		"limit=0",
+		"sort=author_id",
+		"sort=password",
+		"secret[eq]=1",
+		"author_id[contains]=1",
+		"author_id=abc",
+		"created_at[in]=2024-01-01",
+		"total=maybe",
+	} {
+		values, _ := url.ParseQuery(raw)
+		_, err := Parse(values, testSchema)
+		assert.ErrorIs(t, err, ErrInvalidQuery, raw)
+	}
+}
+
+func TestFilterCondition(t *testing.T) {
+	condition, args := Filter{Field: "title", Op: "contains", Values: []interface{}{"50%_off"}}.condition(testSchema.Fields["title"])
+	assert.Equal(t, "title ILIKE ?", condition)
+	assert.Equal(t, []interface{}{`%50\%\_off%`}, args)
+
+	condition, args = Filter{Field: "tags", Op: "contains", Values: []interface{}{"go"}}.condition(testSchema.Fields["tags"])
+	assert.Equal(t, "? = ANY(tags)", condition)
+	assert.Equal(t, []interface{}{"go"}, args)
+
+	condition, args = Filter{Field: "author_id", Op: "in", Values: []interface{}{int64(1), int64(2)}}.condition(testSchema.Fields["author_id"])
+	assert.Equal(t, "author_id IN ?", condition)
+	assert.Equal(t, []interface{}{[]interface{}{int64(1), int64(2)}}, args)
+}
+
+func TestSeek(t *testing.T) {
+	keys := testSchema.keys([]Sort{{Field: "created_at", Desc: true}, {Field: "title"}})
+	assert.Equal(t, "-created_at,title,id", signature(keys))
+
+	condition, args := seek(keys, []interface{}{"t", "a", int64(3)}, false)
+	assert.Equal(t, "(created_at < ?) OR (created_at = ? AND title > ?) OR (created_at = ? AND title = ? AND id > ?)", condition)
+	assert.Equal(t, []interface{}{"t", "t", "a", "t", "a", int64(3)}, args)
+	assert.Equal(t, "created_at DESC, title ASC, id ASC", order(keys, false))
+
+	condition, _ = seek(keys, []interface{}{"t", "a", int64(3)}, true)
+	assert.Equal(t, "(created_at > ?) OR (created_at = ? AND title < ?) OR (created_at = ? AND title = ? AND id < ?)", condition)
+	assert.Equal(t, "created_at ASC, title DESC, id DESC", order(keys, true))
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	keys := testSchema.keys(nil)
+	rowSchema, err := schema.Parse(&testRow{}, &schemaCache, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	created := time.Date(2024, 3, 1, 10, 30, 0, 123000, time.UTC)
+	row := testRow{Model: gorm.Model{ID: 7, CreatedAt: created}, Title: "Go"}
+	values, err := rowValues(context.Background(), rowSchema, row, keys)
+	require.NoError(t, err)
+
+	raw := encodeCursor(cursor{Sort: signature(keys), Values: values, Prev: true})
+	decoded, err := decodeCursor(raw, keys)
+	require.NoError(t, err)
+	assert.True(t, decoded.Prev)
+	assert.True(t, created.Equal(decoded.Values[0].(time.Time)))
+	assert.Equal(t, int64(7), decoded.Values[1])
+
+	// A cursor only works with the sort it was created with
+	_, err = decodeCursor(raw, testSchema.keys([]Sort{{Field: "title"}}))
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = decodeCursor("not-a-cursor", keys)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestFieldset(t *testing.T) {
+	fields := ParseFieldset("title, id,,Title")
+	assert.Equal(t, Fieldset{"title", "id"}, fields)
+
+	columns, err := fields.Columns(&testRow{}, "created_at")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "created_at", "title"}, columns)
+
+	_, err = Fieldset{"title", "password"}.Columns(&testRow{})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	columns, err = Fieldset(nil).Columns(&testRow{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, columns)
+}
+
+func TestFieldsetProject(t *testing.T) {
+	rows := []testRow{{Model: gorm.Model{ID: 1}, Title: "Go"}}
+	fields := Fieldset{"title"}
+
+	projected, err := fields.Project(rows[0])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"Title": json.RawMessage(`"Go"`)}, projected)
+
+	page, err := (&Page[testRow]{Data: rows, Page: PageInfo{Limit: 1, NextCursor: "next"}}).Project(fields)
+	require.NoError(t, err)
+	data, _ := json.Marshal(page)
+	assert.JSONEq(t, `{"data":[{"Title":"Go"}],"page":{"limit":1,"next_cursor":"next"}}`, string(data))
+
+	// Without fields the value is returned as is
+	projected, err = Fieldset(nil).Project(rows)
+	require.NoError(t, err)
+	assert.Equal(t, rows, projected)
+}
@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"ezzygo/pkg/auth"
+)
+
+// Auth applies the rules of the REST middleware to the metadata of a call:
+// every call needs x-api-key, as APIKeyAuth, and the methods for which
+// requireJWT returns true a bearer token in authorization, as JWTAuth. The
+// username of the token is stored with auth.WithUsername.
+//
+// The returned options install the check for unary and streaming calls.
+func Auth(requireJWT func(method string) bool) []grpc.ServerOption {
+	authorize := func(ctx context.Context, method string) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if first(md, "x-api-key") != os.Getenv("API_SECRET_KEY") {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		if !requireJWT(method) {
+			return ctx, nil
+		}
+
+		token, ok := strings.CutPrefix(first(md, "authorization"), "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		claims, err := auth.ParseToken(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return auth.WithUsername(ctx, claims.Username), nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := authorize(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := authorize(stream.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+// authorizedStream carries the context returned by the check into a
+// streaming handler.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,251 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: ezzygo/v1/content.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ContentService_GetContent_FullMethodName          = "/ezzygo.v1.ContentService/GetContent"
+	ContentService_GetPublishedContent_FullMethodName = "/ezzygo.v1.ContentService/GetPublishedContent"
+	ContentService_ListContents_FullMethodName        = "/ezzygo.v1.ContentService/ListContents"
+	ContentService_ExportContents_FullMethodName      = "/ezzygo.v1.ContentService/ExportContents"
+)
+
+// ContentServiceClient is the client API for ContentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ContentService serves the CMS content. Every call needs the x-api-key
+// metadata. GetPublishedContent sees only delivered content, like
+// /api/v1/content/{slug}; the other calls see every status and also need a
+// bearer token in authorization, like /api/v1/cms/content.
+type ContentServiceClient interface {
+	GetContent(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Content, error)
+	GetPublishedContent(ctx context.Context, in *GetPublishedContentRequest, opts ...grpc.CallOption) (*Content, error)
+	ListContents(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListContentsResponse, error)
+	// ExportContents streams every content matching the filter, in id order.
+	ExportContents(ctx context.Context, in *ExportContentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Content], error)
+}
+
+type contentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContentServiceClient(cc grpc.ClientConnInterface) ContentServiceClient {
+	return &contentServiceClient{cc}
+}
+
+func (c *contentServiceClient) GetContent(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Content, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Content)
+	err := c.cc.Invoke(ctx, ContentService_GetContent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceClient) GetPublishedContent(ctx context.Context, in *GetPublishedContentRequest, opts ...grpc.CallOption) (*Content, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Content)
+	err := c.cc.Invoke(ctx, ContentService_GetPublishedContent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceClient) ListContents(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListContentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListContentsResponse)
+	err := c.cc.Invoke(ctx, ContentService_ListContents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceClient) ExportContents(ctx context.Context, in *ExportContentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Content], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ContentService_ServiceDesc.Streams[0], ContentService_ExportContents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportContentsRequest, Content]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContentService_ExportContentsClient = grpc.ServerStreamingClient[Content]
+
+// ContentServiceServer is the server API for ContentService service.
+// All implementations must embed UnimplementedContentServiceServer
+// for forward compatibility.
+//
+// ContentService serves the CMS content. Every call needs the x-api-key
+// metadata. GetPublishedContent sees only delivered content, like
+// /api/v1/content/{slug}; the other calls see every status and also need a
+// bearer token in authorization, like /api/v1/cms/content.
+type ContentServiceServer interface {
+	GetContent(context.Context, *GetRequest) (*Content, error)
+	GetPublishedContent(context.Context, *GetPublishedContentRequest) (*Content, error)
+	ListContents(context.Context, *ListRequest) (*ListContentsResponse, error)
+	// ExportContents streams every content matching the filter, in id order.
+	ExportContents(*ExportContentsRequest, grpc.ServerStreamingServer[Content]) error
+	mustEmbedUnimplementedContentServiceServer()
+}
+
+// UnimplementedContentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedContentServiceServer struct{}
+
+func (UnimplementedContentServiceServer) GetContent(context.Context, *GetRequest) (*Content, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContent not implemented")
+}
+func (UnimplementedContentServiceServer) GetPublishedContent(context.Context, *GetPublishedContentRequest) (*Content, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublishedContent not implemented")
+}
+func (UnimplementedContentServiceServer) ListContents(context.Context, *ListRequest) (*ListContentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListContents not implemented")
+}
+func (UnimplementedContentServiceServer) ExportContents(*ExportContentsRequest, grpc.ServerStreamingServer[Content]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportContents not implemented")
+}
+func (UnimplementedContentServiceServer) mustEmbedUnimplementedContentServiceServer() {}
+func (UnimplementedContentServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeContentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContentServiceServer will
+// result in compilation errors.
+type UnsafeContentServiceServer interface {
+	mustEmbedUnimplementedContentServiceServer()
+}
+
+func RegisterContentServiceServer(s grpc.ServiceRegistrar, srv ContentServiceServer) {
+	// If the following call pancis, it indicates UnimplementedContentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ContentService_ServiceDesc, srv)
+}
+
+func _ContentService_GetContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceServer).GetContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentService_GetContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceServer).GetContent(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentService_GetPublishedContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublishedContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceServer).GetPublishedContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentService_GetPublishedContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceServer).GetPublishedContent(ctx, req.(*GetPublishedContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentService_ListContents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceServer).ListContents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentService_ListContents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceServer).ListContents(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentService_ExportContents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportContentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ContentServiceServer).ExportContents(m, &grpc.GenericServerStream[ExportContentsRequest, Content]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ContentService_ExportContentsServer = grpc.ServerStreamingServer[Content]
+
+// ContentService_ServiceDesc is the grpc.ServiceDesc for ContentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ContentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ezzygo.v1.ContentService",
+	HandlerType: (*ContentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetContent",
+			Handler:    _ContentService_GetContent_Handler,
+		},
+		{
+			MethodName: "GetPublishedContent",
+			Handler:    _ContentService_GetPublishedContent_Handler,
+		},
+		{
+			MethodName: "ListContents",
+			Handler:    _ContentService_ListContents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportContents",
+			Handler:       _ContentService_ExportContents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ezzygo/v1/content.proto",
+}
@@ -0,0 +1,31 @@
+// Package rpc holds the gRPC services of proto/ezzygo/v1: the messages and
+// service stubs generated by protoc-gen-go and protoc-gen-go-grpc (make generate-proto)
+// and the interceptors and listener shared by the server.
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ezzygo/v1/common.proto ezzygo/v1/book.proto ezzygo/v1/content.proto ezzygo/v1/media.proto
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// ListenAndServe serves server on addr until ctx is done, then stops it
+// gracefully.
+func ListenAndServe(ctx context.Context, server *grpc.Server, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
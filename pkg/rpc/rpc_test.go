@@ -0,0 +1,156 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type testBooks struct {
+	UnimplementedBookServiceServer
+}
+
+func (testBooks) GetBook(ctx context.Context, req *GetRequest) (*Book, error) {
+	if req.Id != 1 {
+		return nil, status.Errorf(codes.NotFound, "book %d not found", req.Id)
+	}
+	return &Book{Id: 1, Title: "Dune", Author: "Frank Herbert", CreatedAt: timestamppb.New(time.Unix(1700000000, 5))}, nil
+}
+
+func (testBooks) ListBooks(ctx context.Context, req *ListRequest) (*ListBooksResponse, error) {
+	total := int64(0)
+	return &ListBooksResponse{Page: &PageInfo{Limit: req.Limit, Total: &total}}, nil
+}
+
+func (testBooks) CreateBook(ctx context.Context, req *CreateBookRequest) (*Book, error) {
+	return nil, errors.New("database is down")
+}
+
+type testContents struct {
+	UnimplementedContentServiceServer
+}
+
+func (testContents) ExportContents(req *ExportContentsRequest, stream grpc.ServerStreamingServer[Content]) error {
+	for i := int32(1); i <= req.BatchSize; i++ {
+		if err := stream.Send(&Content{Id: uint64(i), Slug: req.Filter["slug"]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTestClient serves the test services in memory and returns a client
+// connection to them.
+func newTestClient(t *testing.T, opts ...grpc.ServerOption) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	RegisterBookServiceServer(server, testBooks{})
+	RegisterContentServiceServer(server, testContents{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUnaryCall(t *testing.T) {
+	books := NewBookServiceClient(newTestClient(t))
+
+	book, err := books.GetBook(context.Background(), &GetRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), book.Id)
+	assert.Equal(t, "Dune", book.Title)
+	assert.True(t, book.CreatedAt.AsTime().Equal(time.Unix(1700000000, 5)))
+
+	// A zero total is still present because the field is optional
+	page, err := books.ListBooks(context.Background(), &ListRequest{Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), page.Page.Limit)
+	require.NotNil(t, page.Page.Total)
+	assert.Equal(t, int64(0), *page.Page.Total)
+}
+
+func TestStatusErrors(t *testing.T) {
+	books := NewBookServiceClient(newTestClient(t))
+
+	_, err := books.GetBook(context.Background(), &GetRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "book 2 not found", status.Convert(err).Message())
+
+	// Errors without a status are reported as Unknown
+	_, err = books.CreateBook(context.Background(), &CreateBookRequest{})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+
+	_, err = NewContentServiceClient(newTestClient(t)).GetContent(context.Background(), &GetRequest{Id: 1})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestServerStream(t *testing.T) {
+	contents := NewContentServiceClient(newTestClient(t))
+
+	stream, err := contents.ExportContents(context.Background(), &ExportContentsRequest{
+		Filter:    map[string]string{"slug": "hello"},
+		BatchSize: 3,
+	})
+	require.NoError(t, err)
+
+	var received []*Content
+	for {
+		content, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received = append(received, content)
+	}
+	require.Len(t, received, 3)
+	for i, content := range received {
+		assert.Equal(t, uint64(i+1), content.Id)
+		assert.Equal(t, "hello", content.Slug)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	t.Setenv("API_SECRET_KEY", "secret")
+	conn := newTestClient(t, Auth(func(method string) bool {
+		return method == BookService_CreateBook_FullMethodName ||
+			method == ContentService_ExportContents_FullMethodName
+	})...)
+	books := NewBookServiceClient(conn)
+	withKey := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+
+	_, err := books.GetBook(context.Background(), &GetRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = books.GetBook(withKey, &GetRequest{Id: 1})
+	assert.NoError(t, err)
+
+	_, err = books.CreateBook(withKey, &CreateBookRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "missing bearer token", status.Convert(err).Message())
+
+	// Streaming calls go through the same check
+	stream, err := NewContentServiceClient(conn).ExportContents(withKey, &ExportContentsRequest{BatchSize: 1})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}